@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 
 	"gitlab.com/NebulousLabs/Sia/types"
@@ -18,6 +19,16 @@ var (
 		// Run field not provided; export requires a subcommand.
 	}
 
+	renterExportContractHistoryCmd = &cobra.Command{
+		Use:   "contract-history [destination]",
+		Short: "export the financial history of the renter's contracts",
+		Long: "Export the financial history of all contracts the renter has formed to the " +
+			"specified file. The history lists the spending and remaining funds of each " +
+			"contract after every block it was revised in, and the payout of contracts " +
+			"that have resolved. Use --format to choose between JSON and CSV.",
+		Run: wrap(renterexportcontracthistorycmd),
+	}
+
 	renterExportContractTxnsCmd = &cobra.Command{
 		Use:   "contract-txns [destination]",
 		Short: "export the renter's contracts for import to `https://rankings.sia.tech/`",
@@ -49,3 +60,34 @@ func renterexportcontracttxnscmd(destination string) {
 	}
 	fmt.Println("Exported contract data to", destination)
 }
+
+// renterexportcontracthistorycmd is the handler for the command `siac renter
+// export contract-history`. Exports the financial history of the renter's
+// contracts to JSON or CSV.
+func renterexportcontracthistorycmd(destination string) {
+	var data []byte
+	switch renterExportFormat {
+	case "json":
+		rch, err := httpClient.RenterContractHistoryGet()
+		if err != nil {
+			die("Could not retrieve contract history:", err)
+		}
+		data, err = json.MarshalIndent(rch.Contracts, "", "  ")
+		if err != nil {
+			die("Could not encode contract history:", err)
+		}
+	case "csv":
+		var err error
+		data, err = httpClient.RenterContractHistoryCSVGet()
+		if err != nil {
+			die("Could not retrieve contract history:", err)
+		}
+	default:
+		die("Unknown export format, must be json or csv:", renterExportFormat)
+	}
+	destination = abs(destination)
+	if err := ioutil.WriteFile(destination, data, 0600); err != nil {
+		die("Could not export to file:", err)
+	}
+	fmt.Println("Exported contract history to", destination)
+}
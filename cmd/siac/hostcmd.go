@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"gitlab.com/NebulousLabs/Sia/crypto"
 	"gitlab.com/NebulousLabs/Sia/modules"
@@ -48,8 +49,22 @@ Available settings:
      maxdownloadbatchsize: bytes
      maxrevisebatchsize:   bytes
      netaddress:           string
+     sectorscrubrate:      bytes / second
      windowsize:           blocks
 
+     maxconnections:          connections
+     maxconnectionsperpeer:   connections
+     maxrpcrate:              RPCs / minute
+     maxrpcrateperpeer:       RPCs / minute
+     maxdownloadspeed:        bytes / second
+     maxdownloadspeedperpeer: bytes / second
+     maxuploadspeed:          bytes / second
+     maxuploadspeedperpeer:   bytes / second
+
+     folderhealthminoperations: reads or writes
+     foldermaxfailedreadrate:   fraction between 0 and 1
+     foldermaxfailedwriterate:  fraction between 0 and 1
+
      collateral:       currency
      collateralbudget: currency
      maxcollateral:    currency
@@ -61,6 +76,14 @@ Available settings:
 
 Currency units can be specified, e.g. 10SC; run 'siac help wallet' for details.
 
+Limits are disabled when set to 0. Per-peer limits apply to each IP address
+and to each renter separately. Speeds can be specified with units, e.g. 10MB.
+
+A storage folder is degraded once the fraction of its reads or writes that
+failed exceeds foldermaxfailedreadrate or foldermaxfailedwriterate. Degraded
+storage folders receive no new sectors and are emptied in the background. The
+rates are only checked after folderhealthminoperations reads or writes.
+
 Durations (maxduration and windowsize) must be specified in either blocks (b),
 hours (h), days (d), or weeks (w). A block is approximately 10 minutes, so one
 hour is six blocks, a day is 144 blocks, and a week is 1008 blocks.
@@ -94,8 +117,18 @@ Available output types:
 
 	hostFolderCmd = &cobra.Command{
 		Use:   "folder",
-		Short: "Add, remove, or resize a storage folder",
-		Long:  "Add, remove, or resize a storage folder.",
+		Short: "Add, move, remove, or resize a storage folder",
+		Long:  "Add, move, remove, or resize a storage folder, or reset its health statistics.",
+	}
+
+	hostFolderMoveCmd = &cobra.Command{
+		Use:   "move [path] [newpath]",
+		Short: "Move a storage folder to a new path",
+		Long: `Move a storage folder to a new path, for example on a different disk. The
+data is copied to the new path while the host keeps running, and the folder is
+only switched over once the copy is complete. The new path must be an existing,
+empty folder.`,
+		Run: wrap(hostfoldermovecmd),
 	}
 
 	hostFolderRemoveCmd = &cobra.Command{
@@ -107,6 +140,15 @@ data; it will instead be distributed across the remaining storage folders.`,
 		Run: wrap(hostfolderremovecmd),
 	}
 
+	hostFolderResetHealthCmd = &cobra.Command{
+		Use:   "resethealth [path]",
+		Short: "Reset the health statistics of a storage folder",
+		Long: `Reset the read and write statistics of a storage folder. A degraded storage
+folder is marked healthy again and will receive new sectors. Only reset a
+storage folder once the underlying disk problem has been fixed.`,
+		Run: wrap(hostfolderresethealthcmd),
+	}
+
 	hostFolderResizeCmd = &cobra.Command{
 		Use:   "resize [path] [size]",
 		Short: "Resize a storage folder",
@@ -116,11 +158,116 @@ other storage folders.`,
 		Run: wrap(hostfolderresizecmd),
 	}
 
+	hostMaintenanceCmd = &cobra.Command{
+		Use:   "maintenance",
+		Short: "View the scheduled maintenance",
+		Long: `View the scheduled maintenance of the host and the contracts whose storage
+proof window falls inside the maintenance. The host has to be online during
+the proof window of a contract to submit its storage proof.`,
+		Run: wrap(hostmaintenancecmd),
+	}
+
+	hostMaintenanceCancelCmd = &cobra.Command{
+		Use:   "cancel",
+		Short: "Cancel the scheduled maintenance",
+		Long:  "Cancel the scheduled maintenance of the host.",
+		Run:   wrap(hostmaintenancecancelcmd),
+	}
+
+	hostMaintenanceScheduleCmd = &cobra.Command{
+		Use:   "schedule [start] [end]",
+		Short: "Schedule maintenance",
+		Long: `Schedule maintenance of the host from block height start up to and including
+block height end, replacing any maintenance that was scheduled before.
+
+During maintenance the host does not accept new contracts, renewals or
+uploads, but keeps serving downloads and submitting storage proofs. With
+--announce, the host stops advertising that it accepts contracts right away
+instead of when the maintenance starts.`,
+		Run: wrap(hostmaintenanceschedulecmd),
+	}
+
+	hostPricingCmd = &cobra.Command{
+		Use:   "pricing",
+		Short: "View the pricing engine",
+		Long: `View the policy of the host's pricing engine and a dry run of the prices
+that it would apply right now. When enabled, the pricing engine derives the
+host's prices from the prices of the other hosts on the network.`,
+		Run: wrap(hostpricingcmd),
+	}
+
+	hostPricingConfigCmd = &cobra.Command{
+		Use:   "config [setting] [value]",
+		Short: "Modify the pricing engine",
+		Long: `Modify the policy of the host's pricing engine.
+
+Available settings:
+     enabled:         boolean
+     fillrules:       list of utilization:multiplier, e.g. 0.5:1.2,0.9:2
+     percentile:      number between 0 and 100
+     updateinterval:  blocks
+     maxstorageprice: currency / TB / Month, 0 for no maximum
+     minstorageprice: currency / TB / Month
+
+The pricing engine sets the host's prices to the given percentile of the
+prices of the other hosts every updateinterval. The storage price is
+multiplied by the fill rule with the highest utilization that the host's
+storage has reached and kept between minstorageprice and maxstorageprice.
+While the engine is enabled, it overwrites the prices set with 'siac host
+config'.
+
+To raise the storage price by half once the host is 80% full:
+	siac host pricing config fillrules 0.8:1.5
+`,
+		Run: wrap(hostpricingconfigcmd),
+	}
+
+	hostPricingHistoryCmd = &cobra.Command{
+		Use:   "history",
+		Short: "Show the price changes of the pricing engine",
+		Long:  "Show the price changes that were made by the host's pricing engine.",
+		Run:   wrap(hostpricinghistorycmd),
+	}
+
+	hostReportCmd = &cobra.Command{
+		Use:   "report",
+		Short: "Show the host's finances by time range",
+		Long: `Show the host's revenue, fees and collateral for each day, week or month of a
+time range, or the profit and loss of each storage obligation that was
+resolved within the time range.
+
+The range is given with --from and --to as dates in the format YYYY-MM-DD,
+and defaults to the last 30 days. Use --format csv to export the report.`,
+		Run: wrap(hostreportcmd),
+	}
+
+	hostSectorAuditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "Cross-check the host's contracts against its stored sectors",
+		Long: `Cross-check the sectors referenced by the host's contracts against the
+sectors stored in the host's storage folders. Missing sectors are referenced by
+a contract but not stored, and will cause the host to fail storage proofs.
+Miscounted sectors are stored a different number of times than they are
+referenced. Orphaned sectors are stored but not referenced by any contract, and
+take up space without earning revenue. They are listed by their storage ID and
+storage folder, because the host doesn't know their Merkle roots.`,
+		Run: wrap(hostsectorauditcmd),
+	}
+
 	hostSectorCmd = &cobra.Command{
 		Use:   "sector",
-		Short: "Add or delete a sector (add not supported)",
-		Long: `Add or delete a sector. Adding is not currently supported. Note that
-deleting a sector may impact host revenue.`,
+		Short: "List, audit or delete sectors",
+		Long: `List, audit or delete the sectors stored by the host. Note that deleting a
+sector may impact host revenue.`,
+	}
+
+	hostSectorListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the sectors referenced by the host's contracts",
+		Long: `List the sectors referenced by the host's contracts, together with the
+number of times each sector is stored, the contracts that reference it and the
+storage folder it is stored in.`,
+		Run: wrap(hostsectorlistcmd),
 	}
 
 	hostSectorDeleteCmd = &cobra.Command{
@@ -208,6 +355,19 @@ Host Internal Settings:
 	minstorageprice:           %v / TB / Month
 	minuploadbandwidthprice:   %v / TB
 
+	maxconnections:          %v
+	maxconnectionsperpeer:   %v
+	maxrpcrate:              %v
+	maxrpcrateperpeer:       %v
+	maxdownloadspeed:        %v
+	maxdownloadspeedperpeer: %v
+	maxuploadspeed:          %v
+	maxuploadspeedperpeer:   %v
+
+	folderhealthminoperations: %v
+	foldermaxfailedreadrate:   %v
+	foldermaxfailedwriterate:  %v
+
 Host Financials:
 	Contract Count:               %v
 	Transaction Fee Compensation: %v
@@ -234,6 +394,9 @@ RPC Stats:
 	Revise Calls:       %v
 	Settings Calls:     %v
 	FormContract Calls: %v
+
+	Rejected Connections: %v
+	Rejected RPCs:        %v
 `,
 			connectabilityString,
 
@@ -251,6 +414,18 @@ RPC Stats:
 			currencyUnits(is.MinStoragePrice.Mul(modules.BlockBytesPerMonthTerabyte)),
 			currencyUnits(is.MinUploadBandwidthPrice.Mul(modules.BytesPerTerabyte)),
 
+			limitString(int64(is.MaxConnections), fmt.Sprint(is.MaxConnections)),
+			limitString(int64(is.MaxConnectionsPerPeer), fmt.Sprint(is.MaxConnectionsPerPeer)),
+			limitString(int64(is.MaxRPCRate), fmt.Sprintf("%v / Minute", is.MaxRPCRate)),
+			limitString(int64(is.MaxRPCRatePerPeer), fmt.Sprintf("%v / Minute", is.MaxRPCRatePerPeer)),
+			limitString(is.MaxDownloadSpeed, filesizeUnits(is.MaxDownloadSpeed)+"/s"),
+			limitString(is.MaxDownloadSpeedPerPeer, filesizeUnits(is.MaxDownloadSpeedPerPeer)+"/s"),
+			limitString(is.MaxUploadSpeed, filesizeUnits(is.MaxUploadSpeed)+"/s"),
+			limitString(is.MaxUploadSpeedPerPeer, filesizeUnits(is.MaxUploadSpeedPerPeer)+"/s"),
+
+			is.FolderHealthMinOperations, is.FolderMaxFailedReadRate,
+			is.FolderMaxFailedWriteRate,
+
 			fm.ContractCount, currencyUnits(fm.ContractCompensation),
 			currencyUnits(fm.PotentialContractCompensation),
 			currencyUnits(fm.TransactionFeeExpenses),
@@ -269,7 +444,9 @@ RPC Stats:
 
 			nm.ErrorCalls, nm.UnrecognizedCalls, nm.DownloadCalls,
 			nm.RenewCalls, nm.ReviseCalls, nm.SettingsCalls,
-			nm.FormContractCalls)
+			nm.FormContractCalls,
+
+			nm.RejectedConnections, nm.RejectedRPCs)
 	} else {
 		fmt.Printf(`Host info:
 	Connectability Status: %v
@@ -303,6 +480,14 @@ RPC Stats:
 		fmt.Println("\nWarning:\n	Your wallet is locked. You must unlock your wallet for the host to function properly.")
 	}
 
+	// print any alerts raised by the host
+	if len(hg.Alerts) > 0 {
+		fmt.Println("\nAlerts:")
+		for _, alert := range hg.Alerts {
+			fmt.Printf("	%v: %v (%v)\n", alert.Severity, alert.Msg, alert.Cause)
+		}
+	}
+
 	fmt.Println("\nStorage Folders:")
 
 	// display storage folder info
@@ -318,11 +503,23 @@ RPC Stats:
 	for _, folder := range sg.Folders {
 		curSize := int64(folder.Capacity - folder.CapacityRemaining)
 		pctUsed := 100 * (float64(curSize) / float64(folder.Capacity))
-		fmt.Fprintf(w, "\t%s\t%s\t%.2f\t%s\n", filesizeUnits(curSize), filesizeUnits(int64(folder.Capacity)), pctUsed, folder.Path)
+		path := folder.Path
+		if folder.Degraded {
+			path += " (degraded)"
+		}
+		fmt.Fprintf(w, "\t%s\t%s\t%.2f\t%s\n", filesizeUnits(curSize), filesizeUnits(int64(folder.Capacity)), pctUsed, path)
 	}
 	w.Flush()
 }
 
+// limitString returns s, or "unlimited" if the limit is disabled.
+func limitString(limit int64, s string) string {
+	if limit == 0 {
+		return "unlimited"
+	}
+	return s
+}
+
 // hostconfigcmd is the handler for the command `siac host config [setting] [value]`.
 // Modifies host settings.
 func hostconfigcmd(param, value string) {
@@ -371,8 +568,17 @@ func hostconfigcmd(param, value string) {
 			die("Could not parse "+param+":", err)
 		}
 
+	// speed (convert to bytes/second)
+	case "maxdownloadspeed", "maxdownloadspeedperpeer", "maxuploadspeed", "maxuploadspeedperpeer", "sectorscrubrate":
+		value, err = parseFilesize(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+
 	// other valid settings
-	case "maxdownloadbatchsize", "maxrevisebatchsize", "netaddress":
+	case "maxdownloadbatchsize", "maxrevisebatchsize", "netaddress",
+		"maxconnections", "maxconnectionsperpeer", "maxrpcrate", "maxrpcrateperpeer",
+		"folderhealthminoperations", "foldermaxfailedreadrate", "foldermaxfailedwriterate":
 
 	// invalid settings
 	default:
@@ -396,6 +602,228 @@ func hostconfigcmd(param, value string) {
 	fmt.Printf("Estimated conversion rate: %v%%\n", eg.ConversionRate)
 }
 
+// hostpricingcmd is the handler for the command `siac host pricing`.
+// Prints the pricing policy and a dry run of the pricing engine.
+func hostpricingcmd() {
+	hpg, err := httpClient.HostPricingGet()
+	if err != nil {
+		die("Could not fetch the pricing policy:", err)
+	}
+	p := hpg.Policy
+	rules := make([]string, 0, len(p.FillRules))
+	for _, rule := range p.FillRules {
+		rules = append(rules, fmt.Sprintf("%v%% full: x%v", rule.Utilization*100, rule.Multiplier))
+	}
+	maxPrice := "none"
+	if !p.MaxStoragePrice.IsZero() {
+		maxPrice = currencyUnits(p.MaxStoragePrice.Mul(modules.BlockBytesPerMonthTerabyte)) + " / TB / Month"
+	}
+	fmt.Printf(`Pricing Policy:
+	enabled:         %v
+	percentile:      %v
+	updateinterval:  %v blocks
+	fillrules:       %v
+	minstorageprice: %v / TB / Month
+	maxstorageprice: %v
+`, yesNo(p.Enabled), p.Percentile, p.UpdateInterval, strings.Join(rules, ", "),
+		currencyUnits(p.MinStoragePrice.Mul(modules.BlockBytesPerMonthTerabyte)), maxPrice)
+
+	est, err := httpClient.HostPricingEstimateGet()
+	if err != nil {
+		fmt.Println("\nNo price estimate available:", err)
+		return
+	}
+	fmt.Printf("\nEstimate (%v hosts, %.2f%% storage used, storage price x%v):\n", est.MarketHosts, est.StorageUtilization*100, est.FillMultiplier)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tCurrent\tProposed")
+	printHostPrices(w, est.Current, est.Proposed)
+	w.Flush()
+}
+
+// printHostPrices prints two sets of host prices side by side.
+func printHostPrices(w *tabwriter.Writer, a, b modules.HostPrices) {
+	fmt.Fprintf(w, "Collateral\t%v / TB / Month\t%v / TB / Month\n",
+		currencyUnits(a.Collateral.Mul(modules.BlockBytesPerMonthTerabyte)),
+		currencyUnits(b.Collateral.Mul(modules.BlockBytesPerMonthTerabyte)))
+	fmt.Fprintf(w, "Contract Price\t%v\t%v\n", currencyUnits(a.MinContractPrice), currencyUnits(b.MinContractPrice))
+	fmt.Fprintf(w, "Download Price\t%v / TB\t%v / TB\n",
+		currencyUnits(a.MinDownloadBandwidthPrice.Mul(modules.BytesPerTerabyte)),
+		currencyUnits(b.MinDownloadBandwidthPrice.Mul(modules.BytesPerTerabyte)))
+	fmt.Fprintf(w, "Storage Price\t%v / TB / Month\t%v / TB / Month\n",
+		currencyUnits(a.MinStoragePrice.Mul(modules.BlockBytesPerMonthTerabyte)),
+		currencyUnits(b.MinStoragePrice.Mul(modules.BlockBytesPerMonthTerabyte)))
+	fmt.Fprintf(w, "Upload Price\t%v / TB\t%v / TB\n",
+		currencyUnits(a.MinUploadBandwidthPrice.Mul(modules.BytesPerTerabyte)),
+		currencyUnits(b.MinUploadBandwidthPrice.Mul(modules.BytesPerTerabyte)))
+}
+
+// hostpricingconfigcmd is the handler for the command `siac host pricing
+// config [setting] [value]`. Modifies the pricing policy.
+func hostpricingconfigcmd(param, value string) {
+	hpg, err := httpClient.HostPricingGet()
+	if err != nil {
+		die("Could not fetch the pricing policy:", err)
+	}
+	policy := hpg.Policy
+	switch param {
+	case "enabled":
+		switch strings.ToLower(value) {
+		case "yes", "true":
+			policy.Enabled = true
+		case "no", "false":
+			policy.Enabled = false
+		default:
+			die("Could not parse enabled: must be true or false")
+		}
+	case "fillrules":
+		policy.FillRules = nil
+		for _, r := range strings.Split(value, ",") {
+			if r == "" {
+				continue
+			}
+			var rule modules.HostPricingFillRule
+			if _, err := fmt.Sscanf(r, "%g:%g", &rule.Utilization, &rule.Multiplier); err != nil {
+				die("Could not parse fill rule "+r+":", err)
+			}
+			policy.FillRules = append(policy.FillRules, rule)
+		}
+	case "percentile":
+		if _, err := fmt.Sscan(value, &policy.Percentile); err != nil {
+			die("Could not parse percentile:", err)
+		}
+	case "updateinterval":
+		blocks, err := parsePeriod(value)
+		if err != nil {
+			die("Could not parse updateinterval:", err)
+		}
+		if _, err := fmt.Sscan(blocks, &policy.UpdateInterval); err != nil {
+			die("Could not parse updateinterval:", err)
+		}
+	case "maxstorageprice", "minstorageprice":
+		hastings, err := parseCurrency(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+		i, _ := new(big.Int).SetString(hastings, 10)
+		price := types.NewCurrency(i).Div(modules.BlockBytesPerMonthTerabyte)
+		if param == "maxstorageprice" {
+			policy.MaxStoragePrice = price
+		} else {
+			policy.MinStoragePrice = price
+		}
+	default:
+		die("\"" + param + "\" is not a pricing setting")
+	}
+	err = httpClient.HostPricingPost(policy)
+	if err != nil {
+		die("Failed to update the pricing policy:", err)
+	}
+	fmt.Println("Pricing policy updated.")
+}
+
+// hostpricinghistorycmd is the handler for the command `siac host pricing
+// history`.
+func hostpricinghistorycmd() {
+	hphg, err := httpClient.HostPricingHistoryGet()
+	if err != nil {
+		die("Could not fetch the price history:", err)
+	}
+	if len(hphg.History) == 0 {
+		fmt.Println("The pricing engine has not changed any prices.")
+		return
+	}
+	for _, change := range hphg.History {
+		fmt.Printf("%v (height %v, %v hosts, %.2f%% storage used, storage price x%v):\n", change.Timestamp.Format(time.RFC822),
+			change.BlockHeight, change.MarketHosts, change.StorageUtilization*100, change.FillMultiplier)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\tOld\tNew")
+		printHostPrices(w, change.OldPrices, change.NewPrices)
+		w.Flush()
+		fmt.Println()
+	}
+}
+
+// hostreportcmd is the handler for the command `siac host report`. Shows the
+// finances of the host by time range.
+func hostreportcmd() {
+	to := time.Now()
+	if hostReportTo != "" {
+		t, err := time.ParseInLocation("2006-01-02", hostReportTo, time.Local)
+		if err != nil {
+			die("Could not parse --to:", err)
+		}
+		// Include the whole day.
+		to = t.AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -30)
+	if hostReportFrom != "" {
+		t, err := time.ParseInLocation("2006-01-02", hostReportFrom, time.Local)
+		if err != nil {
+			die("Could not parse --from:", err)
+		}
+		from = t
+	}
+	interval := hostReportInterval
+	if interval == "all" {
+		interval = ""
+	}
+
+	data := "periods"
+	if hostReportObligations {
+		data = "obligations"
+	}
+	switch hostReportFormat {
+	case "table":
+	case "csv":
+		csv, err := httpClient.HostMetricsCSVGet(from, to, interval, data)
+		if err != nil {
+			die("Could not fetch the financial report:", err)
+		}
+		os.Stdout.Write(csv)
+		return
+	default:
+		die("Unknown report format, must be table or csv:", hostReportFormat)
+	}
+
+	hmg, err := httpClient.HostMetricsGet(from, to, interval)
+	if err != nil {
+		die("Could not fetch the financial report:", err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if hostReportObligations {
+		if len(hmg.Obligations) == 0 {
+			fmt.Println("No storage obligations were resolved in this time range.")
+			return
+		}
+		fmt.Fprintln(w, "Obligation ID\tStatus\tResolution Height\tRevenue\tFees\tLost Collateral\tNet")
+		for _, o := range hmg.Obligations {
+			costs := o.TransactionFees.Add(o.LostCollateral)
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", o.ObligationId, o.ObligationStatus, o.ResolutionHeight,
+				currencyUnits(o.Revenue), currencyUnits(o.TransactionFees), currencyUnits(o.LostCollateral),
+				netCurrencyUnits(o.Revenue, costs))
+		}
+		w.Flush()
+		return
+	}
+	fmt.Fprintln(w, "Period Start\tContracts\tRevenue\tFees\tLost Collateral\tLocked Collateral\tNet")
+	for _, p := range hmg.Periods {
+		costs := p.TransactionFeeExpenses.Add(p.LostStorageCollateral)
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", p.Start.Format("2006-01-02 15:04"), p.ContractCount,
+			currencyUnits(p.TotalRevenue), currencyUnits(p.TransactionFeeExpenses), currencyUnits(p.LostStorageCollateral),
+			currencyUnits(p.LockedStorageCollateral), netCurrencyUnits(p.TotalRevenue, costs))
+	}
+	w.Flush()
+}
+
+// netCurrencyUnits returns the difference between revenue and costs, which
+// may be negative, in human-readable units.
+func netCurrencyUnits(revenue, costs types.Currency) string {
+	if revenue.Cmp(costs) < 0 {
+		return "-" + currencyUnits(costs.Sub(revenue))
+	}
+	return currencyUnits(revenue.Sub(costs))
+}
+
 // hostcontractcmd is the handler for the command `siac host contracts [type]`.
 func hostcontractcmd() {
 	cg, err := httpClient.HostContractInfoGet()
@@ -472,7 +900,25 @@ func hostfolderaddcmd(path, size string) {
 	fmt.Println("Added folder", path)
 }
 
-// hostfolderremovecmd removes a folder from the host.
+// hostfoldermovecmd moves a folder in the host to a new path.
+func hostfoldermovecmd(path, newpath string) {
+	err := httpClient.HostStorageFoldersMovePost(abs(path), abs(newpath))
+	if err != nil {
+		die("Could not move folder:", err)
+	}
+	fmt.Printf("Moved folder %v to %v\n", path, newpath)
+}
+
+// hostfolderresethealthcmd resets the health statistics of a folder in the
+// host.
+func hostfolderresethealthcmd(path string) {
+	err := httpClient.HostStorageFoldersResetHealthPost(abs(path))
+	if err != nil {
+		die("Could not reset folder health:", err)
+	}
+	fmt.Println("Reset health of folder", path)
+}
+
 func hostfolderremovecmd(path string) {
 	err := httpClient.HostStorageFoldersRemovePost(abs(path))
 	if err != nil {
@@ -501,6 +947,135 @@ func hostfolderresizecmd(path, newsize string) {
 }
 
 // hostsectordeletecmd deletes a sector from the host.
+// hostsectorauditcmd is the handler for the command `siac host sector audit`.
+// Cross-checks the host's contracts against its stored sectors.
+func hostsectorauditcmd() {
+	audit, err := httpClient.HostStorageSectorsAuditGet()
+	if err != nil {
+		die("Could not audit sectors:", err)
+	}
+	fmt.Printf(`Referenced Sectors: %v
+Missing Sectors:    %v
+Miscounted Sectors: %v
+Orphaned Sectors:   %v
+`, audit.ReferencedSectors, len(audit.MissingSectors), len(audit.MiscountedSectors), len(audit.OrphanedSectors))
+
+	if len(audit.MissingSectors) > 0 {
+		fmt.Println("\nMissing Sectors:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+		fmt.Fprintf(w, "Root\tContracts\n")
+		for _, sector := range audit.MissingSectors {
+			fmt.Fprintf(w, "%v\t%v\n", sector.Root, len(sector.Obligations))
+		}
+		w.Flush()
+	}
+	if len(audit.MiscountedSectors) > 0 {
+		fmt.Println("\nMiscounted Sectors:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+		fmt.Fprintf(w, "Root\tStored\tReferenced\n")
+		for _, sector := range audit.MiscountedSectors {
+			fmt.Fprintf(w, "%v\t%v\t%v\n", sector.Root, sector.Count, sector.References)
+		}
+		w.Flush()
+	}
+	if len(audit.OrphanedSectors) > 0 {
+		fmt.Println("\nOrphaned Sectors:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+		fmt.Fprintf(w, "ID\tStored\tFolder\n")
+		for _, sector := range audit.OrphanedSectors {
+			fmt.Fprintf(w, "%v\t%v\t%v\n", sector.ID, sector.Count, sector.FolderPath)
+		}
+		w.Flush()
+	}
+}
+
+// hostmaintenancecmd is the handler for the command `siac host maintenance`.
+// Prints the scheduled maintenance and the contracts that conflict with it.
+func hostmaintenancecmd() {
+	hmg, err := httpClient.HostMaintenanceGet()
+	if err != nil {
+		die("Could not fetch the scheduled maintenance:", err)
+	}
+	if !hmg.Scheduled {
+		fmt.Println("No maintenance scheduled")
+		return
+	}
+	fmt.Printf(`Maintenance:
+	start:    %v
+	end:      %v
+	announce: %v
+	active:   %v
+	safe:     %v
+`, hmg.Start, hmg.End, yesNo(hmg.Announce), yesNo(hmg.Active), yesNo(hmg.Safe))
+
+	if len(hmg.Conflicts) > 0 {
+		fmt.Println("\nContracts with a proof window during maintenance:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+		fmt.Fprintf(w, "Obligation Id\tWindow Start\tWindow End\n")
+		for _, c := range hmg.Conflicts {
+			fmt.Fprintf(w, "%v\t%v\t%v\n", c.ObligationID, c.ExpirationHeight, c.ProofDeadline)
+		}
+		w.Flush()
+	}
+}
+
+// hostmaintenancecancelcmd is the handler for the command `siac host
+// maintenance cancel`. Cancels the scheduled maintenance.
+func hostmaintenancecancelcmd() {
+	err := httpClient.HostMaintenanceCancelPost()
+	if err != nil {
+		die("Could not cancel the scheduled maintenance:", err)
+	}
+	fmt.Println("Scheduled maintenance cancelled.")
+}
+
+// hostmaintenanceschedulecmd is the handler for the command `siac host
+// maintenance schedule [start] [end]`. Schedules maintenance of the host.
+func hostmaintenanceschedulecmd(start, end string) {
+	m := modules.HostMaintenance{
+		Announce: hostMaintenanceAnnounce,
+	}
+	if _, err := fmt.Sscan(start, &m.Start); err != nil {
+		die("Could not parse start:", err)
+	}
+	if _, err := fmt.Sscan(end, &m.End); err != nil {
+		die("Could not parse end:", err)
+	}
+	err := httpClient.HostMaintenancePost(m)
+	if err != nil {
+		die("Could not schedule maintenance:", err)
+	}
+	fmt.Printf("Maintenance scheduled from block %v to %v.\n", m.Start, m.End)
+
+	hmg, err := httpClient.HostMaintenanceGet()
+	if err == nil && !hmg.Safe {
+		fmt.Printf("Warning: %v contracts have their proof window during maintenance. The host must be online to submit their storage proofs, see 'siac host maintenance'.\n", len(hmg.Conflicts))
+	}
+}
+
+// hostsectorlistcmd is the handler for the command `siac host sector list`.
+// Lists the sectors referenced by the host's contracts.
+func hostsectorlistcmd() {
+	hsg, err := httpClient.HostStorageSectorsGet("", 0)
+	if err != nil {
+		die("Could not fetch sectors:", err)
+	}
+	if len(hsg.Sectors) == 0 {
+		fmt.Println("No sectors stored")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintf(w, "Root\tCount\tContracts\tFolder\n")
+	for _, sector := range hsg.Sectors {
+		folder := sector.FolderPath
+		if sector.Count == 0 {
+			folder = "missing"
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", sector.Root, sector.Count, len(sector.Obligations), folder)
+	}
+	w.Flush()
+}
+
 func hostsectordeletecmd(root string) {
 	var hash crypto.Hash
 	err := hash.LoadString(root)
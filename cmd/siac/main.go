@@ -15,16 +15,45 @@ import (
 
 var (
 	// Flags.
-	hostContractOutputType string // output type for host contracts
-	hostVerbose            bool   // display additional host info
-	initForce              bool   // destroy and re-encrypt the wallet on init if it already exists
-	initPassword           bool   // supply a custom password when creating a wallet
-	renterAllContracts     bool   // Show all active and expired contracts
-	renterDownloadAsync    bool   // Downloads files asynchronously
-	renterListVerbose      bool   // Show additional info about uploaded files.
-	renterShowHistory      bool   // Show download history in addition to download queue.
-	siaDir                 string // Path to sia data dir
-	walletRawTxn           bool   // Encode/decode transactions in base64-encoded binary.
+	hostContractOutputType   string // output type for host contracts
+	hostMaintenanceAnnounce  bool   // announce scheduled maintenance ahead of time
+	hostReportFormat         string // output format of the host's financial report
+	hostReportFrom           string // start date of the host's financial report
+	hostReportInterval       string // interval of the periods in the host's financial report
+	hostReportObligations    bool   // report the host's obligations instead of periods
+	hostReportTo             string // end date of the host's financial report
+	hostVerbose              bool   // display additional host info
+	initForce                bool   // destroy and re-encrypt the wallet on init if it already exists
+	initPassword             bool   // supply a custom password when creating a wallet
+	renterAllContracts       bool   // Show all active and expired contracts
+	renterDownloadAsync      bool   // Downloads files asynchronously
+	renterExportFormat       string // output format for exported renter data
+	renterListVerbose        bool   // Show additional info about uploaded files.
+	renterPricesDataPieces   uint64 // Data pieces of a detailed price estimation.
+	renterPricesDataSize     string // Data size of a detailed price estimation.
+	renterPricesDetailed     bool   // Make a detailed price estimation.
+	renterPricesDownloadSize string // Download size of a detailed price estimation.
+	renterPricesParityPieces uint64 // Parity pieces of a detailed price estimation.
+	renterShowHistory        bool   // Show download history in addition to download queue.
+	siaDir                   string // Path to sia data dir
+	walletBumpRebroadcast    bool   // Broadcast the whole replacement set of a fee bump.
+	walletMultisigUnused     bool   // The registered multisig address has never been used.
+	walletRawTxn             bool   // Encode/decode transactions in base64-encoded binary.
+	walletScheduleCount      uint64 // Number of times a scheduled payment is paid.
+	walletScheduleDryRun     bool   // Only check that a scheduled payment could be funded.
+	walletScheduleInterval   uint64 // Number of blocks between repetitions of a scheduled payment.
+	walletSeedFormat         string // Encoding of an exported seed, bip39 or sia.
+	walletSendExclude        string // Comma-separated outputs that must not fund the transaction.
+	walletSendPin            string // Comma-separated outputs that must fund the transaction.
+	walletSendStrategy       string // Coin selection strategy of the transaction.
+	walletTxnsCSV            bool   // Export the transaction history as CSV.
+	walletTxnsCategory       string // Only show transactions of this category.
+	walletTxnsLabel          string // Only show transactions related to addresses with this label.
+	walletWatchKeysUnused    bool   // The added watch keys have never been used.
+	walletWebhookAddrs       string // Comma-separated addresses watched by a webhook.
+	walletWebhookConfs       uint64 // Confirmations after which a webhook is notified.
+	walletWebhookEvents      string // Comma-separated event types delivered to a webhook.
+	walletWebhookSecret      string // Secret that the events of a webhook are signed with.
 )
 
 var (
@@ -94,11 +123,19 @@ func main() {
 	updateCmd.AddCommand(updateCheckCmd)
 
 	root.AddCommand(hostCmd)
-	hostCmd.AddCommand(hostConfigCmd, hostAnnounceCmd, hostFolderCmd, hostContractCmd, hostSectorCmd)
-	hostFolderCmd.AddCommand(hostFolderAddCmd, hostFolderRemoveCmd, hostFolderResizeCmd)
-	hostSectorCmd.AddCommand(hostSectorDeleteCmd)
+	hostCmd.AddCommand(hostConfigCmd, hostAnnounceCmd, hostFolderCmd, hostContractCmd, hostMaintenanceCmd, hostPricingCmd, hostReportCmd, hostSectorCmd)
+	hostMaintenanceCmd.AddCommand(hostMaintenanceCancelCmd, hostMaintenanceScheduleCmd)
+	hostPricingCmd.AddCommand(hostPricingConfigCmd, hostPricingHistoryCmd)
+	hostFolderCmd.AddCommand(hostFolderAddCmd, hostFolderMoveCmd, hostFolderRemoveCmd, hostFolderResetHealthCmd, hostFolderResizeCmd)
+	hostSectorCmd.AddCommand(hostSectorAuditCmd, hostSectorDeleteCmd, hostSectorListCmd)
 	hostCmd.Flags().BoolVarP(&hostVerbose, "verbose", "v", false, "Display detailed host info")
 	hostContractCmd.Flags().StringVarP(&hostContractOutputType, "type", "t", "value", "Select output type")
+	hostMaintenanceScheduleCmd.Flags().BoolVarP(&hostMaintenanceAnnounce, "announce", "a", false, "Stop advertising that the host accepts contracts right away")
+	hostReportCmd.Flags().StringVarP(&hostReportFormat, "format", "f", "table", "Report format, either table or csv")
+	hostReportCmd.Flags().StringVarP(&hostReportFrom, "from", "", "", "First day of the report (YYYY-MM-DD)")
+	hostReportCmd.Flags().StringVarP(&hostReportInterval, "interval", "i", "day", "Length of each period, either day, week, month or all")
+	hostReportCmd.Flags().BoolVarP(&hostReportObligations, "obligations", "o", false, "Report the profit and loss of each resolved obligation")
+	hostReportCmd.Flags().StringVarP(&hostReportTo, "to", "", "", "Last day of the report (YYYY-MM-DD)")
 
 	root.AddCommand(hostdbCmd)
 	hostdbCmd.AddCommand(hostdbViewCmd)
@@ -109,34 +146,79 @@ func main() {
 	minerCmd.AddCommand(minerStartCmd, minerStopCmd)
 
 	root.AddCommand(walletCmd)
-	walletCmd.AddCommand(walletAddressCmd, walletAddressesCmd, walletChangepasswordCmd, walletInitCmd, walletInitSeedCmd,
-		walletLoadCmd, walletLockCmd, walletSeedsCmd, walletSendCmd, walletSweepCmd, walletSignCmd,
-		walletBalanceCmd, walletBroadcastCmd, walletTransactionsCmd, walletUnlockCmd)
+	walletCmd.AddCommand(walletAddressCmd, walletAddressesCmd, walletChangepasswordCmd, walletInitCmd,
+		walletInitSeedCmd, walletInitWatchOnlyCmd, walletLabelCmd, walletLabelsCmd, walletLoadCmd, walletLockCmd,
+		walletMultisigCmd, walletNamedCmd, walletNoteCmd, walletPSTCmd, walletScheduleCmd, walletSeedsCmd,
+		walletSendCmd, walletSweepCmd, walletSignCmd, walletBalanceCmd, walletBroadcastCmd, walletBumpCmd,
+		walletTimelockCmd, walletTransactionsCmd, walletUnlockCmd, walletWatchKeysCmd, walletWebhooksCmd,
+		walletFreezeCmd, walletUnfreezeCmd, walletUnspentCmd)
 	walletInitCmd.Flags().BoolVarP(&initPassword, "password", "p", false, "Prompt for a custom password")
 	walletInitCmd.Flags().BoolVarP(&initForce, "force", "", false, "destroy the existing wallet and re-encrypt")
 	walletInitSeedCmd.Flags().BoolVarP(&initForce, "force", "", false, "destroy the existing wallet")
+	walletInitWatchOnlyCmd.Flags().BoolVarP(&initForce, "force", "", false, "destroy the existing wallet")
 	walletLoadCmd.AddCommand(walletLoad033xCmd, walletLoadSeedCmd, walletLoadSiagCmd)
+	walletMultisigCmd.AddCommand(walletMultisigAddCmd, walletMultisigBuildCmd, walletMultisigCombineCmd,
+		walletMultisigPubkeyCmd, walletMultisigSignCmd)
+	walletMultisigAddCmd.Flags().BoolVarP(&walletMultisigUnused, "unused", "", false, "The address has never been used, skip the blockchain rescan")
+	walletMultisigBuildCmd.Flags().BoolVarP(&walletRawTxn, "raw", "", false, "Encode transaction as base64 instead of JSON")
+	walletMultisigCombineCmd.Flags().BoolVarP(&walletRawTxn, "raw", "", false, "Encode combined transaction as base64 instead of JSON")
+	walletMultisigSignCmd.Flags().BoolVarP(&walletRawTxn, "raw", "", false, "Encode signed transaction as base64 instead of JSON")
+	walletNamedCmd.AddCommand(walletNamedCreateCmd, walletNamedDeleteCmd)
+	walletPSTCmd.AddCommand(walletPSTBroadcastCmd, walletPSTCombineCmd, walletPSTCreateCmd, walletPSTInspectCmd, walletPSTSignCmd)
+	walletPSTCombineCmd.Flags().BoolVarP(&walletRawTxn, "raw", "", false, "Encode combined PST as base64 instead of JSON")
+	walletPSTCreateCmd.Flags().BoolVarP(&walletRawTxn, "raw", "", false, "Encode PST as base64 instead of JSON")
+	walletPSTSignCmd.Flags().BoolVarP(&walletRawTxn, "raw", "", false, "Encode signed PST as base64 instead of JSON")
+	walletScheduleCmd.AddCommand(walletScheduleAddCmd, walletScheduleCancelCmd)
+	walletScheduleAddCmd.Flags().Uint64VarP(&walletScheduleInterval, "interval", "", 0, "Repeat the payment every interval blocks")
+	walletScheduleAddCmd.Flags().Uint64VarP(&walletScheduleCount, "count", "", 0, "Number of times a repeated payment is paid, 0 to repeat until cancelled")
+	walletScheduleAddCmd.Flags().BoolVarP(&walletScheduleDryRun, "dry-run", "", false, "Check that the payment could be funded now instead of scheduling it")
+	walletSeedsCmd.AddCommand(walletSeedsExportCmd)
+	walletSeedsExportCmd.Flags().StringVarP(&walletSeedFormat, "format", "", "bip39", "Encoding of the phrase, either bip39 or sia")
 	walletSendCmd.AddCommand(walletSendSiacoinsCmd, walletSendSiafundsCmd)
+	walletSendSiacoinsCmd.Flags().StringVarP(&walletSendStrategy, "strategy", "", "", "Coin selection strategy: largest-first, smallest-first, branch-and-bound or privacy")
+	walletSendSiacoinsCmd.Flags().StringVarP(&walletSendPin, "pin", "", "", "Comma-separated IDs of outputs that must fund the transaction")
+	walletSendSiacoinsCmd.Flags().StringVarP(&walletSendExclude, "exclude", "", "", "Comma-separated IDs of outputs that must not fund the transaction")
+	walletTimelockCmd.AddCommand(walletTimelockNewCmd, walletTimelockSweepCmd)
+	walletTransactionsCmd.Flags().BoolVarP(&walletTxnsCSV, "csv", "", false, "Export the confirmed transactions as CSV")
+	walletTransactionsCmd.Flags().StringVarP(&walletTxnsCategory, "category", "", "", "Only show transactions of this category")
+	walletTransactionsCmd.Flags().StringVarP(&walletTxnsLabel, "label", "", "", "Only show transactions related to addresses with this label")
 	walletUnlockCmd.Flags().BoolVarP(&initPassword, "password", "p", false, "Display interactive password prompt even if SIA_WALLET_PASSWORD is set")
 	walletBroadcastCmd.Flags().BoolVarP(&walletRawTxn, "raw", "", false, "Decode transaction as base64 instead of JSON")
+	walletBumpCmd.Flags().BoolVarP(&walletBumpRebroadcast, "rebroadcast", "", false, "Broadcast the whole replacement set instead of only the child transaction")
 	walletSignCmd.Flags().BoolVarP(&walletRawTxn, "raw", "", false, "Encode signed transaction as base64 instead of JSON")
+	walletWatchKeysCmd.AddCommand(walletWatchKeysAddCmd, walletWatchKeysExportCmd)
+	walletWatchKeysAddCmd.Flags().BoolVarP(&walletWatchKeysUnused, "unused", "", false, "The keys have never been used, skip the blockchain rescan")
+	walletWatchKeysExportCmd.Flags().BoolVarP(&walletRawTxn, "raw", "", false, "Encode watch keys as base64 instead of JSON")
+	walletWebhooksCmd.AddCommand(walletWebhooksAddCmd, walletWebhooksLogCmd, walletWebhooksRemoveCmd)
+	walletWebhooksAddCmd.Flags().StringVarP(&walletWebhookAddrs, "addresses", "", "", "Comma-separated addresses to watch, all addresses of the wallet by default")
+	walletWebhooksAddCmd.Flags().Uint64VarP(&walletWebhookConfs, "confirmations", "", 0, "Confirmations after which the confirmed event is delivered, 6 by default")
+	walletWebhooksAddCmd.Flags().StringVarP(&walletWebhookEvents, "events", "", "", "Comma-separated events to deliver: pending, received, confirmed and reverted")
+	walletWebhooksAddCmd.Flags().StringVarP(&walletWebhookSecret, "secret", "", "", "Secret to sign the events with, generated by the wallet by default")
 
 	root.AddCommand(renterCmd)
 	renterCmd.AddCommand(renterFilesDeleteCmd, renterFilesDownloadCmd,
 		renterDownloadsCmd, renterAllowanceCmd, renterSetAllowanceCmd,
 		renterContractsCmd, renterFilesListCmd, renterFilesRenameCmd,
 		renterFilesUploadCmd, renterUploadsCmd, renterExportCmd,
-		renterPricesCmd)
+		renterPricesCmd, renterProfilesCmd)
 
-	renterContractsCmd.AddCommand(renterContractsViewCmd)
+	renterContractsCmd.AddCommand(renterContractsViewCmd, renterContractsFormCmd, renterContractsRenewCmd,
+		renterContractsRefreshCmd, renterContractsLockCmd, renterContractsUnlockCmd)
 	renterAllowanceCmd.AddCommand(renterAllowanceCancelCmd)
+	renterProfilesCmd.AddCommand(renterProfilesCreateCmd, renterProfilesDeleteCmd)
 
 	renterCmd.Flags().BoolVarP(&renterListVerbose, "verbose", "v", false, "Show additional file info such as redundancy")
 	renterContractsCmd.Flags().BoolVarP(&renterAllContracts, "all", "A", false, "Show all expired contracts in addition to active contracts")
 	renterDownloadsCmd.Flags().BoolVarP(&renterShowHistory, "history", "H", false, "Show download history in addition to the download queue")
 	renterFilesDownloadCmd.Flags().BoolVarP(&renterDownloadAsync, "async", "A", false, "Download file asynchronously")
 	renterFilesListCmd.Flags().BoolVarP(&renterListVerbose, "verbose", "v", false, "Show additional file info such as redundancy")
-	renterExportCmd.AddCommand(renterExportContractTxnsCmd)
+	renterPricesCmd.Flags().BoolVarP(&renterPricesDetailed, "detailed", "", false, "Price the contracts with a realistic host selection")
+	renterPricesCmd.Flags().StringVarP(&renterPricesDataSize, "datasize", "", "", "Amount of data to store for a detailed estimate, e.g. 1TB")
+	renterPricesCmd.Flags().StringVarP(&renterPricesDownloadSize, "downloadsize", "", "", "Amount of data expected to be downloaded for a detailed estimate")
+	renterPricesCmd.Flags().Uint64VarP(&renterPricesDataPieces, "datapieces", "", 0, "Data pieces for a detailed estimate, defaults to the renter's default")
+	renterPricesCmd.Flags().Uint64VarP(&renterPricesParityPieces, "paritypieces", "", 0, "Parity pieces for a detailed estimate, defaults to the renter's default")
+	renterExportCmd.AddCommand(renterExportContractTxnsCmd, renterExportContractHistoryCmd)
+	renterExportContractHistoryCmd.Flags().StringVarP(&renterExportFormat, "format", "f", "json", "Export format, either json or csv")
 
 	root.AddCommand(gatewayCmd)
 	gatewayCmd.AddCommand(gatewayConnectCmd, gatewayDisconnectCmd, gatewayAddressCmd, gatewayListCmd)
@@ -152,6 +234,8 @@ func main() {
 	root.PersistentFlags().StringVarP(&httpClient.Password, "apipassword", "", "", "the password for the API's http authentication")
 	root.PersistentFlags().StringVarP(&siaDir, "sia-directory", "d", build.DefaultSiaDir(), "location of the sia directory")
 	root.PersistentFlags().StringVarP(&httpClient.UserAgent, "useragent", "", "Sia-Agent", "the useragent used by siac to connect to the daemon's API")
+	root.PersistentFlags().StringVarP(&httpClient.RenterProfile, "renter-profile", "", "", "the renter profile that serves the renter commands")
+	root.PersistentFlags().StringVarP(&httpClient.NamedWallet, "wallet", "", "", "the named wallet that serves the wallet commands")
 
 	// Check if the api password environment variable is set.
 	apiPassword := os.Getenv("SIA_API_PASSWORD")
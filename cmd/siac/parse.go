@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,8 +12,11 @@ import (
 	"os"
 	"strings"
 
+	"gitlab.com/NebulousLabs/Sia/crypto"
 	"gitlab.com/NebulousLabs/Sia/encoding"
+	"gitlab.com/NebulousLabs/Sia/modules"
 	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/entropy-mnemonics"
 )
 
 var errUnableToParseSize = errors.New("unable to parse size")
@@ -201,3 +205,112 @@ func parseTxn(s string) (types.Transaction, error) {
 	}
 	return txn, nil
 }
+
+// parsePST decodes the partially signed transaction s, which may be JSON,
+// base64, or a path to a file containing either.
+func parsePST(s string) (modules.PartiallySignedTransaction, error) {
+	// first assume s is a file
+	pstBytes, err := ioutil.ReadFile(s)
+	if os.IsNotExist(err) {
+		// assume s is a literal encoding
+		pstBytes = []byte(s)
+	} else if err != nil {
+		return modules.PartiallySignedTransaction{}, errors.New("could not read partially signed transaction file: " + err.Error())
+	}
+	var pst modules.PartiallySignedTransaction
+	if json.Valid(pstBytes) {
+		if err := json.Unmarshal(pstBytes, &pst); err != nil {
+			return modules.PartiallySignedTransaction{}, errors.New("could not decode JSON partially signed transaction: " + err.Error())
+		}
+	} else {
+		bin, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(pstBytes)))
+		if err != nil {
+			return modules.PartiallySignedTransaction{}, errors.New("argument is not valid JSON, base64, or filepath")
+		}
+		if err := encoding.Unmarshal(bin, &pst); err != nil {
+			return modules.PartiallySignedTransaction{}, errors.New("could not decode binary partially signed transaction: " + err.Error())
+		}
+	}
+	return pst, nil
+}
+
+// parseWatchKeys decodes the watch keys exported from a seed from s, which
+// may be JSON, base64, or a path to a file containing either.
+func parseWatchKeys(s string) (modules.WatchKeys, error) {
+	// first assume s is a file
+	wkBytes, err := ioutil.ReadFile(s)
+	if os.IsNotExist(err) {
+		// assume s is a literal encoding
+		wkBytes = []byte(s)
+	} else if err != nil {
+		return modules.WatchKeys{}, errors.New("could not read watch keys file: " + err.Error())
+	}
+	var wk modules.WatchKeys
+	if json.Valid(wkBytes) {
+		if err := json.Unmarshal(wkBytes, &wk); err != nil {
+			return modules.WatchKeys{}, errors.New("could not decode JSON watch keys: " + err.Error())
+		}
+	} else {
+		bin, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(wkBytes)))
+		if err != nil {
+			return modules.WatchKeys{}, errors.New("argument is not valid JSON, base64, or filepath")
+		}
+		if err := encoding.Unmarshal(bin, &wk); err != nil {
+			return modules.WatchKeys{}, errors.New("could not decode binary watch keys: " + err.Error())
+		}
+	}
+	return wk, nil
+}
+
+// parseOutputIDs parses a comma-separated list of siacoin output IDs. An
+// empty string results in no IDs.
+func parseOutputIDs(s string) ([]types.SiacoinOutputID, error) {
+	var ids []types.SiacoinOutputID
+	for _, str := range strings.Split(s, ",") {
+		str = strings.TrimSpace(str)
+		if str == "" {
+			continue
+		}
+		var id crypto.Hash
+		if err := id.LoadString(str); err != nil {
+			return nil, fmt.Errorf("invalid output ID %q: %v", str, err)
+		}
+		ids = append(ids, types.SiacoinOutputID(id))
+	}
+	return ids, nil
+}
+
+// parseUnlockHashes parses a comma-separated list of addresses.
+func parseUnlockHashes(s string) ([]types.UnlockHash, error) {
+	var addrs []types.UnlockHash
+	for _, str := range strings.Split(s, ",") {
+		str = strings.TrimSpace(str)
+		if str == "" {
+			continue
+		}
+		var addr types.UnlockHash
+		if err := addr.LoadString(str); err != nil {
+			return nil, fmt.Errorf("invalid address %q: %v", str, err)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+// parseSeed decodes a seed that is encoded either as a Sia mnemonic or as a
+// 24 word BIP-39 phrase. Sia mnemonics are longer than 24 words, so the
+// encoding is determined by the number of words.
+func parseSeed(str string) (modules.Seed, error) {
+	if len(strings.Fields(str)) == 24 {
+		return modules.StringToSeed(str, modules.BIP39)
+	}
+	return modules.StringToSeed(str, mnemonics.English)
+}
+
+// seedChecksum returns the checksum of a seed that is printed next to its
+// phrase, so that a restored phrase can be compared to a backup without
+// revealing the seed. It is independent of the encoding of the phrase.
+func seedChecksum(seed modules.Seed) string {
+	h := crypto.HashObject(seed)
+	return hex.EncodeToString(h[:modules.SeedChecksumSize])
+}
@@ -4,7 +4,10 @@ import (
 	"math/big"
 	"testing"
 
+	"gitlab.com/NebulousLabs/Sia/modules"
 	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/entropy-mnemonics"
+	"gitlab.com/NebulousLabs/fastrand"
 )
 
 func TestParseFilesize(t *testing.T) {
@@ -122,3 +125,29 @@ func TestCurrencyUnits(t *testing.T) {
 		}
 	}
 }
+
+// TestParseSeed tests parsing seeds encoded as Sia mnemonics and as BIP-39
+// phrases.
+func TestParseSeed(t *testing.T) {
+	var seed modules.Seed
+	fastrand.Read(seed[:])
+	for _, did := range []mnemonics.DictionaryID{mnemonics.English, modules.BIP39} {
+		str, err := modules.SeedToString(seed, did)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := parseSeed(str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed != seed {
+			t.Errorf("wrong seed parsed from %v phrase", did)
+		}
+	}
+	if _, err := parseSeed("foo"); err == nil {
+		t.Error("expected error for invalid seed")
+	}
+	if len(seedChecksum(seed)) != 2*modules.SeedChecksumSize {
+		t.Error("wrong checksum length", seedChecksum(seed))
+	}
+}
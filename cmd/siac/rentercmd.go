@@ -50,6 +50,52 @@ var (
 		Run:   wrap(rentercontractscmd),
 	}
 
+	renterContractsFormCmd = &cobra.Command{
+		Use:   "form [hostkey] [amount] [endheight]",
+		Short: "Form a contract with a specific host",
+		Long: `Form a contract with the host that has the given public key, bypassing the
+renter's automatic host selection. amount is given in currency units (SC, KS,
+etc.) and is taken from the allowance. If no endheight is given, the contract
+ends at the end of the current period.`,
+		Run: rentercontractsformcmd,
+	}
+
+	renterContractsLockCmd = &cobra.Command{
+		Use:   "lock [contract-id]",
+		Short: "Lock the utility of a contract",
+		Long: `Lock the utility of a contract. The renter won't mark a locked contract as
+good for upload or good for renew, which means a canceled contract stays
+canceled until it is unlocked.`,
+		Run: wrap(rentercontractslockcmd),
+	}
+
+	renterContractsRefreshCmd = &cobra.Command{
+		Use:   "refresh [contract-id] [amount]",
+		Short: "Add funds to a contract",
+		Long: `Add funds to a contract by renewing it without changing its end height.
+amount is given in currency units (SC, KS, etc.) and is taken from the
+allowance.`,
+		Run: wrap(rentercontractsrefreshcmd),
+	}
+
+	renterContractsRenewCmd = &cobra.Command{
+		Use:   "renew [contract-id] [amount] [endheight]",
+		Short: "Renew a contract right away",
+		Long: `Renew a contract right away, even if it hasn't entered its renew window yet.
+amount is given in currency units (SC, KS, etc.) and is taken from the
+allowance. If no endheight is given, the new contract ends at the end of the
+current period.`,
+		Run: rentercontractsrenewcmd,
+	}
+
+	renterContractsUnlockCmd = &cobra.Command{
+		Use:   "unlock [contract-id]",
+		Short: "Unlock the utility of a contract",
+		Long: `Unlock the utility of a contract, allowing the renter to mark it as good for
+upload and good for renew again.`,
+		Run: wrap(rentercontractsunlockcmd),
+	}
+
 	renterContractsViewCmd = &cobra.Command{
 		Use:   "view [contract-id]",
 		Short: "View details of the specified contract",
@@ -108,10 +154,39 @@ var (
 		Long: `Display the estimated prices of storing files, retrieving files, and creating a set of contracts.
 
 An allowance can be provided for a more accurate estimate, if no allowance is provided the current set allowance will be used,
-and if no allowance is set an allowance of 500SC, 12w period, 50 hosts, and 4w renew window will be used.`,
+and if no allowance is set an allowance of 500SC, 12w period, 50 hosts, and 4w renew window will be used.
+
+With --detailed, the hosts are selected the same way they are selected when forming contracts and the
+contracts required to store --datasize bytes of data for the allowance period are priced individually.
+The erasure coding settings and the expected amount of downloads can be provided as well.`,
 		Run: renterpricescmd,
 	}
 
+	renterProfilesCmd = &cobra.Command{
+		Use:   "profiles",
+		Short: "List the renter profiles",
+		Long: `List the renter profiles. A renter profile has its own allowance, contracts
+and files but shares the hostdb and the wallet with the default renter.
+
+To run a renter command with a profile, pass the name of the profile to the
+--renter-profile flag.`,
+		Run: wrap(renterprofilescmd),
+	}
+
+	renterProfilesCreateCmd = &cobra.Command{
+		Use:   "create [name]",
+		Short: "Create a renter profile",
+		Long:  "Create a new renter profile. Names may only contain letters, numbers, '-' and '_'.",
+		Run:   wrap(renterprofilescreatecmd),
+	}
+
+	renterProfilesDeleteCmd = &cobra.Command{
+		Use:   "delete [name]",
+		Short: "Delete a renter profile",
+		Long:  "Delete a renter profile. Only profiles without files and contracts can be deleted.",
+		Run:   wrap(renterprofilesdeletecmd),
+	}
+
 	renterSetAllowanceCmd = &cobra.Command{
 		Use:   "setallowance [amount] [period] [hosts] [renew window]",
 		Short: "Set the allowance",
@@ -347,6 +422,40 @@ again:
 	fmt.Println("Allowance canceled.")
 }
 
+// renterprofilescmd lists the renter profiles.
+func renterprofilescmd() {
+	rp, err := httpClient.RenterProfilesGet()
+	if err != nil {
+		die("Could not get renter profiles:", err)
+	}
+	if len(rp.Profiles) == 0 {
+		fmt.Println("No renter profiles.")
+		return
+	}
+	fmt.Println("Renter profiles:")
+	for _, name := range rp.Profiles {
+		fmt.Println("  " + name)
+	}
+}
+
+// renterprofilescreatecmd creates a new renter profile.
+func renterprofilescreatecmd(name string) {
+	err := httpClient.RenterProfileCreatePost(name)
+	if err != nil {
+		die("Could not create renter profile:", err)
+	}
+	fmt.Printf("Created renter profile %v.\n", name)
+}
+
+// renterprofilesdeletecmd deletes a renter profile.
+func renterprofilesdeletecmd(name string) {
+	err := httpClient.RenterProfileDeletePost(name)
+	if err != nil {
+		die("Could not delete renter profile:", err)
+	}
+	fmt.Printf("Deleted renter profile %v.\n", name)
+}
+
 // rentersetallowancecmd allows the user to set the allowance.
 // the first two parameters, amount and period, are required.
 // the second two parameters are optional:
@@ -557,6 +666,108 @@ func rentercontractscmd() {
 	}
 }
 
+// parseContractID parses a file contract id, exiting if it is invalid.
+func parseContractID(id string) types.FileContractID {
+	var fcid types.FileContractID
+	if err := fcid.LoadString(id); err != nil {
+		die("Could not parse contract id:", err)
+	}
+	return fcid
+}
+
+// parseContractFunds parses a currency amount, exiting if it is invalid.
+func parseContractFunds(amount string) types.Currency {
+	hastings, err := parseCurrency(amount)
+	if err != nil {
+		die("Could not parse amount:", err)
+	}
+	var funds types.Currency
+	if _, err := fmt.Sscan(hastings, &funds); err != nil {
+		die("Could not parse amount:", err)
+	}
+	return funds
+}
+
+// rentercontractsformcmd is the handler for the command `siac renter
+// contracts form [hostkey] [amount] [endheight]`. It forms a contract with a
+// specific host.
+func rentercontractsformcmd(cmd *cobra.Command, args []string) {
+	if len(args) < 2 || len(args) > 3 {
+		cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	var hostKey types.SiaPublicKey
+	hostKey.LoadString(args[0])
+	if hostKey.Key == nil {
+		die("Could not parse host public key")
+	}
+	funds := parseContractFunds(args[1])
+	var endHeight types.BlockHeight
+	if len(args) > 2 {
+		if _, err := fmt.Sscan(args[2], &endHeight); err != nil {
+			die("Could not parse end height:", err)
+		}
+	}
+	rc, err := httpClient.RenterContractFormPost(hostKey, funds, endHeight)
+	if err != nil {
+		die("Could not form contract:", err)
+	}
+	fmt.Printf("Formed contract %v with %v, ending at height %v\n", rc.ID, rc.NetAddress, rc.EndHeight)
+}
+
+// rentercontractslockcmd is the handler for the command `siac renter
+// contracts lock [contract-id]`. It locks the utility of a contract.
+func rentercontractslockcmd(id string) {
+	err := httpClient.RenterContractUtilityPost(parseContractID(id), true)
+	if err != nil {
+		die("Could not lock contract utility:", err)
+	}
+	fmt.Println("Contract utility locked")
+}
+
+// rentercontractsrefreshcmd is the handler for the command `siac renter
+// contracts refresh [contract-id] [amount]`. It adds funds to a contract.
+func rentercontractsrefreshcmd(id, amount string) {
+	rc, err := httpClient.RenterContractRefreshPost(parseContractID(id), parseContractFunds(amount))
+	if err != nil {
+		die("Could not refresh contract:", err)
+	}
+	fmt.Printf("Refreshed contract, new contract id is %v with %v remaining\n", rc.ID, currencyUnits(rc.RenterFunds))
+}
+
+// rentercontractsrenewcmd is the handler for the command `siac renter
+// contracts renew [contract-id] [amount] [endheight]`. It renews a contract
+// right away.
+func rentercontractsrenewcmd(cmd *cobra.Command, args []string) {
+	if len(args) < 2 || len(args) > 3 {
+		cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	fcid := parseContractID(args[0])
+	funds := parseContractFunds(args[1])
+	var endHeight types.BlockHeight
+	if len(args) > 2 {
+		if _, err := fmt.Sscan(args[2], &endHeight); err != nil {
+			die("Could not parse end height:", err)
+		}
+	}
+	rc, err := httpClient.RenterContractRenewPost(fcid, funds, endHeight)
+	if err != nil {
+		die("Could not renew contract:", err)
+	}
+	fmt.Printf("Renewed contract, new contract id is %v, ending at height %v\n", rc.ID, rc.EndHeight)
+}
+
+// rentercontractsunlockcmd is the handler for the command `siac renter
+// contracts unlock [contract-id]`. It unlocks the utility of a contract.
+func rentercontractsunlockcmd(id string) {
+	err := httpClient.RenterContractUtilityPost(parseContractID(id), false)
+	if err != nil {
+		die("Could not unlock contract utility:", err)
+	}
+	fmt.Println("Contract utility unlocked")
+}
+
 // rentercontractsviewcmd is the handler for the command `siac renter contracts <id>`.
 // It lists details of a specific contract.
 func rentercontractsviewcmd(cid string) {
@@ -908,6 +1119,11 @@ func renterpricescmd(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	if renterPricesDetailed {
+		renterpricesdetailed(allowance)
+		return
+	}
+
 	rpg, err := httpClient.RenterPricesGet(allowance)
 	if err != nil {
 		die("Could not read the renter prices:", err)
@@ -932,3 +1148,58 @@ func renterpricescmd(cmd *cobra.Command, args []string) {
 	fmt.Fprintln(w, "\tRenew Window:\t", rpg.Allowance.RenewWindow)
 	w.Flush()
 }
+
+// renterpricesdetailed displays a detailed price estimation for the data
+// described by the renterPrices flags.
+func renterpricesdetailed(allowance modules.Allowance) {
+	var params modules.RenterPriceEstimationParams
+	if renterPricesDataSize == "" {
+		die("--datasize must be specified for a detailed estimate")
+	}
+	dataSize, err := parseFilesize(renterPricesDataSize)
+	if err != nil {
+		die("Could not parse data size:", err)
+	}
+	if _, err := fmt.Sscan(dataSize, &params.DataSize); err != nil {
+		die("Could not parse data size:", err)
+	}
+	if renterPricesDownloadSize != "" {
+		downloadSize, err := parseFilesize(renterPricesDownloadSize)
+		if err != nil {
+			die("Could not parse download size:", err)
+		}
+		if _, err := fmt.Sscan(downloadSize, &params.DownloadSize); err != nil {
+			die("Could not parse download size:", err)
+		}
+	}
+	params.DataPieces = renterPricesDataPieces
+	params.ParityPieces = renterPricesParityPieces
+
+	rpg, err := httpClient.RenterPricesDetailedGet(params, allowance)
+	if err != nil {
+		die("Could not read the renter prices:", err)
+	}
+
+	fmt.Printf("Estimate for %v with %v data and %v parity pieces over %v blocks:\n",
+		filesizeUnits(int64(rpg.DataSize)), rpg.DataPieces, rpg.ParityPieces, rpg.Allowance.Period)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tContract Fees:\t", currencyUnits(rpg.ContractCost))
+	fmt.Fprintln(w, "\tTransaction Fees:\t", currencyUnits(rpg.TransactionFees))
+	fmt.Fprintln(w, "\tSiafund Fees:\t", currencyUnits(rpg.SiafundFees))
+	fmt.Fprintln(w, "\tStorage:\t", currencyUnits(rpg.StorageCost))
+	fmt.Fprintln(w, "\tUpload:\t", currencyUnits(rpg.UploadCost))
+	fmt.Fprintln(w, "\tDownload:\t", currencyUnits(rpg.DownloadCost))
+	fmt.Fprintln(w, "\tTotal:\t", currencyUnits(rpg.Total))
+	fmt.Fprintf(w, "\t90%% of %v Host Selections:\t %v - %v\n", rpg.Samples, currencyUnits(rpg.TotalLowerBound), currencyUnits(rpg.TotalUpperBound))
+	w.Flush()
+
+	fmt.Println("\nPer Host Breakdown:")
+	fmt.Fprintln(w, "  Host\tStored\tContract\tStorage\tUpload\tDownload\tFees\tTotal")
+	for _, h := range rpg.Hosts {
+		fees := h.TransactionFee.Add(h.SiafundFee)
+		fmt.Fprintf(w, "  %v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", h.NetAddress, filesizeUnits(int64(h.StoredBytes)),
+			currencyUnits(h.ContractPrice), currencyUnits(h.StorageCost), currencyUnits(h.UploadCost),
+			currencyUnits(h.DownloadCost), currencyUnits(fees), currencyUnits(h.Total))
+	}
+	w.Flush()
+}
@@ -11,8 +11,10 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
+	"github.com/skip2/go-qrcode"
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh/terminal"
 
@@ -54,6 +56,18 @@ be valid. txn may be either JSON, base64, or a file containing either.`,
 		Run: wrap(walletbroadcastcmd),
 	}
 
+	walletBumpCmd = &cobra.Command{
+		Use:   "bump [txid] [fee]",
+		Short: "Raise the fee of a stuck transaction",
+		Long: `Raise the fee of an unconfirmed transaction by spending one of its wallet
+outputs in a child transaction that pays the difference (child-pays-for-parent).
+fee is the new total fee of the transaction, its unconfirmed parents and the
+child, and must be higher than what they currently pay. With --rebroadcast, the
+whole replacement set is broadcast to peers, which helps if they dropped the
+stuck transaction.`,
+		Run: wrap(walletbumpcmd),
+	}
+
 	walletChangepasswordCmd = &cobra.Command{
 		Use:   "change-password",
 		Short: "Change the wallet password",
@@ -80,6 +94,15 @@ The smallest unit of siacoins is the hasting. One siacoin is 10^24 hastings. Oth
 		Run: wrap(walletbalancecmd),
 	}
 
+	walletFreezeCmd = &cobra.Command{
+		Use:   "freeze [outputid]...",
+		Short: "Prevent the wallet from spending outputs",
+		Long: `Freeze outputs of the wallet. Frozen outputs are never used to fund
+transactions until they are unfrozen, not even when they are pinned. Output IDs
+are listed by 'wallet unspent'.`,
+		Run: walletfreezecmd,
+	}
+
 	walletInitCmd = &cobra.Command{
 		Use:   "init",
 		Short: "Initialize and encrypt a new wallet",
@@ -95,6 +118,17 @@ By default the wallet encryption / unlock password is the same as the generated
 		Run:   wrap(walletinitseedcmd),
 	}
 
+	walletInitWatchOnlyCmd = &cobra.Command{
+		Use:   "init-watchonly [watchkeys]",
+		Short: "Initialize a watch-only wallet from the public keys of a seed",
+		Long: `Initialize and encrypt a watch-only wallet from the public keys exported
+from a seed with 'siac wallet watchkeys export 0 [n]'. A watch-only wallet
+tracks the balance of the seed, generates receive addresses and creates
+unsigned PSTs, but never holds the seed itself. watchkeys may be either JSON,
+base64, or a file containing either.`,
+		Run: wrap(walletinitwatchonlycmd),
+	}
+
 	walletLoad033xCmd = &cobra.Command{
 		Use:   "033x [filepath]",
 		Short: "Load a v0.3.3.x wallet",
@@ -102,6 +136,22 @@ By default the wallet encryption / unlock password is the same as the generated
 		Run:   wrap(walletload033xcmd),
 	}
 
+	walletLabelCmd = &cobra.Command{
+		Use:   "label [address] [label]",
+		Short: "Label an address",
+		Long: `Label an address, which doesn't need to belong to the wallet. Transactions
+related to the address can be filtered by the label with 'wallet transactions
+--label'. An empty label removes the label of the address.`,
+		Run: wrap(walletlabelcmd),
+	}
+
+	walletLabelsCmd = &cobra.Command{
+		Use:   "labels",
+		Short: "List labeled addresses",
+		Long:  "List the addresses that were labeled with 'wallet label'.",
+		Run:   wrap(walletlabelscmd),
+	}
+
 	walletLoadCmd = &cobra.Command{
 		Use:   "load",
 		Short: "Load a wallet seed, v0.3.3.x wallet, or siag keyset",
@@ -131,6 +181,249 @@ By default the wallet encryption / unlock password is the same as the generated
 		Run:   wrap(walletlockcmd),
 	}
 
+	walletMultisigCmd = &cobra.Command{
+		Use:   "multisig",
+		Short: "View and spend from multisig addresses",
+		Long: `List the M-of-N addresses registered with the wallet, along with their
+balances and the public keys the wallet can sign for.`,
+		Run: wrap(walletmultisigcmd),
+	}
+
+	walletMultisigAddCmd = &cobra.Command{
+		Use:   "add [required] [pubkey] [pubkey]...",
+		Short: "Register a multisig address",
+		Long: `Register the M-of-N address of the provided public keys that needs
+'required' signatures to be spent. The wallet must own at least one of the
+public keys. The order of the public keys determines the address, so all
+co-signers must register them in the same order.
+
+Unless --unused is set, the wallet rescans the blockchain for outputs of the
+address.`,
+		Run: walletmultisigaddcmd,
+	}
+
+	walletMultisigBuildCmd = &cobra.Command{
+		Use:   "build [address] [amount] [dest]",
+		Short: "Build a transaction spending from a multisig address",
+		Long: `Build an unsigned transaction that sends amount to dest from a multisig
+address. The change is returned to the multisig address. The transaction has to
+be signed by enough co-signers with 'wallet multisig sign' before it can be
+broadcast.`,
+		Run: wrap(walletmultisigbuildcmd),
+	}
+
+	walletMultisigCombineCmd = &cobra.Command{
+		Use:   "combine [txn] [txn]...",
+		Short: "Combine the signatures of co-signers",
+		Long: `Merge the signatures of copies of a transaction that were signed by
+different co-signers. Each txn may be either JSON, base64, or a file containing
+either.`,
+		Run: walletmultisigcombinecmd,
+	}
+
+	walletMultisigPubkeyCmd = &cobra.Command{
+		Use:   "pubkey",
+		Short: "Get a public key for a multisig address",
+		Long: `Generate a new wallet address and print its public key, which can be shared
+with the co-signers of a multisig address.`,
+		Run: wrap(walletmultisigpubkeycmd),
+	}
+
+	walletMultisigSignCmd = &cobra.Command{
+		Use:   "sign [txn]",
+		Short: "Sign a multisig transaction",
+		Long: `Add the signatures of the wallet to the inputs of a transaction that spend
+from registered multisig addresses. txn may be either JSON, base64, or a file
+containing either.`,
+		Run: wrap(walletmultisigsigncmd),
+	}
+
+	walletNamedCmd = &cobra.Command{
+		Use:   "named",
+		Short: "List the named wallets",
+		Long: `List the named wallets. A named wallet has its own seed, encryption key and
+addresses, and shares the consensus set and the transaction pool with the
+primary wallet. It is initialized, unlocked and locked independently.
+
+To run a wallet command with a named wallet, pass the name of the wallet to
+the --wallet flag.`,
+		Run: wrap(walletnamedcmd),
+	}
+
+	walletNamedCreateCmd = &cobra.Command{
+		Use:   "create [name]",
+		Short: "Create a named wallet",
+		Long: `Create a new named wallet. Names may only contain letters, numbers, '-' and
+'_', and can't be the name of a wallet command of the API. The new wallet
+must be initialized with 'siac wallet init --wallet [name]'.`,
+		Run: wrap(walletnamedcreatecmd),
+	}
+
+	walletNamedDeleteCmd = &cobra.Command{
+		Use:   "delete [name]",
+		Short: "Delete a named wallet",
+		Long: `Delete a named wallet and its seed. Only wallets without coins and
+unconfirmed transactions can be deleted.`,
+		Run: wrap(walletnameddeletecmd),
+	}
+
+	walletNoteCmd = &cobra.Command{
+		Use:   "note [txid] [note]",
+		Short: "Attach a note to a transaction",
+		Long: `Attach a note to a confirmed or unconfirmed transaction of the wallet. The
+note is shown by 'wallet transactions'. An empty note removes the note of the
+transaction.`,
+		Run: wrap(walletnotecmd),
+	}
+
+	walletPSTCmd = &cobra.Command{
+		Use:   "pst",
+		Short: "Create and sign partially signed transactions",
+		Long: `Create, inspect, sign, combine and broadcast partially signed transactions
+(PSTs). A PST contains a transaction along with everything a signer needs to
+know about its inputs, so it can be signed by a cold wallet that is fully
+offline. Each pst argument may be either JSON, base64, or a file containing
+either.`,
+	}
+
+	walletPSTBroadcastCmd = &cobra.Command{
+		Use:   "broadcast [pst]",
+		Short: "Broadcast a fully signed PST",
+		Long:  "Broadcast the transaction of a PST along with its parents once all of its inputs are signed.",
+		Run:   wrap(walletpstbroadcastcmd),
+	}
+
+	walletPSTCombineCmd = &cobra.Command{
+		Use:   "combine [pst] [pst]...",
+		Short: "Combine the signatures of PSTs",
+		Long: `Merge the signatures of copies of a PST that were signed by different
+signers. Combining does not need siad.`,
+		Run: walletpstcombinecmd,
+	}
+
+	walletPSTCreateCmd = &cobra.Command{
+		Use:   "create [amount] [dest]",
+		Short: "Create an unsigned PST",
+		Long: `Create an unsigned PST that sends amount to dest from the confirmed outputs
+of the wallet. The change is returned to a new address of the wallet. The PST
+includes the seed indices of the wallet's keys, so an offline signer doesn't
+have to search the seed for them.`,
+		Run: wrap(walletpstcreatecmd),
+	}
+
+	walletPSTInspectCmd = &cobra.Command{
+		Use:   "inspect [pst]",
+		Short: "Show the inputs, outputs and signing status of a PST",
+		Long:  "Show the inputs, outputs and signing status of a PST. Inspecting does not need siad.",
+		Run:   wrap(walletpstinspectcmd),
+	}
+
+	walletPSTSignCmd = &cobra.Command{
+		Use:   "sign [pst]",
+		Short: "Sign a PST",
+		Long: `Add the signatures of the wallet to the inputs of a PST that are missing
+signatures. If siad is not running, sign with keys derived from a seed
+instead.`,
+		Run: wrap(walletpstsigncmd),
+	}
+
+	walletWatchKeysCmd = &cobra.Command{
+		Use:   "watchkeys",
+		Short: "Export and add the public keys of a watch-only wallet",
+		Long: `Export the public keys of a seed for a watch-only wallet, and add more keys
+to a watch-only wallet that is running low on addresses. The public keys of a
+seed can't be derived from each other, so every key of a watch-only wallet
+has to be exported from the seed.`,
+		// Run field is not set, as the watchkeys command itself is not a valid
+		// command. A subcommand must be provided.
+	}
+
+	walletWatchKeysAddCmd = &cobra.Command{
+		Use:   "add [watchkeys]",
+		Short: "Add public keys to a watch-only wallet",
+		Long: `Add public keys exported from the seed of a watch-only wallet. The keys must
+not leave a gap after the keys the wallet already has. Unless --unused is set,
+the wallet rescans the blockchain for outputs of the new keys. watchkeys may be
+either JSON, base64, or a file containing either.`,
+		Run: wrap(walletwatchkeysaddcmd),
+	}
+
+	walletWatchKeysExportCmd = &cobra.Command{
+		Use:   "export [start] [n]",
+		Short: "Export the public keys of a seed",
+		Long: `Export the public keys of n indices of a seed, starting at index start, for
+a watch-only wallet. The keys are signed by the seed, so the watch-only wallet
+can verify that all of its keys belong to the same seed. Exporting does not
+need siad, so it can be done on an offline machine.`,
+		Run: wrap(walletwatchkeysexportcmd),
+	}
+
+	walletWebhooksCmd = &cobra.Command{
+		Use:   "webhooks",
+		Short: "View and register webhooks",
+		Long: `List the webhooks that the wallet POSTs its events to. Events are delivered
+when a watched address receives an unconfirmed (pending) or confirmed
+(received) payment, when a transaction of a watched address reaches the
+confirmations of the webhook (confirmed) and when a reorg reverts one
+(reverted).`,
+		Run: wrap(walletwebhookscmd),
+	}
+
+	walletWebhooksAddCmd = &cobra.Command{
+		Use:   "add [url]",
+		Short: "Register a webhook",
+		Long: `Register a webhook that the wallet POSTs the JSON of its events to. By
+default, all events of all addresses of the wallet are delivered; use --events
+and --addresses to restrict them. The body of each request is signed with the
+secret of the webhook: the Sia-Webhook-Signature header contains the
+hex-encoded HMAC-SHA256 of the body. If --secret is not set, the wallet
+generates a secret, which is printed once the webhook is registered.`,
+		Run: wrap(walletwebhooksaddcmd),
+	}
+
+	walletWebhooksRemoveCmd = &cobra.Command{
+		Use:   "remove [id]",
+		Short: "Remove a webhook",
+		Long:  "Remove a webhook along with its delivery log.",
+		Run:   wrap(walletwebhooksremovecmd),
+	}
+
+	walletWebhooksLogCmd = &cobra.Command{
+		Use:   "log [id]",
+		Short: "View the delivery log of a webhook",
+		Long: `List the events that were queued for a webhook, along with the outcome of
+their delivery. Failed deliveries are retried with exponential backoff.`,
+		Run: wrap(walletwebhookslogcmd),
+	}
+
+	walletScheduleCmd = &cobra.Command{
+		Use:   "schedule",
+		Short: "View and schedule future payments",
+		Long: `List the payments that the wallet broadcasts once the blockchain reaches
+their height. Payments are only broadcast while siad is running and the wallet
+is unlocked and synced.`,
+		Run: wrap(walletschedulecmd),
+	}
+
+	walletScheduleAddCmd = &cobra.Command{
+		Use:   "add [amount] [dest] [height]",
+		Short: "Schedule a payment",
+		Long: `Schedule a payment of amount to dest at the provided block height. With
+--interval, the payment is repeated every interval blocks, either until it is
+cancelled or, if --count is set, until it has been paid count times.
+
+With --dry-run, the payment is not scheduled. Instead, siac checks that the
+wallet could fund it right now and prints the fee it would pay.`,
+		Run: wrap(walletscheduleaddcmd),
+	}
+
+	walletScheduleCancelCmd = &cobra.Command{
+		Use:   "cancel [id]",
+		Short: "Cancel a scheduled payment",
+		Long:  "Cancel a scheduled payment, including all of its future repetitions.",
+		Run:   wrap(walletschedulecancelcmd),
+	}
+
 	walletSeedsCmd = &cobra.Command{
 		Use:   "seeds",
 		Short: "View information about your seeds",
@@ -138,6 +431,17 @@ By default the wallet encryption / unlock password is the same as the generated
 		Run:   wrap(walletseedscmd),
 	}
 
+	walletSeedsExportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export the primary seed for a paper backup",
+		Long: `Print the primary seed as a phrase and as a QR code that can be scanned from
+the terminal, followed by the checksum of the seed. The phrase is a 24 word
+BIP-39 phrase by default, or a Sia mnemonic with --format sia. Seeds that are
+entered into siac print the same checksum, so a restored backup can be checked
+against it.`,
+		Run: wrap(walletseedsexportcmd),
+	}
+
 	walletSendCmd = &cobra.Command{
 		Use:   "send",
 		Short: "Send either siacoins or siafunds to an address",
@@ -153,7 +457,15 @@ By default the wallet encryption / unlock password is the same as the generated
 'amount' can be specified in units, e.g. 1.23KS. Run 'wallet --help' for a list of units.
 If no unit is supplied, hastings will be assumed.
 
-A dynamic transaction fee is applied depending on the size of the transaction and how busy the network is.`,
+A dynamic transaction fee is applied depending on the size of the transaction and how busy the network is.
+
+The outputs that fund the transaction can be controlled with --pin and
+--exclude, which take comma-separated output IDs as listed by 'wallet unspent',
+and with --strategy:
+  largest-first:    spend the largest outputs first (default)
+  smallest-first:   spend the smallest outputs first
+  branch-and-bound: spend the outputs that need the least change
+  privacy:          spend all outputs of as few addresses as possible`,
 		Run: wrap(walletsendsiacoinscmd),
 	}
 
@@ -188,11 +500,56 @@ will be sent to your wallet.`,
 		Run: wrap(walletsweepcmd),
 	}
 
+	walletTimelockCmd = &cobra.Command{
+		Use:   "timelock",
+		Short: "View and create timelocked addresses",
+		Long: `List the timelocked addresses of the wallet along with their unlock heights.
+Coins sent to a timelocked address can't be spent before the blockchain
+reaches its unlock height.`,
+		Run: wrap(wallettimelockcmd),
+	}
+
+	walletTimelockNewCmd = &cobra.Command{
+		Use:   "new [height]",
+		Short: "Create a timelocked address",
+		Long:  "Create a new address of the wallet that can't be spent from before the provided block height.",
+		Run:   wrap(wallettimelocknewcmd),
+	}
+
+	walletTimelockSweepCmd = &cobra.Command{
+		Use:   "sweep",
+		Short: "Sweep expired timelocked addresses",
+		Long: `Send the confirmed coins of all timelocked addresses whose timelock has
+expired to a new address of the wallet.`,
+		Run: wrap(wallettimelocksweepcmd),
+	}
+
 	walletTransactionsCmd = &cobra.Command{
 		Use:   "transactions",
 		Short: "View transactions",
-		Long:  "View transactions related to addresses spendable by the wallet, providing a net flow of siacoins and siafunds for each transaction",
-		Run:   wrap(wallettransactionscmd),
+		Long: `View transactions related to addresses spendable by the wallet, providing a
+net flow of siacoins and siafunds for each transaction, along with its category
+and note.
+
+The transactions can be filtered by category and by the label of the addresses
+they are related to. Categories are contractformation, contractrevision,
+storageproof, minerpayout, siafundclaim, defrag and send. With --csv, the
+confirmed transactions are exported as CSV.`,
+		Run: wrap(wallettransactionscmd),
+	}
+
+	walletUnfreezeCmd = &cobra.Command{
+		Use:   "unfreeze [outputid]...",
+		Short: "Allow the wallet to spend frozen outputs again",
+		Long:  "Unfreeze outputs of the wallet that were frozen with 'wallet freeze'.",
+		Run:   walletunfreezecmd,
+	}
+
+	walletUnspentCmd = &cobra.Command{
+		Use:   "unspent",
+		Short: "List the unspent outputs of the wallet",
+		Long:  "List the unspent siacoin outputs of the wallet, along with whether they are frozen.",
+		Run:   wrap(walletunspentcmd),
 	}
 
 	walletUnlockCmd = &cobra.Command{
@@ -224,6 +581,26 @@ func passwordPrompt(prompt string) (string, error) {
 	return string(pw), err
 }
 
+// seedPrompt securely reads a seed from stdin, either as a Sia mnemonic or as
+// a BIP-39 phrase, and prints its checksum. The seed is returned as an English
+// Sia mnemonic, which is the encoding the API expects by default.
+func seedPrompt(prompt string) string {
+	seedStr, err := passwordPrompt(prompt)
+	if err != nil {
+		die("Reading seed failed:", err)
+	}
+	seed, err := parseSeed(seedStr)
+	if err != nil {
+		die("Invalid seed:", err)
+	}
+	fmt.Println("Seed checksum:", seedChecksum(seed))
+	seedStr, err = modules.SeedToString(seed, mnemonics.English)
+	if err != nil {
+		die("Could not encode seed:", err)
+	}
+	return seedStr
+}
+
 // confirmPassword requests confirmation of a previously-entered password.
 func confirmPassword(prev string) error {
 	pw, err := passwordPrompt(confirmPasswordText)
@@ -256,6 +633,30 @@ func walletaddressescmd() {
 	}
 }
 
+// walletbumpcmd raises the fee of an unconfirmed transaction.
+func walletbumpcmd(txidStr, fee string) {
+	var txid crypto.Hash
+	if err := txid.LoadString(txidStr); err != nil {
+		die("Could not parse transaction ID:", err)
+	}
+	hastings, err := parseCurrency(fee)
+	if err != nil {
+		die("Could not parse fee:", err)
+	}
+	var value types.Currency
+	if _, err := fmt.Sscan(hastings, &value); err != nil {
+		die("Failed to parse fee", err)
+	}
+	wbp, err := httpClient.WalletBumpPost(types.TransactionID(txid), value, walletBumpRebroadcast)
+	if err != nil {
+		die("Could not bump fee:", err)
+	}
+	fmt.Printf("Bumped fee to %v with child transaction %v.\n", currencyUnits(value), wbp.TransactionIDs[len(wbp.TransactionIDs)-1])
+	if walletBumpRebroadcast {
+		fmt.Printf("Rebroadcast replacement set of %v transactions.\n", len(wbp.TransactionIDs))
+	}
+}
+
 // walletchangepasswordcmd changes the password of the wallet.
 func walletchangepasswordcmd() {
 	currentPassword, err := passwordPrompt(currentPasswordText)
@@ -275,6 +676,22 @@ func walletchangepasswordcmd() {
 	fmt.Println("Password changed successfully.")
 }
 
+// walletfreezecmd freezes outputs of the wallet.
+func walletfreezecmd(cmd *cobra.Command, args []string) {
+	if len(args) < 1 {
+		cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	ids, err := parseOutputIDs(strings.Join(args, ","))
+	if err != nil {
+		die("Could not parse outputs:", err)
+	}
+	if err := httpClient.WalletFreezePost(ids); err != nil {
+		die("Could not freeze outputs:", err)
+	}
+	fmt.Printf("Froze %v outputs.\n", len(ids))
+}
+
 // walletinitcmd encrypts the wallet with the given password
 func walletinitcmd() {
 	var password string
@@ -301,11 +718,9 @@ func walletinitcmd() {
 
 // walletinitseedcmd initializes the wallet from a preexisting seed.
 func walletinitseedcmd() {
-	seed, err := passwordPrompt("Seed: ")
-	if err != nil {
-		die("Reading seed failed:", err)
-	}
+	seed := seedPrompt("Seed: ")
 	var password string
+	var err error
 	if initPassword {
 		password, err = passwordPrompt("Wallet password: ")
 		if err != nil {
@@ -325,6 +740,26 @@ func walletinitseedcmd() {
 	}
 }
 
+// walletinitwatchonlycmd initializes a watch-only wallet from the public
+// keys of a seed.
+func walletinitwatchonlycmd(wkStr string) {
+	wk, err := parseWatchKeys(wkStr)
+	if err != nil {
+		die("Could not decode watch keys:", err)
+	}
+	password, err := passwordPrompt("Wallet password: ")
+	if err != nil {
+		die("Reading password failed:", err)
+	} else if err = confirmPassword(password); err != nil {
+		die(err)
+	}
+	err = httpClient.WalletInitWatchOnlyPost(wk, password, initForce)
+	if err != nil {
+		die("Could not initialize watch-only wallet:", err)
+	}
+	fmt.Printf("Watch-only wallet initialized with %v public keys and encrypted with given password.\n", len(wk.PublicKeys))
+}
+
 // walletload033xcmd loads a v0.3.3.x wallet into the current wallet.
 func walletload033xcmd(source string) {
 	password, err := passwordPrompt(askPasswordText)
@@ -340,10 +775,7 @@ func walletload033xcmd(source string) {
 
 // walletloadseedcmd adds a seed to the wallet's list of seeds
 func walletloadseedcmd() {
-	seed, err := passwordPrompt("New seed: ")
-	if err != nil {
-		die("Reading seed failed:", err)
-	}
+	seed := seedPrompt("New seed: ")
 	password, err := passwordPrompt(askPasswordText)
 	if err != nil {
 		die("Reading password failed:", err)
@@ -368,6 +800,40 @@ func walletloadsiagcmd(keyfiles string) {
 	fmt.Println("Wallet loading successful.")
 }
 
+// walletlabelcmd labels an address.
+func walletlabelcmd(addr, label string) {
+	var uh types.UnlockHash
+	if err := uh.LoadString(addr); err != nil {
+		die("Could not parse address:", err)
+	}
+	if err := httpClient.WalletLabelsPost(uh, label); err != nil {
+		die("Could not label address:", err)
+	}
+	if label == "" {
+		fmt.Println("Removed label of", uh)
+	} else {
+		fmt.Printf("Labeled %v as %q.\n", uh, label)
+	}
+}
+
+// walletlabelscmd lists the labeled addresses.
+func walletlabelscmd() {
+	wlg, err := httpClient.WalletLabelsGet()
+	if err != nil {
+		die("Could not get labels:", err)
+	}
+	if len(wlg.Labels) == 0 {
+		fmt.Println("No labeled addresses.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Label\tAddress")
+	for _, l := range wlg.Labels {
+		fmt.Fprintf(w, "%v\t%v\n", l.Label, l.Address)
+	}
+	w.Flush()
+}
+
 // walletlockcmd locks the wallet
 func walletlockcmd() {
 	err := httpClient.WalletLockPost()
@@ -399,6 +865,41 @@ func walletseedscmd() {
 	}
 }
 
+// walletseedsexportcmd prints the primary seed as a phrase and a QR code,
+// along with its checksum.
+func walletseedsexportcmd() {
+	seedInfo, err := httpClient.WalletSeedsGet()
+	if err != nil {
+		die("Error retrieving the current seed:", err)
+	}
+	seed, err := modules.StringToSeed(seedInfo.PrimarySeed, mnemonics.English)
+	if err != nil {
+		die("Could not decode seed:", err)
+	}
+	var phrase string
+	switch walletSeedFormat {
+	case "bip39":
+		phrase, err = modules.SeedToString(seed, modules.BIP39)
+		if err != nil {
+			die("Could not encode seed:", err)
+		}
+	case "sia":
+		phrase = seedInfo.PrimarySeed
+	default:
+		die("Unknown format, use bip39 or sia")
+	}
+	qr, err := qrcode.New(phrase, qrcode.Medium)
+	if err != nil {
+		die("Could not create QR code:", err)
+	}
+	fmt.Println("Primary Seed:")
+	fmt.Println(phrase)
+	fmt.Println()
+	fmt.Print(qr.ToSmallString(false))
+	fmt.Println()
+	fmt.Println("Checksum:", seedChecksum(seed))
+}
+
 // walletsendsiacoinscmd sends siacoins to a destination address.
 func walletsendsiacoinscmd(amount, dest string) {
 	hastings, err := parseCurrency(amount)
@@ -413,7 +914,19 @@ func walletsendsiacoinscmd(amount, dest string) {
 	if _, err := fmt.Sscan(dest, &hash); err != nil {
 		die("Failed to parse destination address", err)
 	}
-	_, err = httpClient.WalletSiacoinsPost(value, hash)
+	if walletSendStrategy == "" && walletSendPin == "" && walletSendExclude == "" {
+		_, err = httpClient.WalletSiacoinsPost(value, hash)
+	} else {
+		var cc modules.CoinControl
+		cc.Strategy = modules.CoinSelectionStrategy(walletSendStrategy)
+		if cc.Pinned, err = parseOutputIDs(walletSendPin); err != nil {
+			die("Could not parse pinned outputs:", err)
+		}
+		if cc.Excluded, err = parseOutputIDs(walletSendExclude); err != nil {
+			die("Could not parse excluded outputs:", err)
+		}
+		_, err = httpClient.WalletSiacoinsCoinControlPost([]types.SiacoinOutput{{Value: value, UnlockHash: hash}}, cc)
+	}
 	if err != nil {
 		die("Could not send siacoins:", err)
 	}
@@ -480,6 +993,11 @@ Estimated Fee:       %v / KB
 `, encStatus, status.Height, currencyUnits(status.ConfirmedSiacoinBalance), delta,
 		status.ConfirmedSiacoinBalance, status.SiafundBalance, status.SiacoinClaimBalance,
 		fees.Maximum.Mul64(1e3).HumanString())
+	if status.WatchOnly {
+		fmt.Printf(`
+Watch-only wallet:   %v unused public keys remaining
+`, status.WatchKeysRemaining)
+	}
 }
 
 // walletbroadcastcmd broadcasts a transaction.
@@ -495,13 +1013,534 @@ func walletbroadcastcmd(txnStr string) {
 	fmt.Println("Transaction has been broadcast successfully")
 }
 
-// walletsweepcmd sweeps coins and funds from a seed.
-func walletsweepcmd() {
-	seed, err := passwordPrompt("Seed: ")
+// walletmultisigcmd lists the multisig addresses registered with the wallet.
+func walletmultisigcmd() {
+	wmg, err := httpClient.WalletMultisigGet()
+	if err != nil {
+		die("Could not get multisig addresses:", err)
+	}
+	if len(wmg.Addresses) == 0 {
+		fmt.Println("No multisig addresses registered.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Address\tRequired\tOwned Keys\tSiacoins\tSiafunds")
+	for _, ma := range wmg.Addresses {
+		fmt.Fprintf(w, "%v\t%v of %v\t%v\t%v\t%v\n", ma.Address, ma.UnlockConditions.SignaturesRequired,
+			len(ma.UnlockConditions.PublicKeys), ma.OwnedKeys, currencyUnits(ma.SiacoinBalance), ma.SiafundBalance)
+	}
+	w.Flush()
+}
+
+// walletmultisigaddcmd registers a multisig address with the wallet.
+func walletmultisigaddcmd(cmd *cobra.Command, args []string) {
+	if len(args) < 3 {
+		cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	required, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		die("Could not parse the number of required signatures:", err)
+	}
+	var pubkeys []types.SiaPublicKey
+	for _, arg := range args[1:] {
+		var spk types.SiaPublicKey
+		spk.LoadString(arg)
+		if spk.Algorithm != types.SignatureEd25519 || len(spk.Key) != crypto.PublicKeySize {
+			die("Invalid public key", arg, "(must be of the form ed25519:<hex>)")
+		}
+		pubkeys = append(pubkeys, spk)
+	}
+	wmap, err := httpClient.WalletMultisigAddPost(pubkeys, required, walletMultisigUnused)
+	if err != nil {
+		die("Could not register multisig address:", err)
+	}
+	fmt.Printf("Registered %v-of-%v multisig address %v\n", required, len(pubkeys), wmap.Address)
+}
+
+// walletmultisigbuildcmd builds an unsigned transaction that spends from a
+// multisig address.
+func walletmultisigbuildcmd(addr, amount, dest string) {
+	var from, to types.UnlockHash
+	if _, err := fmt.Sscan(addr, &from); err != nil {
+		die("Failed to parse multisig address", err)
+	}
+	hastings, err := parseCurrency(amount)
+	if err != nil {
+		die("Could not parse amount:", err)
+	}
+	var value types.Currency
+	if _, err := fmt.Sscan(hastings, &value); err != nil {
+		die("Failed to parse amount", err)
+	}
+	if _, err := fmt.Sscan(dest, &to); err != nil {
+		die("Failed to parse destination address", err)
+	}
+	wmt, err := httpClient.WalletMultisigBuildPost(from, []types.SiacoinOutput{{Value: value, UnlockHash: to}})
+	if err != nil {
+		die("Could not build transaction:", err)
+	}
+	printTxn(wmt.Transaction)
+}
+
+// walletmultisigcombinecmd merges the signatures of co-signers.
+func walletmultisigcombinecmd(cmd *cobra.Command, args []string) {
+	if len(args) < 2 {
+		cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	var txns []types.Transaction
+	for _, arg := range args {
+		txn, err := parseTxn(arg)
+		if err != nil {
+			die("Could not decode transaction:", err)
+		}
+		txns = append(txns, txn)
+	}
+	wmt, err := httpClient.WalletMultisigCombinePost(txns)
+	if err != nil {
+		die("Could not combine signatures:", err)
+	}
+	printTxn(wmt.Transaction)
+}
+
+// walletmultisigpubkeycmd prints the public key of a new wallet address.
+func walletmultisigpubkeycmd() {
+	wag, err := httpClient.WalletAddressGet()
+	if err != nil {
+		die("Could not generate new address:", err)
+	}
+	wucg, err := httpClient.WalletUnlockConditionsGet(wag.Address)
+	if err != nil {
+		die("Could not get unlock conditions of the new address:", err)
+	}
+	fmt.Println(wucg.UnlockConditions.PublicKeys[0])
+}
+
+// walletmultisigsigncmd adds the wallet's signatures to a multisig
+// transaction.
+func walletmultisigsigncmd(txnStr string) {
+	txn, err := parseTxn(txnStr)
+	if err != nil {
+		die("Could not decode transaction:", err)
+	}
+	wmt, err := httpClient.WalletMultisigSignPost(txn)
+	if err != nil {
+		die("Could not sign transaction:", err)
+	}
+	printTxn(wmt.Transaction)
+}
+
+// walletnamedcmd lists the named wallets.
+func walletnamedcmd() {
+	nw, err := httpClient.WalletsGet()
+	if err != nil {
+		die("Could not get named wallets:", err)
+	}
+	if len(nw.Wallets) == 0 {
+		fmt.Println("No named wallets.")
+		return
+	}
+	fmt.Println("Named wallets:")
+	for _, name := range nw.Wallets {
+		fmt.Println("  " + name)
+	}
+}
+
+// walletnamedcreatecmd creates a new named wallet.
+func walletnamedcreatecmd(name string) {
+	err := httpClient.WalletsCreatePost(name)
+	if err != nil {
+		die("Could not create named wallet:", err)
+	}
+	fmt.Printf("Created named wallet %v.\n", name)
+}
+
+// walletnameddeletecmd deletes a named wallet.
+func walletnameddeletecmd(name string) {
+	err := httpClient.WalletsDeletePost(name)
+	if err != nil {
+		die("Could not delete named wallet:", err)
+	}
+	fmt.Printf("Deleted named wallet %v.\n", name)
+}
+
+// walletnotecmd attaches a note to a transaction.
+func walletnotecmd(txidStr, note string) {
+	var txid crypto.Hash
+	if err := txid.LoadString(txidStr); err != nil {
+		die("Could not parse transaction ID:", err)
+	}
+	if err := httpClient.WalletNotePost(types.TransactionID(txid), note); err != nil {
+		die("Could not attach note:", err)
+	}
+	if note == "" {
+		fmt.Println("Removed note of transaction", txid)
+	} else {
+		fmt.Println("Attached note to transaction", txid)
+	}
+}
+
+// walletpstbroadcastcmd broadcasts a fully signed PST.
+func walletpstbroadcastcmd(pstStr string) {
+	pst, err := parsePST(pstStr)
+	if err != nil {
+		die("Could not decode PST:", err)
+	}
+	wpbp, err := httpClient.WalletPSTBroadcastPost(pst)
+	if err != nil {
+		die("Could not broadcast PST:", err)
+	}
+	fmt.Println("Broadcast transaction", wpbp.TransactionID)
+}
+
+// walletpstcombinecmd merges the signatures of copies of a PST.
+func walletpstcombinecmd(cmd *cobra.Command, args []string) {
+	if len(args) < 2 {
+		cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	var psts []modules.PartiallySignedTransaction
+	for _, arg := range args {
+		pst, err := parsePST(arg)
+		if err != nil {
+			die("Could not decode PST:", err)
+		}
+		psts = append(psts, pst)
+	}
+	pst, err := wallet.CombinePSTs(psts)
+	if err != nil {
+		die("Could not combine PSTs:", err)
+	}
+	printPST(pst)
+}
+
+// walletpstcreatecmd creates an unsigned PST that sends siacoins from the
+// wallet.
+func walletpstcreatecmd(amount, dest string) {
+	hastings, err := parseCurrency(amount)
+	if err != nil {
+		die("Could not parse amount:", err)
+	}
+	var value types.Currency
+	if _, err := fmt.Sscan(hastings, &value); err != nil {
+		die("Failed to parse amount", err)
+	}
+	var to types.UnlockHash
+	if _, err := fmt.Sscan(dest, &to); err != nil {
+		die("Failed to parse destination address", err)
+	}
+	wp, err := httpClient.WalletPSTCreatePost([]types.SiacoinOutput{{Value: value, UnlockHash: to}})
+	if err != nil {
+		die("Could not create PST:", err)
+	}
+	printPST(wp.PST)
+}
+
+// walletpstinspectcmd prints the inputs, outputs and signing status of a PST.
+func walletpstinspectcmd(pstStr string) {
+	pst, err := parsePST(pstStr)
+	if err != nil {
+		die("Could not decode PST:", err)
+	}
+	if err := wallet.UpdatePSTStatus(&pst); err != nil {
+		die("Invalid PST:", err)
+	}
+	complete := true
+	for _, in := range pst.Inputs {
+		complete = complete && in.Signed
+	}
+	fmt.Printf(`Version:     %v
+Transaction: %v
+Height:      %v
+Parents:     %v
+Complete:    %v
+
+`, pst.Version, pst.Transaction.ID(), pst.Height, len(pst.Parents), yesNo(complete))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Input\tType\tValue\tAddress\tSignatures\tKey Hints")
+	for _, in := range pst.Inputs {
+		value := currencyUnits(in.Value)
+		if in.FundType == types.SpecifierSiafundOutput {
+			value = in.Value.String() + " SF"
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v of %v\t%v\n", in.ParentID, in.FundType, value, in.UnlockConditions.UnlockHash(),
+			in.Signatures, in.UnlockConditions.SignaturesRequired, len(in.KeyHints))
+	}
+	w.Flush()
+	fmt.Println()
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Output\tValue")
+	for _, sco := range pst.Transaction.SiacoinOutputs {
+		fmt.Fprintf(w, "%v\t%v\n", sco.UnlockHash, currencyUnits(sco.Value))
+	}
+	for _, sfo := range pst.Transaction.SiafundOutputs {
+		fmt.Fprintf(w, "%v\t%v SF\n", sfo.UnlockHash, sfo.Value)
+	}
+	for _, fee := range pst.Transaction.MinerFees {
+		fmt.Fprintf(w, "miner fee\t%v\n", currencyUnits(fee))
+	}
+	w.Flush()
+}
+
+// walletpstsigncmd adds the signatures of the wallet to a PST. If siad is not
+// running, it signs with keys derived from a seed.
+func walletpstsigncmd(pstStr string) {
+	pst, err := parsePST(pstStr)
+	if err != nil {
+		die("Could not decode PST:", err)
+	}
+
+	// try API first
+	wp, err := httpClient.WalletPSTSignPost(pst)
+	if err == nil {
+		printPST(wp.PST)
+		return
+	}
+	// if siad is running, but the wallet is locked, assume the user wanted
+	// to sign with siad
+	if strings.Contains(err.Error(), modules.ErrLockedWallet.Error()) {
+		die("Signing via API failed: siad is running, but the wallet is locked.")
+	}
+
+	// siad is not running; fallback to offline keygen
+	fmt.Println("Enter your wallet seed to generate the signing key(s) now and sign without siad.")
+	seedString, err := passwordPrompt("Seed: ")
+	if err != nil {
+		die("Reading seed failed:", err)
+	}
+	seed, err := parseSeed(seedString)
+	if err != nil {
+		die("Invalid seed:", err)
+	}
+	if err := wallet.SignPST(&pst, seed); err != nil {
+		die("Failed to sign PST:", err)
+	}
+	printPST(pst)
+}
+
+// walletwatchkeysaddcmd adds public keys to a watch-only wallet.
+func walletwatchkeysaddcmd(wkStr string) {
+	wk, err := parseWatchKeys(wkStr)
+	if err != nil {
+		die("Could not decode watch keys:", err)
+	}
+	err = httpClient.WalletWatchKeysPost(wk, walletWatchKeysUnused)
+	if err != nil {
+		die("Could not add watch keys:", err)
+	}
+	fmt.Printf("Added public keys %v to %v to the watch-only wallet.\n", wk.StartIndex, wk.StartIndex+uint64(len(wk.PublicKeys))-1)
+}
+
+// walletwatchkeysexportcmd exports the public keys of a seed for a
+// watch-only wallet.
+func walletwatchkeysexportcmd(startStr, nStr string) {
+	start, err := strconv.ParseUint(startStr, 10, 64)
+	if err != nil {
+		die("Invalid start index:", err)
+	}
+	n, err := strconv.ParseUint(nStr, 10, 64)
+	if err != nil || n == 0 {
+		die("Invalid number of keys (must be a positive integer)")
+	}
+	seedString, err := passwordPrompt("Seed: ")
 	if err != nil {
 		die("Reading seed failed:", err)
 	}
+	seed, err := parseSeed(seedString)
+	if err != nil {
+		die("Invalid seed:", err)
+	}
+	wk := wallet.ExportWatchKeys(seed, start, n)
+	if walletRawTxn {
+		base64.NewEncoder(base64.StdEncoding, os.Stdout).Write(encoding.Marshal(wk))
+	} else {
+		json.NewEncoder(os.Stdout).Encode(wk)
+	}
+	fmt.Println()
+}
+
+// walletwebhookscmd lists the registered webhooks.
+func walletwebhookscmd() {
+	wwg, err := httpClient.WalletWebhooksGet()
+	if err != nil {
+		die("Could not get webhooks:", err)
+	}
+	if len(wwg.Webhooks) == 0 {
+		fmt.Println("No webhooks registered.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tURL\tEvents\tAddresses\tConfirmations")
+	for _, wh := range wwg.Webhooks {
+		events := "all"
+		if len(wh.Events) > 0 {
+			var names []string
+			for _, typ := range wh.Events {
+				names = append(names, string(typ))
+			}
+			events = strings.Join(names, ",")
+		}
+		addrs := "wallet"
+		if len(wh.Addresses) > 0 {
+			addrs = fmt.Sprint(len(wh.Addresses))
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", wh.ID, wh.URL, events, addrs, wh.Confirmations)
+	}
+	w.Flush()
+}
+
+// walletwebhooksaddcmd registers a webhook.
+func walletwebhooksaddcmd(url string) {
+	addrs, err := parseUnlockHashes(walletWebhookAddrs)
+	if err != nil {
+		die("Could not parse addresses:", err)
+	}
+	var events []modules.WebhookEventType
+	for _, str := range strings.Split(walletWebhookEvents, ",") {
+		if str = strings.TrimSpace(str); str != "" {
+			events = append(events, modules.WebhookEventType(str))
+		}
+	}
+	wh, err := httpClient.WalletWebhooksPost(modules.Webhook{
+		URL:           url,
+		Events:        events,
+		Addresses:     addrs,
+		Confirmations: types.BlockHeight(walletWebhookConfs),
+		Secret:        walletWebhookSecret,
+	})
+	if err != nil {
+		die("Could not register webhook:", err)
+	}
+	fmt.Printf("Registered webhook %v.\n", wh.ID)
+	if walletWebhookSecret == "" {
+		fmt.Println("Secret:", wh.Secret)
+	}
+}
+
+// walletwebhooksremovecmd removes a webhook.
+func walletwebhooksremovecmd(idStr string) {
+	var id crypto.Hash
+	if err := id.LoadString(idStr); err != nil {
+		die("Could not parse webhook ID:", err)
+	}
+	if err := httpClient.WalletWebhooksRemovePost(id); err != nil {
+		die("Could not remove webhook:", err)
+	}
+	fmt.Println("Removed webhook", id)
+}
+
+// walletwebhookslogcmd lists the delivery log of a webhook.
+func walletwebhookslogcmd(idStr string) {
+	var id crypto.Hash
+	if err := id.LoadString(idStr); err != nil {
+		die("Could not parse webhook ID:", err)
+	}
+	wwdg, err := httpClient.WalletWebhookDeliveriesGet(id)
+	if err != nil {
+		die("Could not get delivery log:", err)
+	}
+	if len(wwdg.Deliveries) == 0 {
+		fmt.Println("No events queued.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Time\tEvent\tTransaction\tAttempts\tStatus\tLast Error")
+	for _, wd := range wwdg.Deliveries {
+		status := "pending"
+		if wd.Delivered {
+			status = "delivered"
+		} else if wd.Attempts > 0 {
+			status = fmt.Sprintf("failed (%v)", wd.StatusCode)
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", time.Unix(int64(wd.Event.Timestamp), 0).Format(time.RFC3339),
+			wd.Event.Type, wd.Event.TransactionID, wd.Attempts, status, wd.LastError)
+	}
+	w.Flush()
+}
+
+// walletschedulecmd lists the scheduled payments of the wallet.
+func walletschedulecmd() {
+	wsg, err := httpClient.WalletScheduleGet()
+	if err != nil {
+		die("Could not get scheduled payments:", err)
+	}
+	if len(wsg.Payments) == 0 {
+		fmt.Println("No payments scheduled.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tHeight\tAmount\tInterval\tRemaining\tPaid\tLast Error")
+	for _, sp := range wsg.Payments {
+		var amount types.Currency
+		for _, sco := range sp.Outputs {
+			amount = amount.Add(sco.Value)
+		}
+		interval, remaining := "-", "-"
+		if sp.Interval > 0 {
+			interval = fmt.Sprint(sp.Interval)
+			remaining = "until cancelled"
+			if sp.Remaining > 0 {
+				remaining = fmt.Sprint(sp.Remaining)
+			}
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", sp.ID, sp.Height, currencyUnits(amount), interval,
+			remaining, sp.Executions, sp.LastError)
+	}
+	w.Flush()
+}
+
+// walletscheduleaddcmd schedules a payment.
+func walletscheduleaddcmd(amount, dest, heightStr string) {
+	hastings, err := parseCurrency(amount)
+	if err != nil {
+		die("Could not parse amount:", err)
+	}
+	var value types.Currency
+	if _, err := fmt.Sscan(hastings, &value); err != nil {
+		die("Failed to parse amount", err)
+	}
+	var hash types.UnlockHash
+	if _, err := fmt.Sscan(dest, &hash); err != nil {
+		die("Failed to parse destination address", err)
+	}
+	height, err := strconv.ParseUint(heightStr, 10, 64)
+	if err != nil {
+		die("Invalid height:", err)
+	}
+	wsp, err := httpClient.WalletSchedulePost(modules.ScheduledPayment{
+		Outputs:   []types.SiacoinOutput{{Value: value, UnlockHash: hash}},
+		Height:    types.BlockHeight(height),
+		Interval:  types.BlockHeight(walletScheduleInterval),
+		Remaining: walletScheduleCount,
+	}, walletScheduleDryRun)
+	if err != nil {
+		die("Could not schedule payment:", err)
+	}
+	if walletScheduleDryRun {
+		fmt.Printf("The payment could be funded now, paying a fee of %v.\n", currencyUnits(wsp.Fee))
+		return
+	}
+	fmt.Printf("Scheduled payment %v at height %v.\n", wsp.Payment.ID, wsp.Payment.Height)
+}
+
+// walletschedulecancelcmd cancels a scheduled payment.
+func walletschedulecancelcmd(idStr string) {
+	var id crypto.Hash
+	if err := id.LoadString(idStr); err != nil {
+		die("Could not parse payment ID:", err)
+	}
+	if err := httpClient.WalletScheduleCancelPost(id); err != nil {
+		die("Could not cancel payment:", err)
+	}
+	fmt.Println("Cancelled payment", id)
+}
 
+// walletsweepcmd sweeps coins and funds from a seed.
+func walletsweepcmd() {
+	seed := seedPrompt("Seed: ")
 	swept, err := httpClient.WalletSweepPost(seed)
 	if err != nil {
 		die("Could not sweep seed:", err)
@@ -547,6 +1586,11 @@ func walletsigncmd(cmd *cobra.Command, args []string) {
 		walletsigncmdoffline(&txn, toSign)
 	}
 
+	printTxn(txn)
+}
+
+// printTxn prints a transaction as JSON, or as base64 if --raw is set.
+func printTxn(txn types.Transaction) {
 	if walletRawTxn {
 		base64.NewEncoder(base64.StdEncoding, os.Stdout).Write(encoding.Marshal(txn))
 	} else {
@@ -555,6 +1599,16 @@ func walletsigncmd(cmd *cobra.Command, args []string) {
 	fmt.Println()
 }
 
+// printPST prints a PST as JSON, or as base64 if --raw is set.
+func printPST(pst modules.PartiallySignedTransaction) {
+	if walletRawTxn {
+		base64.NewEncoder(base64.StdEncoding, os.Stdout).Write(encoding.Marshal(pst))
+	} else {
+		json.NewEncoder(os.Stdout).Encode(pst)
+	}
+	fmt.Println()
+}
+
 // walletsigncmdoffline is a helper for walletsigncmd that handles signing
 // transactions without siad.
 func walletsigncmdoffline(txn *types.Transaction, toSign []crypto.Hash) {
@@ -563,7 +1617,7 @@ func walletsigncmdoffline(txn *types.Transaction, toSign []crypto.Hash) {
 	if err != nil {
 		die("Reading seed failed:", err)
 	}
-	seed, err := modules.StringToSeed(seedString, mnemonics.English)
+	seed, err := parseSeed(seedString)
 	if err != nil {
 		die("Invalid seed:", err)
 	}
@@ -585,14 +1639,64 @@ func walletsigncmdoffline(txn *types.Transaction, toSign []crypto.Hash) {
 	close(done)
 }
 
+// wallettimelockcmd lists the timelocked addresses of the wallet.
+func wallettimelockcmd() {
+	wtg, err := httpClient.WalletTimelockedGet()
+	if err != nil {
+		die("Could not get timelocked addresses:", err)
+	}
+	if len(wtg.Addresses) == 0 {
+		fmt.Println("No timelocked addresses.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Address\tUnlock Height")
+	for _, ta := range wtg.Addresses {
+		fmt.Fprintf(w, "%v\t%v\n", ta.Address, ta.UnlockConditions.Timelock)
+	}
+	w.Flush()
+}
+
+// wallettimelocknewcmd creates a new timelocked address.
+func wallettimelocknewcmd(heightStr string) {
+	height, err := strconv.ParseUint(heightStr, 10, 64)
+	if err != nil {
+		die("Invalid height:", err)
+	}
+	wta, err := httpClient.WalletTimelockedPost(types.BlockHeight(height))
+	if err != nil {
+		die("Could not create timelocked address:", err)
+	}
+	fmt.Printf("Created timelocked address %v, which unlocks at height %v.\n", wta.Address, wta.UnlockConditions.Timelock)
+}
+
+// wallettimelocksweepcmd sweeps the timelocked addresses whose timelock has
+// expired.
+func wallettimelocksweepcmd() {
+	wsp, err := httpClient.WalletTimelockedSweepPost()
+	if err != nil {
+		die("Could not sweep timelocked addresses:", err)
+	}
+	fmt.Println("Swept timelocked addresses in transaction", wsp.TransactionIDs[len(wsp.TransactionIDs)-1])
+}
+
 // wallettransactionscmd lists all of the transactions related to the wallet,
 // providing a net flow of siacoins and siafunds for each.
 func wallettransactionscmd() {
-	wtg, err := httpClient.WalletTransactionsGet(0, math.MaxInt64)
+	category := modules.TransactionCategory(walletTxnsCategory)
+	if walletTxnsCSV {
+		csv, err := httpClient.WalletTransactionsCSVGet(0, math.MaxInt64, category, walletTxnsLabel)
+		if err != nil {
+			die("Could not export transaction history:", err)
+		}
+		os.Stdout.Write(csv)
+		return
+	}
+	wtg, err := httpClient.WalletTransactionsFilteredGet(0, math.MaxInt64, category, walletTxnsLabel)
 	if err != nil {
 		die("Could not fetch transaction history:", err)
 	}
-	fmt.Println("             [timestamp]    [height]                                                   [transaction id]    [net siacoins]   [net siafunds]")
+	fmt.Println("             [timestamp]    [height]                                                   [transaction id]    [net siacoins]   [net siafunds]  [category]         [note]")
 	txns := append(wtg.ConfirmedTransactions, wtg.UnconfirmedTransactions...)
 	for _, txn := range txns {
 		// Determine the number of outgoing siacoins and siafunds.
@@ -640,11 +1744,49 @@ func wallettransactionscmd() {
 		fmt.Printf("%67v%15.2f SC", txn.TransactionID, incomingSiacoinsFloat-outgoingSiacoinsFloat)
 		// For siafunds, need to avoid having a negative types.Currency.
 		if incomingSiafunds.Cmp(outgoingSiafunds) >= 0 {
-			fmt.Printf("%14v SF\n", incomingSiafunds.Sub(outgoingSiafunds))
+			fmt.Printf("%14v SF", incomingSiafunds.Sub(outgoingSiafunds))
 		} else {
-			fmt.Printf("-%14v SF\n", outgoingSiafunds.Sub(incomingSiafunds))
+			fmt.Printf("-%14v SF", outgoingSiafunds.Sub(incomingSiafunds))
+		}
+		fmt.Printf("  %-18v %v\n", txn.Category, txn.Note)
+	}
+}
+
+// walletunfreezecmd unfreezes outputs of the wallet.
+func walletunfreezecmd(cmd *cobra.Command, args []string) {
+	if len(args) < 1 {
+		cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	ids, err := parseOutputIDs(strings.Join(args, ","))
+	if err != nil {
+		die("Could not parse outputs:", err)
+	}
+	if err := httpClient.WalletUnfreezePost(ids); err != nil {
+		die("Could not unfreeze outputs:", err)
+	}
+	fmt.Printf("Unfroze %v outputs.\n", len(ids))
+}
+
+// walletunspentcmd lists the unspent siacoin outputs of the wallet.
+func walletunspentcmd() {
+	wug, err := httpClient.WalletUnspentGet()
+	if err != nil {
+		die("Could not get unspent outputs:", err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tValue\tAddress\tConfirmed\tFrozen")
+	for _, o := range wug.Outputs {
+		if o.FundType != types.SpecifierSiacoinOutput {
+			continue
+		}
+		confirmed := "unconfirmed"
+		if o.ConfirmationHeight != types.BlockHeight(math.MaxUint64) {
+			confirmed = fmt.Sprint(o.ConfirmationHeight)
 		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", o.ID, currencyUnits(o.Value), o.UnlockHash, confirmed, yesNo(o.Frozen))
 	}
+	w.Flush()
 }
 
 // walletunlockcmd unlocks a saved wallet
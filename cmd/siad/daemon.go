@@ -117,7 +117,7 @@ func processConfig(config Config) (Config, error) {
 // unlock the wallet with the given password string.
 func unlockWallet(w modules.Wallet, password string) error {
 	var validKeys []crypto.TwofishKey
-	dicts := []mnemonics.DictionaryID{"english", "german", "japanese"}
+	dicts := []mnemonics.DictionaryID{"english", "german", "japanese", modules.BIP39}
 	for _, dict := range dicts {
 		seed, err := modules.StringToSeed(password, dict)
 		if err != nil {
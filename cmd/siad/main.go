@@ -37,6 +37,9 @@ type Config struct {
 		HostAddr     string
 		AllowAPIBind bool
 
+		HostWallet   string
+		RenterWallet string
+
 		Modules           string
 		NoBootstrap       bool
 		RequiredUserAgent string
@@ -164,6 +167,8 @@ func main() {
 	// Set default values, which have the lowest priority.
 	root.Flags().StringVarP(&globalConfig.Siad.RequiredUserAgent, "agent", "", "Sia-Agent", "required substring for the user agent")
 	root.Flags().StringVarP(&globalConfig.Siad.HostAddr, "host-addr", "", ":9982", "which port the host listens on")
+	root.Flags().StringVarP(&globalConfig.Siad.HostWallet, "host-wallet", "", "", "the named wallet used by the host, created if it doesn't exist")
+	root.Flags().StringVarP(&globalConfig.Siad.RenterWallet, "renter-wallet", "", "", "the named wallet used by the renter, created if it doesn't exist")
 	root.Flags().StringVarP(&globalConfig.Siad.ProfileDir, "profile-directory", "", "profiles", "location of the profiling directory")
 	root.Flags().StringVarP(&globalConfig.Siad.APIaddr, "api-addr", "", "localhost:9980", "which host:port the API server listens on")
 	root.Flags().StringVarP(&globalConfig.Siad.SiaDir, "sia-directory", "d", "", "location of the sia directory")
@@ -492,7 +492,11 @@ func (srv *Server) loadModules() error {
 	if strings.Contains(srv.config.Siad.Modules, "h") {
 		i++
 		fmt.Printf("(%d/%d) Loading host...\n", i, len(srv.config.Siad.Modules))
-		h, err = host.New(cs, g, tpool, w, srv.config.Siad.HostAddr, filepath.Join(srv.config.Siad.SiaDir, modules.HostDir))
+		hw, err := wallet.NamedWallet(w, srv.config.Siad.HostWallet)
+		if err != nil {
+			return err
+		}
+		h, err = host.New(cs, g, tpool, hw, srv.config.Siad.HostAddr, filepath.Join(srv.config.Siad.SiaDir, modules.HostDir))
 		if err != nil {
 			return err
 		}
@@ -502,7 +506,11 @@ func (srv *Server) loadModules() error {
 	if strings.Contains(srv.config.Siad.Modules, "r") {
 		i++
 		fmt.Printf("(%d/%d) Loading renter...\n", i, len(srv.config.Siad.Modules))
-		r, err = renter.New(g, cs, w, tpool, filepath.Join(srv.config.Siad.SiaDir, modules.RenterDir))
+		rw, err := wallet.NamedWallet(w, srv.config.Siad.RenterWallet)
+		if err != nil {
+			return err
+		}
+		r, err = renter.New(g, cs, rw, tpool, filepath.Join(srv.config.Siad.SiaDir, modules.RenterDir))
 		if err != nil {
 			return err
 		}
@@ -1,6 +1,8 @@
 package modules
 
 import (
+	"time"
+
 	"gitlab.com/NebulousLabs/Sia/types"
 )
 
@@ -30,6 +32,14 @@ var (
 	// netaddress.
 	HostConnectabilityStatusNotConnectable = HostConnectabilityStatus("not connectable")
 
+	// HostAlertSeverityError is the severity of alerts about problems that
+	// already cost the host money, such as a missed storage proof.
+	HostAlertSeverityError = HostAlertSeverity("error")
+
+	// HostAlertSeverityWarning is the severity of alerts about problems that
+	// do not yet cost the host money, but will if they are not resolved.
+	HostAlertSeverityWarning = HostAlertSeverity("warning")
+
 	// HostWorkingStatusChecking is returned from WorkingStatus() if the host is
 	// still determining if it is working, that is, if settings calls are
 	// incrementing.
@@ -77,15 +87,92 @@ type (
 		UploadBandwidthRevenue            types.Currency `json:"uploadbandwidthrevenue"`
 	}
 
+	// HostFinancialPeriod summarizes the finances of the host during a period
+	// of time. Revenue, fees and losses are the amounts that were earned or
+	// spent during the period, the contract count and collateral are taken
+	// at the end of the period.
+	HostFinancialPeriod struct {
+		Start time.Time `json:"start"`
+		End   time.Time `json:"end"`
+
+		ContractCount           uint64         `json:"contractcount"`
+		LockedStorageCollateral types.Currency `json:"lockedstoragecollateral"`
+		RiskedStorageCollateral types.Currency `json:"riskedstoragecollateral"`
+
+		ContractCompensation     types.Currency `json:"contractcompensation"`
+		DownloadBandwidthRevenue types.Currency `json:"downloadbandwidthrevenue"`
+		StorageRevenue           types.Currency `json:"storagerevenue"`
+		UploadBandwidthRevenue   types.Currency `json:"uploadbandwidthrevenue"`
+		TotalRevenue             types.Currency `json:"totalrevenue"`
+
+		LostRevenue            types.Currency `json:"lostrevenue"`
+		LostStorageCollateral  types.Currency `json:"loststoragecollateral"`
+		TransactionFeeExpenses types.Currency `json:"transactionfeeexpenses"`
+	}
+
+	// HostFinancialReport contains the finances of the host for each period
+	// of a time range, and the results of the storage obligations that were
+	// resolved within the time range.
+	HostFinancialReport struct {
+		Periods     []HostFinancialPeriod  `json:"periods"`
+		Obligations []HostObligationResult `json:"obligations"`
+	}
+
+	// HostFinancialSnapshot is a copy of the host's financial metrics at a
+	// point in time.
+	HostFinancialSnapshot struct {
+		BlockHeight      types.BlockHeight    `json:"blockheight"`
+		Timestamp        time.Time            `json:"timestamp"`
+		FinancialMetrics HostFinancialMetrics `json:"financialmetrics"`
+	}
+
+	// HostObligationResult is the profit and loss of a storage obligation
+	// after it was resolved. Revenue is only earned by obligations that
+	// succeeded, collateral is only lost by obligations that failed.
+	HostObligationResult struct {
+		ObligationId     types.FileContractID `json:"obligationid"`
+		ObligationStatus string               `json:"obligationstatus"`
+		ResolutionHeight types.BlockHeight    `json:"resolutionheight"`
+		Timestamp        time.Time            `json:"timestamp"`
+
+		ContractCost     types.Currency `json:"contractcost"`
+		DownloadRevenue  types.Currency `json:"downloadrevenue"`
+		StorageRevenue   types.Currency `json:"storagerevenue"`
+		UploadRevenue    types.Currency `json:"uploadrevenue"`
+		Revenue          types.Currency `json:"revenue"`
+		LockedCollateral types.Currency `json:"lockedcollateral"`
+		LostCollateral   types.Currency `json:"lostcollateral"`
+		LostRevenue      types.Currency `json:"lostrevenue"`
+		TransactionFees  types.Currency `json:"transactionfees"`
+	}
+
 	// HostInternalSettings contains a list of settings that can be changed.
+	// The connection, RPC rate and speed limits are disabled when set to
+	// zero. Per-peer limits apply to each IP address and to each renter key
+	// separately. RPC rates are per minute and speeds are in bytes per
+	// second.
 	HostInternalSettings struct {
 		AcceptingContracts   bool              `json:"acceptingcontracts"`
 		MaxDownloadBatchSize uint64            `json:"maxdownloadbatchsize"`
 		MaxDuration          types.BlockHeight `json:"maxduration"`
 		MaxReviseBatchSize   uint64            `json:"maxrevisebatchsize"`
 		NetAddress           NetAddress        `json:"netaddress"`
+		SectorScrubRate      uint64            `json:"sectorscrubrate"`
 		WindowSize           types.BlockHeight `json:"windowsize"`
 
+		MaxConnections          uint64 `json:"maxconnections"`
+		MaxConnectionsPerPeer   uint64 `json:"maxconnectionsperpeer"`
+		MaxDownloadSpeed        int64  `json:"maxdownloadspeed"`
+		MaxDownloadSpeedPerPeer int64  `json:"maxdownloadspeedperpeer"`
+		MaxRPCRate              uint64 `json:"maxrpcrate"`
+		MaxRPCRatePerPeer       uint64 `json:"maxrpcrateperpeer"`
+		MaxUploadSpeed          int64  `json:"maxuploadspeed"`
+		MaxUploadSpeedPerPeer   int64  `json:"maxuploadspeedperpeer"`
+
+		FolderHealthMinOperations uint64  `json:"folderhealthminoperations"`
+		FolderMaxFailedReadRate   float64 `json:"foldermaxfailedreadrate"`
+		FolderMaxFailedWriteRate  float64 `json:"foldermaxfailedwriterate"`
+
 		Collateral       types.Currency `json:"collateral"`
 		CollateralBudget types.Currency `json:"collateralbudget"`
 		MaxCollateral    types.Currency `json:"maxcollateral"`
@@ -96,8 +183,101 @@ type (
 		MinUploadBandwidthPrice   types.Currency `json:"minuploadbandwidthprice"`
 	}
 
+	// HostPrices contains the prices that the host's pricing engine manages.
+	HostPrices struct {
+		Collateral                types.Currency `json:"collateral"`
+		MinContractPrice          types.Currency `json:"mincontractprice"`
+		MinDownloadBandwidthPrice types.Currency `json:"mindownloadbandwidthprice"`
+		MinStoragePrice           types.Currency `json:"minstorageprice"`
+		MinUploadBandwidthPrice   types.Currency `json:"minuploadbandwidthprice"`
+	}
+
+	// HostMaintenance schedules maintenance of the host from block Start up
+	// to and including block End. During maintenance the host does not
+	// accept new contracts, renewals or uploads, but keeps serving downloads
+	// and submitting storage proofs. If Announce is set, the host already
+	// reports that it is not accepting contracts before the maintenance
+	// starts, so that renters stop forming contracts with it.
+	HostMaintenance struct {
+		Start    types.BlockHeight `json:"start"`
+		End      types.BlockHeight `json:"end"`
+		Announce bool              `json:"announce"`
+	}
+
+	// HostMaintenanceConflict is a storage obligation whose proof window
+	// overlaps a scheduled maintenance of the host. The host has to be online
+	// during the proof window to submit the storage proof.
+	HostMaintenanceConflict struct {
+		ObligationID     types.FileContractID `json:"obligationid"`
+		ExpirationHeight types.BlockHeight    `json:"expirationheight"`
+		ProofDeadline    types.BlockHeight    `json:"proofdeadline"`
+	}
+
+	// HostMaintenanceStatus describes the scheduled maintenance of the host.
+	// Maintenance is safe if no storage obligation has its proof window
+	// inside the maintenance.
+	HostMaintenanceStatus struct {
+		HostMaintenance
+		Active    bool                      `json:"active"`
+		Scheduled bool                      `json:"scheduled"`
+		Safe      bool                      `json:"safe"`
+		Conflicts []HostMaintenanceConflict `json:"conflicts"`
+	}
+
+	// HostPricingFillRule raises the storage price derived from the market
+	// by Multiplier once the host's storage utilization reaches Utilization.
+	// Utilization is a fraction between 0 and 1.
+	HostPricingFillRule struct {
+		Utilization float64 `json:"utilization"`
+		Multiplier  float64 `json:"multiplier"`
+	}
+
+	// HostPricingPolicy configures the host's pricing engine. When enabled,
+	// the engine sets the host's prices to the Percentile of the prices
+	// announced by the other hosts on the network every UpdateInterval
+	// blocks. The storage price is then adjusted by the fill rule with the
+	// highest utilization that the host has reached, and kept within
+	// MinStoragePrice and MaxStoragePrice. A zero MaxStoragePrice means that
+	// the storage price is not capped.
+	HostPricingPolicy struct {
+		Enabled        bool                  `json:"enabled"`
+		FillRules      []HostPricingFillRule `json:"fillrules"`
+		Percentile     float64               `json:"percentile"`
+		UpdateInterval types.BlockHeight     `json:"updateinterval"`
+
+		MaxStoragePrice types.Currency `json:"maxstorageprice"`
+		MinStoragePrice types.Currency `json:"minstorageprice"`
+	}
+
+	// HostPricingEstimate is the result of a dry run of the host's pricing
+	// engine. It contains the prices that the engine would apply given the
+	// current market and the current storage utilization of the host.
+	HostPricingEstimate struct {
+		Current  HostPrices `json:"current"`
+		Proposed HostPrices `json:"proposed"`
+
+		FillMultiplier     float64 `json:"fillmultiplier"`
+		MarketHosts        uint64  `json:"markethosts"`
+		StorageUtilization float64 `json:"storageutilization"`
+	}
+
+	// HostPriceChange is an entry in the history of price changes made by
+	// the host's pricing engine.
+	HostPriceChange struct {
+		BlockHeight types.BlockHeight `json:"blockheight"`
+		Timestamp   time.Time         `json:"timestamp"`
+
+		NewPrices HostPrices `json:"newprices"`
+		OldPrices HostPrices `json:"oldprices"`
+
+		FillMultiplier     float64 `json:"fillmultiplier"`
+		MarketHosts        uint64  `json:"markethosts"`
+		StorageUtilization float64 `json:"storageutilization"`
+	}
+
 	// HostNetworkMetrics reports the quantity of each type of RPC call that
-	// has been made to the host.
+	// has been made to the host, and the number of connections and RPCs that
+	// were rejected for exceeding the host's limits.
 	HostNetworkMetrics struct {
 		DownloadCalls     uint64 `json:"downloadcalls"`
 		ErrorCalls        uint64 `json:"errorcalls"`
@@ -106,6 +286,9 @@ type (
 		ReviseCalls       uint64 `json:"revisecalls"`
 		SettingsCalls     uint64 `json:"settingscalls"`
 		UnrecognizedCalls uint64 `json:"unrecognizedcalls"`
+
+		RejectedConnections uint64 `json:"rejectedconnections"`
+		RejectedRPCs        uint64 `json:"rejectedrpcs"`
 	}
 
 	// StorageObligation contains information about a storage obligation that
@@ -140,8 +323,102 @@ type (
 		RevisionConstructed bool   `json:"revisionconstructed"`
 	}
 
+	// HostObligationDetails contains information about a single storage
+	// obligation, including the heights at which the host has scheduled
+	// actions for the obligation, such as submitting its revision or
+	// storage proof, and the storage proofs the host attempted.
+	HostObligationDetails struct {
+		StorageObligation
+		ActionItems  []types.BlockHeight `json:"actionitems"`
+		ProofHistory []HostProofAttempt  `json:"proofhistory"`
+	}
+
+	// HostProofAttempt describes an attempt of the host to build a storage
+	// proof for a storage obligation. Dry runs are performed shortly before
+	// the proof window opens and build a proof for a random segment without
+	// submitting it, so that missing or corrupt data is found while there is
+	// still time to fix it.
+	HostProofAttempt struct {
+		Height       types.BlockHeight `json:"height"`
+		DryRun       bool              `json:"dryrun"`
+		SegmentIndex uint64            `json:"segmentindex"`
+		Success      bool              `json:"success"`
+		Error        string            `json:"error"`
+	}
+
+	// HostObligationPage is a page of the host's storage obligations. If
+	// there are more obligations, NextCursor can be used to request the next
+	// page.
+	HostObligationPage struct {
+		Obligations []StorageObligation `json:"obligations"`
+		NextCursor  string              `json:"nextcursor"`
+	}
+
+	// HostObligationQuery filters, sorts and paginates the host's storage
+	// obligations. Empty or nil fields don't filter, and a MaxExpiration of
+	// zero means that there is no maximum. Obligations are sorted by SortBy,
+	// which is one of "id", "expiration", "negotiation" or "proofdeadline".
+	// Cursor is the id of the last obligation of the previous page, and a
+	// Limit of zero returns all remaining obligations.
+	HostObligationQuery struct {
+		MaxExpiration    types.BlockHeight
+		MinExpiration    types.BlockHeight
+		ProofConfirmed   *bool
+		ProofConstructed *bool
+		Status           string
+
+		Cursor     string
+		Descending bool
+		Limit      int
+		SortBy     string
+	}
+
 	// HostWorkingStatus reports the working state of a host. Can be one of
 	// "checking", "working", or "not working".
+	// HostAlert describes a problem with the host that requires the
+	// attention of the host operator.
+	HostAlert struct {
+		Cause    string            `json:"cause"`
+		Msg      string            `json:"msg"`
+		Severity HostAlertSeverity `json:"severity"`
+	}
+
+	// HostAlertSeverity indicates how urgent an alert is.
+	HostAlertSeverity string
+
+	// HostSector describes a sector stored by the host and the storage
+	// obligations that reference it. References is the number of times the
+	// sector appears in the host's unresolved storage obligations, which
+	// should match the number of virtual sectors tracked by the storage
+	// manager.
+	HostSector struct {
+		SectorInfo
+		Obligations []types.FileContractID `json:"obligations"`
+		References  uint64                 `json:"references"`
+	}
+
+	// HostSectorAudit is the result of cross-checking the sectors referenced
+	// by the host's unresolved storage obligations against the storage
+	// manager. Missing sectors are referenced but not stored, miscounted
+	// sectors are stored a different number of times than they are
+	// referenced, and orphaned sectors are stored but not referenced by any
+	// storage obligation. Orphaned sectors are identified by their storage
+	// manager ID, because their roots are unknown.
+	HostSectorAudit struct {
+		ReferencedSectors uint64         `json:"referencedsectors"`
+		MissingSectors    []HostSector   `json:"missingsectors"`
+		MiscountedSectors []HostSector   `json:"miscountedsectors"`
+		OrphanedSectors   []StoredSector `json:"orphanedsectors"`
+	}
+
+	// HostSectorPage is a page of the sectors referenced by the host's
+	// storage obligations, sorted by root. If there are more sectors,
+	// NextCursor can be used to request the next page.
+	HostSectorPage struct {
+		Sectors    []HostSector `json:"sectors"`
+		NextCursor string       `json:"nextcursor"`
+	}
+
 	HostWorkingStatus string
 
 	// HostConnectabilityStatus reports the connectability state of a host. Can be
@@ -152,12 +429,20 @@ type (
 	// things such as announcements, settings, and implementing all of the RPCs
 	// of the host protocol.
 	Host interface {
+		// Alerts returns the problems with the host that require the
+		// attention of the host operator.
+		Alerts() []HostAlert
+
 		// Announce submits a host announcement to the blockchain.
 		Announce() error
 
 		// AnnounceAddress submits an announcement using the given address.
 		AnnounceAddress(NetAddress) error
 
+		// AuditSectors cross-checks the sectors referenced by the host's
+		// storage obligations against the storage manager.
+		AuditSectors() (HostSectorAudit, error)
+
 		// ExternalSettings returns the settings of the host as seen by an
 		// untrusted node querying the host for settings.
 		ExternalSettings() HostExternalSettings
@@ -165,24 +450,65 @@ type (
 		// FinancialMetrics returns the financial statistics of the host.
 		FinancialMetrics() HostFinancialMetrics
 
+		// FinancialReport returns the finances of the host between from and
+		// to, split into periods of the given interval. The interval is
+		// either "day", "week", "month", or "" for a single period.
+		FinancialReport(from, to time.Time, interval string) (HostFinancialReport, error)
+
 		// InternalSettings returns the host's internal settings, including
 		// potentially private or sensitive information.
 		InternalSettings() HostInternalSettings
 
+		// Maintenance returns the scheduled maintenance of the host and the
+		// storage obligations that conflict with it.
+		Maintenance() (HostMaintenanceStatus, error)
+
 		// NetworkMetrics returns information on the types of RPC calls that
 		// have been made to the host.
 		NetworkMetrics() HostNetworkMetrics
 
+		// PriceHistory returns the price changes that were made by the
+		// host's pricing engine, oldest first.
+		PriceHistory() ([]HostPriceChange, error)
+
+		// PricingEstimate performs a dry run of the host's pricing engine and
+		// returns the prices that it would apply right now.
+		PricingEstimate() (HostPricingEstimate, error)
+
+		// PricingPolicy returns the policy of the host's pricing engine.
+		PricingPolicy() HostPricingPolicy
+
 		// PublicKey returns the public key of the host.
 		PublicKey() types.SiaPublicKey
 
+		// Sectors returns a page of the sectors referenced by the host's
+		// storage obligations, starting after the root in cursor. A limit
+		// of zero returns all remaining sectors.
+		Sectors(cursor string, limit int) (HostSectorPage, error)
+
 		// SetInternalSettings sets the hosting parameters of the host.
 		SetInternalSettings(HostInternalSettings) error
 
+		// SetMaintenance schedules maintenance of the host, replacing any
+		// maintenance that was scheduled before. An empty maintenance
+		// cancels the scheduled maintenance.
+		SetMaintenance(HostMaintenance) error
+
+		// SetPricingPolicy sets the policy of the host's pricing engine.
+		SetPricingPolicy(HostPricingPolicy) error
+
+		// StorageObligation returns the details of the storage obligation
+		// with the given id.
+		StorageObligation(types.FileContractID) (HostObligationDetails, error)
+
 		// StorageObligations returns the set of storage obligations held by
 		// the host.
 		StorageObligations() []StorageObligation
 
+		// StorageObligationsQuery returns a page of the storage obligations
+		// held by the host that match the query.
+		StorageObligationsQuery(HostObligationQuery) (HostObligationPage, error)
+
 		// ConnectabilityStatus returns the connectability status of the host, that
 		// is, if it can connect to itself on the configured NetAddress.
 		ConnectabilityStatus() HostConnectabilityStatus
@@ -0,0 +1,51 @@
+package host
+
+import (
+	"fmt"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+
+	"github.com/coreos/bbolt"
+)
+
+// Alerts returns the problems with the host that require the attention of the
+// host operator.
+func (h *Host) Alerts() []modules.HostAlert {
+	alerts := []modules.HostAlert{}
+	if err := h.tg.Add(); err != nil {
+		return alerts
+	}
+	defer h.tg.Done()
+
+	for _, sf := range h.StorageFolders() {
+		if !sf.Degraded {
+			continue
+		}
+		msg := fmt.Sprintf("storage folder %v is degraded and no longer receives new sectors", sf.Path)
+		if sf.CapacityRemaining < sf.Capacity {
+			msg += ", its sectors are being moved to other storage folders"
+		}
+		alerts = append(alerts, modules.HostAlert{
+			Cause:    fmt.Sprintf("%v failed reads and %v failed writes", sf.FailedReads, sf.FailedWrites),
+			Msg:      msg,
+			Severity: modules.HostAlertSeverityWarning,
+		})
+	}
+
+	h.mu.RLock()
+	err := h.db.View(func(tx *bolt.Tx) error {
+		proofAlerts, err := h.proofAlerts(tx)
+		if err != nil {
+			return err
+		}
+		alerts = append(alerts, proofAlerts...)
+		maintenanceAlerts, err := h.maintenanceAlerts(tx)
+		alerts = append(alerts, maintenanceAlerts...)
+		return err
+	})
+	h.mu.RUnlock()
+	if err != nil {
+		h.log.Println("Unable to check the storage obligations for alerts:", err)
+	}
+	return alerts
+}
@@ -0,0 +1,239 @@
+package host
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/ratelimit"
+)
+
+var (
+	// errMaxConnections is returned if a connection is rejected because the
+	// host has reached its maximum number of concurrent connections.
+	errMaxConnections = errors.New("host has reached its maximum number of connections")
+
+	// errMaxConnectionsPerPeer is returned if a connection is rejected
+	// because the peer has reached its maximum number of concurrent
+	// connections.
+	errMaxConnectionsPerPeer = errors.New("peer has reached its maximum number of connections")
+
+	// errMaxRPCRate is returned if an RPC is rejected because the host has
+	// reached its maximum RPC rate.
+	errMaxRPCRate = errors.New("host has reached its maximum RPC rate")
+
+	// errMaxRPCRatePerPeer is returned if an RPC is rejected because the peer
+	// has reached its maximum RPC rate.
+	errMaxRPCRatePerPeer = errors.New("peer has reached its maximum RPC rate")
+)
+
+type (
+	// peerUsage tracks the resources used by a single peer of the host. A
+	// peer is either an IP address or a renter key.
+	peerUsage struct {
+		conns uint64
+		rpcs  uint64
+		rl    *ratelimit.RateLimit
+	}
+
+	// connLimiter tracks the resources used by the connections to the host
+	// and decides whether new connections are allowed. Every connection
+	// carries a single RPC, so the RPC rate is the rate at which connections
+	// are accepted.
+	connLimiter struct {
+		conns       uint64
+		rpcs        uint64
+		windowStart time.Time
+		rl          *ratelimit.RateLimit
+		peers       map[string]*peerUsage
+		mu          sync.Mutex
+	}
+
+	// limitedConn is a connection that counts towards the limits of its
+	// peers. Its bandwidth is limited by the host's limits and by the limits
+	// of each of its peers. The limits of a renter are added to the embedded
+	// connection once the renter is known, which is why closing the
+	// connection closes the underlying connection directly.
+	limitedConn struct {
+		net.Conn
+		peers []string
+		raw   net.Conn
+	}
+)
+
+// newConnLimiter returns a connLimiter without any limits.
+func newConnLimiter() *connLimiter {
+	return &connLimiter{
+		windowStart: time.Now(),
+		rl:          ratelimit.NewRateLimit(0, 0, 0),
+		peers:       make(map[string]*peerUsage),
+	}
+}
+
+// Close closes the underlying connection.
+func (lc *limitedConn) Close() error {
+	return lc.raw.Close()
+}
+
+// ipPeer returns the peer of the IP address that a connection originates
+// from.
+func ipPeer(conn net.Conn) string {
+	addr := conn.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return "ip:" + addr
+}
+
+// renterPeer returns the peer of a renter key.
+func renterPeer(pk types.SiaPublicKey) string {
+	return "renter:" + pk.String()
+}
+
+// resetWindow starts a new RPC rate window if the current window has passed.
+// Peers without any connections are forgotten when a new window starts.
+func (cl *connLimiter) resetWindow() {
+	if time.Since(cl.windowStart) < rpcRateWindow {
+		return
+	}
+	cl.windowStart = time.Now()
+	cl.rpcs = 0
+	for peer, pu := range cl.peers {
+		if pu.conns == 0 {
+			delete(cl.peers, peer)
+			continue
+		}
+		pu.rpcs = 0
+	}
+}
+
+// managedAcquire adds a connection of the peer, returning the rate limit of
+// the peer. If global is false, the connection is already counted towards
+// the host's limits and only the limits of the peer are checked.
+func (cl *connLimiter) managedAcquire(peer string, global bool, settings modules.HostInternalSettings) (*ratelimit.RateLimit, error) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.resetWindow()
+
+	pu, exists := cl.peers[peer]
+	if !exists {
+		pu = &peerUsage{
+			rl: ratelimit.NewRateLimit(settings.MaxUploadSpeedPerPeer, settings.MaxDownloadSpeedPerPeer, connRatelimitPacketSize),
+		}
+	}
+	if global && settings.MaxConnections != 0 && cl.conns >= settings.MaxConnections {
+		return nil, errMaxConnections
+	}
+	if settings.MaxConnectionsPerPeer != 0 && pu.conns >= settings.MaxConnectionsPerPeer {
+		return nil, errMaxConnectionsPerPeer
+	}
+	if global && settings.MaxRPCRate != 0 && cl.rpcs >= settings.MaxRPCRate {
+		return nil, errMaxRPCRate
+	}
+	if settings.MaxRPCRatePerPeer != 0 && pu.rpcs >= settings.MaxRPCRatePerPeer {
+		return nil, errMaxRPCRatePerPeer
+	}
+
+	if global {
+		cl.conns++
+		cl.rpcs++
+	}
+	pu.conns++
+	pu.rpcs++
+	cl.peers[peer] = pu
+	return pu.rl, nil
+}
+
+// managedRelease removes a connection of the peer.
+func (cl *connLimiter) managedRelease(peer string, global bool) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if global {
+		cl.conns--
+	}
+	pu, exists := cl.peers[peer]
+	if !exists {
+		return
+	}
+	pu.conns--
+	if pu.conns == 0 && pu.rpcs == 0 {
+		delete(cl.peers, peer)
+	}
+}
+
+// managedSetLimits applies the bandwidth limits of the settings to the host
+// and to all of its peers.
+func (cl *connLimiter) managedSetLimits(settings modules.HostInternalSettings) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.rl.SetLimits(settings.MaxUploadSpeed, settings.MaxDownloadSpeed, connRatelimitPacketSize)
+	for _, pu := range cl.peers {
+		pu.rl.SetLimits(settings.MaxUploadSpeedPerPeer, settings.MaxDownloadSpeedPerPeer, connRatelimitPacketSize)
+	}
+}
+
+// managedCountRejection adds a rejection to the network metrics of the host.
+func (h *Host) managedCountRejection(err error) {
+	switch err {
+	case errMaxConnections, errMaxConnectionsPerPeer:
+		atomic.AddUint64(&h.atomicRejectedConnections, 1)
+	case errMaxRPCRate, errMaxRPCRatePerPeer:
+		atomic.AddUint64(&h.atomicRejectedRPCs, 1)
+	}
+}
+
+// managedLimitConn applies the host's limits and the limits of the IP address
+// to an incoming connection. The connection needs to be released using
+// managedReleaseConn.
+func (h *Host) managedLimitConn(conn net.Conn) (*limitedConn, error) {
+	h.mu.RLock()
+	settings := h.settings
+	h.mu.RUnlock()
+
+	peer := ipPeer(conn)
+	rl, err := h.connLimiter.managedAcquire(peer, true, settings)
+	if err != nil {
+		h.managedCountRejection(err)
+		return nil, err
+	}
+	rlConn := ratelimit.NewRLConn(conn, h.connLimiter.rl, h.tg.StopChan())
+	rlConn = ratelimit.NewRLConn(rlConn, rl, h.tg.StopChan())
+	return &limitedConn{
+		Conn:  rlConn,
+		peers: []string{peer},
+		raw:   conn,
+	}, nil
+}
+
+// managedLimitRenter applies the limits of a renter to a connection once the
+// renter has identified itself.
+func (h *Host) managedLimitRenter(conn net.Conn, pk types.SiaPublicKey) error {
+	lc, ok := conn.(*limitedConn)
+	if !ok {
+		return nil
+	}
+	h.mu.RLock()
+	settings := h.settings
+	h.mu.RUnlock()
+
+	peer := renterPeer(pk)
+	rl, err := h.connLimiter.managedAcquire(peer, false, settings)
+	if err != nil {
+		h.managedCountRejection(err)
+		return err
+	}
+	lc.Conn = ratelimit.NewRLConn(lc.Conn, rl, h.tg.StopChan())
+	lc.peers = append(lc.peers, peer)
+	return nil
+}
+
+// managedReleaseConn releases a connection from the limits of its peers.
+func (h *Host) managedReleaseConn(lc *limitedConn) {
+	for i, peer := range lc.peers {
+		h.connLimiter.managedRelease(peer, i == 0)
+	}
+}
@@ -0,0 +1,157 @@
+package host
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/encoding"
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+// TestConnLimiter probes the connection and RPC rate limits of the
+// connLimiter.
+func TestConnLimiter(t *testing.T) {
+	cl := newConnLimiter()
+	settings := modules.HostInternalSettings{
+		MaxConnections:        3,
+		MaxConnectionsPerPeer: 2,
+	}
+
+	// The per-peer limit should apply to each peer separately.
+	for i := 0; i < 2; i++ {
+		if _, err := cl.managedAcquire("ip:foo", true, settings); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := cl.managedAcquire("ip:foo", true, settings); err != errMaxConnectionsPerPeer {
+		t.Fatal("expected errMaxConnectionsPerPeer, got", err)
+	}
+	if _, err := cl.managedAcquire("ip:bar", true, settings); err != nil {
+		t.Fatal(err)
+	}
+	// The global limit should apply to all peers together.
+	if _, err := cl.managedAcquire("ip:baz", true, settings); err != errMaxConnections {
+		t.Fatal("expected errMaxConnections, got", err)
+	}
+	// Renter peers don't count towards the global limit.
+	if _, err := cl.managedAcquire("renter:foo", false, settings); err != nil {
+		t.Fatal(err)
+	}
+	cl.managedRelease("renter:foo", false)
+	// Releasing a connection should make room for a new one.
+	cl.managedRelease("ip:foo", true)
+	if _, err := cl.managedAcquire("ip:baz", true, settings); err != nil {
+		t.Fatal(err)
+	}
+	if cl.conns != 3 {
+		t.Fatal("expected 3 connections, got", cl.conns)
+	}
+
+	// The RPC rates should count every acquired connection in the window,
+	// including connections that have been released.
+	cl = newConnLimiter()
+	settings = modules.HostInternalSettings{
+		MaxRPCRate:        3,
+		MaxRPCRatePerPeer: 2,
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := cl.managedAcquire("ip:foo", true, settings); err != nil {
+			t.Fatal(err)
+		}
+		cl.managedRelease("ip:foo", true)
+	}
+	if _, err := cl.managedAcquire("ip:foo", true, settings); err != errMaxRPCRatePerPeer {
+		t.Fatal("expected errMaxRPCRatePerPeer, got", err)
+	}
+	if _, err := cl.managedAcquire("ip:bar", true, settings); err != nil {
+		t.Fatal(err)
+	}
+	cl.managedRelease("ip:bar", true)
+	if _, err := cl.managedAcquire("ip:baz", true, settings); err != errMaxRPCRate {
+		t.Fatal("expected errMaxRPCRate, got", err)
+	}
+	// A new window should reset the rates and forget the idle peers.
+	cl.windowStart = time.Now().Add(-rpcRateWindow)
+	if _, err := cl.managedAcquire("ip:foo", true, settings); err != nil {
+		t.Fatal(err)
+	}
+	if len(cl.peers) != 1 {
+		t.Fatal("expected idle peers to be forgotten, got", len(cl.peers))
+	}
+}
+
+// TestHostConnectionLimits checks that the host rejects connections that
+// exceed its limits and reports the rejections in its network metrics.
+func TestHostConnectionLimits(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	settings := ht.host.InternalSettings()
+	settings.MaxConnectionsPerPeer = 1
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	// Negative speeds should be rejected.
+	settings.MaxDownloadSpeed = -1
+	if err := ht.host.SetInternalSettings(settings); err == nil {
+		t.Fatal("expected a negative speed to be rejected")
+	}
+
+	// Open a connection and keep it open while opening another one.
+	addr := string(ht.host.NetAddress())
+	conn1, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn1.Close()
+	err = build.Retry(50, 100*time.Millisecond, func() error {
+		conn2, err := net.Dial("tcp", addr)
+		if err != nil {
+			return err
+		}
+		defer conn2.Close()
+		conn2.SetDeadline(time.Now().Add(5 * time.Second))
+		if _, err := conn2.Read(make([]byte, 1)); err == nil {
+			return errors.New("expected the connection to be closed")
+		}
+		if ht.host.NetworkMetrics().RejectedConnections == 0 {
+			return errors.New("rejection wasn't counted")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the first connection is closed, RPCs should succeed again.
+	conn1.Close()
+	err = build.Retry(50, 100*time.Millisecond, func() error {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if err := encoding.WriteObject(conn, modules.RPCSettings); err != nil {
+			return err
+		}
+		var pk crypto.PublicKey
+		copy(pk[:], ht.host.PublicKey().Key)
+		var hes modules.HostExternalSettings
+		return crypto.ReadSignedObject(conn, &hes, maxMarketSettingsLen, pk)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -9,6 +9,10 @@ import (
 )
 
 const (
+	// connRatelimitPacketSize is the packet size used to limit the bandwidth
+	// of the connections to the host.
+	connRatelimitPacketSize = 4 * 4096
+
 	// defaultMaxDuration defines the maximum number of blocks into the future
 	// that the host will accept for the duration of an incoming file contract
 	// obligation. 6 months is chosen because hosts are expected to be
@@ -21,11 +25,36 @@ const (
 	// necessary to limit the impact of DoS attacks.
 	fileContractNegotiationTimeout = 120 * time.Second
 
+	// defaultPricingPercentile is the percentile of the market prices that
+	// the pricing engine targets by default.
+	defaultPricingPercentile = 50
+
+	// maxReportPeriods is the maximum number of periods in a financial
+	// report.
+	maxReportPeriods = 10e3
+
 	// iteratedConnectionTime is the amount of time that is allowed to pass
 	// before the host will stop accepting new iterations on an iterated
 	// connection.
 	iteratedConnectionTime = 1200 * time.Second
 
+	// marketScanThreads is the number of hosts that the host scans in
+	// parallel when it refreshes its view of the market.
+	marketScanThreads = 10
+
+	// maxMarketSettingsLen is the maximum length in bytes of the external
+	// settings that the host accepts from another host during a market scan.
+	maxMarketSettingsLen = 10e3
+
+	// rpcRateWindow is the window over which the RPC rates of the host and
+	// its peers are measured. The RPC rate limits are the maximum number of
+	// RPCs within a window.
+	rpcRateWindow = time.Minute
+
+	// maxProofHistory is the maximum number of storage proof attempts that
+	// the host remembers for each storage obligation.
+	maxProofHistory = 10
+
 	// resubmissionTimeout defines the number of blocks that a host will wait
 	// before attempting to resubmit a transaction to the blockchain.
 	// Typically, this transaction will contain either a file contract, a file
@@ -42,6 +71,15 @@ var (
 		Testing:  time.Second * 3,
 	}).(time.Duration)
 
+	// corruptSectorCheckFrequency defines how often the host checks whether
+	// the sector scrubber found new corrupt sectors, so that the affected
+	// storage obligations can be logged.
+	corruptSectorCheckFrequency = build.Select(build.Var{
+		Standard: time.Minute * 10,
+		Dev:      time.Minute * 1,
+		Testing:  time.Second * 1,
+	}).(time.Duration)
+
 	// connectablityCheckFrequency defines how often the host's connectability
 	// check is run.
 	connectabilityCheckFrequency = build.Select(build.Var{
@@ -58,6 +96,15 @@ var (
 		Testing:  time.Second * 90,
 	}).(time.Duration)
 
+	// sectorReferencesCacheTimeout defines how long the host pages through
+	// the sector references that it collected for the first page of a sector
+	// query before collecting them again.
+	sectorReferencesCacheTimeout = build.Select(build.Var{
+		Standard: time.Minute * 10,
+		Dev:      time.Minute * 1,
+		Testing:  time.Second * 5,
+	}).(time.Duration)
+
 	// defaultCollateral defines the amount of money that the host puts up as
 	// collateral per-byte by default. The collateral should be considered as
 	// an absolute instead of as a percentage, because low prices result in
@@ -88,6 +135,23 @@ var (
 	// download bandwidth is expected to be plentiful but also in-demand.
 	defaultDownloadBandwidthPrice = types.SiacoinPrecision.Mul64(25).Div(modules.BytesPerTerabyte) // 25 SC / TB
 
+	// defaultFolderHealthMinOperations defines the number of reads or writes
+	// that a storage folder needs to have performed before its failure rate
+	// is checked against the health thresholds.
+	defaultFolderHealthMinOperations = build.Select(build.Var{
+		Standard: uint64(100),
+		Dev:      uint64(100),
+		Testing:  uint64(10),
+	}).(uint64)
+
+	// defaultFolderMaxFailedReadRate and defaultFolderMaxFailedWriteRate
+	// define the fraction of reads and writes that are allowed to fail before
+	// a storage folder is considered degraded. A healthy disk should not
+	// return any errors, so a few percent of failures indicate that the disk
+	// is failing.
+	defaultFolderMaxFailedReadRate  = 0.05
+	defaultFolderMaxFailedWriteRate = 0.05
+
 	// defaultMaxCollateral defines the maximum amount of collateral that the
 	// host is comfortable putting into a single file contract. 10e3 is a
 	// relatively small file contract, but millions of siacoins could be locked
@@ -115,6 +179,24 @@ var (
 	// with a number like 65 MiB.
 	defaultMaxReviseBatchSize = 17 * (1 << 20)
 
+	// defaultPricingUpdateInterval is the number of blocks between two
+	// price updates of the pricing engine by default.
+	defaultPricingUpdateInterval = build.Select(build.Var{
+		Dev:      types.BlockHeight(20),
+		Standard: types.BlockHeight(144), // 1 day.
+		Testing:  types.BlockHeight(3),
+	}).(types.BlockHeight)
+
+	// defaultSectorScrubRate defines the number of bytes per second that the
+	// storage manager reads to verify the integrity of the stored sectors. 4
+	// MiB/s scrubs one sector per second, which takes about 12 days for 4 TiB
+	// of data while barely affecting the performance of the disks.
+	defaultSectorScrubRate = build.Select(build.Var{
+		Standard: uint64(1 << 22), // 4 MiB/s
+		Dev:      uint64(1 << 22), // 4 MiB/s
+		Testing:  uint64(1 << 16), // 64 KiB/s
+	}).(uint64)
+
 	// defaultStoragePrice defines the starting price for hosts selling
 	// storage. We try to match a number that is both reasonably profitable and
 	// reasonably competitive.
@@ -142,6 +224,14 @@ var (
 		Testing:  types.BlockHeight(5),   // 5 seconds.
 	}).(types.BlockHeight)
 
+	// financialSnapshotFrequency defines how often the host stores a
+	// snapshot of its financial metrics.
+	financialSnapshotFrequency = build.Select(build.Var{
+		Standard: time.Hour,
+		Dev:      time.Minute * 5,
+		Testing:  time.Second,
+	}).(time.Duration)
+
 	// logAllLimit is the number of errors of each type that the host will log
 	// before switching to probabilistic logging. If there are not many errors,
 	// it is reasonable that all errors get logged. If there are lots of
@@ -161,6 +251,23 @@ var (
 		Testing:  uint64(500),
 	}).(uint64)
 
+	// marketScanFrequency defines how often the host refreshes the settings
+	// of the other hosts on the network, which are used by the pricing
+	// engine.
+	marketScanFrequency = build.Select(build.Var{
+		Dev:      time.Minute * 10,
+		Standard: time.Hour * 6,
+		Testing:  time.Second * 5,
+	}).(time.Duration)
+
+	// marketScanTimeout defines how long the host waits for another host to
+	// respond with its settings during a market scan.
+	marketScanTimeout = build.Select(build.Var{
+		Dev:      time.Second * 30,
+		Standard: time.Minute,
+		Testing:  time.Second * 5,
+	}).(time.Duration)
+
 	// maximumLockedStorageObligations sets the maximum number of storage
 	// obligations that are allowed to be locked at a time. The map uses an
 	// in-memory lock, but also a locked storage obligation could be reading a
@@ -180,6 +287,41 @@ var (
 		Testing:  time.Second * 3,
 	}).(time.Duration)
 
+	// minMarketHosts is the minimum number of hosts whose settings are
+	// known before the pricing engine derives prices from the market.
+	minMarketHosts = build.Select(build.Var{
+		Dev:      3,
+		Standard: 10,
+		Testing:  1,
+	}).(int)
+
+	// pricingCheckFrequency defines how often the pricing engine checks
+	// whether a price update is due.
+	pricingCheckFrequency = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: time.Minute * 10,
+		Testing:  time.Second,
+	}).(time.Duration)
+
+	// proofAlertDuration is the number of blocks after the proof deadline
+	// during which the host alerts about a storage proof that was not
+	// confirmed.
+	proofAlertDuration = build.Select(build.Var{
+		Dev:      types.BlockHeight(20),  // About 4 minutes
+		Standard: types.BlockHeight(144), // 1 day.
+		Testing:  types.BlockHeight(10),
+	}).(types.BlockHeight)
+
+	// proofDryRunBuffer is the number of blocks before the proof window opens
+	// that the host performs a dry run of the storage proof. It is smaller
+	// than the revision submission buffer, so that the data of the storage
+	// obligation can no longer change after the dry run.
+	proofDryRunBuffer = build.Select(build.Var{
+		Dev:      types.BlockHeight(10), // About 2 minutes
+		Standard: types.BlockHeight(72), // 12 hours.
+		Testing:  types.BlockHeight(2),
+	}).(types.BlockHeight)
+
 	// revisionSubmissionBuffer describes the number of blocks ahead of time
 	// that the host will submit a file contract revision. The host will not
 	// accept any more revisions once inside the submission buffer.
@@ -235,6 +377,26 @@ var (
 	// using the id.
 	bucketActionItems = []byte("BucketActionItems")
 
+	// bucketFinancialSnapshots contains periodic snapshots of the host's
+	// financial metrics, keyed by the big endian unix nano timestamp of the
+	// snapshot.
+	bucketFinancialSnapshots = []byte("BucketFinancialSnapshots")
+
+	// bucketMarketHosts contains the hosts that were announced on the
+	// blockchain together with the settings that were last obtained from
+	// them, sorted by their public key. The pricing engine derives the host's
+	// prices from these settings.
+	bucketMarketHosts = []byte("BucketMarketHosts")
+
+	// bucketObligationResults contains the profit and loss of each resolved
+	// storage obligation, keyed by the big endian unix nano timestamp of the
+	// resolution followed by the file contract id.
+	bucketObligationResults = []byte("BucketObligationResults")
+
+	// bucketPriceHistory contains the price changes made by the pricing
+	// engine, keyed by a big endian sequence number.
+	bucketPriceHistory = []byte("BucketPriceHistory")
+
 	// bucketStorageObligations contains a set of serialized
 	// 'storageObligations' sorted by their file contract id.
 	bucketStorageObligations = []byte("BucketStorageObligations")
@@ -249,4 +411,9 @@ func init() {
 	if revisionSubmissionBuffer < resubmissionTimeout {
 		build.Critical("revision submission buffer needs to be larger than or equal to the resubmission timeout")
 	}
+	// The storage proof dry run should happen after the host stopped
+	// accepting revisions, so that it checks the data that will be proven.
+	if proofDryRunBuffer >= revisionSubmissionBuffer {
+		build.Critical("proof dry run buffer needs to be smaller than the revision submission buffer")
+	}
 }
@@ -129,3 +129,23 @@ var (
 		Testing:  time.Second * 8,
 	}).(time.Duration)
 )
+
+var (
+	// folderHealthCheckInterval specifies how often the contract manager
+	// checks the health of the storage folders and moves sectors out of
+	// degraded storage folders.
+	folderHealthCheckInterval = build.Select(build.Var{
+		Dev:      time.Second * 10,
+		Standard: time.Minute * 5,
+		Testing:  time.Millisecond * 100,
+	}).(time.Duration)
+
+	// scrubIdleInterval specifies the amount of time that the sector scrubber
+	// waits before starting a new pass if the contract manager has no
+	// sectors or scrubbing is disabled.
+	scrubIdleInterval = build.Select(build.Var{
+		Dev:      time.Second * 10,
+		Standard: time.Minute * 10,
+		Testing:  time.Millisecond * 100,
+	}).(time.Duration)
+)
@@ -93,6 +93,16 @@ type ContractManager struct {
 	// or modified.
 	lockedSectors map[sectorID]*sectorLock
 
+	// corruptSectors contains the sectors that the scrubber found to be
+	// corrupt. The scrubber keeps track of its progress in the scrubber
+	// field.
+	corruptSectors map[sectorID]struct{}
+	scrubber       *sectorScrubber
+
+	// healthThresholds determine when a storage folder is considered
+	// degraded.
+	healthThresholds modules.StorageFolderHealthThresholds
+
 	// Utilities.
 	dependencies modules.Dependencies
 	log          *persist.Logger
@@ -115,6 +125,11 @@ func newContractManager(dependencies modules.Dependencies, persistDir string) (*
 
 		lockedSectors: make(map[sectorID]*sectorLock),
 
+		corruptSectors: make(map[sectorID]struct{}),
+		scrubber: &sectorScrubber{
+			rateChanged: make(chan struct{}, 1),
+		},
+
 		dependencies: dependencies,
 		persistDir:   persistDir,
 	}
@@ -211,6 +226,8 @@ func newContractManager(dependencies modules.Dependencies, persistDir string) (*
 	// Spin up the thread that continuously looks for missing storage folders
 	// and adds them if they are discovered.
 	go cm.threadedFolderRecheck()
+	go cm.threadedScrubSectors()
+	go cm.threadedMonitorFolderHealth()
 
 	// Simulate an error to make sure the cleanup code is triggered correctly.
 	if cm.dependencies.Disrupt("erroredStartup") {
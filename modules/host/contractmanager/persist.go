@@ -16,9 +16,10 @@ type (
 	// savedStorageFolder contains fields that are saved automatically to disk
 	// for each storage folder.
 	savedStorageFolder struct {
-		Index uint16
-		Path  string
-		Usage []uint64
+		Index    uint16
+		Path     string
+		Usage    []uint64
+		Degraded bool
 	}
 
 	// savedSettings contains fields that are saved atomically to disk inside
@@ -32,9 +33,10 @@ type (
 // savedStorageFolder returns the persistent version of the storage folder.
 func (sf *storageFolder) savedStorageFolder() savedStorageFolder {
 	ssf := savedStorageFolder{
-		Index: sf.index,
-		Path:  sf.path,
-		Usage: make([]uint64, len(sf.usage)),
+		Index:    sf.index,
+		Path:     sf.path,
+		Usage:    make([]uint64, len(sf.usage)),
+		Degraded: atomic.LoadUint64(&sf.atomicDegraded) == 1,
 	}
 	copy(ssf.Usage, sf.usage)
 	return ssf
@@ -76,6 +78,9 @@ func (cm *ContractManager) loadSettings() error {
 		sf.index = ss.StorageFolders[i].Index
 		sf.path = ss.StorageFolders[i].Path
 		sf.usage = ss.StorageFolders[i].Usage
+		if ss.StorageFolders[i].Degraded {
+			atomic.StoreUint64(&sf.atomicDegraded, 1)
+		}
 		sf.metadataFile, err = cm.dependencies.OpenFile(filepath.Join(ss.StorageFolders[i].Path, metadataFile), os.O_RDWR, 0700)
 		if err != nil {
 			// Mark the folder as unavailable and log an error.
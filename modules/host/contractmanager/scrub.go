@@ -0,0 +1,211 @@
+package contractmanager
+
+import (
+	"sync/atomic"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+// sectorScrubber keeps track of the progress of the sector scrubber. The
+// scrubber continuously reads all sectors stored by the contract manager and
+// verifies that they still hash to the sector root they were stored under.
+// Disks can silently corrupt data, and without scrubbing the host would only
+// find out once a storage proof fails and collateral is lost.
+type sectorScrubber struct {
+	// NOTE: the atomic fields must come first in the struct to ensure proper
+	// alignment.
+	atomicRate              uint64 // bytes per second
+	atomicPasses            uint64
+	atomicLastPassCompleted int64 // unix nano
+	atomicSectorsScrubbed   uint64
+	atomicSectorsTotal      uint64
+
+	// rateChanged wakes the scrubber up when the rate is changed, so that
+	// enabling scrubbing takes effect immediately.
+	rateChanged chan struct{}
+}
+
+// managedScrubQueue returns the ids of all sectors that are currently stored
+// by the contract manager. Corrupt sectors that have been removed in the
+// meantime are forgotten.
+func (cm *ContractManager) managedScrubQueue() []sectorID {
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+	for id := range cm.corruptSectors {
+		if _, exists := cm.sectorLocations[id]; !exists {
+			delete(cm.corruptSectors, id)
+		}
+	}
+	ids := make([]sectorID, 0, len(cm.sectorLocations))
+	for id := range cm.sectorLocations {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// managedScrubSector reads the sector with the given id and checks that its
+// data still matches its id. Sectors are identified by a salted hash of their
+// root, so the data is corrupt if the root computed from the data results in a
+// different id.
+func (cm *ContractManager) managedScrubSector(id sectorID) {
+	cm.wal.managedLockSector(id)
+	defer cm.wal.managedUnlockSector(id)
+
+	cm.wal.mu.Lock()
+	sl, exists1 := cm.sectorLocations[id]
+	sf, exists2 := cm.storageFolders[sl.storageFolder]
+	cm.wal.mu.Unlock()
+	if !exists1 || !exists2 || atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+		// The sector has been removed or its storage folder is unavailable.
+		return
+	}
+	sectorData, err := readSector(sf.sectorFile, sl.index)
+	if err != nil {
+		atomic.AddUint64(&sf.atomicFailedReads, 1)
+		cm.log.Printf("WARN: unable to read sector at index %v of storage folder %v for scrubbing: %v\n", sl.index, sf.path, err)
+		return
+	}
+	atomic.AddUint64(&sf.atomicSuccessfulReads, 1)
+
+	intact := cm.managedSectorID(crypto.MerkleRoot(sectorData)) == id
+	cm.wal.mu.Lock()
+	_, known := cm.corruptSectors[id]
+	if intact {
+		delete(cm.corruptSectors, id)
+	} else {
+		cm.corruptSectors[id] = struct{}{}
+	}
+	cm.wal.mu.Unlock()
+	if !intact && !known {
+		atomic.AddUint64(&sf.atomicCorruptSectors, 1)
+		cm.log.Printf("WARN: sector %x at index %v of storage folder %v is corrupt\n", id, sl.index, sf.path)
+	}
+}
+
+// managedWaitScrub blocks for as long as it takes to read a sector at the
+// current scrub rate. False is returned if scrubbing was disabled or the
+// contract manager is shutting down.
+func (cm *ContractManager) managedWaitScrub() bool {
+	rate := atomic.LoadUint64(&cm.scrubber.atomicRate)
+	if rate == 0 {
+		return false
+	}
+	wait := time.Duration(float64(modules.SectorSize) / float64(rate) * float64(time.Second))
+	select {
+	case <-cm.tg.StopChan():
+		return false
+	case <-cm.scrubber.rateChanged:
+		return atomic.LoadUint64(&cm.scrubber.atomicRate) != 0
+	case <-time.After(wait):
+		return true
+	}
+}
+
+// threadedScrubSectors continuously scrubs all sectors of the contract
+// manager at the configured rate.
+func (cm *ContractManager) threadedScrubSectors() {
+	if cm.dependencies.Disrupt("noScrub") {
+		return
+	}
+	for {
+		select {
+		case <-cm.tg.StopChan():
+			return
+		default:
+		}
+
+		// Wait until scrubbing is enabled.
+		if atomic.LoadUint64(&cm.scrubber.atomicRate) == 0 {
+			select {
+			case <-cm.tg.StopChan():
+				return
+			case <-cm.scrubber.rateChanged:
+			}
+			continue
+		}
+
+		// Scrub all sectors. The pass is aborted if scrubbing is disabled.
+		ids := cm.managedScrubQueue()
+		atomic.StoreUint64(&cm.scrubber.atomicSectorsScrubbed, 0)
+		atomic.StoreUint64(&cm.scrubber.atomicSectorsTotal, uint64(len(ids)))
+		completed := true
+		for _, id := range ids {
+			if !cm.managedWaitScrub() {
+				completed = false
+				break
+			}
+			if err := cm.tg.Add(); err != nil {
+				return
+			}
+			cm.managedScrubSector(id)
+			cm.tg.Done()
+			atomic.AddUint64(&cm.scrubber.atomicSectorsScrubbed, 1)
+		}
+		if completed && len(ids) > 0 {
+			atomic.AddUint64(&cm.scrubber.atomicPasses, 1)
+			atomic.StoreInt64(&cm.scrubber.atomicLastPassCompleted, time.Now().UnixNano())
+		}
+
+		// Don't busy-loop if there are no sectors.
+		if len(ids) == 0 {
+			select {
+			case <-cm.tg.StopChan():
+				return
+			case <-cm.scrubber.rateChanged:
+			case <-time.After(scrubIdleInterval):
+			}
+		}
+	}
+}
+
+// CorruptSectors returns the roots of the provided sectors that were found to
+// be corrupt by the sector scrubber.
+func (cm *ContractManager) CorruptSectors(sectorRoots []crypto.Hash) []crypto.Hash {
+	if err := cm.tg.Add(); err != nil {
+		return nil
+	}
+	defer cm.tg.Done()
+
+	ids := make([]sectorID, len(sectorRoots))
+	for i, root := range sectorRoots {
+		ids[i] = cm.managedSectorID(root)
+	}
+	var corrupt []crypto.Hash
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+	for i, id := range ids {
+		if _, exists := cm.corruptSectors[id]; exists {
+			corrupt = append(corrupt, sectorRoots[i])
+		}
+	}
+	return corrupt
+}
+
+// ScrubStatus returns the progress of the sector scrubber.
+func (cm *ContractManager) ScrubStatus() modules.SectorScrubStatus {
+	status := modules.SectorScrubStatus{
+		Rate:            atomic.LoadUint64(&cm.scrubber.atomicRate),
+		SectorsScrubbed: atomic.LoadUint64(&cm.scrubber.atomicSectorsScrubbed),
+		SectorsTotal:    atomic.LoadUint64(&cm.scrubber.atomicSectorsTotal),
+		Passes:          atomic.LoadUint64(&cm.scrubber.atomicPasses),
+	}
+	if lastPass := atomic.LoadInt64(&cm.scrubber.atomicLastPassCompleted); lastPass != 0 {
+		status.LastPassCompleted = time.Unix(0, lastPass)
+	}
+	cm.wal.mu.Lock()
+	status.CorruptSectors = uint64(len(cm.corruptSectors))
+	cm.wal.mu.Unlock()
+	return status
+}
+
+// SetScrubRate sets the number of bytes per second that the sector scrubber
+// reads. A rate of zero disables scrubbing.
+func (cm *ContractManager) SetScrubRate(bytesPerSecond uint64) {
+	atomic.StoreUint64(&cm.scrubber.atomicRate, bytesPerSecond)
+	select {
+	case cm.scrubber.rateChanged <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,114 @@
+package contractmanager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/fastrand"
+)
+
+// TestScrubSectors checks that the sector scrubber finds sectors whose data
+// was corrupted on disk.
+func TestScrubSectors(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Add a storage folder and two sectors.
+	storageFolderDir := filepath.Join(cmt.persistDir, "storageFolderOne")
+	err = os.MkdirAll(storageFolderDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root1, data1 := randSector()
+	root2, data2 := randSector()
+	if err := cmt.cm.AddSector(root1, data1); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.AddSector(root2, data2); err != nil {
+		t.Fatal(err)
+	}
+
+	// Corrupt the first sector on disk.
+	cmt.cm.wal.mu.Lock()
+	sl := cmt.cm.sectorLocations[cmt.cm.managedSectorID(root1)]
+	sf := cmt.cm.storageFolders[sl.storageFolder]
+	cmt.cm.wal.mu.Unlock()
+	_, err = sf.sectorFile.WriteAt(fastrand.Bytes(64), int64(uint64(sl.index)*modules.SectorSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Scrub as fast as possible and wait for a full pass.
+	cmt.cm.SetScrubRate(modules.SectorSize * 1000)
+	err = build.Retry(100, 50*time.Millisecond, func() error {
+		if status := cmt.cm.ScrubStatus(); status.Passes == 0 {
+			return errors.New("scrubber hasn't completed a pass yet")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The corrupt sector should be reported.
+	status := cmt.cm.ScrubStatus()
+	if status.CorruptSectors != 1 || status.SectorsTotal != 2 {
+		t.Fatal("unexpected scrub status", status)
+	}
+	sfs := cmt.cm.StorageFolders()
+	if len(sfs) != 1 || sfs[0].CorruptSectors != 1 {
+		t.Fatal("storage folder should report one corrupt sector", sfs)
+	}
+	corrupt := cmt.cm.CorruptSectors([]crypto.Hash{root1, root2})
+	if len(corrupt) != 1 || corrupt[0] != root1 {
+		t.Fatal("expected the first sector to be corrupt", corrupt)
+	}
+
+	// Further passes shouldn't count the sector again.
+	passes := status.Passes
+	err = build.Retry(100, 50*time.Millisecond, func() error {
+		if cmt.cm.ScrubStatus().Passes <= passes {
+			return errors.New("scrubber hasn't completed another pass yet")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sfs := cmt.cm.StorageFolders(); sfs[0].CorruptSectors != 1 {
+		t.Fatal("corrupt sector was counted twice", sfs[0].CorruptSectors)
+	}
+
+	// Once the corrupt sector is removed, it should be forgotten.
+	cmt.cm.SetScrubRate(0)
+	if err := cmt.cm.RemoveSector(root1); err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm.SetScrubRate(modules.SectorSize * 1000)
+	err = build.Retry(100, 50*time.Millisecond, func() error {
+		if cmt.cm.ScrubStatus().CorruptSectors != 0 {
+			return errors.New("corrupt sector wasn't forgotten")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,91 @@
+package contractmanager
+
+import (
+	"bytes"
+	"encoding/hex"
+	"sort"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+// String returns the hex encoding of the sector id.
+func (id sectorID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SectorInfo returns the location and the number of virtual sectors of each
+// of the provided sectors. Sectors that are not stored by the contract manager
+// are reported with a count of zero.
+func (cm *ContractManager) SectorInfo(sectorRoots []crypto.Hash) []modules.SectorInfo {
+	if err := cm.tg.Add(); err != nil {
+		return nil
+	}
+	defer cm.tg.Done()
+
+	ids := make([]sectorID, len(sectorRoots))
+	for i, root := range sectorRoots {
+		ids[i] = cm.managedSectorID(root)
+	}
+
+	infos := make([]modules.SectorInfo, len(sectorRoots))
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+	for i, id := range ids {
+		infos[i].Root = sectorRoots[i]
+		infos[i].ID = id.String()
+		sl, exists := cm.sectorLocations[id]
+		if !exists {
+			continue
+		}
+		infos[i].Count = sl.count
+		infos[i].FolderIndex = sl.storageFolder
+		infos[i].Index = sl.index
+		if sf, exists := cm.storageFolders[sl.storageFolder]; exists {
+			infos[i].FolderPath = sf.path
+		}
+	}
+	return infos
+}
+
+// StoredSectors returns the physical sectors stored by the contract manager,
+// sorted by sector id. Sectors are identified by their id because the contract
+// manager doesn't store the sector roots. The sector locations are copied
+// while holding the WAL lock and sorted after releasing it, so that sector
+// reads and writes are only blocked for a single pass over the locations.
+func (cm *ContractManager) StoredSectors() ([]modules.StoredSector, error) {
+	if err := cm.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer cm.tg.Done()
+
+	type storedSector struct {
+		id sectorID
+		sl sectorLocation
+	}
+	cm.wal.mu.Lock()
+	sectors := make([]storedSector, 0, len(cm.sectorLocations))
+	for id, sl := range cm.sectorLocations {
+		sectors = append(sectors, storedSector{id: id, sl: sl})
+	}
+	folderPaths := make(map[uint16]string, len(cm.storageFolders))
+	for index, sf := range cm.storageFolders {
+		folderPaths[index] = sf.path
+	}
+	cm.wal.mu.Unlock()
+
+	sort.Slice(sectors, func(i, j int) bool {
+		return bytes.Compare(sectors[i].id[:], sectors[j].id[:]) < 0
+	})
+	stored := make([]modules.StoredSector, len(sectors))
+	for i, sector := range sectors {
+		stored[i] = modules.StoredSector{
+			ID:          sector.id.String(),
+			Count:       sector.sl.count,
+			FolderIndex: sector.sl.storageFolder,
+			FolderPath:  folderPaths[sector.sl.storageFolder],
+			Index:       sector.sl.index,
+		}
+	}
+	return stored, nil
+}
@@ -0,0 +1,78 @@
+package contractmanager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+// TestSectorInfo checks that the contract manager reports the location and
+// the virtual sector count of its sectors, and lists its stored sectors.
+func TestSectorInfo(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	storageFolderOne := filepath.Join(cmt.persistDir, "storageFolderOne")
+	if err := os.MkdirAll(storageFolderOne, 0700); err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add three sectors, the first of which is added twice.
+	roots, _, err := moveTestSectors(cmt.cm, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing, _ := randSector()
+	infos := cmt.cm.SectorInfo([]crypto.Hash{roots[0], roots[1], missing})
+	if len(infos) != 3 {
+		t.Fatal("expected 3 sector infos, got", len(infos))
+	}
+	if infos[0].Root != roots[0] || infos[0].Count != 2 || infos[1].Count != 1 {
+		t.Fatalf("wrong sector counts: %+v", infos)
+	}
+	if infos[0].FolderPath != storageFolderOne || infos[0].Index == infos[1].Index {
+		t.Fatalf("wrong sector locations: %+v", infos)
+	}
+	if infos[2].Root != missing || infos[2].Count != 0 {
+		t.Fatalf("missing sector should have a count of zero: %+v", infos[2])
+	}
+
+	if infos[0].ID == "" || infos[0].ID == infos[1].ID {
+		t.Fatalf("wrong sector ids: %+v", infos)
+	}
+
+	stored, err := cmt.cm.StoredSectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 3 {
+		t.Fatal("expected 3 stored sectors, got", len(stored))
+	}
+	counts := make(map[string]uint16)
+	for i, sector := range stored {
+		if i > 0 && sector.ID <= stored[i-1].ID {
+			t.Fatal("stored sectors are not sorted by id")
+		}
+		if sector.FolderPath != storageFolderOne {
+			t.Fatalf("wrong stored sector location: %+v", sector)
+		}
+		counts[sector.ID] = sector.Count
+	}
+	if counts[infos[0].ID] != 2 || counts[infos[1].ID] != 1 {
+		t.Fatalf("wrong stored sector counts: %+v", stored)
+	}
+}
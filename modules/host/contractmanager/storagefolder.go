@@ -101,11 +101,21 @@ type storageFolder struct {
 	atomicSuccessfulReads  uint64
 	atomicSuccessfulWrites uint64
 
+	// The number of corrupt sectors found by the scrubber for this boot
+	// cycle.
+	atomicCorruptSectors uint64
+
 	// Atomic bool indicating whether or not the storage folder is available. If
 	// the storage folder is not available, it will still be loaded but return
 	// an error if it is queried.
 	atomicUnavailable uint64 // uint64 for alignment
 
+	// Atomic bool indicating whether or not the failure rate of the storage
+	// folder has crossed the health thresholds. A degraded storage folder
+	// does not receive new sectors and gets evacuated in the background.
+	// Unlike the disk statistics, the degraded state is saved to disk.
+	atomicDegraded uint64 // uint64 for alignment
+
 	// The index, path, and usage are all saved directly to disk.
 	index uint16
 	path  string
@@ -268,12 +278,12 @@ func (sf *storageFolder) setUsage(sectorIndex uint32) {
 }
 
 // availableStorageFolders returns the contract manager's storage folders as a
-// slice, excluding any unavailable storeage folders.
+// slice, excluding any unavailable or degraded storeage folders.
 func (cm *ContractManager) availableStorageFolders() []*storageFolder {
 	sfs := make([]*storageFolder, 0)
 	for _, sf := range cm.storageFolders {
-		// Skip unavailable storage folders.
-		if atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+		// Skip unavailable and degraded storage folders.
+		if atomic.LoadUint64(&sf.atomicUnavailable) == 1 || atomic.LoadUint64(&sf.atomicDegraded) == 1 {
 			continue
 		}
 		sfs = append(sfs, sf)
@@ -332,7 +342,8 @@ func (cm *ContractManager) threadedFolderRecheck() {
 }
 
 // ResetStorageFolderHealth will reset the read and write statistics for the
-// input storage folder.
+// input storage folder. The storage folder is no longer considered degraded,
+// meaning that it will receive new sectors again.
 func (cm *ContractManager) ResetStorageFolderHealth(index uint16) error {
 	err := cm.tg.Add()
 	if err != nil {
@@ -350,6 +361,7 @@ func (cm *ContractManager) ResetStorageFolderHealth(index uint16) error {
 	atomic.StoreUint64(&sf.atomicFailedWrites, 0)
 	atomic.StoreUint64(&sf.atomicSuccessfulReads, 0)
 	atomic.StoreUint64(&sf.atomicSuccessfulWrites, 0)
+	atomic.StoreUint64(&sf.atomicDegraded, 0)
 	return nil
 }
 
@@ -414,6 +426,8 @@ func (cm *ContractManager) StorageFolders() []modules.StorageFolderMetadata {
 			FailedWrites:     atomic.LoadUint64(&sf.atomicFailedWrites),
 			SuccessfulReads:  atomic.LoadUint64(&sf.atomicSuccessfulReads),
 			SuccessfulWrites: atomic.LoadUint64(&sf.atomicSuccessfulWrites),
+			CorruptSectors:   atomic.LoadUint64(&sf.atomicCorruptSectors),
+			Degraded:         atomic.LoadUint64(&sf.atomicDegraded) == 1,
 
 			Capacity:          modules.SectorSize * 64 * uint64(len(sf.usage)),
 			CapacityRemaining: ((64 * uint64(len(sf.usage))) - sf.sectors) * modules.SectorSize,
@@ -0,0 +1,140 @@
+package contractmanager
+
+import (
+	"sync/atomic"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+// exceedsHealthThresholds returns whether the fraction of failed reads or
+// writes of the storage folder exceeds the provided thresholds.
+func (sf *storageFolder) exceedsHealthThresholds(t modules.StorageFolderHealthThresholds) bool {
+	exceeds := func(failed, successful uint64, maxRate float64) bool {
+		total := failed + successful
+		if maxRate <= 0 || total == 0 || total < t.MinOperations {
+			return false
+		}
+		return float64(failed)/float64(total) > maxRate
+	}
+	failedReads := atomic.LoadUint64(&sf.atomicFailedReads)
+	successfulReads := atomic.LoadUint64(&sf.atomicSuccessfulReads)
+	failedWrites := atomic.LoadUint64(&sf.atomicFailedWrites)
+	successfulWrites := atomic.LoadUint64(&sf.atomicSuccessfulWrites)
+	return exceeds(failedReads, successfulReads, t.MaxFailedReadRate) ||
+		exceeds(failedWrites, successfulWrites, t.MaxFailedWriteRate)
+}
+
+// managedCheckFolderHealth marks all storage folders that exceed the health
+// thresholds as degraded, and returns the degraded storage folders that still
+// contain sectors.
+func (cm *ContractManager) managedCheckFolderHealth() []*storageFolder {
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+
+	var degraded []*storageFolder
+	for _, sf := range cm.storageFolders {
+		// Unavailable storage folders are handled by threadedFolderRecheck.
+		if atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+			continue
+		}
+		if atomic.LoadUint64(&sf.atomicDegraded) == 0 && sf.exceedsHealthThresholds(cm.healthThresholds) {
+			atomic.StoreUint64(&sf.atomicDegraded, 1)
+			cm.log.Printf("WARN: storage folder %v is degraded after %v failed reads and %v failed writes, moving its sectors to other storage folders\n",
+				sf.path, atomic.LoadUint64(&sf.atomicFailedReads), atomic.LoadUint64(&sf.atomicFailedWrites))
+		}
+		if atomic.LoadUint64(&sf.atomicDegraded) == 1 && sf.sectors > 0 {
+			degraded = append(degraded, sf)
+		}
+	}
+	return degraded
+}
+
+// managedEvacuateStorageFolder moves the sectors of a degraded storage folder
+// to healthy storage folders one at a time. Evacuation stops if the storage
+// folder is no longer degraded, if the healthy storage folders are full, or if
+// the contract manager is shutting down. Sectors that could not be moved are
+// retried during the next health check.
+func (cm *ContractManager) managedEvacuateStorageFolder(sf *storageFolder) {
+	// Skip the storage folder if it is being added, removed, resized or
+	// moved. Holding the lock also guarantees that no other thread moves
+	// sectors out of the storage folder in the meantime.
+	if !sf.mu.TryLock() {
+		return
+	}
+	defer sf.mu.Unlock()
+
+	cm.wal.mu.Lock()
+	var ids []sectorID
+	for id, sl := range cm.sectorLocations {
+		if sl.storageFolder == sf.index {
+			ids = append(ids, id)
+		}
+	}
+	cm.wal.mu.Unlock()
+
+	var moved, failed uint64
+	for _, id := range ids {
+		select {
+		case <-cm.tg.StopChan():
+			return
+		default:
+		}
+		if atomic.LoadUint64(&sf.atomicDegraded) == 0 {
+			return
+		}
+
+		// The sector may have been removed since the list was built.
+		cm.wal.mu.Lock()
+		sl, exists := cm.sectorLocations[id]
+		cm.wal.mu.Unlock()
+		if !exists || sl.storageFolder != sf.index {
+			continue
+		}
+		err := cm.wal.managedMoveSector(id)
+		if err == errInsufficientStorageForSector {
+			cm.log.Printf("WARN: unable to evacuate degraded storage folder %v, no space left in the other storage folders\n", sf.path)
+			break
+		} else if err != nil {
+			cm.log.Printf("WARN: unable to move sector out of degraded storage folder %v: %v\n", sf.path, err)
+			failed++
+			continue
+		}
+		moved++
+	}
+	if moved > 0 || failed > 0 {
+		cm.log.Printf("Moved %v sectors out of degraded storage folder %v, %v sectors could not be moved\n", moved, sf.path, failed)
+	}
+}
+
+// threadedMonitorFolderHealth periodically checks the health of the storage
+// folders and evacuates the storage folders that are degraded.
+func (cm *ContractManager) threadedMonitorFolderHealth() {
+	if cm.dependencies.Disrupt("noFolderHealthCheck") {
+		return
+	}
+	for {
+		select {
+		case <-cm.tg.StopChan():
+			return
+		case <-time.After(folderHealthCheckInterval):
+		}
+
+		if err := cm.tg.Add(); err != nil {
+			return
+		}
+		for _, sf := range cm.managedCheckFolderHealth() {
+			cm.managedEvacuateStorageFolder(sf)
+		}
+		cm.tg.Done()
+	}
+}
+
+// SetFolderHealthThresholds sets the thresholds at which storage folders are
+// considered degraded. Storage folders that are already degraded stay
+// degraded until their health is reset.
+func (cm *ContractManager) SetFolderHealthThresholds(t modules.StorageFolderHealthThresholds) {
+	cm.wal.mu.Lock()
+	cm.healthThresholds = t
+	cm.wal.mu.Unlock()
+}
@@ -0,0 +1,170 @@
+package contractmanager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+// TestExceedsHealthThresholds checks when a storage folder is considered to
+// exceed the health thresholds.
+func TestExceedsHealthThresholds(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		failedReads, successfulReads   uint64
+		failedWrites, successfulWrites uint64
+		thresholds                     modules.StorageFolderHealthThresholds
+		exceeds                        bool
+	}{
+		// No operations.
+		{0, 0, 0, 0, modules.StorageFolderHealthThresholds{MaxFailedReadRate: 0.1, MaxFailedWriteRate: 0.1}, false},
+		// Failure rate below the threshold.
+		{1, 19, 1, 19, modules.StorageFolderHealthThresholds{MaxFailedReadRate: 0.1, MaxFailedWriteRate: 0.1}, false},
+		// Read or write failure rate above the threshold.
+		{3, 17, 0, 20, modules.StorageFolderHealthThresholds{MaxFailedReadRate: 0.1, MaxFailedWriteRate: 0.1}, true},
+		{0, 20, 3, 17, modules.StorageFolderHealthThresholds{MaxFailedReadRate: 0.1, MaxFailedWriteRate: 0.1}, true},
+		// Not enough operations to judge the failure rate.
+		{3, 17, 3, 17, modules.StorageFolderHealthThresholds{MinOperations: 21, MaxFailedReadRate: 0.1, MaxFailedWriteRate: 0.1}, false},
+		// Checks are disabled.
+		{20, 0, 20, 0, modules.StorageFolderHealthThresholds{}, false},
+		{20, 0, 20, 0, modules.StorageFolderHealthThresholds{MaxFailedReadRate: 0.1}, true},
+	}
+	for i, test := range tests {
+		sf := &storageFolder{
+			atomicFailedReads:      test.failedReads,
+			atomicSuccessfulReads:  test.successfulReads,
+			atomicFailedWrites:     test.failedWrites,
+			atomicSuccessfulWrites: test.successfulWrites,
+		}
+		if sf.exceedsHealthThresholds(test.thresholds) != test.exceeds {
+			t.Errorf("test %v: expected %v", i, test.exceeds)
+		}
+	}
+}
+
+// TestStorageFolderEvacuation checks that a storage folder that crosses the
+// health thresholds is marked as degraded, stops receiving new sectors and
+// has its sectors moved to the other storage folders.
+func TestStorageFolderEvacuation(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Add a storage folder with some sectors, then add a second storage
+	// folder that is large enough to receive all of them.
+	storageFolderOne := filepath.Join(cmt.persistDir, "storageFolderOne")
+	storageFolderTwo := filepath.Join(cmt.persistDir, "storageFolderTwo")
+	for _, dir := range []string{storageFolderOne, storageFolderTwo} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots, datas, err := moveTestSectors(cmt.cm, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sfOne *storageFolder
+	cmt.cm.wal.mu.Lock()
+	for _, sf := range cmt.cm.storageFolders {
+		if sf.path == storageFolderOne {
+			sfOne = sf
+		}
+	}
+	cmt.cm.wal.mu.Unlock()
+
+	// Simulate a failing disk. The storage folder should not be degraded
+	// before thresholds are set.
+	atomic.AddUint64(&sfOne.atomicFailedReads, 10)
+	time.Sleep(folderHealthCheckInterval * 3)
+	if atomic.LoadUint64(&sfOne.atomicDegraded) == 1 {
+		t.Fatal("storage folder was degraded without thresholds")
+	}
+	cmt.cm.SetFolderHealthThresholds(modules.StorageFolderHealthThresholds{
+		MinOperations:     5,
+		MaxFailedReadRate: 0.2,
+	})
+
+	// The storage folder should be degraded and emptied.
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		for _, sf := range cmt.cm.StorageFolders() {
+			if sf.Path != storageFolderOne {
+				continue
+			}
+			if !sf.Degraded {
+				return errors.New("storage folder is not degraded")
+			}
+			if sf.CapacityRemaining != sf.Capacity {
+				return errors.New("storage folder was not emptied")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkMoveTestSectors(t, cmt.cm, roots, datas)
+
+	// New sectors should not be added to the degraded storage folder.
+	if _, _, err := moveTestSectors(cmt.cm, 5); err != nil {
+		t.Fatal(err)
+	}
+	for _, sf := range cmt.cm.StorageFolders() {
+		if sf.Path == storageFolderOne && sf.CapacityRemaining != sf.Capacity {
+			t.Fatal("sectors were added to a degraded storage folder")
+		}
+	}
+
+	// The degraded state should persist across restarts.
+	err = cmt.cm.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sfs := cmt.cm.StorageFolders()
+	var index uint16
+	degraded := 0
+	for _, sf := range sfs {
+		if sf.Degraded {
+			degraded++
+			index = sf.Index
+		}
+	}
+	if degraded != 1 {
+		t.Fatal("expected one degraded storage folder after restart, got", degraded)
+	}
+	checkMoveTestSectors(t, cmt.cm, roots, datas)
+
+	// Resetting the health of the storage folder should clear the degraded
+	// state.
+	if err := cmt.cm.ResetStorageFolderHealth(index); err != nil {
+		t.Fatal(err)
+	}
+	for _, sf := range cmt.cm.StorageFolders() {
+		if sf.Degraded {
+			t.Fatal("storage folder is still degraded after resetting its health")
+		}
+	}
+}
@@ -0,0 +1,402 @@
+package contractmanager
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+var (
+	// errMoveDestinationInUse is returned if a storage folder is moved to a
+	// folder that already contains storage folder files.
+	errMoveDestinationInUse = errors.New("destination folder already contains storage folder files")
+)
+
+type (
+	// storageFolderMove is the data saved to the WAL to indicate that a
+	// storage folder has been moved to a new path. The same type is used to
+	// indicate an unfinished move, which provides the information needed to
+	// clean up the partially copied files.
+	storageFolderMove struct {
+		Index   uint16
+		OldPath string
+		NewPath string
+	}
+)
+
+// findUnfinishedStorageFolderMoves will scroll through a set of state changes
+// and pull out all of the storage folder moves which have not yet completed.
+func findUnfinishedStorageFolderMoves(scs []stateChange) []storageFolderMove {
+	usfmMap := make(map[uint16]storageFolderMove)
+	for _, sc := range scs {
+		for _, usfm := range sc.UnfinishedStorageFolderMoves {
+			usfmMap[usfm.Index] = usfm
+		}
+		for _, sfm := range sc.StorageFolderMoves {
+			delete(usfmMap, sfm.Index)
+		}
+		for _, index := range sc.ErroredStorageFolderMoves {
+			delete(usfmMap, index)
+		}
+		for _, sfr := range sc.StorageFolderRemovals {
+			delete(usfmMap, sfr.Index)
+		}
+	}
+
+	// Return the active unfinished storage folder moves as a slice.
+	usfms := make([]storageFolderMove, 0, len(usfmMap))
+	for _, usfm := range usfmMap {
+		usfms = append(usfms, usfm)
+	}
+	return usfms
+}
+
+// cleanupUnfinishedStorageFolderMoves will remove the partially copied files
+// of any storage folder moves that did not complete during the previous run.
+// The storage folder remains at its old path.
+func (wal *writeAheadLog) cleanupUnfinishedStorageFolderMoves(scs []stateChange) {
+	usfms := findUnfinishedStorageFolderMoves(scs)
+	for _, usfm := range usfms {
+		sectorLookupName := filepath.Join(usfm.NewPath, metadataFile)
+		sectorHousingName := filepath.Join(usfm.NewPath, sectorFile)
+		err := wal.cm.dependencies.RemoveFile(sectorLookupName)
+		if err != nil && !os.IsNotExist(err) {
+			wal.cm.log.Println("Unable to remove partially moved sector metadata lookup:", sectorLookupName, err)
+		}
+		err = wal.cm.dependencies.RemoveFile(sectorHousingName)
+		if err != nil && !os.IsNotExist(err) {
+			wal.cm.log.Println("Unable to remove partially moved sector housing:", sectorHousingName, err)
+		}
+
+		// Append an error call to the changeset, indicating that the storage
+		// folder move was not completed successfully.
+		wal.appendChange(stateChange{
+			ErroredStorageFolderMoves: []uint16{usfm.Index},
+		})
+	}
+}
+
+// commitStorageFolderMove will apply a storage folder move to the state,
+// pointing the storage folder at the files in the new path and removing the
+// files in the old path. The update is idempotent.
+func (wal *writeAheadLog) commitStorageFolderMove(sfm storageFolderMove) {
+	sf, exists := wal.cm.storageFolders[sfm.Index]
+	if !exists {
+		wal.cm.log.Critical("ERROR: storage folder move provided for storage folder that does not exist")
+		return
+	}
+
+	// During recovery the storage folder may still be pointed at the old
+	// path, in which case the files in the new path need to be opened.
+	if sf.path != sfm.NewPath {
+		if atomic.LoadUint64(&sf.atomicUnavailable) == 0 {
+			sf.metadataFile.Close()
+			sf.sectorFile.Close()
+		}
+		sf.path = sfm.NewPath
+		var err1, err2 error
+		sf.metadataFile, err1 = wal.cm.dependencies.OpenFile(filepath.Join(sf.path, metadataFile), os.O_RDWR, 0700)
+		sf.sectorFile, err2 = wal.cm.dependencies.OpenFile(filepath.Join(sf.path, sectorFile), os.O_RDWR, 0700)
+		if err1 == nil && err2 == nil {
+			atomic.StoreUint64(&sf.atomicUnavailable, 0)
+		} else {
+			atomic.StoreUint64(&sf.atomicUnavailable, 1)
+			wal.cm.log.Printf("ERROR: unable to open the files of storage folder %v after it was moved: %v\n", sf.path, build.ComposeErrors(err1, err2))
+			if err1 == nil {
+				sf.metadataFile.Close()
+			}
+			if err2 == nil {
+				sf.sectorFile.Close()
+			}
+		}
+	}
+
+	// Delete the files in the old path.
+	err := wal.cm.dependencies.RemoveFile(filepath.Join(sfm.OldPath, metadataFile))
+	if err != nil && !os.IsNotExist(err) {
+		wal.cm.log.Printf("Error: unable to remove metadata file as storage folder %v is moved\n", sfm.OldPath)
+	}
+	err = wal.cm.dependencies.RemoveFile(filepath.Join(sfm.OldPath, sectorFile))
+	if err != nil && !os.IsNotExist(err) {
+		wal.cm.log.Printf("Error: unable to remove sector file as storage folder %v is moved\n", sfm.OldPath)
+	}
+}
+
+// managedCopyStorageFolder copies the sectors of the storage folder into the
+// provided files. The storage folder must be locked, which guarantees that no
+// sectors are added to the folder while it is copied. Sectors that are
+// removed during the copy are copied anyway, which is harmless.
+func (wal *writeAheadLog) managedCopyStorageFolder(sf *storageFolder, newMetadataFile, newSectorFile modules.File) error {
+	wal.mu.Lock()
+	usage := make([]uint64, len(sf.usage))
+	copy(usage, sf.usage)
+	wal.mu.Unlock()
+
+	// Allocate the new files, and then copy the sectors that are in use one
+	// at a time.
+	sectorIndices := usageSectors(usage)
+	numSectors := uint64(len(usage)) * storageFolderGranularity
+	err := newSectorFile.Truncate(int64(numSectors * modules.SectorSize))
+	if err != nil {
+		return build.ExtendErr("could not allocate sector data file", err)
+	}
+	err = newMetadataFile.Truncate(int64(numSectors * sectorMetadataDiskSize))
+	if err != nil {
+		return build.ExtendErr("could not allocate sector metadata file", err)
+	}
+	atomic.StoreUint64(&sf.atomicProgressDenominator, uint64(len(sectorIndices))*modules.SectorSize)
+	for _, sectorIndex := range sectorIndices {
+		select {
+		case <-wal.cm.tg.StopChan():
+			return errors.New("contract manager is shutting down")
+		default:
+		}
+		sectorData, err := readSector(sf.sectorFile, sectorIndex)
+		if err != nil {
+			atomic.AddUint64(&sf.atomicFailedReads, 1)
+			return build.ExtendErr("unable to read sector selected for migration", err)
+		}
+		atomic.AddUint64(&sf.atomicSuccessfulReads, 1)
+		err = writeSector(newSectorFile, sectorIndex, sectorData)
+		if err != nil {
+			return build.ExtendErr("unable to write sector to new location", err)
+		}
+		atomic.AddUint64(&sf.atomicProgressNumerator, modules.SectorSize)
+	}
+	return nil
+}
+
+// managedLockFolderSectors grabs the locks of all of the sectors in the
+// storage folder, returning the ids of the locked sectors. No sectors can be
+// added to the storage folder while it is locked, so no other sectors will
+// appear in the folder until the locks are released.
+func (wal *writeAheadLog) managedLockFolderSectors(index uint16) []sectorID {
+	wal.mu.Lock()
+	var ids []sectorID
+	for id, sl := range wal.cm.sectorLocations {
+		if sl.storageFolder == index {
+			ids = append(ids, id)
+		}
+	}
+	wal.mu.Unlock()
+
+	for _, id := range ids {
+		wal.managedLockSector(id)
+	}
+	return ids
+}
+
+// writeFolderMetadata writes the metadata of all sectors in the storage folder
+// to the provided file, based on the in-memory sector locations. The WAL lock
+// and the locks of all sectors in the folder must be held.
+func (wal *writeAheadLog) writeFolderMetadata(sf *storageFolder, f modules.File) error {
+	sectorLookupBytes := make([]byte, len(sf.usage)*storageFolderGranularity*sectorMetadataDiskSize)
+	for id, sl := range wal.cm.sectorLocations {
+		if sl.storageFolder != sf.index {
+			continue
+		}
+		writeHead := sl.index * sectorMetadataDiskSize
+		copy(sectorLookupBytes[writeHead:], id[:])
+		binary.LittleEndian.PutUint16(sectorLookupBytes[writeHead+12:], sl.count)
+	}
+	_, err := f.WriteAt(sectorLookupBytes, 0)
+	return err
+}
+
+// moveStorageFolder will move the storage folder with the provided index to
+// the new path. The sectors are copied while the storage folder keeps serving
+// reads of and updates to the sectors it already stores, but new sectors are
+// added to other storage folders until the move has finished. Once the copy is
+// complete, the storage folder is switched over to the new files atomically
+// through the WAL.
+func (wal *writeAheadLog) moveStorageFolder(index uint16, newPath string) (err error) {
+	// Retrieve the specified storage folder.
+	wal.mu.Lock()
+	sf, exists := wal.cm.storageFolders[index]
+	wal.mu.Unlock()
+	if !exists || atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+		return errStorageFolderNotFound
+	}
+
+	// Lock the storage folder for the duration of the operation. This
+	// prevents new sectors from being added to the storage folder.
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	sectorLookupName := filepath.Join(newPath, metadataFile)
+	sectorHousingName := filepath.Join(newPath, sectorFile)
+	var newMetadataFile, newSectorFile modules.File
+	var oldPath string
+	var syncChan chan struct{}
+	err = func() error {
+		wal.mu.Lock()
+		defer wal.mu.Unlock()
+
+		// Check that the new path is not in use by any storage folder,
+		// including the storage folder being moved.
+		for _, csf := range wal.cm.storageFolders {
+			if newPath == csf.path {
+				return ErrRepeatFolder
+			}
+		}
+		for _, name := range []string{sectorLookupName, sectorHousingName} {
+			if _, err := os.Stat(name); !os.IsNotExist(err) {
+				return errMoveDestinationInUse
+			}
+		}
+		oldPath = sf.path
+
+		// Create the files in the new path.
+		var err error
+		newMetadataFile, err = wal.cm.dependencies.CreateFile(sectorLookupName)
+		if err != nil {
+			return build.ExtendErr("could not create storage folder file", err)
+		}
+		newSectorFile, err = wal.cm.dependencies.CreateFile(sectorHousingName)
+		if err != nil {
+			err = build.ComposeErrors(err, newMetadataFile.Close())
+			err = build.ComposeErrors(err, wal.cm.dependencies.RemoveFile(sectorLookupName))
+			return build.ExtendErr("could not create storage folder file", err)
+		}
+
+		// Add the move to the list of unfinished storage folder moves, so
+		// that the new files are removed in the event of unclean shutdown.
+		wal.appendChange(stateChange{
+			UnfinishedStorageFolderMoves: []storageFolderMove{{
+				Index:   index,
+				OldPath: oldPath,
+				NewPath: newPath,
+			}},
+		})
+		syncChan = wal.syncChan
+		return nil
+	}()
+	if err != nil {
+		return err
+	}
+	<-syncChan
+
+	// If there's an error in the rest of the function, the new files need to
+	// be removed and the move needs to be marked as errored in the WAL. Any
+	// errors from the cleanup are returned alongside the original error.
+	defer func() {
+		if err != nil {
+			wal.mu.Lock()
+			defer wal.mu.Unlock()
+			err = build.ComposeErrors(err, newSectorFile.Close())
+			err = build.ComposeErrors(err, newMetadataFile.Close())
+			err = build.ComposeErrors(err, wal.cm.dependencies.RemoveFile(sectorLookupName))
+			err = build.ComposeErrors(err, wal.cm.dependencies.RemoveFile(sectorHousingName))
+			wal.appendChange(stateChange{
+				ErroredStorageFolderMoves: []uint16{index},
+			})
+		}
+		atomic.StoreUint64(&sf.atomicProgressNumerator, 0)
+		atomic.StoreUint64(&sf.atomicProgressDenominator, 0)
+	}()
+
+	// Copy the sectors, which is the long running part of the move.
+	err = wal.managedCopyStorageFolder(sf, newMetadataFile, newSectorFile)
+	if err != nil {
+		return err
+	}
+
+	// Block all operations on the sectors of the storage folder, so that the
+	// metadata can be written and the files can be switched without racing
+	// with sector updates.
+	ids := wal.managedLockFolderSectors(index)
+	defer func() {
+		for _, id := range ids {
+			wal.managedUnlockSector(id)
+		}
+	}()
+	wal.mu.Lock()
+	err = wal.writeFolderMetadata(sf, newMetadataFile)
+	wal.mu.Unlock()
+	if err != nil {
+		return build.ExtendErr("unable to write sector metadata to new location", err)
+	}
+
+	// Sync the new files.
+	var err1, err2 error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		err1 = newMetadataFile.Sync()
+	}()
+	go func() {
+		defer wg.Done()
+		err2 = newSectorFile.Sync()
+	}()
+	wg.Wait()
+	if err1 != nil || err2 != nil {
+		err = build.ComposeErrors(err1, err2)
+		return build.ExtendErr("unable to synchronize moved storage folder", err)
+	}
+
+	// Simulate power failure at this point for some testing scenarios.
+	if wal.cm.dependencies.Disrupt("incompleteMoveStorageFolder") {
+		return build.ComposeErrors(newMetadataFile.Close(), newSectorFile.Close())
+	}
+
+	// Switch the storage folder over to the new files and commit the move
+	// through the WAL. The old files are removed once the move has synced.
+	wal.mu.Lock()
+	err = build.ComposeErrors(sf.metadataFile.Close(), sf.sectorFile.Close())
+	if err != nil {
+		wal.cm.log.Printf("Error: unable to close the files of storage folder %v as it is moved: %v\n", oldPath, err)
+		err = nil
+	}
+	sf.metadataFile = newMetadataFile
+	sf.sectorFile = newSectorFile
+	sf.path = newPath
+	wal.appendChange(stateChange{
+		StorageFolderMoves: []storageFolderMove{{
+			Index:   index,
+			OldPath: oldPath,
+			NewPath: newPath,
+		}},
+	})
+	syncChan = wal.syncChan
+	wal.mu.Unlock()
+	<-syncChan
+	return nil
+}
+
+// MoveStorageFolder will move a storage folder to a new path, for example on
+// a different disk, while keeping its index. All sectors are copied to the new
+// path before the storage folder is switched over, so no data is lost if the
+// move fails or is interrupted by an unclean shutdown.
+func (cm *ContractManager) MoveStorageFolder(index uint16, newPath string) error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+
+	// Check that the new path is an absolute path to an existing folder.
+	if !filepath.IsAbs(newPath) {
+		return errRelativePath
+	}
+	pathInfo, err := os.Stat(newPath)
+	if err != nil {
+		return err
+	}
+	if !pathInfo.Mode().IsDir() {
+		return errStorageFolderNotFolder
+	}
+
+	err = cm.wal.moveStorageFolder(index, newPath)
+	if err != nil {
+		cm.log.Println("Call to MoveStorageFolder has failed:", err)
+		return err
+	}
+	return nil
+}
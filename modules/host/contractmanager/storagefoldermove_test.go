@@ -0,0 +1,263 @@
+package contractmanager
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+// moveTestSectors adds a number of sectors to the contract manager, one of
+// which is added twice, and returns them.
+func moveTestSectors(cm *ContractManager, n int) ([]crypto.Hash, [][]byte, error) {
+	var roots []crypto.Hash
+	var datas [][]byte
+	for i := 0; i < n; i++ {
+		root, data := randSector()
+		if err := cm.AddSector(root, data); err != nil {
+			return nil, nil, err
+		}
+		roots = append(roots, root)
+		datas = append(datas, data)
+	}
+	return roots, datas, cm.AddSector(roots[0], datas[0])
+}
+
+// checkMoveTestSectors checks that the sectors can be read from the contract
+// manager.
+func checkMoveTestSectors(t *testing.T, cm *ContractManager, roots []crypto.Hash, datas [][]byte) {
+	t.Helper()
+	for i, root := range roots {
+		data, err := cm.ReadSector(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, datas[i]) {
+			t.Fatal("sector has the wrong data after the move")
+		}
+	}
+}
+
+// TestMoveStorageFolder checks that a storage folder can be moved to a new
+// path without losing any sectors.
+func TestMoveStorageFolder(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Add a storage folder with some sectors.
+	storageFolderOne := filepath.Join(cmt.persistDir, "storageFolderOne")
+	storageFolderTwo := filepath.Join(cmt.persistDir, "storageFolderTwo")
+	for _, dir := range []string{storageFolderOne, storageFolderTwo} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots, datas, err := moveTestSectors(cmt.cm, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sfIndex := cmt.cm.StorageFolders()[0].Index
+
+	// Invalid destinations should be rejected.
+	if err := cmt.cm.MoveStorageFolder(sfIndex, "relative"); err != errRelativePath {
+		t.Fatal("expected errRelativePath, got", err)
+	}
+	if err := cmt.cm.MoveStorageFolder(sfIndex, storageFolderOne); err != ErrRepeatFolder {
+		t.Fatal("expected ErrRepeatFolder, got", err)
+	}
+	if err := cmt.cm.MoveStorageFolder(sfIndex+1, storageFolderTwo); err != errStorageFolderNotFound {
+		t.Fatal("expected errStorageFolderNotFound, got", err)
+	}
+
+	// Move the storage folder.
+	err = cmt.cm.MoveStorageFolder(sfIndex, storageFolderTwo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sfs := cmt.cm.StorageFolders()
+	if len(sfs) != 1 || sfs[0].Index != sfIndex || sfs[0].Path != storageFolderTwo {
+		t.Fatal("storage folder wasn't moved:", sfs)
+	}
+	if sfs[0].CapacityRemaining != modules.SectorSize*(storageFolderGranularity*2-10) {
+		t.Error("moved storage folder is reporting the wrong remaining capacity")
+	}
+	checkMoveTestSectors(t, cmt.cm, roots, datas)
+	for _, name := range []string{metadataFile, sectorFile} {
+		if _, err := os.Stat(filepath.Join(storageFolderOne, name)); !os.IsNotExist(err) {
+			t.Error("old storage folder file was not removed:", name)
+		}
+	}
+
+	// The virtual sector should have kept its count, so it can be removed
+	// once without removing the data.
+	if err := cmt.cm.RemoveSector(roots[0]); err != nil {
+		t.Fatal(err)
+	}
+	checkMoveTestSectors(t, cmt.cm, roots, datas)
+
+	// The storage folder shouldn't accept a move to a folder that contains
+	// storage folder files.
+	if err := cmt.cm.MoveStorageFolder(sfIndex, storageFolderOne); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(storageFolderTwo, 0700); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(storageFolderTwo, sectorFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err := cmt.cm.MoveStorageFolder(sfIndex, storageFolderTwo); err != errMoveDestinationInUse {
+		t.Fatal("expected errMoveDestinationInUse, got", err)
+	}
+
+	// Restart the contract manager to see that the move is persistent.
+	err = cmt.cm.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sfs = cmt.cm.StorageFolders()
+	if len(sfs) != 1 || sfs[0].Path != storageFolderOne {
+		t.Fatal("storage folder move wasn't persisted:", sfs)
+	}
+	checkMoveTestSectors(t, cmt.cm, roots, datas)
+}
+
+// dependencyMoveNoFinalize will not add a confirmation to the WAL that a
+// moveStorageFolder operation has completed, and will leave the WAL behind
+// during shutdown.
+type dependencyMoveNoFinalize struct {
+	modules.ProductionDependencies
+}
+
+// Disrupt will prevent the moveStorageFolder operation from switching the
+// storage folder over to the new path.
+func (*dependencyMoveNoFinalize) Disrupt(s string) bool {
+	return s == "incompleteMoveStorageFolder" || s == "cleanWALFile"
+}
+
+// TestMoveStorageFolderShutdownBeforeSwitch simulates an unclean shutdown
+// that occurs after the sectors have been copied, but before the storage
+// folder has been switched over to the new path. The storage folder should
+// stay at its old path after restart.
+func TestMoveStorageFolderShutdownBeforeSwitch(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	d := new(dependencyMoveNoFinalize)
+	cmt, err := newMockedContractManagerTester(d, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	storageFolderOne := filepath.Join(cmt.persistDir, "storageFolderOne")
+	storageFolderTwo := filepath.Join(cmt.persistDir, "storageFolderTwo")
+	for _, dir := range []string{storageFolderOne, storageFolderTwo} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots, datas, err := moveTestSectors(cmt.cm, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.MoveStorageFolder(cmt.cm.StorageFolders()[0].Index, storageFolderTwo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Restart the contract manager.
+	err = cmt.cm.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sfs := cmt.cm.StorageFolders()
+	if len(sfs) != 1 || sfs[0].Path != storageFolderOne {
+		t.Fatal("storage folder should not have been moved:", sfs)
+	}
+	checkMoveTestSectors(t, cmt.cm, roots, datas)
+	for _, name := range []string{metadataFile, sectorFile} {
+		if _, err := os.Stat(filepath.Join(storageFolderTwo, name)); !os.IsNotExist(err) {
+			t.Error("partially moved file was not removed:", name)
+		}
+	}
+}
+
+// TestMoveStorageFolderWAL completes a storage folder move, but leaves the WAL
+// behind so that the move is committed again after restart.
+func TestMoveStorageFolderWAL(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	d := new(dependencyLeaveWAL)
+	cmt, err := newMockedContractManagerTester(d, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	storageFolderOne := filepath.Join(cmt.persistDir, "storageFolderOne")
+	storageFolderTwo := filepath.Join(cmt.persistDir, "storageFolderTwo")
+	for _, dir := range []string{storageFolderOne, storageFolderTwo} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots, datas, err := moveTestSectors(cmt.cm, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.MoveStorageFolder(cmt.cm.StorageFolders()[0].Index, storageFolderTwo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Restart the contract manager.
+	err = cmt.cm.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sfs := cmt.cm.StorageFolders()
+	if len(sfs) != 1 || sfs[0].Path != storageFolderTwo {
+		t.Fatal("storage folder move wasn't persisted:", sfs)
+	}
+	checkMoveTestSectors(t, cmt.cm, roots, datas)
+}
@@ -50,12 +50,15 @@ type (
 		// storage folder addition.
 		ErroredStorageFolderAdditions     []uint16
 		ErroredStorageFolderExtensions    []uint16
+		ErroredStorageFolderMoves         []uint16
 		StorageFolderAdditions            []savedStorageFolder
 		StorageFolderExtensions           []storageFolderExtension
+		StorageFolderMoves                []storageFolderMove
 		StorageFolderRemovals             []storageFolderRemoval
 		StorageFolderReductions           []storageFolderReduction
 		UnfinishedStorageFolderAdditions  []savedStorageFolder
 		UnfinishedStorageFolderExtensions []unfinishedStorageFolderExtension
+		UnfinishedStorageFolderMoves      []storageFolderMove
 
 		// Updates to the sector metadata. Careful ordering of events ensures
 		// that a sector update will not make it into the synced WAL unless the
@@ -177,6 +180,11 @@ func (wal *writeAheadLog) commitChange(sc stateChange) {
 			wal.commitStorageFolderExtension(sfe)
 		}
 	}
+	for _, sfm := range sc.StorageFolderMoves {
+		for i := uint64(0); i < wal.cm.dependencies.AtLeastOne(); i++ {
+			wal.commitStorageFolderMove(sfm)
+		}
+	}
 	for _, sfr := range sc.StorageFolderReductions {
 		for i := uint64(0); i < wal.cm.dependencies.AtLeastOne(); i++ {
 			wal.commitStorageFolderReduction(sfr)
@@ -250,6 +258,7 @@ func (wal *writeAheadLog) recoverWAL(walFile modules.File) error {
 	// completed.
 	wal.cleanupUnfinishedStorageFolderAdditions(scs)
 	wal.cleanupUnfinishedStorageFolderExtensions(scs)
+	wal.cleanupUnfinishedStorageFolderMoves(scs)
 	return nil
 }
 
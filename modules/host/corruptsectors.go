@@ -0,0 +1,70 @@
+package host
+
+import (
+	"encoding/json"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// managedCorruptObligations returns the unresolved storage obligations that
+// contain sectors which were found to be corrupt by the sector scrubber,
+// together with the roots of the corrupt sectors.
+func (h *Host) managedCorruptObligations() (map[types.FileContractID][]crypto.Hash, error) {
+	corrupt := make(map[types.FileContractID][]crypto.Hash)
+	err := h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).ForEach(func(_, v []byte) error {
+			var so storageObligation
+			if err := json.Unmarshal(v, &so); err != nil {
+				return err
+			}
+			if so.ObligationStatus != obligationUnresolved {
+				return nil
+			}
+			if roots := h.StorageManager.CorruptSectors(so.SectorRoots); len(roots) > 0 {
+				corrupt[so.id()] = roots
+			}
+			return nil
+		})
+	})
+	return corrupt, err
+}
+
+// threadedCheckCorruptSectors periodically checks whether the sector scrubber
+// found new corrupt sectors. If it did, the storage obligations that contain
+// them are logged, since the host will likely fail to submit storage proofs
+// for them.
+func (h *Host) threadedCheckCorruptSectors() {
+	var lastCorrupt uint64
+	for {
+		select {
+		case <-h.tg.StopChan():
+			return
+		case <-time.After(corruptSectorCheckFrequency):
+		}
+
+		corruptSectors := h.StorageManager.ScrubStatus().CorruptSectors
+		if corruptSectors <= lastCorrupt {
+			lastCorrupt = corruptSectors
+			continue
+		}
+		lastCorrupt = corruptSectors
+
+		if err := h.tg.Add(); err != nil {
+			return
+		}
+		corrupt, err := h.managedCorruptObligations()
+		h.tg.Done()
+		if err != nil {
+			h.log.Println("Unable to check storage obligations for corrupt sectors:", err)
+			continue
+		}
+		h.log.Printf("WARN: the sector scrubber found %v corrupt sectors, %v storage obligations are affected\n", corruptSectors, len(corrupt))
+		for id, roots := range corrupt {
+			h.log.Printf("WARN: storage obligation %v contains %v corrupt sectors, storage proofs may fail: %v\n", id, len(roots), roots)
+		}
+	}
+}
@@ -125,6 +125,12 @@ type Host struct {
 	atomicSettingsCalls     uint64
 	atomicUnrecognizedCalls uint64
 
+	// Rejection metrics - the number of connections and RPCs that were
+	// rejected for exceeding the host's limits. These values are not
+	// persistent.
+	atomicRejectedConnections uint64
+	atomicRejectedRPCs        uint64
+
 	// Error management. There are a few different types of errors returned by
 	// the host. These errors intentionally not persistent, so that the logging
 	// limits of each error type will be reset each time the host is reset.
@@ -162,11 +168,30 @@ type Host struct {
 	workingStatus        modules.HostWorkingStatus
 	connectabilityStatus modules.HostConnectabilityStatus
 
+	// Pricing engine fields. The market change tracks the consensus change
+	// up to which host announcements have been added to the market. The
+	// pricing update height is the height at which the prices were last
+	// updated, zero means that the prices are due for an update.
+	marketChange         modules.ConsensusChangeID
+	pricingPolicy        modules.HostPricingPolicy
+	pricingPolicyChanged chan struct{}
+	pricingUpdateHeight  types.BlockHeight
+
+	// The scheduled maintenance of the host.
+	maintenance modules.HostMaintenance
+
+	// The connection limiter enforces the limits on the connections, RPCs
+	// and bandwidth of the host and its peers.
+	connLimiter *connLimiter
+
 	// A map of storage obligations that are currently being modified. Locks on
 	// storage obligations can be long-running, and each storage obligation can
 	// be locked separately.
 	lockedStorageObligations map[types.FileContractID]*siasync.TryMutex
 
+	// The sector references that sector queries page through.
+	sectorReferences sectorReferencesCache
+
 	// Utilities.
 	db         *persist.BoltDatabase
 	listener   net.Listener
@@ -239,7 +264,9 @@ func newHost(dependencies modules.Dependencies, cs modules.ConsensusSet, g modul
 		wallet:       wallet,
 		dependencies: dependencies,
 
+		connLimiter:              newConnLimiter(),
 		lockedStorageObligations: make(map[types.FileContractID]*siasync.TryMutex),
+		pricingPolicyChanged:     make(chan struct{}, 1),
 
 		persistDir: persistDir,
 	}
@@ -300,6 +327,21 @@ func newHost(dependencies modules.Dependencies, cs modules.ConsensusSet, g modul
 		}
 	})
 
+	// Apply the bandwidth limits of the host.
+	h.connLimiter.managedSetLimits(h.settings)
+
+	// Start scrubbing the stored sectors.
+	h.StorageManager.SetScrubRate(h.settings.SectorScrubRate)
+	h.StorageManager.SetFolderHealthThresholds(folderHealthThresholds(h.settings))
+	go h.threadedCheckCorruptSectors()
+
+	// Start the pricing engine.
+	go h.threadedSubscribeMarket()
+	go h.threadedUpdatePrices()
+
+	// Start taking snapshots of the financial metrics.
+	go h.threadedSnapshotFinancialMetrics()
+
 	// Initialize the networking. We need to hold the lock while doing so since
 	// the previous load subscribed the host to the consenus set.
 	h.mu.Lock()
@@ -374,6 +416,16 @@ func (h *Host) PublicKey() types.SiaPublicKey {
 	return h.publicKey
 }
 
+// folderHealthThresholds returns the storage folder health thresholds of the
+// provided settings.
+func folderHealthThresholds(settings modules.HostInternalSettings) modules.StorageFolderHealthThresholds {
+	return modules.StorageFolderHealthThresholds{
+		MinOperations:      settings.FolderHealthMinOperations,
+		MaxFailedReadRate:  settings.FolderMaxFailedReadRate,
+		MaxFailedWriteRate: settings.FolderMaxFailedWriteRate,
+	}
+}
+
 // SetInternalSettings updates the host's internal HostInternalSettings object.
 func (h *Host) SetInternalSettings(settings modules.HostInternalSettings) error {
 	h.mu.Lock()
@@ -393,6 +445,16 @@ func (h *Host) SetInternalSettings(settings modules.HostInternalSettings) error
 		}
 	}
 
+	if settings.MaxDownloadSpeed < 0 || settings.MaxDownloadSpeedPerPeer < 0 ||
+		settings.MaxUploadSpeed < 0 || settings.MaxUploadSpeedPerPeer < 0 {
+		return errors.New("internal settings not updated, speed limits can't be below 0")
+	}
+
+	if settings.FolderMaxFailedReadRate < 0 || settings.FolderMaxFailedReadRate > 1 ||
+		settings.FolderMaxFailedWriteRate < 0 || settings.FolderMaxFailedWriteRate > 1 {
+		return errors.New("internal settings not updated, storage folder failure rates must be between 0 and 1")
+	}
+
 	if settings.NetAddress != "" {
 		err := settings.NetAddress.IsValid()
 		if err != nil {
@@ -409,6 +471,9 @@ func (h *Host) SetInternalSettings(settings modules.HostInternalSettings) error
 
 	h.settings = settings
 	h.revisionNumber++
+	h.StorageManager.SetScrubRate(settings.SectorScrubRate)
+	h.StorageManager.SetFolderHealthThresholds(folderHealthThresholds(settings))
+	h.connLimiter.managedSetLimits(settings)
 
 	err = h.saveSync()
 	if err != nil {
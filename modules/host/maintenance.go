@@ -0,0 +1,145 @@
+package host
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+
+	"github.com/coreos/bbolt"
+)
+
+var (
+	// errHostInMaintenance is returned to renters that try to form or renew
+	// a contract, or to upload data, while the host is in maintenance.
+	errHostInMaintenance = ErrorCommunication("host is in maintenance and does not accept new contracts or uploads")
+
+	// errMaintenanceEnded is returned if maintenance is scheduled to end
+	// before the current block height.
+	errMaintenanceEnded = errors.New("maintenance would end before the current block height")
+
+	// errMaintenanceEndBeforeStart is returned if maintenance is scheduled to
+	// end before it starts.
+	errMaintenanceEndBeforeStart = errors.New("maintenance cannot end before it starts")
+)
+
+// maintenanceScheduled returns whether maintenance is scheduled that has not
+// ended yet.
+func (h *Host) maintenanceScheduled() bool {
+	return h.maintenance != (modules.HostMaintenance{}) && h.blockHeight <= h.maintenance.End
+}
+
+// maintenanceActive returns whether the host is in maintenance at the current
+// block height.
+func (h *Host) maintenanceActive() bool {
+	return h.maintenanceScheduled() && h.blockHeight >= h.maintenance.Start
+}
+
+// maintenanceAnnounced returns whether the host reports that it is not
+// accepting contracts because of maintenance.
+func (h *Host) maintenanceAnnounced() bool {
+	return h.maintenanceActive() || (h.maintenanceScheduled() && h.maintenance.Announce)
+}
+
+// maintenanceConflicts returns the unresolved storage obligations whose proof
+// window overlaps the maintenance.
+func maintenanceConflicts(tx *bolt.Tx, m modules.HostMaintenance) ([]modules.HostMaintenanceConflict, error) {
+	conflicts := []modules.HostMaintenanceConflict{}
+	err := tx.Bucket(bucketStorageObligations).ForEach(func(_, v []byte) error {
+		var so storageObligation
+		if err := json.Unmarshal(v, &so); err != nil {
+			return err
+		}
+		if so.ObligationStatus != obligationUnresolved || len(so.SectorRoots) == 0 {
+			return nil
+		}
+		if so.expiration() > m.End || so.proofDeadline() < m.Start {
+			return nil
+		}
+		conflicts = append(conflicts, modules.HostMaintenanceConflict{
+			ObligationID:     so.id(),
+			ExpirationHeight: so.expiration(),
+			ProofDeadline:    so.proofDeadline(),
+		})
+		return nil
+	})
+	return conflicts, err
+}
+
+// maintenanceAlerts returns an alert if the scheduled maintenance overlaps the
+// proof windows of storage obligations.
+func (h *Host) maintenanceAlerts(tx *bolt.Tx) ([]modules.HostAlert, error) {
+	if !h.maintenanceScheduled() {
+		return nil, nil
+	}
+	conflicts, err := maintenanceConflicts(tx, h.maintenance)
+	if err != nil || len(conflicts) == 0 {
+		return nil, err
+	}
+	return []modules.HostAlert{{
+		Cause:    fmt.Sprintf("maintenance from height %v to %v", h.maintenance.Start, h.maintenance.End),
+		Msg:      fmt.Sprintf("%v contracts have their proof window during maintenance, the host has to be online to submit their storage proofs", len(conflicts)),
+		Severity: modules.HostAlertSeverityWarning,
+	}}, nil
+}
+
+// Maintenance returns the scheduled maintenance of the host and the storage
+// obligations whose proof window overlaps it.
+func (h *Host) Maintenance() (modules.HostMaintenanceStatus, error) {
+	if err := h.tg.Add(); err != nil {
+		return modules.HostMaintenanceStatus{}, err
+	}
+	defer h.tg.Done()
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	status := modules.HostMaintenanceStatus{
+		HostMaintenance: h.maintenance,
+		Active:          h.maintenanceActive(),
+		Scheduled:       h.maintenanceScheduled(),
+		Conflicts:       []modules.HostMaintenanceConflict{},
+	}
+	if status.Scheduled {
+		err := h.db.View(func(tx *bolt.Tx) (err error) {
+			status.Conflicts, err = maintenanceConflicts(tx, h.maintenance)
+			return err
+		})
+		if err != nil {
+			return modules.HostMaintenanceStatus{}, err
+		}
+	}
+	status.Safe = len(status.Conflicts) == 0
+	return status, nil
+}
+
+// SetMaintenance schedules maintenance of the host, replacing any maintenance
+// that was scheduled before. An empty maintenance cancels the scheduled
+// maintenance.
+func (h *Host) SetMaintenance(m modules.HostMaintenance) error {
+	if err := h.tg.Add(); err != nil {
+		return err
+	}
+	defer h.tg.Done()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if m != (modules.HostMaintenance{}) {
+		if m.End < m.Start {
+			return errMaintenanceEndBeforeStart
+		}
+		if m.End < h.blockHeight {
+			return errMaintenanceEnded
+		}
+	}
+	h.maintenance = m
+	if err := h.saveSync(); err != nil {
+		return errors.New("maintenance updated, but failed saving to disk: " + err.Error())
+	}
+	if m == (modules.HostMaintenance{}) {
+		h.log.Println("Scheduled maintenance was cancelled")
+	} else {
+		h.log.Printf("Maintenance scheduled from height %v to %v\n", m.Start, m.End)
+	}
+	return nil
+}
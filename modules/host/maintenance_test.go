@@ -0,0 +1,166 @@
+package host
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// TestMaintenance checks that the host schedules maintenance, stops
+// advertising that it accepts contracts during maintenance, and reports the
+// storage obligations whose proof window overlaps the maintenance.
+func TestMaintenance(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	settings := ht.host.InternalSettings()
+	settings.AcceptingContracts = true
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	ht.host.mu.RLock()
+	height := ht.host.blockHeight
+	ht.host.mu.RUnlock()
+	acceptingContracts := func() bool {
+		ht.host.mu.Lock()
+		defer ht.host.mu.Unlock()
+		return ht.host.externalSettings().AcceptingContracts
+	}
+
+	// Invalid maintenance should be rejected.
+	err = ht.host.SetMaintenance(modules.HostMaintenance{Start: height + 10, End: height + 5})
+	if err != errMaintenanceEndBeforeStart {
+		t.Fatal("expected errMaintenanceEndBeforeStart, got", err)
+	}
+	if height > 0 {
+		err = ht.host.SetMaintenance(modules.HostMaintenance{Start: 0, End: height - 1})
+		if err != errMaintenanceEnded {
+			t.Fatal("expected errMaintenanceEnded, got", err)
+		}
+	}
+
+	// Add an obligation with a proof window during the maintenance, one
+	// with a proof window after the maintenance and an empty one.
+	newObligation := func(windowStart types.BlockHeight, roots []crypto.Hash) storageObligation {
+		return storageObligation{
+			NegotiationHeight: windowStart,
+			SectorRoots:       roots,
+			OriginTransactionSet: []types.Transaction{{
+				FileContracts: []types.FileContract{{
+					WindowStart: windowStart,
+					WindowEnd:   windowStart + 5,
+				}},
+			}},
+		}
+	}
+	roots := []crypto.Hash{{1}}
+	conflicting := newObligation(height+12, roots)
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		for _, so := range []storageObligation{conflicting, newObligation(height+30, roots), newObligation(height+13, nil)} {
+			soBytes, err := json.Marshal(so)
+			if err != nil {
+				return err
+			}
+			id := so.id()
+			if err := tx.Bucket(bucketStorageObligations).Put(id[:], soBytes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Schedule maintenance in the future. The host should still accept
+	// contracts.
+	m := modules.HostMaintenance{Start: height + 10, End: height + 20}
+	if err := ht.host.SetMaintenance(m); err != nil {
+		t.Fatal(err)
+	}
+	status, err := ht.host.Maintenance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Scheduled || status.Active || status.Safe || status.HostMaintenance != m {
+		t.Fatalf("wrong maintenance status: %+v", status)
+	}
+	if len(status.Conflicts) != 1 || status.Conflicts[0].ObligationID != conflicting.id() {
+		t.Fatalf("wrong conflicts: %+v", status.Conflicts)
+	}
+	if !acceptingContracts() {
+		t.Fatal("host stopped accepting contracts before the maintenance")
+	}
+	var alerted bool
+	for _, alert := range ht.host.Alerts() {
+		alerted = alerted || alert.Cause == fmt.Sprintf("maintenance from height %v to %v", m.Start, m.End)
+	}
+	if !alerted {
+		t.Fatal("host did not alert about conflicting maintenance")
+	}
+
+	// Announcing the maintenance should stop the host from accepting
+	// contracts right away.
+	m.Announce = true
+	if err := ht.host.SetMaintenance(m); err != nil {
+		t.Fatal(err)
+	}
+	if acceptingContracts() {
+		t.Fatal("host accepts contracts after announcing maintenance")
+	}
+
+	// The maintenance should persist across restarts.
+	if err := ht.host.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ht.host, err = New(ht.cs, ht.gateway, ht.tpool, ht.wallet, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err = ht.host.Maintenance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.HostMaintenance != m {
+		t.Fatalf("maintenance was not persisted: %+v", status)
+	}
+
+	// Maintenance that is in progress should stop the host from accepting
+	// contracts even if it was not announced.
+	m = modules.HostMaintenance{Start: height, End: height + 20}
+	if err := ht.host.SetMaintenance(m); err != nil {
+		t.Fatal(err)
+	}
+	ht.host.mu.RLock()
+	active := ht.host.maintenanceActive()
+	ht.host.mu.RUnlock()
+	if !active || acceptingContracts() {
+		t.Fatal("host accepts contracts during maintenance")
+	}
+
+	// Cancelling the maintenance should restore the host.
+	if err := ht.host.SetMaintenance(modules.HostMaintenance{}); err != nil {
+		t.Fatal(err)
+	}
+	status, err = ht.host.Maintenance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Scheduled || !status.Safe || !acceptingContracts() {
+		t.Fatalf("maintenance was not cancelled: %+v", status)
+	}
+}
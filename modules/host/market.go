@@ -0,0 +1,259 @@
+package host
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/encoding"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+type (
+	// marketHost is a host that was announced on the blockchain, together
+	// with the settings that the host reported during the most recent market
+	// scan.
+	marketHost struct {
+		NetAddress    modules.NetAddress           `json:"netaddress"`
+		PublicKey     types.SiaPublicKey           `json:"publickey"`
+		LastScan      time.Time                    `json:"lastscan"`
+		ScanSucceeded bool                         `json:"scansucceeded"`
+		Settings      modules.HostExternalSettings `json:"settings"`
+	}
+
+	// marketSubscriber subscribes to the consensus set on behalf of the host
+	// to learn about host announcements. It is separate from the host's own
+	// subscription so that hosts which existed before the pricing engine can
+	// learn about announcements from before their most recent consensus
+	// change.
+	marketSubscriber struct {
+		h *Host
+	}
+)
+
+// ProcessConsensusChange adds the hosts announced in the applied blocks of
+// the consensus change to the host's view of the market.
+func (ms *marketSubscriber) ProcessConsensusChange(cc modules.ConsensusChange) {
+	h := ms.h
+	err := h.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketMarketHosts)
+		for _, block := range cc.AppliedBlocks {
+			for _, txn := range block.Transactions {
+				for _, arb := range txn.ArbitraryData {
+					addr, pk, err := modules.DecodeAnnouncement(arb)
+					if err != nil {
+						continue
+					}
+					// Keep the settings of hosts that announce a new address.
+					var mh marketHost
+					if mhBytes := b.Get([]byte(pk.String())); mhBytes != nil {
+						if err := json.Unmarshal(mhBytes, &mh); err != nil {
+							return err
+						}
+					}
+					mh.NetAddress = addr
+					mh.PublicKey = pk
+					if err := putMarketHost(tx, mh); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.log.Println("Unable to update the market with the consensus change:", err)
+	}
+
+	h.mu.Lock()
+	h.marketChange = cc.ID
+	h.mu.Unlock()
+}
+
+// putMarketHost stores a market host in the database.
+func putMarketHost(tx *bolt.Tx, mh marketHost) error {
+	mhBytes, err := json.Marshal(mh)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(bucketMarketHosts).Put([]byte(mh.PublicKey.String()), mhBytes)
+}
+
+// marketHosts returns all hosts that are known to the market, except for the
+// host itself.
+func (h *Host) marketHosts() (hosts []marketHost, err error) {
+	pk := h.publicKey.String()
+	err = h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketMarketHosts).ForEach(func(k, v []byte) error {
+			if string(k) == pk {
+				return nil
+			}
+			var mh marketHost
+			if err := json.Unmarshal(v, &mh); err != nil {
+				return err
+			}
+			hosts = append(hosts, mh)
+			return nil
+		})
+	})
+	return hosts, err
+}
+
+// managedMarketSettings returns the settings of the hosts on the network
+// that responded to the most recent market scan and are accepting contracts.
+func (h *Host) managedMarketSettings() ([]modules.HostExternalSettings, error) {
+	h.mu.RLock()
+	hosts, err := h.marketHosts()
+	h.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	var settings []modules.HostExternalSettings
+	for _, mh := range hosts {
+		if mh.ScanSucceeded && mh.Settings.AcceptingContracts {
+			settings = append(settings, mh.Settings)
+		}
+	}
+	return settings, nil
+}
+
+// managedScanMarketHost requests the settings of a market host.
+func (h *Host) managedScanMarketHost(mh marketHost) (modules.HostExternalSettings, error) {
+	var settings modules.HostExternalSettings
+	if mh.PublicKey.Algorithm != types.SignatureEd25519 {
+		return settings, errors.New("unsupported host key")
+	}
+	dialer := &net.Dialer{
+		Cancel:  h.tg.StopChan(),
+		Timeout: marketScanTimeout,
+	}
+	conn, err := dialer.Dial("tcp", string(mh.NetAddress))
+	if err != nil {
+		return settings, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(marketScanTimeout))
+
+	err = encoding.WriteObject(conn, modules.RPCSettings)
+	if err != nil {
+		return settings, err
+	}
+	var pk crypto.PublicKey
+	copy(pk[:], mh.PublicKey.Key)
+	err = crypto.ReadSignedObject(conn, &settings, maxMarketSettingsLen, pk)
+	return settings, err
+}
+
+// managedScanMarket requests the settings of all hosts in the market and
+// stores the results in the database.
+func (h *Host) managedScanMarket() {
+	err := h.tg.Add()
+	if err != nil {
+		return
+	}
+	defer h.tg.Done()
+
+	h.mu.RLock()
+	hosts, err := h.marketHosts()
+	h.mu.RUnlock()
+	if err != nil {
+		h.log.Println("Unable to load the market hosts:", err)
+		return
+	}
+
+	// Scan the hosts in parallel.
+	scanChan := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < marketScanThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range scanChan {
+				settings, err := h.managedScanMarketHost(hosts[j])
+				hosts[j].LastScan = time.Now()
+				hosts[j].ScanSucceeded = err == nil
+				if err != nil {
+					h.log.Debugf("Market scan of host at %v failed: %v\n", hosts[j].NetAddress, err)
+					continue
+				}
+				hosts[j].Settings = settings
+			}
+		}()
+	}
+	for i := range hosts {
+		select {
+		case scanChan <- i:
+		case <-h.tg.StopChan():
+		}
+	}
+	close(scanChan)
+	wg.Wait()
+
+	err = h.db.Update(func(tx *bolt.Tx) error {
+		for _, mh := range hosts {
+			if mh.LastScan.IsZero() {
+				continue
+			}
+			if err := putMarketHost(tx, mh); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.log.Println("Unable to store the results of the market scan:", err)
+	}
+}
+
+// threadedScanMarket periodically refreshes the settings of the hosts in the
+// market.
+func (h *Host) threadedScanMarket() {
+	if h.dependencies.Disrupt("disableMarketScan") {
+		return
+	}
+	for {
+		h.managedScanMarket()
+		select {
+		case <-h.tg.StopChan():
+			return
+		case <-time.After(marketScanFrequency):
+		}
+	}
+}
+
+// threadedSubscribeMarket subscribes the market subscriber to the consensus
+// set and starts scanning the market once the subscriber has caught up.
+// Subscribing can take a long time if the host has never seen the
+// announcements on the blockchain, which is why it happens in the background.
+func (h *Host) threadedSubscribeMarket() {
+	err := h.tg.Add()
+	if err != nil {
+		return
+	}
+	defer h.tg.Done()
+
+	h.mu.RLock()
+	change := h.marketChange
+	h.mu.RUnlock()
+	ms := &marketSubscriber{h: h}
+	err = h.cs.ConsensusSetSubscribe(ms, change, h.tg.StopChan())
+	if err == modules.ErrInvalidConsensusChangeID {
+		// Start over if the consensus set doesn't recognize the change, which
+		// can happen if the consensus set was replaced.
+		err = h.cs.ConsensusSetSubscribe(ms, modules.ConsensusChangeBeginning, h.tg.StopChan())
+	}
+	if err != nil {
+		h.log.Println("Unable to subscribe to host announcements:", err)
+		return
+	}
+	h.tg.OnStop(func() {
+		h.cs.Unsubscribe(ms)
+	})
+	go h.threadedScanMarket()
+}
@@ -139,6 +139,16 @@ func (h *Host) managedRPCRecentRevision(conn net.Conn) (types.FileContractID, st
 		}
 	}()
 
+	// Now that the renter is known, apply the limits of the renter to the
+	// connection.
+	if len(recentRevision.UnlockConditions.PublicKeys) > 0 {
+		err = h.managedLimitRenter(conn, recentRevision.UnlockConditions.PublicKeys[0])
+		if err != nil {
+			modules.WriteNegotiationRejection(conn, err)
+			return types.FileContractID{}, storageObligation{}, extendErr("renter exceeded its limits: ", err)
+		}
+	}
+
 	// Send the file contract revision and the corresponding signatures to the
 	// renter.
 	err = modules.WriteNegotiationAcceptance(conn)
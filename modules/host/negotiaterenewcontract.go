@@ -123,8 +123,16 @@ func (h *Host) managedRPCRenewContract(conn net.Conn) error {
 
 	h.mu.Lock()
 	settings := h.externalSettings()
+	inMaintenance := h.maintenanceActive()
 	h.mu.Unlock()
 
+	// A renewal forms a new contract, which the host does not accept during
+	// maintenance.
+	if inMaintenance {
+		modules.WriteNegotiationRejection(conn, errHostInMaintenance) // Error is ignored to preserve type for extendErr
+		return extendErr("renewal rejected: ", errHostInMaintenance)
+	}
+
 	// Verify that the transaction coming over the wire is a proper renewal.
 	err = h.managedVerifyRenewedContract(so, txnSet, renterPK)
 	if err != nil {
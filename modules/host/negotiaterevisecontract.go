@@ -42,6 +42,7 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 	settings := h.externalSettings()
 	secretKey := h.secretKey
 	blockHeight := h.blockHeight
+	inMaintenance := h.maintenanceActive()
 	h.mu.Unlock()
 
 	// The renter is going to send its intended modifications, followed by the
@@ -81,6 +82,11 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 			if uint64(len(modification.Data)) > modules.SectorSize {
 				return errLargeSector
 			}
+			// Uploads are not accepted during maintenance, but renters may
+			// still delete sectors.
+			if inMaintenance && modification.Type != modules.ActionDelete {
+				return errHostInMaintenance
+			}
 
 			switch modification.Type {
 			case modules.ActionDelete:
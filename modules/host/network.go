@@ -241,6 +241,17 @@ func (h *Host) threadedHandleConn(conn net.Conn) {
 	}
 	defer h.tg.Done()
 
+	// Reject the connection if it exceeds the limits of the host or of the
+	// peer, and limit its bandwidth otherwise.
+	lc, err := h.managedLimitConn(conn)
+	if err != nil {
+		conn.Close()
+		h.log.Debugf("WARN: rejected incoming conn %v: %v", conn.RemoteAddr(), err)
+		return
+	}
+	defer h.managedReleaseConn(lc)
+	conn = lc
+
 	// Close the conn on host.Close or when the method terminates, whichever comes
 	// first.
 	connCloseChan := make(chan struct{})
@@ -333,7 +344,7 @@ func (h *Host) NetAddress() modules.NetAddress {
 }
 
 // NetworkMetrics returns information about the types of rpc calls that have
-// been made to the host.
+// been made to the host, and about the calls that were rejected.
 func (h *Host) NetworkMetrics() modules.HostNetworkMetrics {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
@@ -345,5 +356,8 @@ func (h *Host) NetworkMetrics() modules.HostNetworkMetrics {
 		ReviseCalls:       atomic.LoadUint64(&h.atomicReviseCalls),
 		SettingsCalls:     atomic.LoadUint64(&h.atomicSettingsCalls),
 		UnrecognizedCalls: atomic.LoadUint64(&h.atomicUnrecognizedCalls),
+
+		RejectedConnections: atomic.LoadUint64(&h.atomicRejectedConnections),
+		RejectedRPCs:        atomic.LoadUint64(&h.atomicRejectedRPCs),
 	}
 }
@@ -0,0 +1,270 @@
+package host
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+var (
+	// errInvalidObligationCursor is returned if a query contains a cursor
+	// that is not the id of a storage obligation.
+	errInvalidObligationCursor = errors.New("cursor is not the id of a storage obligation")
+
+	// errInvalidObligationLimit is returned if a query has a negative limit.
+	errInvalidObligationLimit = errors.New("limit must not be negative")
+
+	// errInvalidObligationSort is returned if a query sorts by an unknown
+	// field.
+	errInvalidObligationSort = errors.New("obligations can only be sorted by 'id', 'expiration', 'negotiation' or 'proofdeadline'")
+
+	// errInvalidObligationStatus is returned if a query filters by an unknown
+	// status.
+	errInvalidObligationStatus = errors.New("unknown obligation status")
+)
+
+// obligationMatches returns whether the storage obligation matches the
+// filters of the query.
+func obligationMatches(q modules.HostObligationQuery, so modules.StorageObligation) bool {
+	switch {
+	case q.Status != "" && so.ObligationStatus != q.Status:
+		return false
+	case so.ExpirationHeight < q.MinExpiration:
+		return false
+	case q.MaxExpiration != 0 && so.ExpirationHeight > q.MaxExpiration:
+		return false
+	case q.ProofConfirmed != nil && so.ProofConfirmed != *q.ProofConfirmed:
+		return false
+	case q.ProofConstructed != nil && so.ProofConstructed != *q.ProofConstructed:
+		return false
+	}
+	return true
+}
+
+// obligationSortKey returns the height by which the storage obligation is
+// sorted.
+func obligationSortKey(sortBy string, so modules.StorageObligation) types.BlockHeight {
+	switch sortBy {
+	case "expiration":
+		return so.ExpirationHeight
+	case "negotiation":
+		return so.NegotiationHeight
+	case "proofdeadline":
+		return so.ProofDeadLine
+	}
+	return 0
+}
+
+// validateObligationQuery checks the query and returns the id of its cursor.
+func validateObligationQuery(q modules.HostObligationQuery) (cursor types.FileContractID, err error) {
+	switch q.SortBy {
+	case "", "id", "expiration", "negotiation", "proofdeadline":
+	default:
+		return cursor, errInvalidObligationSort
+	}
+	switch q.Status {
+	case "", obligationUnresolved.String(), obligationRejected.String(), obligationSucceeded.String(), obligationFailed.String():
+	default:
+		return cursor, errInvalidObligationStatus
+	}
+	if q.Limit < 0 {
+		return cursor, errInvalidObligationLimit
+	}
+	if q.Cursor != "" {
+		if err := cursor.LoadString(q.Cursor); err != nil {
+			return cursor, errInvalidObligationCursor
+		}
+	}
+	return cursor, nil
+}
+
+// queryObligationsByID returns the storage obligations that match the query
+// in the order of their ids. Only the obligations of the page are decoded
+// after the cursor, which keeps paging cheap for hosts with many obligations.
+// One obligation more than the limit is returned, if it exists.
+func queryObligationsByID(tx *bolt.Tx, q modules.HostObligationQuery, cursor types.FileContractID) ([]modules.StorageObligation, error) {
+	c := tx.Bucket(bucketStorageObligations).Cursor()
+	var k, v []byte
+	switch {
+	case q.Cursor == "" && q.Descending:
+		k, v = c.Last()
+	case q.Cursor == "":
+		k, v = c.First()
+	case q.Descending:
+		// Seek returns the first obligation at or after the cursor, so the
+		// previous obligation is the first one of the page.
+		if k, _ = c.Seek(cursor[:]); k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+	default:
+		if k, v = c.Seek(cursor[:]); k != nil && bytes.Equal(k, cursor[:]) {
+			k, v = c.Next()
+		}
+	}
+
+	var sos []modules.StorageObligation
+	for ; k != nil; k, v = nextKey(c, q.Descending) {
+		var so storageObligation
+		if err := json.Unmarshal(v, &so); err != nil {
+			return nil, err
+		}
+		mso := so.info()
+		if !obligationMatches(q, mso) {
+			continue
+		}
+		sos = append(sos, mso)
+		if q.Limit != 0 && len(sos) > q.Limit {
+			break
+		}
+	}
+	return sos, nil
+}
+
+// nextKey moves the cursor to the next key in the given direction.
+func nextKey(c *bolt.Cursor, descending bool) ([]byte, []byte) {
+	if descending {
+		return c.Prev()
+	}
+	return c.Next()
+}
+
+// queryObligationsByHeight returns the storage obligations that match the
+// query sorted by the height in SortBy, with ties broken by id. All
+// obligations need to be decoded to sort them.
+func queryObligationsByHeight(tx *bolt.Tx, q modules.HostObligationQuery, cursor types.FileContractID) ([]modules.StorageObligation, error) {
+	var sos []modules.StorageObligation
+	err := tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+		var so storageObligation
+		if err := json.Unmarshal(soBytes, &so); err != nil {
+			return err
+		}
+		if mso := so.info(); obligationMatches(q, mso) {
+			sos = append(sos, mso)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// less reports whether the obligation with key ki and id idi is sorted
+	// before the obligation with key kj and id idj.
+	less := func(ki types.BlockHeight, idi types.FileContractID, kj types.BlockHeight, idj types.FileContractID) bool {
+		if ki != kj {
+			return (ki < kj) != q.Descending
+		}
+		if q.Descending {
+			return bytes.Compare(idi[:], idj[:]) > 0
+		}
+		return bytes.Compare(idi[:], idj[:]) < 0
+	}
+	sort.Slice(sos, func(i, j int) bool {
+		return less(obligationSortKey(q.SortBy, sos[i]), sos[i].ObligationId,
+			obligationSortKey(q.SortBy, sos[j]), sos[j].ObligationId)
+	})
+
+	if q.Cursor != "" {
+		// The page starts after the obligation of the cursor, which is
+		// located using its current sort key.
+		so, err := getStorageObligation(tx, cursor)
+		if err == errNoStorageObligation {
+			return nil, errInvalidObligationCursor
+		} else if err != nil {
+			return nil, err
+		}
+		key := obligationSortKey(q.SortBy, so.info())
+		start := sort.Search(len(sos), func(i int) bool {
+			return less(key, cursor, obligationSortKey(q.SortBy, sos[i]), sos[i].ObligationId)
+		})
+		sos = sos[start:]
+	}
+	if q.Limit != 0 && len(sos) > q.Limit+1 {
+		sos = sos[:q.Limit+1]
+	}
+	return sos, nil
+}
+
+// StorageObligation returns the details of the storage obligation with the
+// given id.
+func (h *Host) StorageObligation(id types.FileContractID) (modules.HostObligationDetails, error) {
+	if err := h.tg.Add(); err != nil {
+		return modules.HostObligationDetails{}, err
+	}
+	defer h.tg.Done()
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var details modules.HostObligationDetails
+	err := h.db.View(func(tx *bolt.Tx) error {
+		so, err := getStorageObligation(tx, id)
+		if err != nil {
+			return err
+		}
+		details.StorageObligation = so.info()
+		details.ActionItems = []types.BlockHeight{}
+		details.ProofHistory = append([]modules.HostProofAttempt{}, so.ProofHistory...)
+
+		// Action items are never scheduled before the obligation was
+		// negotiated.
+		heightBytes := make([]byte, 8)
+		binary.BigEndian.PutUint64(heightBytes, uint64(so.NegotiationHeight))
+		c := tx.Bucket(bucketActionItems).Cursor()
+		for k, v := c.Seek(heightBytes); k != nil; k, v = c.Next() {
+			for i := 0; i+len(id) <= len(v); i += len(id) {
+				if bytes.Equal(v[i:i+len(id)], id[:]) {
+					details.ActionItems = append(details.ActionItems, types.BlockHeight(binary.BigEndian.Uint64(k)))
+					break
+				}
+			}
+		}
+		return nil
+	})
+	return details, err
+}
+
+// StorageObligationsQuery returns a page of the storage obligations held by
+// the host that match the query.
+func (h *Host) StorageObligationsQuery(q modules.HostObligationQuery) (modules.HostObligationPage, error) {
+	if err := h.tg.Add(); err != nil {
+		return modules.HostObligationPage{}, err
+	}
+	defer h.tg.Done()
+	cursor, err := validateObligationQuery(q)
+	if err != nil {
+		return modules.HostObligationPage{}, err
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var sos []modules.StorageObligation
+	err = h.db.View(func(tx *bolt.Tx) (err error) {
+		if q.SortBy == "" || q.SortBy == "id" {
+			sos, err = queryObligationsByID(tx, q, cursor)
+		} else {
+			sos, err = queryObligationsByHeight(tx, q, cursor)
+		}
+		return err
+	})
+	if err != nil {
+		return modules.HostObligationPage{}, err
+	}
+
+	page := modules.HostObligationPage{
+		Obligations: []modules.StorageObligation{},
+	}
+	if q.Limit != 0 && len(sos) > q.Limit {
+		sos = sos[:q.Limit]
+		page.NextCursor = sos[len(sos)-1].ObligationId.String()
+	}
+	page.Obligations = append(page.Obligations, sos...)
+	return page, nil
+}
@@ -0,0 +1,209 @@
+package host
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// TestStorageObligationsQuery probes the filtering, sorting and pagination
+// of the host's storage obligations.
+func TestStorageObligationsQuery(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Store obligations that expire at different heights, every third of
+	// which has a confirmed storage proof.
+	var ids []types.FileContractID
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		for i := 0; i < 10; i++ {
+			so := storageObligation{
+				NegotiationHeight: types.BlockHeight(i),
+				OriginTransactionSet: []types.Transaction{{
+					FileContracts: []types.FileContract{{
+						WindowStart: types.BlockHeight(100 - i),
+						WindowEnd:   types.BlockHeight(110 - i),
+					}},
+				}},
+				ProofConfirmed: i%3 == 0,
+			}
+			soBytes, err := json.Marshal(so)
+			if err != nil {
+				return err
+			}
+			id := so.id()
+			ids = append(ids, id)
+			if err := tx.Bucket(bucketStorageObligations).Put(id[:], soBytes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// pageAll requests all pages of a query and returns the ids of the
+	// obligations in order.
+	pageAll := func(q modules.HostObligationQuery) []types.FileContractID {
+		var got []types.FileContractID
+		for {
+			page, err := ht.host.StorageObligationsQuery(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if q.Limit != 0 && len(page.Obligations) > q.Limit {
+				t.Fatal("page exceeds limit:", len(page.Obligations))
+			}
+			for _, so := range page.Obligations {
+				got = append(got, so.ObligationId)
+			}
+			if page.NextCursor == "" {
+				return got
+			}
+			q.Cursor = page.NextCursor
+		}
+	}
+
+	// Sorting by id should return the obligations in the order of their ids.
+	got := pageAll(modules.HostObligationQuery{Limit: 3})
+	if len(got) != len(ids) {
+		t.Fatalf("expected %v obligations, got %v", len(ids), len(got))
+	}
+	for i := 1; i < len(got); i++ {
+		if bytes.Compare(got[i-1][:], got[i][:]) >= 0 {
+			t.Fatal("obligations are not sorted by id")
+		}
+	}
+	desc := pageAll(modules.HostObligationQuery{Limit: 4, Descending: true})
+	for i := range desc {
+		if desc[i] != got[len(got)-1-i] {
+			t.Fatal("descending order is not the reverse of ascending order")
+		}
+	}
+
+	// Sorting by expiration should return the last obligation first.
+	got = pageAll(modules.HostObligationQuery{Limit: 3, SortBy: "expiration"})
+	if len(got) != len(ids) {
+		t.Fatalf("expected %v obligations, got %v", len(ids), len(got))
+	}
+	for i := range got {
+		if got[i] != ids[len(ids)-1-i] {
+			t.Fatal("obligations are not sorted by expiration")
+		}
+	}
+	got = pageAll(modules.HostObligationQuery{Limit: 3, SortBy: "negotiation", Descending: true})
+	for i := range got {
+		if got[i] != ids[len(ids)-1-i] {
+			t.Fatal("obligations are not sorted by negotiation height")
+		}
+	}
+
+	// Filters should apply to all pages.
+	confirmed := true
+	got = pageAll(modules.HostObligationQuery{Limit: 1, ProofConfirmed: &confirmed, SortBy: "proofdeadline"})
+	if len(got) != 4 || got[0] != ids[9] || got[3] != ids[0] {
+		t.Fatal("unexpected obligations with confirmed proofs:", got)
+	}
+	got = pageAll(modules.HostObligationQuery{MinExpiration: 95, MaxExpiration: 97})
+	if len(got) != 3 {
+		t.Fatal("expected 3 obligations in the expiration range, got", len(got))
+	}
+	got = pageAll(modules.HostObligationQuery{Status: obligationSucceeded.String()})
+	if len(got) != 0 {
+		t.Fatal("expected no succeeded obligations, got", len(got))
+	}
+
+	// Invalid queries should be rejected.
+	for _, q := range []modules.HostObligationQuery{
+		{SortBy: "size"},
+		{Status: "done"},
+		{Limit: -1},
+		{Cursor: "foo"},
+		{Cursor: types.FileContractID{}.String(), SortBy: "expiration"},
+	} {
+		if _, err := ht.host.StorageObligationsQuery(q); err == nil {
+			t.Errorf("expected query %+v to be rejected", q)
+		}
+	}
+}
+
+// TestStorageObligationDetails checks that the details of a storage
+// obligation include its action items.
+func TestStorageObligationDetails(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	so := storageObligation{
+		NegotiationHeight: 5,
+		OriginTransactionSet: []types.Transaction{{
+			FileContracts: []types.FileContract{{
+				WindowStart: 20,
+				WindowEnd:   30,
+			}},
+		}},
+	}
+	id := so.id()
+	other := types.FileContractID{1}
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		soBytes, err := json.Marshal(so)
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket(bucketStorageObligations).Put(id[:], soBytes); err != nil {
+			return err
+		}
+		// Store action items before the negotiation height, shared with
+		// another obligation, and for the other obligation only.
+		for height, items := range map[uint64][]byte{
+			1:  id[:],
+			10: append(other[:], id[:]...),
+			20: other[:],
+			25: id[:],
+		} {
+			heightBytes := make([]byte, 8)
+			binary.BigEndian.PutUint64(heightBytes, height)
+			if err := tx.Bucket(bucketActionItems).Put(heightBytes, items); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	details, err := ht.host.StorageObligation(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.ObligationId != id || details.ExpirationHeight != 20 || details.ProofDeadLine != 30 {
+		t.Fatalf("unexpected obligation: %+v", details.StorageObligation)
+	}
+	if len(details.ActionItems) != 2 || details.ActionItems[0] != 10 || details.ActionItems[1] != 25 {
+		t.Fatal("unexpected action items:", details.ActionItems)
+	}
+	if _, err := ht.host.StorageObligation(other); err != errNoStorageObligation {
+		t.Fatal("expected errNoStorageObligation, got", err)
+	}
+}
@@ -29,6 +29,14 @@ type persistence struct {
 	SecretKey        crypto.SecretKey             `json:"secretkey"`
 	Settings         modules.HostInternalSettings `json:"settings"`
 	UnlockHash       types.UnlockHash             `json:"unlockhash"`
+
+	// Pricing Engine.
+	MarketChange        modules.ConsensusChangeID `json:"marketchange"`
+	PricingPolicy       modules.HostPricingPolicy `json:"pricingpolicy"`
+	PricingUpdateHeight types.BlockHeight         `json:"pricingupdateheight"`
+
+	// Maintenance.
+	Maintenance modules.HostMaintenance `json:"maintenance"`
 }
 
 // persistData returns the data in the Host that will be saved to disk.
@@ -47,6 +55,14 @@ func (h *Host) persistData() persistence {
 		SecretKey:        h.secretKey,
 		Settings:         h.settings,
 		UnlockHash:       h.unlockHash,
+
+		// Pricing Engine.
+		MarketChange:        h.marketChange,
+		PricingPolicy:       h.pricingPolicy,
+		PricingUpdateHeight: h.pricingUpdateHeight,
+
+		// Maintenance.
+		Maintenance: h.maintenance,
 	}
 }
 
@@ -58,8 +74,13 @@ func (h *Host) establishDefaults() error {
 		MaxDownloadBatchSize: uint64(defaultMaxDownloadBatchSize),
 		MaxDuration:          defaultMaxDuration,
 		MaxReviseBatchSize:   uint64(defaultMaxReviseBatchSize),
+		SectorScrubRate:      defaultSectorScrubRate,
 		WindowSize:           defaultWindowSize,
 
+		FolderHealthMinOperations: defaultFolderHealthMinOperations,
+		FolderMaxFailedReadRate:   defaultFolderMaxFailedReadRate,
+		FolderMaxFailedWriteRate:  defaultFolderMaxFailedWriteRate,
+
 		Collateral:       defaultCollateral,
 		CollateralBudget: defaultCollateralBudget,
 		MaxCollateral:    defaultMaxCollateral,
@@ -69,6 +90,7 @@ func (h *Host) establishDefaults() error {
 		MinDownloadBandwidthPrice: defaultDownloadBandwidthPrice,
 		MinUploadBandwidthPrice:   defaultUploadBandwidthPrice,
 	}
+	h.pricingPolicy = defaultPricingPolicy()
 
 	// Generate signing key, for revising contracts.
 	sk, pk := crypto.GenerateKeyPair()
@@ -107,6 +129,14 @@ func (h *Host) loadPersistObject(p *persistence) {
 		h.settings.NetAddress = ""
 	}
 	h.unlockHash = p.UnlockHash
+
+	// Copy over the pricing engine.
+	h.marketChange = p.MarketChange
+	h.pricingPolicy = p.PricingPolicy
+	h.pricingUpdateHeight = p.PricingUpdateHeight
+
+	// Copy over the maintenance.
+	h.maintenance = p.Maintenance
 }
 
 // initDB will check that the database has been initialized and if not, will
@@ -129,6 +159,10 @@ func (h *Host) initDB() (err error) {
 		// database needs to be initialized. Create the database buckets.
 		buckets := [][]byte{
 			bucketActionItems,
+			bucketFinancialSnapshots,
+			bucketMarketHosts,
+			bucketObligationResults,
+			bucketPriceHistory,
 			bucketStorageObligations,
 		}
 		for _, bucket := range buckets {
@@ -155,6 +189,17 @@ func (h *Host) load() error {
 	// the most recent version, but older versions need to be updated to the
 	// more recent structures.
 	p := new(persistence)
+	// Hosts that were created before sector scrubbing existed should scrub at
+	// the default rate.
+	p.Settings.SectorScrubRate = defaultSectorScrubRate
+	// Hosts that were created before storage folder health monitoring existed
+	// use the default health thresholds.
+	p.Settings.FolderHealthMinOperations = defaultFolderHealthMinOperations
+	p.Settings.FolderMaxFailedReadRate = defaultFolderMaxFailedReadRate
+	p.Settings.FolderMaxFailedWriteRate = defaultFolderMaxFailedWriteRate
+	// Hosts that were created before the pricing engine existed use the
+	// default pricing policy.
+	p.PricingPolicy = defaultPricingPolicy()
 	err = h.dependencies.LoadFile(persistMetadata, p, filepath.Join(h.persistDir, settingsFile))
 	if err == nil {
 		// Copy in the persistence.
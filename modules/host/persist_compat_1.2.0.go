@@ -389,6 +389,8 @@ func (h *Host) upgradeFromV112ToV120() error {
 	}
 	// Try loading the persist again.
 	p := new(persistence)
+	p.Settings.SectorScrubRate = defaultSectorScrubRate
+	p.PricingPolicy = defaultPricingPolicy()
 	err = h.dependencies.LoadFile(v112PersistMetadata, p, filepath.Join(h.persistDir, settingsFile))
 	if err != nil {
 		return build.ExtendErr("upgrade appears complete, but having difficulties reloading host after upgrade", err)
@@ -0,0 +1,349 @@
+package host
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+var (
+	// errInsufficientMarketData is returned if the pricing engine doesn't
+	// know the settings of enough hosts to derive prices from the market.
+	errInsufficientMarketData = errors.New("the host doesn't know the settings of enough other hosts to derive prices from the market")
+
+	// errInvalidFillRule is returned if a fill rule of a pricing policy has a
+	// utilization outside of [0, 1] or a multiplier that is not positive.
+	errInvalidFillRule = errors.New("fill rules need a utilization between 0 and 1 and a positive multiplier")
+
+	// errInvalidPercentile is returned if the percentile of a pricing policy
+	// is outside of [0, 100].
+	errInvalidPercentile = errors.New("percentile must be between 0 and 100")
+
+	// errInvalidStoragePriceRange is returned if the maximum storage price of
+	// a pricing policy is lower than the minimum storage price.
+	errInvalidStoragePriceRange = errors.New("maximum storage price must not be lower than the minimum storage price")
+
+	// errInvalidUpdateInterval is returned if a pricing policy has an update
+	// interval of zero.
+	errInvalidUpdateInterval = errors.New("update interval must be at least one block")
+)
+
+// defaultPricingPolicy returns the pricing policy of a new host. The pricing
+// engine is disabled by default.
+func defaultPricingPolicy() modules.HostPricingPolicy {
+	return modules.HostPricingPolicy{
+		Percentile:     defaultPricingPercentile,
+		UpdateInterval: defaultPricingUpdateInterval,
+	}
+}
+
+// validatePricingPolicy checks that a pricing policy is sane.
+func validatePricingPolicy(policy modules.HostPricingPolicy) error {
+	if policy.Percentile < 0 || policy.Percentile > 100 || math.IsNaN(policy.Percentile) {
+		return errInvalidPercentile
+	}
+	if policy.UpdateInterval == 0 {
+		return errInvalidUpdateInterval
+	}
+	for _, rule := range policy.FillRules {
+		if !(rule.Utilization >= 0 && rule.Utilization <= 1) || !(rule.Multiplier > 0) || math.IsInf(rule.Multiplier, 0) {
+			return errInvalidFillRule
+		}
+	}
+	if !policy.MaxStoragePrice.IsZero() && policy.MaxStoragePrice.Cmp(policy.MinStoragePrice) < 0 {
+		return errInvalidStoragePriceRange
+	}
+	return nil
+}
+
+// currencyPercentile returns the nearest-rank percentile of a set of
+// currencies. The set must not be empty.
+func currencyPercentile(values []types.Currency, percentile float64) types.Currency {
+	sorted := append([]types.Currency(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Cmp(sorted[j]) < 0
+	})
+	rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// fillMultiplier returns the multiplier of the fill rule with the highest
+// utilization that has been reached, or 1 if no rule applies.
+func fillMultiplier(rules []modules.HostPricingFillRule, utilization float64) float64 {
+	multiplier, threshold := 1.0, -1.0
+	for _, rule := range rules {
+		if rule.Utilization <= utilization && rule.Utilization > threshold {
+			multiplier, threshold = rule.Multiplier, rule.Utilization
+		}
+	}
+	return multiplier
+}
+
+// marketPrices derives the host's prices from the settings of the other hosts
+// on the network according to the pricing policy. It returns the prices and
+// the fill multiplier that was applied to the storage price.
+func marketPrices(market []modules.HostExternalSettings, policy modules.HostPricingPolicy, utilization float64) (modules.HostPrices, float64) {
+	var collateral, contract, download, storage, upload []types.Currency
+	for _, settings := range market {
+		collateral = append(collateral, settings.Collateral)
+		contract = append(contract, settings.ContractPrice)
+		download = append(download, settings.DownloadBandwidthPrice)
+		storage = append(storage, settings.StoragePrice)
+		upload = append(upload, settings.UploadBandwidthPrice)
+	}
+	multiplier := fillMultiplier(policy.FillRules, utilization)
+	prices := modules.HostPrices{
+		Collateral:                currencyPercentile(collateral, policy.Percentile),
+		MinContractPrice:          currencyPercentile(contract, policy.Percentile),
+		MinDownloadBandwidthPrice: currencyPercentile(download, policy.Percentile),
+		MinStoragePrice:           currencyPercentile(storage, policy.Percentile).MulFloat(multiplier),
+		MinUploadBandwidthPrice:   currencyPercentile(upload, policy.Percentile),
+	}
+	if prices.MinStoragePrice.Cmp(policy.MinStoragePrice) < 0 {
+		prices.MinStoragePrice = policy.MinStoragePrice
+	}
+	if !policy.MaxStoragePrice.IsZero() && prices.MinStoragePrice.Cmp(policy.MaxStoragePrice) > 0 {
+		prices.MinStoragePrice = policy.MaxStoragePrice
+	}
+	return prices, multiplier
+}
+
+// pricesEqual returns whether two sets of prices are equal.
+func pricesEqual(a, b modules.HostPrices) bool {
+	return a.Collateral.Equals(b.Collateral) &&
+		a.MinContractPrice.Equals(b.MinContractPrice) &&
+		a.MinDownloadBandwidthPrice.Equals(b.MinDownloadBandwidthPrice) &&
+		a.MinStoragePrice.Equals(b.MinStoragePrice) &&
+		a.MinUploadBandwidthPrice.Equals(b.MinUploadBandwidthPrice)
+}
+
+// currentPrices returns the prices from the host's internal settings.
+func (h *Host) currentPrices() modules.HostPrices {
+	return modules.HostPrices{
+		Collateral:                h.settings.Collateral,
+		MinContractPrice:          h.settings.MinContractPrice,
+		MinDownloadBandwidthPrice: h.settings.MinDownloadBandwidthPrice,
+		MinStoragePrice:           h.settings.MinStoragePrice,
+		MinUploadBandwidthPrice:   h.settings.MinUploadBandwidthPrice,
+	}
+}
+
+// managedStorageUtilization returns the fraction of the host's storage
+// capacity that is in use.
+func (h *Host) managedStorageUtilization() float64 {
+	var capacity, remaining uint64
+	for _, sf := range h.StorageFolders() {
+		capacity += sf.Capacity
+		remaining += sf.CapacityRemaining
+	}
+	if capacity == 0 {
+		return 0
+	}
+	return float64(capacity-remaining) / float64(capacity)
+}
+
+// managedPricingEstimate computes the prices that the pricing engine would
+// apply given the current market and storage utilization.
+func (h *Host) managedPricingEstimate() (modules.HostPricingEstimate, error) {
+	market, err := h.managedMarketSettings()
+	if err != nil {
+		return modules.HostPricingEstimate{}, err
+	}
+	if len(market) < minMarketHosts {
+		return modules.HostPricingEstimate{}, errInsufficientMarketData
+	}
+	utilization := h.managedStorageUtilization()
+
+	h.mu.RLock()
+	policy := h.pricingPolicy
+	current := h.currentPrices()
+	h.mu.RUnlock()
+
+	proposed, multiplier := marketPrices(market, policy, utilization)
+	return modules.HostPricingEstimate{
+		Current:  current,
+		Proposed: proposed,
+
+		FillMultiplier:     multiplier,
+		MarketHosts:        uint64(len(market)),
+		StorageUtilization: utilization,
+	}, nil
+}
+
+// managedUpdatePrices applies the prices of the pricing engine if the engine
+// is enabled and an update is due.
+func (h *Host) managedUpdatePrices() {
+	h.mu.RLock()
+	policy := h.pricingPolicy
+	due := h.pricingUpdateHeight == 0 || h.blockHeight >= h.pricingUpdateHeight+policy.UpdateInterval
+	h.mu.RUnlock()
+	if !policy.Enabled || !due {
+		return
+	}
+
+	estimate, err := h.managedPricingEstimate()
+	if err == errInsufficientMarketData {
+		h.log.Debugln("Not updating prices:", err)
+		return
+	} else if err != nil {
+		h.log.Println("Unable to compute new prices:", err)
+		return
+	}
+
+	h.mu.Lock()
+	// The policy might have changed while the estimate was computed, in which
+	// case the engine is woken up again by SetPricingPolicy.
+	if !reflect.DeepEqual(h.pricingPolicy, policy) {
+		h.mu.Unlock()
+		return
+	}
+	applied := !pricesEqual(estimate.Proposed, estimate.Current)
+	if applied {
+		h.settings.Collateral = estimate.Proposed.Collateral
+		h.settings.MinContractPrice = estimate.Proposed.MinContractPrice
+		h.settings.MinDownloadBandwidthPrice = estimate.Proposed.MinDownloadBandwidthPrice
+		h.settings.MinStoragePrice = estimate.Proposed.MinStoragePrice
+		h.settings.MinUploadBandwidthPrice = estimate.Proposed.MinUploadBandwidthPrice
+		h.revisionNumber++
+	}
+	h.pricingUpdateHeight = h.blockHeight
+	change := modules.HostPriceChange{
+		BlockHeight: h.blockHeight,
+		Timestamp:   time.Now(),
+
+		NewPrices: estimate.Proposed,
+		OldPrices: estimate.Current,
+
+		FillMultiplier:     estimate.FillMultiplier,
+		MarketHosts:        estimate.MarketHosts,
+		StorageUtilization: estimate.StorageUtilization,
+	}
+	err = h.saveSync()
+	h.mu.Unlock()
+	if err != nil {
+		h.log.Println("Unable to save the host after updating the prices:", err)
+	}
+	if !applied {
+		return
+	}
+
+	h.log.Printf("Pricing engine updated the prices using %v hosts: %+v\n", change.MarketHosts, change.NewPrices)
+	err = h.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketPriceHistory)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		changeBytes, err := json.Marshal(change)
+		if err != nil {
+			return err
+		}
+		return b.Put(key, changeBytes)
+	})
+	if err != nil {
+		h.log.Println("Unable to add the price change to the history:", err)
+	}
+}
+
+// threadedUpdatePrices periodically checks whether the pricing engine needs
+// to update the host's prices.
+func (h *Host) threadedUpdatePrices() {
+	for {
+		select {
+		case <-h.tg.StopChan():
+			return
+		case <-h.pricingPolicyChanged:
+		case <-time.After(pricingCheckFrequency):
+		}
+		if err := h.tg.Add(); err != nil {
+			return
+		}
+		h.managedUpdatePrices()
+		h.tg.Done()
+	}
+}
+
+// PriceHistory returns the price changes that were made by the host's pricing
+// engine, oldest first.
+func (h *Host) PriceHistory() (history []modules.HostPriceChange, err error) {
+	if err := h.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer h.tg.Done()
+	err = h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketPriceHistory).ForEach(func(_, v []byte) error {
+			var change modules.HostPriceChange
+			if err := json.Unmarshal(v, &change); err != nil {
+				return err
+			}
+			history = append(history, change)
+			return nil
+		})
+	})
+	return history, err
+}
+
+// PricingEstimate performs a dry run of the host's pricing engine. The prices
+// are computed from the current market and storage utilization, regardless
+// of whether the engine is enabled, but they are not applied.
+func (h *Host) PricingEstimate() (modules.HostPricingEstimate, error) {
+	if err := h.tg.Add(); err != nil {
+		return modules.HostPricingEstimate{}, err
+	}
+	defer h.tg.Done()
+	return h.managedPricingEstimate()
+}
+
+// PricingPolicy returns the policy of the host's pricing engine.
+func (h *Host) PricingPolicy() modules.HostPricingPolicy {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.pricingPolicy
+}
+
+// SetPricingPolicy sets the policy of the host's pricing engine. If the engine
+// is enabled, the new policy is applied right away and then every
+// UpdateInterval blocks. While the engine is enabled, it overwrites the
+// prices of the host's internal settings.
+func (h *Host) SetPricingPolicy(policy modules.HostPricingPolicy) error {
+	if err := h.tg.Add(); err != nil {
+		return err
+	}
+	defer h.tg.Done()
+	if err := validatePricingPolicy(policy); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	h.pricingPolicy = policy
+	h.pricingUpdateHeight = 0
+	err := h.saveSync()
+	h.mu.Unlock()
+	if err != nil {
+		return errors.New("pricing policy updated, but failed saving to disk: " + err.Error())
+	}
+
+	// Wake the pricing engine.
+	select {
+	case h.pricingPolicyChanged <- struct{}{}:
+	default:
+	}
+	return nil
+}
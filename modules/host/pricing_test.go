@@ -0,0 +1,272 @@
+package host
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// dependencyDisableMarketScan prevents the host from scanning the market, so
+// that tests can control the settings of the market hosts.
+type dependencyDisableMarketScan struct {
+	modules.ProductionDependencies
+}
+
+// Disrupt returns true if the market scan should be disabled.
+func (*dependencyDisableMarketScan) Disrupt(s string) bool {
+	return s == "disableMarketScan"
+}
+
+// TestMarketPrices probes the price computation of the pricing engine.
+func TestMarketPrices(t *testing.T) {
+	var market []modules.HostExternalSettings
+	for i := uint64(1); i <= 5; i++ {
+		market = append(market, modules.HostExternalSettings{
+			Collateral:             types.NewCurrency64(10 * i),
+			ContractPrice:          types.NewCurrency64(20 * i),
+			DownloadBandwidthPrice: types.NewCurrency64(30 * i),
+			StoragePrice:           types.NewCurrency64(100 * (6 - i)),
+			UploadBandwidthPrice:   types.NewCurrency64(40 * i),
+		})
+	}
+
+	// The median of the market should be used without fill rules.
+	policy := modules.HostPricingPolicy{Percentile: 50, UpdateInterval: 1}
+	prices, multiplier := marketPrices(market, policy, 0.9)
+	if multiplier != 1 {
+		t.Fatal("expected a multiplier of 1, got", multiplier)
+	}
+	expected := modules.HostPrices{
+		Collateral:                types.NewCurrency64(30),
+		MinContractPrice:          types.NewCurrency64(60),
+		MinDownloadBandwidthPrice: types.NewCurrency64(90),
+		MinStoragePrice:           types.NewCurrency64(300),
+		MinUploadBandwidthPrice:   types.NewCurrency64(120),
+	}
+	if !pricesEqual(prices, expected) {
+		t.Fatalf("expected %v, got %v", expected, prices)
+	}
+
+	// Check the bounds of the percentile.
+	policy.Percentile = 0
+	if prices, _ = marketPrices(market, policy, 0); !prices.MinStoragePrice.Equals64(100) {
+		t.Fatal("wrong storage price for the 0th percentile:", prices.MinStoragePrice)
+	}
+	policy.Percentile = 100
+	if prices, _ = marketPrices(market, policy, 0); !prices.MinStoragePrice.Equals64(500) {
+		t.Fatal("wrong storage price for the 100th percentile:", prices.MinStoragePrice)
+	}
+
+	// The fill rule with the highest utilization that has been reached should
+	// apply.
+	policy.Percentile = 50
+	policy.FillRules = []modules.HostPricingFillRule{
+		{Utilization: 0.8, Multiplier: 2},
+		{Utilization: 0.5, Multiplier: 1.5},
+		{Utilization: 0.95, Multiplier: 4},
+	}
+	for _, test := range []struct {
+		utilization float64
+		multiplier  float64
+		price       uint64
+	}{
+		{0.1, 1, 300},
+		{0.5, 1.5, 450},
+		{0.9, 2, 600},
+		{1, 4, 1200},
+	} {
+		prices, multiplier = marketPrices(market, policy, test.utilization)
+		if multiplier != test.multiplier || !prices.MinStoragePrice.Equals64(test.price) {
+			t.Errorf("utilization %v: expected multiplier %v and price %v, got %v and %v", test.utilization, test.multiplier, test.price, multiplier, prices.MinStoragePrice)
+		}
+		if !prices.MinContractPrice.Equals64(60) {
+			t.Error("fill rules should only affect the storage price")
+		}
+	}
+
+	// The storage price should be kept within its bounds.
+	policy.MinStoragePrice = types.NewCurrency64(350)
+	if prices, _ = marketPrices(market, policy, 0); !prices.MinStoragePrice.Equals64(350) {
+		t.Fatal("storage price should be raised to the minimum:", prices.MinStoragePrice)
+	}
+	policy.MaxStoragePrice = types.NewCurrency64(500)
+	if prices, _ = marketPrices(market, policy, 1); !prices.MinStoragePrice.Equals64(500) {
+		t.Fatal("storage price should be capped at the maximum:", prices.MinStoragePrice)
+	}
+}
+
+// TestValidatePricingPolicy checks that insane pricing policies are rejected.
+func TestValidatePricingPolicy(t *testing.T) {
+	if err := validatePricingPolicy(defaultPricingPolicy()); err != nil {
+		t.Fatal("default policy should be valid:", err)
+	}
+	for _, test := range []struct {
+		modify func(*modules.HostPricingPolicy)
+		err    error
+	}{
+		{func(p *modules.HostPricingPolicy) { p.Percentile = 101 }, errInvalidPercentile},
+		{func(p *modules.HostPricingPolicy) { p.Percentile = -1 }, errInvalidPercentile},
+		{func(p *modules.HostPricingPolicy) { p.UpdateInterval = 0 }, errInvalidUpdateInterval},
+		{func(p *modules.HostPricingPolicy) {
+			p.FillRules = []modules.HostPricingFillRule{{Utilization: 1.5, Multiplier: 2}}
+		}, errInvalidFillRule},
+		{func(p *modules.HostPricingPolicy) {
+			p.FillRules = []modules.HostPricingFillRule{{Utilization: 0.5, Multiplier: 0}}
+		}, errInvalidFillRule},
+		{func(p *modules.HostPricingPolicy) {
+			p.MinStoragePrice = types.NewCurrency64(2)
+			p.MaxStoragePrice = types.NewCurrency64(1)
+		}, errInvalidStoragePriceRange},
+	} {
+		policy := defaultPricingPolicy()
+		test.modify(&policy)
+		if err := validatePricingPolicy(policy); err != test.err {
+			t.Errorf("expected %v, got %v", test.err, err)
+		}
+	}
+}
+
+// TestPricingEngine checks that the pricing engine applies the prices derived
+// from the market and records the changes in the price history.
+func TestPricingEngine(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newMockHostTester(&dependencyDisableMarketScan{}, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// The host should add its own announcement to the market.
+	err = ht.host.Announce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ht.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(50, 100*time.Millisecond, func() error {
+		return ht.host.db.View(func(tx *bolt.Tx) error {
+			if tx.Bucket(bucketMarketHosts).Get([]byte(ht.host.PublicKey().String())) == nil {
+				return errors.New("announcement not found in the market")
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without other hosts, there is no market to derive prices from.
+	if _, err := ht.host.PricingEstimate(); err != errInsufficientMarketData {
+		t.Fatal("expected errInsufficientMarketData, got", err)
+	}
+
+	// Add some scanned hosts to the market.
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		for i := uint64(1); i <= 3; i++ {
+			_, pk := crypto.GenerateKeyPair()
+			mh := marketHost{
+				NetAddress:    "foo.com:1234",
+				PublicKey:     types.Ed25519PublicKey(pk),
+				ScanSucceeded: true,
+				Settings: modules.HostExternalSettings{
+					AcceptingContracts:     true,
+					Collateral:             types.NewCurrency64(10 * i),
+					ContractPrice:          types.NewCurrency64(20 * i),
+					DownloadBandwidthPrice: types.NewCurrency64(30 * i),
+					StoragePrice:           types.NewCurrency64(40 * i),
+					UploadBandwidthPrice:   types.NewCurrency64(50 * i),
+				},
+			}
+			if err := putMarketHost(tx, mh); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A dry run shouldn't change the prices.
+	original := ht.host.InternalSettings()
+	estimate, err := ht.host.PricingEstimate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if estimate.MarketHosts != 3 || !estimate.Proposed.MinStoragePrice.Equals64(80) {
+		t.Fatalf("unexpected estimate: %+v", estimate)
+	}
+	if !estimate.Current.MinStoragePrice.Equals(original.MinStoragePrice) {
+		t.Fatal("estimate should contain the current storage price")
+	}
+	if !ht.host.InternalSettings().MinStoragePrice.Equals(original.MinStoragePrice) {
+		t.Fatal("dry run changed the storage price")
+	}
+
+	// Invalid policies should be rejected.
+	policy := ht.host.PricingPolicy()
+	policy.Enabled = true
+	policy.Percentile = 200
+	if err := ht.host.SetPricingPolicy(policy); err != errInvalidPercentile {
+		t.Fatal("expected errInvalidPercentile, got", err)
+	}
+
+	// Enable the pricing engine and wait for the prices to be applied.
+	policy.Percentile = 100
+	policy.FillRules = []modules.HostPricingFillRule{{Utilization: 0, Multiplier: 2}}
+	if err := ht.host.SetPricingPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(50, 100*time.Millisecond, func() error {
+		if !ht.host.InternalSettings().MinStoragePrice.Equals64(240) {
+			return errors.New("storage price wasn't updated")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings := ht.host.InternalSettings()
+	if !settings.Collateral.Equals64(30) || !settings.MinContractPrice.Equals64(60) ||
+		!settings.MinDownloadBandwidthPrice.Equals64(90) || !settings.MinUploadBandwidthPrice.Equals64(150) {
+		t.Fatalf("prices weren't updated: %+v", settings)
+	}
+	history, err := ht.host.PriceHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 {
+		t.Fatal("expected one price change, got", len(history))
+	}
+	if !history[0].OldPrices.MinStoragePrice.Equals(original.MinStoragePrice) || !history[0].NewPrices.MinStoragePrice.Equals64(240) {
+		t.Fatalf("unexpected price change: %+v", history[0])
+	}
+
+	// The policy should persist.
+	err = ht.host.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host, err = newHost(&dependencyDisableMarketScan{}, ht.cs, ht.gateway, ht.tpool, ht.wallet, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := ht.host.PricingPolicy(); !p.Enabled || p.Percentile != 100 || len(p.FillRules) != 1 {
+		t.Fatalf("pricing policy wasn't persisted: %+v", p)
+	}
+	if history, err := ht.host.PriceHistory(); err != nil || len(history) != 1 {
+		t.Fatal("price history wasn't persisted:", len(history), err)
+	}
+}
@@ -0,0 +1,224 @@
+package host
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/encoding"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"github.com/coreos/bbolt"
+)
+
+var (
+	// errCorruptProofSector is returned if the sector needed for a storage
+	// proof does not match its Merkle root.
+	errCorruptProofSector = errors.New("sector needed for the storage proof is corrupt")
+
+	// errInvalidStorageProof is returned if a storage proof does not verify
+	// against the file Merkle root of the storage obligation.
+	errInvalidStorageProof = errors.New("storage proof does not match the file merkle root of the contract")
+
+	// errProofSegmentOutOfBounds is returned if the segment needed for a
+	// storage proof is not covered by the sectors of the storage obligation.
+	errProofSegmentOutOfBounds = errors.New("storage proof segment is outside of the contract data")
+)
+
+// recordProofAttempt adds a storage proof attempt to the proof history of the
+// storage obligation, dropping the oldest attempts if the history is full.
+func (so *storageObligation) recordProofAttempt(height types.BlockHeight, dryRun bool, segmentIndex uint64, err error) {
+	attempt := modules.HostProofAttempt{
+		Height:       height,
+		DryRun:       dryRun,
+		SegmentIndex: segmentIndex,
+		Success:      err == nil,
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	so.ProofHistory = append(so.ProofHistory, attempt)
+	if len(so.ProofHistory) > maxProofHistory {
+		so.ProofHistory = so.ProofHistory[len(so.ProofHistory)-maxProofHistory:]
+	}
+}
+
+// lastDryRun returns the most recent storage proof dry run of the storage
+// obligation.
+func (so storageObligation) lastDryRun() (modules.HostProofAttempt, bool) {
+	for i := len(so.ProofHistory) - 1; i >= 0; i-- {
+		if so.ProofHistory[i].DryRun {
+			return so.ProofHistory[i], true
+		}
+	}
+	return modules.HostProofAttempt{}, false
+}
+
+// managedBuildStorageProof builds the storage proof for the segment at
+// segmentIndex and verifies it against the file Merkle root of the storage
+// obligation, so that a proof that would be rejected by consensus is never
+// submitted.
+func (h *Host) managedBuildStorageProof(so storageObligation, segmentIndex uint64) (types.StorageProof, error) {
+	// Pull the sector containing the segment into memory.
+	sectorIndex := segmentIndex / (modules.SectorSize / crypto.SegmentSize)
+	if sectorIndex >= uint64(len(so.SectorRoots)) {
+		return types.StorageProof{}, errProofSegmentOutOfBounds
+	}
+	sectorRoot := so.SectorRoots[sectorIndex]
+	sectorBytes, err := h.ReadSector(sectorRoot)
+	if err != nil {
+		return types.StorageProof{}, err
+	}
+	if crypto.MerkleRoot(sectorBytes) != sectorRoot {
+		return types.StorageProof{}, errCorruptProofSector
+	}
+
+	// Build the storage proof for just the sector.
+	sectorSegment := segmentIndex % (modules.SectorSize / crypto.SegmentSize)
+	base, cachedHashSet := crypto.MerkleProof(sectorBytes, sectorSegment)
+
+	// Using the sector, build a cached root.
+	log2SectorSize := uint64(0)
+	for 1<<log2SectorSize < (modules.SectorSize / crypto.SegmentSize) {
+		log2SectorSize++
+	}
+	ct := crypto.NewCachedTree(log2SectorSize)
+	ct.SetIndex(segmentIndex)
+	for _, root := range so.SectorRoots {
+		ct.Push(root)
+	}
+	hashSet := ct.Prove(base, cachedHashSet)
+	sp := types.StorageProof{
+		ParentID: so.id(),
+		HashSet:  hashSet,
+	}
+	copy(sp.Segment[:], base)
+
+	numSegments := crypto.CalculateLeaves(so.fileSize())
+	if !crypto.VerifySegment(sp.Segment[:], sp.HashSet, numSegments, segmentIndex, so.merkleRoot()) {
+		return types.StorageProof{}, errInvalidStorageProof
+	}
+	return sp, nil
+}
+
+// managedDryRunStorageProof builds and verifies a storage proof for a random
+// segment of the storage obligation without submitting it. The segment that
+// has to be proven is only known once the proof window opens, so checking a
+// random segment is the best the host can do ahead of time. The attempt is
+// recorded in the proof history of the storage obligation.
+func (h *Host) managedDryRunStorageProof(so *storageObligation, blockHeight types.BlockHeight) error {
+	numSegments := crypto.CalculateLeaves(so.fileSize())
+	segmentIndex := fastrand.Uint64n(numSegments)
+	_, err := h.managedBuildStorageProof(*so, segmentIndex)
+	so.recordProofAttempt(blockHeight, true, segmentIndex, err)
+	if err != nil {
+		h.log.Printf("WARN: storage proof dry run failed for contract %v, the proof window opens at height %v: %v\n", so.id(), so.expiration(), err)
+		return err
+	}
+	h.log.Debugln("Storage proof dry run succeeded for", so.id())
+	return nil
+}
+
+// managedSubmitStorageProof builds the storage proof for the storage
+// obligation and submits it to the transaction pool. The attempt is recorded
+// in the proof history of the storage obligation.
+func (h *Host) managedSubmitStorageProof(so *storageObligation, blockHeight types.BlockHeight) error {
+	// Get the index of the segment that needs to be proven.
+	segmentIndex, err := h.cs.StorageProofSegment(so.id())
+	if err != nil {
+		return fmt.Errorf("unable to fetch the storage proof segment: %v", err)
+	}
+	err = func() error {
+		sp, err := h.managedBuildStorageProof(*so, segmentIndex)
+		if err != nil {
+			return err
+		}
+
+		// Create and build the transaction with the storage proof.
+		builder, err := h.wallet.StartTransaction()
+		if err != nil {
+			return fmt.Errorf("failed to start transaction: %v", err)
+		}
+		_, feeRecommendation := h.tpool.FeeEstimation()
+		if so.value().Cmp(feeRecommendation) < 0 {
+			// There's no sense submitting the storage proof if the fee is more
+			// than the anticipated revenue.
+			builder.Drop()
+			return errors.New("value of the contract does not sufficiently exceed the fee cost")
+		}
+		txnSize := uint64(len(encoding.Marshal(sp)) + 300)
+		requiredFee := feeRecommendation.Mul64(txnSize)
+		err = builder.FundSiacoins(requiredFee)
+		if err != nil {
+			builder.Drop()
+			return fmt.Errorf("unable to fund the storage proof transaction fee: %v", err)
+		}
+		builder.AddMinerFee(requiredFee)
+		builder.AddStorageProof(sp)
+		storageProofSet, err := builder.Sign(true)
+		if err != nil {
+			builder.Drop()
+			return fmt.Errorf("unable to sign the storage proof transaction: %v", err)
+		}
+		err = h.tpool.AcceptTransactionSet(storageProofSet)
+		if err != nil {
+			builder.Drop()
+			return fmt.Errorf("unable to submit the storage proof transaction to the transaction pool: %v", err)
+		}
+		so.TransactionFeesAdded = so.TransactionFeesAdded.Add(requiredFee)
+		so.ProofConstructed = true
+		return nil
+	}()
+	so.recordProofAttempt(blockHeight, false, segmentIndex, err)
+	return err
+}
+
+// proofAlerts returns the alerts about storage proofs that failed their dry
+// run, that are not confirmed yet, or that were not confirmed within the proof
+// window.
+func (h *Host) proofAlerts(tx *bolt.Tx) ([]modules.HostAlert, error) {
+	var alerts []modules.HostAlert
+	err := tx.Bucket(bucketStorageObligations).ForEach(func(_, v []byte) error {
+		var so storageObligation
+		if err := json.Unmarshal(v, &so); err != nil {
+			return err
+		}
+		switch {
+		case so.ObligationStatus == obligationFailed && len(so.ProofHistory) > 0:
+			if h.blockHeight > so.proofDeadline()+proofAlertDuration {
+				return nil
+			}
+			cause := fmt.Sprintf("proof deadline %v passed", so.proofDeadline())
+			if last := so.ProofHistory[len(so.ProofHistory)-1]; !last.Success {
+				cause = last.Error
+			}
+			alerts = append(alerts, modules.HostAlert{
+				Cause:    cause,
+				Msg:      fmt.Sprintf("storage proof for contract %v was not confirmed within the proof window, the collateral of the contract is lost", so.id()),
+				Severity: modules.HostAlertSeverityError,
+			})
+		case so.ObligationStatus != obligationUnresolved:
+		case so.ProofConstructed && !so.ProofConfirmed && h.blockHeight >= so.expiration()+2*resubmissionTimeout:
+			alerts = append(alerts, modules.HostAlert{
+				Cause:    fmt.Sprintf("proof submitted after height %v", so.expiration()),
+				Msg:      fmt.Sprintf("storage proof for contract %v is not confirmed yet, the proof window closes at height %v", so.id(), so.proofDeadline()),
+				Severity: modules.HostAlertSeverityWarning,
+			})
+		default:
+			dryRun, exists := so.lastDryRun()
+			if !exists || dryRun.Success {
+				return nil
+			}
+			alerts = append(alerts, modules.HostAlert{
+				Cause:    dryRun.Error,
+				Msg:      fmt.Sprintf("storage proof dry run failed for contract %v, the proof window opens at height %v", so.id(), so.expiration()),
+				Severity: modules.HostAlertSeverityWarning,
+			})
+		}
+		return nil
+	})
+	return alerts, err
+}
@@ -0,0 +1,177 @@
+package host
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"github.com/coreos/bbolt"
+)
+
+// TestBuildStorageProof checks that the host verifies the storage proofs it
+// builds and that dry runs detect missing sectors.
+func TestBuildStorageProof(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Create a storage obligation with two sectors.
+	var roots []crypto.Hash
+	for i := 0; i < 2; i++ {
+		data := fastrand.Bytes(int(modules.SectorSize))
+		root := crypto.MerkleRoot(data)
+		if err := ht.host.AddSector(root, data); err != nil {
+			t.Fatal(err)
+		}
+		roots = append(roots, root)
+	}
+	tree := crypto.NewCachedTree(0)
+	for _, root := range roots {
+		tree.Push(root)
+	}
+	so := storageObligation{
+		SectorRoots: roots,
+		OriginTransactionSet: []types.Transaction{{
+			FileContracts: []types.FileContract{{
+				FileSize:       2 * modules.SectorSize,
+				FileMerkleRoot: tree.Root(),
+				WindowStart:    100,
+				WindowEnd:      110,
+			}},
+		}},
+	}
+
+	// Proofs for segments in both sectors should verify.
+	segmentsPerSector := modules.SectorSize / crypto.SegmentSize
+	for _, segmentIndex := range []uint64{0, segmentsPerSector - 1, segmentsPerSector, 2*segmentsPerSector - 1} {
+		if _, err := ht.host.managedBuildStorageProof(so, segmentIndex); err != nil {
+			t.Fatalf("segment %v: %v", segmentIndex, err)
+		}
+	}
+	if _, err := ht.host.managedBuildStorageProof(so, 2*segmentsPerSector); err != errProofSegmentOutOfBounds {
+		t.Fatal("expected errProofSegmentOutOfBounds, got", err)
+	}
+	if err := ht.host.managedDryRunStorageProof(&so, 95); err != nil {
+		t.Fatal(err)
+	}
+
+	// A proof against the wrong file Merkle root should not verify.
+	so.OriginTransactionSet[0].FileContracts[0].FileMerkleRoot = crypto.Hash{}
+	if _, err := ht.host.managedBuildStorageProof(so, 0); err != errInvalidStorageProof {
+		t.Fatal("expected errInvalidStorageProof, got", err)
+	}
+	so.OriginTransactionSet[0].FileContracts[0].FileMerkleRoot = tree.Root()
+
+	// The dry run should fail once the sectors are removed.
+	if err := ht.host.RemoveSectorBatch(roots); err != nil {
+		t.Fatal(err)
+	}
+	if err := ht.host.managedDryRunStorageProof(&so, 96); err == nil {
+		t.Fatal("dry run succeeded without sectors")
+	}
+	if len(so.ProofHistory) != 2 || !so.ProofHistory[0].Success || so.ProofHistory[1].Success || so.ProofHistory[1].Error == "" {
+		t.Fatalf("wrong proof history: %+v", so.ProofHistory)
+	}
+	if dryRun, exists := so.lastDryRun(); !exists || dryRun.Height != 96 {
+		t.Fatalf("wrong last dry run: %+v", dryRun)
+	}
+
+	// The proof history is capped.
+	for i := 0; i < 2*maxProofHistory; i++ {
+		so.recordProofAttempt(types.BlockHeight(i), false, 0, nil)
+	}
+	if len(so.ProofHistory) != maxProofHistory || so.ProofHistory[maxProofHistory-1].Height != types.BlockHeight(2*maxProofHistory-1) {
+		t.Fatal("proof history was not capped correctly")
+	}
+}
+
+// TestProofAlerts checks that the host alerts about failed storage proof dry
+// runs and storage proofs that are not confirmed.
+func TestProofAlerts(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Move the host to a height where all of the obligations below fit.
+	height := 2 * proofAlertDuration
+	ht.host.mu.Lock()
+	ht.host.blockHeight = height
+	ht.host.mu.Unlock()
+	newObligation := func(windowStart types.BlockHeight) storageObligation {
+		return storageObligation{
+			NegotiationHeight: windowStart,
+			OriginTransactionSet: []types.Transaction{{
+				FileContracts: []types.FileContract{{
+					WindowStart: windowStart,
+					WindowEnd:   windowStart + 5,
+				}},
+			}},
+		}
+	}
+
+	// An obligation with a failed dry run, an obligation with a successful
+	// dry run, an obligation with an unconfirmed proof, a failed obligation
+	// and a failed obligation from long ago.
+	failedDryRun := newObligation(height + 1)
+	failedDryRun.recordProofAttempt(height, true, 0, errCorruptProofSector)
+	successfulDryRun := newObligation(height + 2)
+	successfulDryRun.recordProofAttempt(height, true, 0, errCorruptProofSector)
+	successfulDryRun.recordProofAttempt(height, true, 0, nil)
+	unconfirmed := newObligation(height - 2*resubmissionTimeout)
+	unconfirmed.ProofConstructed = true
+	unconfirmed.recordProofAttempt(height, false, 0, nil)
+	failed := newObligation(height - 5)
+	failed.ObligationStatus = obligationFailed
+	failed.recordProofAttempt(height, false, 0, errInvalidStorageProof)
+	old := newObligation(height - 10 - proofAlertDuration)
+	old.ObligationStatus = obligationFailed
+	old.recordProofAttempt(height, false, 0, nil)
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		for _, so := range []storageObligation{failedDryRun, successfulDryRun, unconfirmed, failed, old} {
+			soBytes, err := json.Marshal(so)
+			if err != nil {
+				return err
+			}
+			id := so.id()
+			if err := tx.Bucket(bucketStorageObligations).Put(id[:], soBytes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var warnings, errs int
+	for _, alert := range ht.host.Alerts() {
+		switch alert.Severity {
+		case modules.HostAlertSeverityWarning:
+			warnings++
+		case modules.HostAlertSeverityError:
+			errs++
+			if alert.Cause != errInvalidStorageProof.Error() {
+				t.Error("wrong cause for failed storage proof:", alert.Cause)
+			}
+		}
+	}
+	if warnings != 2 || errs != 1 {
+		t.Fatalf("expected 2 warnings and 1 error, got %v and %v", warnings, errs)
+	}
+}
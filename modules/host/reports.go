@@ -0,0 +1,254 @@
+package host
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+var (
+	// errInvalidReportInterval is returned if a financial report is requested
+	// with an unknown interval.
+	errInvalidReportInterval = errors.New("report interval must be 'day', 'week', 'month' or empty")
+
+	// errInvalidReportRange is returned if a financial report is requested
+	// for a time range that ends before it starts.
+	errInvalidReportRange = errors.New("report range must not end before it starts")
+
+	// errTooManyReportPeriods is returned if a financial report would contain
+	// more than maxReportPeriods periods.
+	errTooManyReportPeriods = errors.New("report contains too many periods, use a larger interval")
+)
+
+// timeKey returns the database key of a point in time. Keys sort in
+// chronological order.
+func timeKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return key
+}
+
+// subCurrency returns a-b, or zero if b is larger than a.
+func subCurrency(a, b types.Currency) types.Currency {
+	if a.Cmp(b) < 0 {
+		return types.ZeroCurrency
+	}
+	return a.Sub(b)
+}
+
+// reportBoundaries splits the time range between from and to into periods of
+// the given interval, returning the start of each period followed by the end
+// of the last period. Periods are calendar based, so that monthly periods
+// start on the same day of each month.
+func reportBoundaries(from, to time.Time, interval string) ([]time.Time, error) {
+	if to.Before(from) {
+		return nil, errInvalidReportRange
+	}
+	var step func(time.Time) time.Time
+	switch interval {
+	case "":
+		return []time.Time{from, to}, nil
+	case "day":
+		step = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case "week":
+		step = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	case "month":
+		step = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		return nil, errInvalidReportInterval
+	}
+	boundaries := []time.Time{from}
+	for t := step(from); t.Before(to); t = step(t) {
+		if len(boundaries) >= maxReportPeriods {
+			return nil, errTooManyReportPeriods
+		}
+		boundaries = append(boundaries, t)
+	}
+	return append(boundaries, to), nil
+}
+
+// financialPeriod computes the finances of the host during a period from the
+// financial metrics at the start and at the end of the period.
+func financialPeriod(start, end time.Time, a, b modules.HostFinancialMetrics) modules.HostFinancialPeriod {
+	p := modules.HostFinancialPeriod{
+		Start: start,
+		End:   end,
+
+		ContractCount:           b.ContractCount,
+		LockedStorageCollateral: b.LockedStorageCollateral,
+		RiskedStorageCollateral: b.RiskedStorageCollateral,
+
+		ContractCompensation:     subCurrency(b.ContractCompensation, a.ContractCompensation),
+		DownloadBandwidthRevenue: subCurrency(b.DownloadBandwidthRevenue, a.DownloadBandwidthRevenue),
+		StorageRevenue:           subCurrency(b.StorageRevenue, a.StorageRevenue),
+		UploadBandwidthRevenue:   subCurrency(b.UploadBandwidthRevenue, a.UploadBandwidthRevenue),
+
+		LostRevenue:            subCurrency(b.LostRevenue, a.LostRevenue),
+		LostStorageCollateral:  subCurrency(b.LostStorageCollateral, a.LostStorageCollateral),
+		TransactionFeeExpenses: subCurrency(b.TransactionFeeExpenses, a.TransactionFeeExpenses),
+	}
+	p.TotalRevenue = p.ContractCompensation.Add(p.DownloadBandwidthRevenue).Add(p.StorageRevenue).Add(p.UploadBandwidthRevenue)
+	return p
+}
+
+// financialMetricsAt returns the financial metrics of the host at time t,
+// which is the most recent snapshot taken at or before t. If there is no such
+// snapshot, the oldest snapshot is used, and if there are no snapshots at all
+// or t is not in the past, the current metrics are used.
+func financialMetricsAt(tx *bolt.Tx, t, now time.Time, current modules.HostFinancialMetrics) (modules.HostFinancialMetrics, error) {
+	if !t.Before(now) {
+		return current, nil
+	}
+	c := tx.Bucket(bucketFinancialSnapshots).Cursor()
+	key := timeKey(t)
+	k, v := c.Seek(key)
+	if k == nil {
+		// All snapshots were taken before t.
+		k, v = c.Last()
+		if k == nil {
+			return current, nil
+		}
+	} else if !bytes.Equal(k, key) {
+		// Use the snapshot before t if there is one, otherwise the oldest
+		// snapshot, which is the one found by Seek.
+		if pk, pv := c.Prev(); pk != nil {
+			v = pv
+		}
+	}
+	var snap modules.HostFinancialSnapshot
+	err := json.Unmarshal(v, &snap)
+	return snap.FinancialMetrics, err
+}
+
+// putObligationResult stores the profit and loss of a storage obligation that
+// was resolved with the given status.
+func (h *Host) putObligationResult(tx *bolt.Tx, so storageObligation, sos storageObligationStatus) error {
+	res := modules.HostObligationResult{
+		ObligationId:     so.id(),
+		ObligationStatus: sos.String(),
+		ResolutionHeight: h.blockHeight,
+		Timestamp:        time.Now(),
+
+		LockedCollateral: so.LockedCollateral,
+	}
+	revenue := so.ContractCost.Add(so.PotentialStorageRevenue).Add(so.PotentialDownloadRevenue).Add(so.PotentialUploadRevenue)
+	switch sos {
+	case obligationSucceeded:
+		res.ContractCost = so.ContractCost
+		res.DownloadRevenue = so.PotentialDownloadRevenue
+		res.StorageRevenue = so.PotentialStorageRevenue
+		res.UploadRevenue = so.PotentialUploadRevenue
+		res.Revenue = revenue
+		res.TransactionFees = so.TransactionFeesAdded
+	case obligationFailed:
+		res.LostCollateral = so.RiskedCollateral
+		res.LostRevenue = revenue
+		res.TransactionFees = so.TransactionFeesAdded
+	}
+	resBytes, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	id := so.id()
+	key := append(timeKey(res.Timestamp), id[:]...)
+	return tx.Bucket(bucketObligationResults).Put(key, resBytes)
+}
+
+// managedSnapshotFinancialMetrics stores a snapshot of the host's current
+// financial metrics.
+func (h *Host) managedSnapshotFinancialMetrics() error {
+	h.mu.RLock()
+	snap := modules.HostFinancialSnapshot{
+		BlockHeight:      h.blockHeight,
+		Timestamp:        time.Now(),
+		FinancialMetrics: h.financialMetrics,
+	}
+	h.mu.RUnlock()
+
+	snapBytes, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	return h.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketFinancialSnapshots).Put(timeKey(snap.Timestamp), snapBytes)
+	})
+}
+
+// threadedSnapshotFinancialMetrics periodically stores a snapshot of the
+// host's financial metrics, so that the finances of the host can be reported
+// for any time range.
+func (h *Host) threadedSnapshotFinancialMetrics() {
+	for {
+		if err := h.tg.Add(); err != nil {
+			return
+		}
+		err := h.managedSnapshotFinancialMetrics()
+		h.tg.Done()
+		if err != nil {
+			h.log.Println("Unable to store a snapshot of the financial metrics:", err)
+		}
+
+		select {
+		case <-h.tg.StopChan():
+			return
+		case <-time.After(financialSnapshotFrequency):
+		}
+	}
+}
+
+// FinancialReport returns the finances of the host between from and to, split
+// into periods of the given interval, together with the results of the
+// storage obligations that were resolved between from and to.
+func (h *Host) FinancialReport(from, to time.Time, interval string) (modules.HostFinancialReport, error) {
+	if err := h.tg.Add(); err != nil {
+		return modules.HostFinancialReport{}, err
+	}
+	defer h.tg.Done()
+
+	boundaries, err := reportBoundaries(from, to, interval)
+	if err != nil {
+		return modules.HostFinancialReport{}, err
+	}
+	h.mu.RLock()
+	current := h.financialMetrics
+	h.mu.RUnlock()
+	now := time.Now()
+
+	report := modules.HostFinancialReport{
+		Periods:     []modules.HostFinancialPeriod{},
+		Obligations: []modules.HostObligationResult{},
+	}
+	err = h.db.View(func(tx *bolt.Tx) error {
+		prev, err := financialMetricsAt(tx, boundaries[0], now, current)
+		if err != nil {
+			return err
+		}
+		for i := 1; i < len(boundaries); i++ {
+			next, err := financialMetricsAt(tx, boundaries[i], now, current)
+			if err != nil {
+				return err
+			}
+			report.Periods = append(report.Periods, financialPeriod(boundaries[i-1], boundaries[i], prev, next))
+			prev = next
+		}
+
+		c := tx.Bucket(bucketObligationResults).Cursor()
+		end := timeKey(to)
+		for k, v := c.Seek(timeKey(from)); k != nil && bytes.Compare(k[:8], end) < 0; k, v = c.Next() {
+			var res modules.HostObligationResult
+			if err := json.Unmarshal(v, &res); err != nil {
+				return err
+			}
+			report.Obligations = append(report.Obligations, res)
+		}
+		return nil
+	})
+	return report, err
+}
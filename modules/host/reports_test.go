@@ -0,0 +1,160 @@
+package host
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// TestReportBoundaries probes the splitting of time ranges into periods.
+func TestReportBoundaries(t *testing.T) {
+	from := time.Date(2018, time.January, 31, 0, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		to       time.Time
+		interval string
+		periods  int
+		err      error
+	}{
+		{from.AddDate(0, 0, 3), "", 1, nil},
+		{from.AddDate(0, 0, 3), "day", 3, nil},
+		{from.AddDate(0, 0, 3).Add(time.Hour), "day", 4, nil},
+		{from.AddDate(0, 0, 14), "week", 2, nil},
+		{from.AddDate(0, 3, 0), "month", 3, nil},
+		{from, "day", 1, nil},
+		{from.Add(-time.Second), "day", 0, errInvalidReportRange},
+		{from.AddDate(0, 0, 3), "year", 0, errInvalidReportInterval},
+		{from.AddDate(100, 0, 0), "day", 0, errTooManyReportPeriods},
+	} {
+		boundaries, err := reportBoundaries(from, test.to, test.interval)
+		if err != test.err {
+			t.Errorf("%v %v: expected error %v, got %v", test.to, test.interval, test.err, err)
+			continue
+		}
+		if err == nil && len(boundaries)-1 != test.periods {
+			t.Errorf("%v %v: expected %v periods, got %v", test.to, test.interval, test.periods, len(boundaries)-1)
+		}
+		if err == nil && (!boundaries[0].Equal(from) || !boundaries[len(boundaries)-1].Equal(test.to)) {
+			t.Errorf("%v %v: boundaries don't cover the range", test.to, test.interval)
+		}
+	}
+}
+
+// TestFinancialReport checks that the host reports its finances based on the
+// stored snapshots and obligation results.
+func TestFinancialReport(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// The host should take snapshots in the background.
+	err = build.Retry(50, 100*time.Millisecond, func() error {
+		return ht.host.db.View(func(tx *bolt.Tx) error {
+			if tx.Bucket(bucketFinancialSnapshots).Stats().KeyN == 0 {
+				return errors.New("no snapshots were taken")
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Store snapshots of two days in the past.
+	start := time.Now().AddDate(0, 0, -10)
+	snapshots := []modules.HostFinancialSnapshot{
+		{Timestamp: start, FinancialMetrics: modules.HostFinancialMetrics{
+			ContractCount:          1,
+			StorageRevenue:         types.NewCurrency64(100),
+			TransactionFeeExpenses: types.NewCurrency64(10),
+		}},
+		{Timestamp: start.AddDate(0, 0, 1), FinancialMetrics: modules.HostFinancialMetrics{
+			ContractCount:          2,
+			StorageRevenue:         types.NewCurrency64(150),
+			LostStorageCollateral:  types.NewCurrency64(5),
+			TransactionFeeExpenses: types.NewCurrency64(12),
+		}},
+	}
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		for _, snap := range snapshots {
+			snapBytes, err := json.Marshal(snap)
+			if err != nil {
+				return err
+			}
+			if err := tx.Bucket(bucketFinancialSnapshots).Put(timeKey(snap.Timestamp), snapBytes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := ht.host.FinancialReport(start, start.AddDate(0, 0, 2), "day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Periods) != 2 {
+		t.Fatal("expected 2 periods, got", len(report.Periods))
+	}
+	p := report.Periods[0]
+	if p.ContractCount != 2 || !p.StorageRevenue.Equals64(50) || !p.TotalRevenue.Equals64(50) ||
+		!p.LostStorageCollateral.Equals64(5) || !p.TransactionFeeExpenses.Equals64(2) {
+		t.Fatalf("unexpected first period: %+v", p)
+	}
+	// Nothing happened during the second day.
+	p = report.Periods[1]
+	if p.ContractCount != 2 || !p.TotalRevenue.IsZero() || !p.TransactionFeeExpenses.IsZero() {
+		t.Fatalf("unexpected second period: %+v", p)
+	}
+
+	// Resolve an obligation and check that its result is reported.
+	so := storageObligation{
+		ContractCost:            types.NewCurrency64(20),
+		PotentialStorageRevenue: types.NewCurrency64(30),
+		RiskedCollateral:        types.NewCurrency64(40),
+		TransactionFeesAdded:    types.NewCurrency64(5),
+		OriginTransactionSet: []types.Transaction{{
+			FileContracts: []types.FileContract{{}},
+		}},
+	}
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		return ht.host.putObligationResult(tx, so, obligationFailed)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err = ht.host.FinancialReport(time.Now().Add(-time.Hour), time.Now(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Obligations) != 1 {
+		t.Fatal("expected 1 obligation, got", len(report.Obligations))
+	}
+	res := report.Obligations[0]
+	if res.ObligationId != so.id() || res.ObligationStatus != obligationFailed.String() ||
+		!res.Revenue.IsZero() || !res.LostCollateral.Equals64(40) || !res.LostRevenue.Equals64(50) {
+		t.Fatalf("unexpected obligation result: %+v", res)
+	}
+	// The obligation shouldn't be reported outside of the range.
+	report, err = ht.host.FinancialReport(start, start.AddDate(0, 0, 2), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Obligations) != 0 {
+		t.Fatal("expected no obligations, got", len(report.Obligations))
+	}
+}
@@ -27,9 +27,6 @@ package host
 
 // TODO: Make sure that not too many action items are being created.
 
-// TODO: The ProofConstructed and NegotiationHeight fields of storageObligation
-// are not set or used.
-
 import (
 	"encoding/binary"
 	"encoding/json"
@@ -149,6 +146,10 @@ type storageObligation struct {
 	ProofConstructed    bool
 	RevisionConfirmed   bool
 	RevisionConstructed bool
+
+	// ProofHistory contains the most recent storage proof attempts of the
+	// host, including the dry runs before the proof window opens.
+	ProofHistory []modules.HostProofAttempt
 }
 
 func (i storageObligationStatus) String() string {
@@ -355,6 +356,9 @@ func (h *Host) managedAddStorageObligation(so storageObligation) error {
 			h.log.Critical("host is misconfigured - the storage proof window needs to be long enough to resubmit if needed")
 			return errors.New("fill me in")
 		}
+		// The obligation is negotiated at the current height, both when forming
+		// and when renewing a contract.
+		so.NegotiationHeight = h.blockHeight
 
 		// Add the storage obligation information to the database.
 		err := h.db.Update(func(tx *bolt.Tx) error {
@@ -429,7 +433,9 @@ func (h *Host) managedAddStorageObligation(so storageObligation) error {
 	// The storage proof should be submitted
 	err5 := h.queueActionItem(so.expiration()+resubmissionTimeout, soid)
 	err6 := h.queueActionItem(so.expiration()+resubmissionTimeout*2, soid) // Paranoia
-	err = composeErrors(err1, err2, err3, err4, err5, err6)
+	// The storage proof should be dry run before the proof window opens.
+	err7 := h.queueActionItem(so.expiration()-proofDryRunBuffer, soid)
+	err = composeErrors(err1, err2, err3, err4, err5, err6, err7)
 	if err != nil {
 		h.log.Println("Error with transaction set, redacting obligation, id", so.id())
 		return composeErrors(err, h.removeStorageObligation(so, obligationRejected))
@@ -620,6 +626,11 @@ func (h *Host) removeStorageObligation(so storageObligation, sos storageObligati
 	so.ObligationStatus = sos
 	so.SectorRoots = nil
 	return h.db.Update(func(tx *bolt.Tx) error {
+		if sos != obligationUnresolved {
+			if err := h.putObligationResult(tx, so, sos); err != nil {
+				return err
+			}
+		}
 		return putStorageObligation(tx, so)
 	})
 }
@@ -775,6 +786,23 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		// return
 	}
 
+	// Check whether the storage proof should be dry run. If the dry run
+	// fails, it is retried until the proof window opens so that the host
+	// notices when the problem is fixed.
+	if len(so.SectorRoots) > 0 && blockHeight < so.expiration() && blockHeight+proofDryRunBuffer >= so.expiration() {
+		if dryRun, exists := so.lastDryRun(); !exists || !dryRun.Success {
+			err := h.managedDryRunStorageProof(&so, blockHeight)
+			if err != nil && blockHeight+resubmissionTimeout < so.expiration() {
+				h.mu.Lock()
+				err = h.queueActionItem(blockHeight+resubmissionTimeout, so.id())
+				h.mu.Unlock()
+				if err != nil {
+					h.log.Println("Error queuing action item:", err)
+				}
+			}
+		}
+	}
+
 	// Check whether a storage proof is ready to be provided, and whether it
 	// has been accepted. Check for death.
 	if !so.ProofConfirmed && blockHeight >= so.expiration()+resubmissionTimeout {
@@ -805,88 +833,18 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 			}
 			return
 		}
-		// Get the index of the segment, and the index of the sector containing
-		// the segment.
-		segmentIndex, err := h.cs.StorageProofSegment(so.id())
-		if err != nil {
-			h.log.Debugln("Host got an error when fetching a storage proof segment:", err)
-			return
-		}
-		sectorIndex := segmentIndex / (modules.SectorSize / crypto.SegmentSize)
-		// Pull the corresponding sector into memory.
-		sectorRoot := so.SectorRoots[sectorIndex]
-		sectorBytes, err := h.ReadSector(sectorRoot)
+		err := h.managedSubmitStorageProof(&so, blockHeight)
 		if err != nil {
-			h.log.Debugln(err)
-			return
-		}
-
-		// Build the storage proof for just the sector.
-		sectorSegment := segmentIndex % (modules.SectorSize / crypto.SegmentSize)
-		base, cachedHashSet := crypto.MerkleProof(sectorBytes, sectorSegment)
-
-		// Using the sector, build a cached root.
-		log2SectorSize := uint64(0)
-		for 1<<log2SectorSize < (modules.SectorSize / crypto.SegmentSize) {
-			log2SectorSize++
-		}
-		ct := crypto.NewCachedTree(log2SectorSize)
-		ct.SetIndex(segmentIndex)
-		for _, root := range so.SectorRoots {
-			ct.Push(root)
-		}
-		hashSet := ct.Prove(base, cachedHashSet)
-		sp := types.StorageProof{
-			ParentID: so.id(),
-			HashSet:  hashSet,
-		}
-		copy(sp.Segment[:], base)
-
-		// Create and build the transaction with the storage proof.
-		builder, err := h.wallet.StartTransaction()
-		if err != nil {
-			h.log.Println("Failed to start transaction:", err)
-			return
-		}
-		_, feeRecommendation := h.tpool.FeeEstimation()
-		if so.value().Cmp(feeRecommendation) < 0 {
-			// There's no sense submitting the storage proof if the fee is more
-			// than the anticipated revenue.
-			h.log.Debugln("Host not submitting storage proof due to a value that does not sufficiently exceed the fee cost")
-			builder.Drop()
-			return
-		}
-		txnSize := uint64(len(encoding.Marshal(sp)) + 300)
-		requiredFee := feeRecommendation.Mul64(txnSize)
-		err = builder.FundSiacoins(requiredFee)
-		if err != nil {
-			h.log.Println("Host error when funding a storage proof transaction fee:", err)
-			builder.Drop()
-			return
-		}
-		builder.AddMinerFee(requiredFee)
-		builder.AddStorageProof(sp)
-		storageProofSet, err := builder.Sign(true)
-		if err != nil {
-			h.log.Println("Host error when signing the storage proof transaction:", err)
-			builder.Drop()
-			return
-		}
-		err = h.tpool.AcceptTransactionSet(storageProofSet)
-		if err != nil {
-			h.log.Println("Host unable to submit storage proof transaction to transaction pool:", err)
-			builder.Drop()
-			return
-		}
-		so.TransactionFeesAdded = so.TransactionFeesAdded.Add(requiredFee)
-
-		// Queue another action item to check whether the storage proof
-		// got confirmed.
-		h.mu.Lock()
-		err = h.queueActionItem(so.proofDeadline(), so.id())
-		h.mu.Unlock()
-		if err != nil {
-			h.log.Println("Error queuing action item:", err)
+			h.log.Println("Host unable to submit storage proof:", err)
+		} else {
+			// Queue another action item to check whether the storage proof
+			// got confirmed.
+			h.mu.Lock()
+			err = h.queueActionItem(so.proofDeadline(), so.id())
+			h.mu.Unlock()
+			if err != nil {
+				h.log.Println("Error queuing action item:", err)
+			}
 		}
 	}
 
@@ -912,6 +870,34 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 	}
 }
 
+// info returns the information about the storage obligation that is exposed
+// to callers of the host.
+func (so storageObligation) info() modules.StorageObligation {
+	return modules.StorageObligation{
+		ContractCost:             so.ContractCost,
+		DataSize:                 so.fileSize(),
+		LockedCollateral:         so.LockedCollateral,
+		ObligationId:             so.id(),
+		PotentialDownloadRevenue: so.PotentialDownloadRevenue,
+		PotentialStorageRevenue:  so.PotentialStorageRevenue,
+		PotentialUploadRevenue:   so.PotentialUploadRevenue,
+		RiskedCollateral:         so.RiskedCollateral,
+		SectorRootsCount:         uint64(len(so.SectorRoots)),
+		TransactionFeesAdded:     so.TransactionFeesAdded,
+
+		ExpirationHeight:  so.expiration(),
+		NegotiationHeight: so.NegotiationHeight,
+		ProofDeadLine:     so.proofDeadline(),
+
+		ObligationStatus:    so.ObligationStatus.String(),
+		OriginConfirmed:     so.OriginConfirmed,
+		ProofConfirmed:      so.ProofConfirmed,
+		ProofConstructed:    so.ProofConstructed,
+		RevisionConfirmed:   so.RevisionConfirmed,
+		RevisionConstructed: so.RevisionConstructed,
+	}
+}
+
 // StorageObligations fetches the set of storage obligations in the host and
 // returns metadata on them.
 func (h *Host) StorageObligations() (sos []modules.StorageObligation) {
@@ -926,30 +912,7 @@ func (h *Host) StorageObligations() (sos []modules.StorageObligation) {
 			if err != nil {
 				return build.ExtendErr("unable to unmarshal storage obligation:", err)
 			}
-			mso := modules.StorageObligation{
-				ContractCost:             so.ContractCost,
-				DataSize:                 so.fileSize(),
-				LockedCollateral:         so.LockedCollateral,
-				ObligationId:             so.id(),
-				PotentialDownloadRevenue: so.PotentialDownloadRevenue,
-				PotentialStorageRevenue:  so.PotentialStorageRevenue,
-				PotentialUploadRevenue:   so.PotentialUploadRevenue,
-				RiskedCollateral:         so.RiskedCollateral,
-				SectorRootsCount:         uint64(len(so.SectorRoots)),
-				TransactionFeesAdded:     so.TransactionFeesAdded,
-
-				ExpirationHeight:  so.expiration(),
-				NegotiationHeight: so.NegotiationHeight,
-				ProofDeadLine:     so.proofDeadline(),
-
-				ObligationStatus:    so.ObligationStatus.String(),
-				OriginConfirmed:     so.OriginConfirmed,
-				ProofConfirmed:      so.ProofConfirmed,
-				ProofConstructed:    so.ProofConstructed,
-				RevisionConfirmed:   so.RevisionConfirmed,
-				RevisionConstructed: so.RevisionConstructed,
-			}
-			sos = append(sos, mso)
+			sos = append(sos, so.info())
 			return nil
 		})
 		if err != nil {
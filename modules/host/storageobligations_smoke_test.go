@@ -112,6 +112,7 @@ func TestBlankStorageObligation(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	negotiationHeight := ht.cs.Height()
 	ht.host.managedLockStorageObligation(so.id())
 	err = ht.host.managedAddStorageObligation(so)
 	if err != nil {
@@ -153,6 +154,9 @@ func TestBlankStorageObligation(t *testing.T) {
 	if !so.OriginConfirmed {
 		t.Fatal("origin transaction for storage obligation was not confirmed after a block was mined")
 	}
+	if so.NegotiationHeight != negotiationHeight {
+		t.Fatalf("negotiation height should be %v, got %v", negotiationHeight, so.NegotiationHeight)
+	}
 
 	// Mine until the host would be submitting a storage proof. Check that the
 	// host has cleared out the storage proof - the consensus code makes it
@@ -246,6 +250,7 @@ func TestSingleSectorStorageObligationStack(t *testing.T) {
 			NewUnlockHash:         types.UnlockConditions{}.UnlockHash(),
 		}},
 	}}
+	so.RevisionTransactionSet = revisionSet
 	ht.host.managedLockStorageObligation(so.id())
 	ht.host.mu.Lock()
 	err = ht.host.modifyStorageObligation(so, nil, []crypto.Hash{sectorRoot}, [][]byte{sectorData})
@@ -343,6 +348,14 @@ func TestSingleSectorStorageObligationStack(t *testing.T) {
 	if !so.ProofConfirmed {
 		t.Fatal("storage obligation is not saying that the storage proof was confirmed on the blockchain")
 	}
+	// The storage proof should be recorded in the proof history.
+	var proofRecorded bool
+	for _, attempt := range so.ProofHistory {
+		proofRecorded = proofRecorded || (!attempt.DryRun && attempt.Success)
+	}
+	if !proofRecorded {
+		t.Fatalf("storage proof was not recorded in the proof history: %+v", so.ProofHistory)
+	}
 
 	// Mine blocks until the storage proof has enough confirmations that the
 	// host will finalize the obligation.
@@ -516,6 +529,7 @@ func TestMultiSectorStorageObligationStack(t *testing.T) {
 			NewUnlockHash:         types.UnlockConditions{}.UnlockHash(),
 		}},
 	}}
+	so.RevisionTransactionSet = revisionSet2
 	ht.host.managedLockStorageObligation(so.id())
 	ht.host.mu.Lock()
 	err = ht.host.modifyStorageObligation(so, nil, []crypto.Hash{sectorRoot2}, [][]byte{sectorData2})
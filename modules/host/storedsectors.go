@@ -0,0 +1,231 @@
+package host
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+
+	"github.com/coreos/bbolt"
+)
+
+var (
+	// errInvalidSectorCursor is returned if a sector query contains a cursor
+	// that is not a sector root.
+	errInvalidSectorCursor = errors.New("cursor is not a sector root")
+
+	// errInvalidSectorLimit is returned if a sector query has a negative
+	// limit.
+	errInvalidSectorLimit = errors.New("limit must not be negative")
+)
+
+// sectorReferencesCache contains the sector references collected for the
+// first page of a sector query. The following pages are served from the cache,
+// so that the storage obligations aren't scanned again for every page.
+type sectorReferencesCache struct {
+	sectors []modules.HostSector
+	created time.Time
+	mu      sync.Mutex
+}
+
+// managedSectorReferences returns the sectors referenced by the host's
+// unresolved storage obligations sorted by root. Only unresolved obligations
+// hold references, because the sectors of an obligation are removed from the
+// storage manager once the obligation is resolved. The obligations are read in
+// a database transaction without holding h.mu, so that the scan doesn't block
+// uploads and other updates of the host.
+func (h *Host) managedSectorReferences() ([]modules.HostSector, error) {
+	refs := make(map[crypto.Hash]*modules.HostSector)
+	err := h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).ForEach(func(_, v []byte) error {
+			var so storageObligation
+			if err := json.Unmarshal(v, &so); err != nil {
+				return err
+			}
+			if so.ObligationStatus != obligationUnresolved {
+				return nil
+			}
+			id := so.id()
+			for _, root := range so.SectorRoots {
+				ref, exists := refs[root]
+				if !exists {
+					ref = &modules.HostSector{
+						SectorInfo: modules.SectorInfo{Root: root},
+					}
+					refs[root] = ref
+				}
+				// A sector can appear multiple times in the same obligation.
+				if n := len(ref.Obligations); n == 0 || ref.Obligations[n-1] != id {
+					ref.Obligations = append(ref.Obligations, id)
+				}
+				ref.References++
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sectors := make([]modules.HostSector, 0, len(refs))
+	for _, ref := range refs {
+		sectors = append(sectors, *ref)
+	}
+	sort.Slice(sectors, func(i, j int) bool {
+		return bytes.Compare(sectors[i].Root[:], sectors[j].Root[:]) < 0
+	})
+	return sectors, nil
+}
+
+// managedCachedSectorReferences returns the cached sector references. The
+// references are collected again if refresh is set or if the cache has
+// expired.
+func (h *Host) managedCachedSectorReferences(refresh bool) ([]modules.HostSector, error) {
+	c := &h.sectorReferences
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if refresh || c.sectors == nil || time.Since(c.created) > sectorReferencesCacheTimeout {
+		sectors, err := h.managedSectorReferences()
+		if err != nil {
+			return nil, err
+		}
+		c.sectors = sectors
+		c.created = time.Now()
+	}
+	return c.sectors, nil
+}
+
+// managedAddSectorInfo fills in how the storage manager stores each of the
+// sectors.
+func (h *Host) managedAddSectorInfo(sectors []modules.HostSector) {
+	roots := make([]crypto.Hash, len(sectors))
+	for i := range sectors {
+		roots[i] = sectors[i].Root
+	}
+	for i, info := range h.StorageManager.SectorInfo(roots) {
+		sectors[i].SectorInfo = info
+	}
+}
+
+// AuditSectors cross-checks the sectors referenced by the host's unresolved
+// storage obligations against the storage manager, reporting sectors that are
+// missing, stored the wrong number of times, or not referenced at all.
+func (h *Host) AuditSectors() (modules.HostSectorAudit, error) {
+	if err := h.tg.Add(); err != nil {
+		return modules.HostSectorAudit{}, err
+	}
+	defer h.tg.Done()
+
+	// Get the stored sectors before the references, so that sectors that are
+	// uploaded during the audit aren't reported as orphaned.
+	stored, err := h.StorageManager.StoredSectors()
+	if err != nil {
+		return modules.HostSectorAudit{}, err
+	}
+	sectors, err := h.managedSectorReferences()
+	if err != nil {
+		return modules.HostSectorAudit{}, err
+	}
+	h.managedAddSectorInfo(sectors)
+
+	audit := modules.HostSectorAudit{
+		ReferencedSectors: uint64(len(sectors)),
+		MissingSectors:    []modules.HostSector{},
+		MiscountedSectors: []modules.HostSector{},
+		OrphanedSectors:   []modules.StoredSector{},
+	}
+	roots := make(map[crypto.Hash]struct{}, len(sectors))
+	referenced := make(map[string]struct{}, len(sectors))
+	for _, sector := range sectors {
+		roots[sector.Root] = struct{}{}
+		referenced[sector.ID] = struct{}{}
+		if sector.Count == 0 {
+			audit.MissingSectors = append(audit.MissingSectors, sector)
+		} else if uint64(sector.Count) != sector.References {
+			audit.MiscountedSectors = append(audit.MiscountedSectors, sector)
+		}
+	}
+	var orphans []modules.StoredSector
+	for _, sector := range stored {
+		if _, exists := referenced[sector.ID]; !exists {
+			orphans = append(orphans, sector)
+		}
+	}
+	if len(orphans) == 0 {
+		return audit, nil
+	}
+
+	// A sector is stored before the storage obligation that references it is
+	// updated. Check the orphans again against the current obligations, so
+	// that only the sectors that are still unreferenced are reported.
+	sectors, err = h.managedSectorReferences()
+	if err != nil {
+		return modules.HostSectorAudit{}, err
+	}
+	var added []modules.HostSector
+	for _, sector := range sectors {
+		if _, exists := roots[sector.Root]; !exists {
+			added = append(added, sector)
+		}
+	}
+	h.managedAddSectorInfo(added)
+	for _, sector := range added {
+		referenced[sector.ID] = struct{}{}
+	}
+	for _, sector := range orphans {
+		if _, exists := referenced[sector.ID]; !exists {
+			audit.OrphanedSectors = append(audit.OrphanedSectors, sector)
+		}
+	}
+	return audit, nil
+}
+
+// Sectors returns a page of the sectors referenced by the host's unresolved
+// storage obligations, sorted by root. The page starts after the root in
+// cursor, and a limit of zero returns all remaining sectors. A query without
+// a cursor collects the references, and the following pages are served from
+// the same references until they expire.
+func (h *Host) Sectors(cursor string, limit int) (modules.HostSectorPage, error) {
+	if err := h.tg.Add(); err != nil {
+		return modules.HostSectorPage{}, err
+	}
+	defer h.tg.Done()
+	if limit < 0 {
+		return modules.HostSectorPage{}, errInvalidSectorLimit
+	}
+	var after crypto.Hash
+	if cursor != "" {
+		if err := after.LoadString(cursor); err != nil {
+			return modules.HostSectorPage{}, errInvalidSectorCursor
+		}
+	}
+
+	sectors, err := h.managedCachedSectorReferences(cursor == "")
+	if err != nil {
+		return modules.HostSectorPage{}, err
+	}
+	if cursor != "" {
+		start := sort.Search(len(sectors), func(i int) bool {
+			return bytes.Compare(sectors[i].Root[:], after[:]) > 0
+		})
+		sectors = sectors[start:]
+	}
+
+	page := modules.HostSectorPage{
+		Sectors: []modules.HostSector{},
+	}
+	if limit != 0 && len(sectors) > limit {
+		sectors = sectors[:limit]
+		page.NextCursor = sectors[len(sectors)-1].Root.String()
+	}
+	// Copy the page before adding the sector info, since the references are
+	// shared with the following queries.
+	page.Sectors = append(page.Sectors, sectors...)
+	h.managedAddSectorInfo(page.Sectors)
+	return page, nil
+}
@@ -0,0 +1,137 @@
+package host
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"github.com/coreos/bbolt"
+)
+
+// TestSectorsAndAudit checks that the host pages through the sectors of its
+// storage obligations and detects missing, miscounted and orphaned sectors.
+func TestSectorsAndAudit(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Store five sectors. The first sector is shared by two obligations,
+	// the second sector appears twice in the same obligation but is only
+	// stored once, the third sector is orphaned and the fourth sector is
+	// referenced but not stored.
+	var roots []crypto.Hash
+	for i := 0; i < 5; i++ {
+		data := fastrand.Bytes(int(modules.SectorSize))
+		root := crypto.MerkleRoot(data)
+		roots = append(roots, root)
+		if i == 3 {
+			continue
+		}
+		if err := ht.host.AddSector(root, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ht.host.AddSector(roots[0], nil); err != nil {
+		t.Fatal(err)
+	}
+	obligations := [][]crypto.Hash{
+		{roots[0], roots[1], roots[1]},
+		{roots[0], roots[3], roots[4]},
+	}
+	var ids []types.FileContractID
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		for i, sectorRoots := range obligations {
+			so := storageObligation{
+				NegotiationHeight: types.BlockHeight(i),
+				SectorRoots:       sectorRoots,
+				OriginTransactionSet: []types.Transaction{{
+					FileContracts: []types.FileContract{{
+						WindowStart: types.BlockHeight(100 + i),
+					}},
+				}},
+			}
+			soBytes, err := json.Marshal(so)
+			if err != nil {
+				return err
+			}
+			id := so.id()
+			ids = append(ids, id)
+			if err := tx.Bucket(bucketStorageObligations).Put(id[:], soBytes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Page through the sectors.
+	var sectors []modules.HostSector
+	var cursor string
+	for {
+		page, err := ht.host.Sectors(cursor, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sectors = append(sectors, page.Sectors...)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if len(sectors) != 4 {
+		t.Fatal("expected 4 referenced sectors, got", len(sectors))
+	}
+	for _, sector := range sectors {
+		switch sector.Root {
+		case roots[0]:
+			if sector.Count != 2 || sector.References != 2 || len(sector.Obligations) != 2 {
+				t.Errorf("wrong shared sector: %+v", sector)
+			}
+		case roots[1]:
+			if sector.Count != 1 || sector.References != 2 || len(sector.Obligations) != 1 || sector.Obligations[0] != ids[0] {
+				t.Errorf("wrong duplicated sector: %+v", sector)
+			}
+		case roots[3]:
+			if sector.Count != 0 || sector.References != 1 {
+				t.Errorf("wrong missing sector: %+v", sector)
+			}
+		}
+	}
+	if _, err := ht.host.Sectors("foo", 0); err != errInvalidSectorCursor {
+		t.Fatal("expected errInvalidSectorCursor, got", err)
+	}
+	if _, err := ht.host.Sectors("", -1); err != errInvalidSectorLimit {
+		t.Fatal("expected errInvalidSectorLimit, got", err)
+	}
+
+	// Audit the sectors.
+	audit, err := ht.host.AuditSectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if audit.ReferencedSectors != 4 {
+		t.Fatalf("wrong audit totals: %+v", audit)
+	}
+	if len(audit.MissingSectors) != 1 || audit.MissingSectors[0].Root != roots[3] {
+		t.Fatal("wrong missing sectors:", audit.MissingSectors)
+	}
+	if len(audit.MiscountedSectors) != 1 || audit.MiscountedSectors[0].Root != roots[1] {
+		t.Fatal("wrong miscounted sectors:", audit.MiscountedSectors)
+	}
+	orphan := ht.host.StorageManager.SectorInfo([]crypto.Hash{roots[2]})[0]
+	if len(audit.OrphanedSectors) != 1 || audit.OrphanedSectors[0].ID != orphan.ID || audit.OrphanedSectors[0].FolderPath != orphan.FolderPath {
+		t.Fatal("wrong orphaned sectors:", audit.OrphanedSectors)
+	}
+}
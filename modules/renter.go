@@ -82,6 +82,11 @@ const (
 	// renter's persistent data.
 	RenterDir = "renter"
 
+	// RenterProfilesDir is the name of the directory that is used to store the
+	// persistent data of the renter profiles. It is located next to the
+	// RenterDir.
+	RenterProfilesDir = "renterprofiles"
+
 	// EstimatedFileContractTransactionSetSize is the estimated blockchain size
 	// of a transaction set between a renter and a host that contains a file
 	// contract. This transaction set will contain a setup transaction from each
@@ -254,6 +259,73 @@ type RenterPriceEstimation struct {
 	UploadTerabyte types.Currency `json:"uploadterabyte"`
 }
 
+// RenterPriceEstimationParams describe the data that a detailed price
+// estimation is made for.
+type RenterPriceEstimationParams struct {
+	// The amount of data that is uploaded and stored for a period, before
+	// redundancy.
+	DataSize uint64 `json:"datasize"`
+
+	// The erasure coding settings of the data. The data is spread across
+	// DataPieces+ParityPieces hosts.
+	DataPieces   uint64 `json:"datapieces"`
+	ParityPieces uint64 `json:"paritypieces"`
+
+	// The amount of data that is expected to be downloaded during the period.
+	DownloadSize uint64 `json:"downloadsize"`
+}
+
+// RenterHostPriceEstimation is the estimated cost of the contract with a
+// single host in a detailed price estimation.
+type RenterHostPriceEstimation struct {
+	NetAddress NetAddress         `json:"netaddress"`
+	PublicKey  types.SiaPublicKey `json:"publickey"`
+
+	// The number of bytes the host stores, including the padding of the
+	// pieces to full sectors.
+	StoredBytes uint64 `json:"storedbytes"`
+
+	ContractPrice  types.Currency `json:"contractprice"`
+	DownloadCost   types.Currency `json:"downloadcost"`
+	StorageCost    types.Currency `json:"storagecost"`
+	TransactionFee types.Currency `json:"transactionfee"`
+	UploadCost     types.Currency `json:"uploadcost"`
+
+	// The collateral the host is expected to put into the contract and the
+	// siafund fee that is paid on the payout of the contract.
+	HostCollateral types.Currency `json:"hostcollateral"`
+	SiafundFee     types.Currency `json:"siafundfee"`
+
+	// The total cost of the contract for the renter.
+	Total types.Currency `json:"total"`
+}
+
+// RenterDetailedPriceEstimation is a price estimation that is made by
+// selecting hosts the same way the contractor does and pricing the contracts
+// with each of them.
+type RenterDetailedPriceEstimation struct {
+	RenterPriceEstimationParams
+
+	// The per host breakdown of the costs of the median host selection.
+	Hosts []RenterHostPriceEstimation `json:"hosts"`
+
+	// The sums of the per host costs.
+	ContractCost    types.Currency `json:"contractcost"`
+	DownloadCost    types.Currency `json:"downloadcost"`
+	SiafundFees     types.Currency `json:"siafundfees"`
+	StorageCost     types.Currency `json:"storagecost"`
+	TransactionFees types.Currency `json:"transactionfees"`
+	UploadCost      types.Currency `json:"uploadcost"`
+	Total           types.Currency `json:"total"`
+
+	// The total costs of different host selections are sampled to give an
+	// idea of how much the actual costs can deviate from the estimate. 90% of
+	// the sampled totals lie within the bounds.
+	Samples         int            `json:"samples"`
+	TotalLowerBound types.Currency `json:"totallowerbound"`
+	TotalUpperBound types.Currency `json:"totalupperbound"`
+}
+
 // RenterSettings control the behavior of the Renter.
 type RenterSettings struct {
 	Allowance         Allowance `json:"allowance"`
@@ -386,6 +458,40 @@ type ContractorSpending struct {
 	PreviousSpending types.Currency `json:"previousspending"`
 }
 
+// ContractLedgerEntry is an entry in the financial ledger of a contract. It
+// records the state of the contract's finances after the last revision of a
+// block height.
+type ContractLedgerEntry struct {
+	BlockHeight      types.BlockHeight `json:"blockheight"`
+	RevisionNumber   uint64            `json:"revisionnumber"`
+	DownloadSpending types.Currency    `json:"downloadspending"`
+	StorageSpending  types.Currency    `json:"storagespending"`
+	UploadSpending   types.Currency    `json:"uploadspending"`
+	RenterFunds      types.Currency    `json:"renterfunds"`
+}
+
+// ContractHistory is the financial history of a single contract, from its
+// formation until the host either submitted a storage proof or missed the
+// proof window.
+type ContractHistory struct {
+	ID            types.FileContractID  `json:"id"`
+	HostPublicKey types.SiaPublicKey    `json:"hostpublickey"`
+	StartHeight   types.BlockHeight     `json:"startheight"`
+	EndHeight     types.BlockHeight     `json:"endheight"`
+	TotalCost     types.Currency        `json:"totalcost"`
+	Fees          types.Currency        `json:"fees"`
+	Ledger        []ContractLedgerEntry `json:"ledger"`
+
+	// Resolved indicates whether the contract has resolved, either because
+	// the host submitted a storage proof or because the proof window closed.
+	// ProofSubmitted and RenterPayout are only meaningful once the contract
+	// has resolved.
+	Resolved         bool              `json:"resolved"`
+	ResolutionHeight types.BlockHeight `json:"resolutionheight"`
+	ProofSubmitted   bool              `json:"proofsubmitted"`
+	RenterPayout     types.Currency    `json:"renterpayout"`
+}
+
 // A Renter uploads, tracks, repairs, and downloads a set of files for the
 // user.
 type Renter interface {
@@ -402,6 +508,38 @@ type Renter interface {
 	// CancelContract cancels a specific contract of the renter.
 	CancelContract(id types.FileContractID) error
 
+	// CreateProfile creates a new renter profile. A profile is a renter with
+	// its own allowance, contracts and files that shares the hostdb and wallet
+	// with the renter that created it.
+	CreateProfile(name string) (Renter, error)
+
+	// DeleteProfile deletes a renter profile. Only profiles without files and
+	// contracts can be deleted.
+	DeleteProfile(name string) error
+
+	// Profile returns the renter profile with the given name.
+	Profile(name string) (Renter, bool)
+
+	// Profiles returns the names of all renter profiles.
+	Profiles() []string
+
+	// FormContract forms a contract with the host that has the provided
+	// public key. The contract ends at endHeight, or at the end of the
+	// current period if endHeight is zero.
+	FormContract(pk types.SiaPublicKey, funds types.Currency, endHeight types.BlockHeight) (RenterContract, error)
+
+	// LockContractUtility locks or unlocks the utility of a contract. A
+	// locked utility can't be set to true by the renter's contractor.
+	LockContractUtility(id types.FileContractID, locked bool) error
+
+	// RefreshContract renews a contract with the same end height, adding
+	// funds to it.
+	RefreshContract(id types.FileContractID, funds types.Currency) (RenterContract, error)
+
+	// RenewContract renews a contract right away, regardless of whether it
+	// has entered its renew window.
+	RenewContract(id types.FileContractID, funds types.Currency, endHeight types.BlockHeight) (RenterContract, error)
+
 	// Contracts returns the staticContracts of the renter's hostContractor.
 	Contracts() []RenterContract
 
@@ -419,6 +557,11 @@ type Renter interface {
 	// billing period.
 	PeriodSpending() ContractorSpending
 
+	// ContractHistory returns the financial history of all contracts the
+	// renter has formed. Resolved contracts are pruned from the history
+	// about one year after they resolved.
+	ContractHistory() []ContractHistory
+
 	// DeleteFile deletes a file entry from the renter.
 	DeleteFile(path string) error
 
@@ -462,6 +605,11 @@ type Renter interface {
 	// storage and data operations.
 	PriceEstimation(allowance Allowance) (RenterPriceEstimation, Allowance, error)
 
+	// DetailedPriceEstimation estimates the cost of storing, uploading and
+	// downloading data with the given erasure coding settings for the period
+	// of the allowance, using the hosts the contractor would select.
+	DetailedPriceEstimation(params RenterPriceEstimationParams, allowance Allowance) (RenterDetailedPriceEstimation, Allowance, error)
+
 	// RenameFile changes the path of a file.
 	RenameFile(path, newPath string) error
 
@@ -119,6 +119,14 @@ var (
 		Testing:  5,
 	}).(int)
 
+	// priceEstimationSamples is the number of host selections that are priced
+	// by a detailed price estimation to determine its confidence bounds.
+	priceEstimationSamples = build.Select(build.Var{
+		Dev:      10,
+		Standard: 20,
+		Testing:  5,
+	}).(int)
+
 	// offlineCheckFrequency is how long the renter will wait to check the
 	// online status if it is offline.
 	offlineCheckFrequency = build.Select(build.Var{
@@ -8,6 +8,22 @@ import (
 
 // Constants related to contract formation parameters.
 var (
+	// contractHistoryRetention is the number of blocks for which a resolved
+	// contract is kept in the contract history.
+	contractHistoryRetention = build.Select(build.Var{
+		Dev:      types.BlockHeight(1000),
+		Standard: types.BlockHeight(52560), // ~1 year
+		Testing:  types.BlockHeight(100),
+	}).(types.BlockHeight)
+
+	// contractHistoryPruneInterval is the number of blocks between two
+	// prunings of the contract history.
+	contractHistoryPruneInterval = build.Select(build.Var{
+		Dev:      types.BlockHeight(10),
+		Standard: types.BlockHeight(144), // ~1 day
+		Testing:  types.BlockHeight(5),
+	}).(types.BlockHeight)
+
 	// consecutiveRenewalsBeforeReplacement is the number of times a contract
 	// attempt to be renewed before it is marked as !goodForRenew.
 	consecutiveRenewalsBeforeReplacement = build.Select(build.Var{
@@ -28,6 +28,11 @@ type (
 	fileContractRenewal struct {
 		id     types.FileContractID
 		amount types.Currency
+
+		// endHeight is the end height of the renewed contract. It is ignored
+		// by the renewSet of the contract maintenance, since expiring
+		// contracts are always renewed until the end of the current period.
+		endHeight types.BlockHeight
 	}
 )
 
@@ -118,9 +123,10 @@ func (c *Contractor) managedEstimateRenewFundingRequirements(contract modules.Re
 			break
 		}
 
-		// If the contract did not start in the current period, then it is not
-		// relevant, and none of the previous contracts will be relevant either.
-		if currentContract.StartHeight < c.currentPeriod {
+		// If the contract does not belong to the current period, then it is
+		// not relevant, and none of the previous contracts will be relevant
+		// either.
+		if !c.inCurrentPeriod(currentContract) {
 			break
 		}
 
@@ -353,6 +359,7 @@ func (c *Contractor) managedNewContract(host modules.HostDBEntry, contractFundin
 		return contractFunding, modules.RenterContract{}, fmt.Errorf("We already have a contract with host %v", contract.HostPublicKey)
 	}
 	c.pubKeysToContractID[string(contract.HostPublicKey.Key)] = contract.ID
+	c.recordRevision(contract)
 	c.mu.Unlock()
 
 	contractValue := contract.RenterFunds
@@ -600,6 +607,7 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 	c.renewedTo[id] = newContract.ID
 	// Store the contract in the record of historic contracts.
 	c.oldContracts[id] = oldContract.Metadata()
+	c.recordRevision(newContract)
 	// Save the contractor.
 	err = c.saveSync()
 	if err != nil {
@@ -739,9 +747,14 @@ func (c *Contractor) threadedContractMaintenance() {
 			// does mean that a larger percentage of funds get locked away from
 			// the user in the event that the user stops uploading immediately
 			// after the renew.
+			//
+			// The refreshed contract keeps the end height of the old contract,
+			// so that refreshing a contract doesn't extend it into the next
+			// period.
 			refreshSet = append(refreshSet, fileContractRenewal{
-				id:     contract.ID,
-				amount: contract.TotalCost.Mul64(2),
+				id:        contract.ID,
+				amount:    contract.TotalCost.Mul64(2),
+				endHeight: contract.EndHeight,
 			})
 		}
 	}
@@ -762,16 +775,9 @@ func (c *Contractor) threadedContractMaintenance() {
 	c.numFailedRenews = newFirstFailedRenew
 	c.mu.Unlock()
 
-	// Depend on the PeriodSpending function to get a breakdown of spending in
-	// the contractor. Then use that to determine how many funds remain
-	// available in the allowance for renewals.
-	spending := c.PeriodSpending()
-	var fundsRemaining types.Currency
-	// Check for an underflow. This can happen if the user reduced their
-	// allowance at some point to less than what we've already spent.
-	if spending.TotalAllocated.Cmp(allowance.Funds) < 0 {
-		fundsRemaining = allowance.Funds.Sub(spending.TotalAllocated)
-	}
+	// Determine how many funds remain available in the allowance for
+	// renewals.
+	fundsRemaining := c.managedFundsRemaining()
 
 	// Go through the contracts we've assembled for renewal. Any contracts that
 	// need to be renewed because they are expiring (renewSet) get priority over
@@ -812,7 +818,7 @@ func (c *Contractor) threadedContractMaintenance() {
 		// Renew one contract. The error is ignored because the renew function
 		// already will have logged the error, and in the event of an error,
 		// 'fundsSpent' will return '0'.
-		fundsSpent, _ := c.managedRenewContract(renewal, currentPeriod, allowance, blockHeight, endHeight)
+		fundsSpent, _ := c.managedRenewContract(renewal, currentPeriod, allowance, blockHeight, renewal.endHeight)
 		fundsRemaining = fundsRemaining.Sub(fundsSpent)
 
 		// Return here if an interrupt or kill signal has been sent.
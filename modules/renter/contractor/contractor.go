@@ -61,6 +61,18 @@ type Contractor struct {
 	oldContracts    map[types.FileContractID]modules.RenterContract
 	renewedFrom     map[types.FileContractID]types.FileContractID
 	renewedTo       map[types.FileContractID]types.FileContractID
+
+	// history contains the financial ledger of the contracts formed by the
+	// contractor. Resolved contracts are pruned from the history once they
+	// resolved more than contractHistoryRetention blocks ago.
+	// unresolvedHistory contains the contracts of the history that haven't
+	// resolved yet, so that only they are checked for every block.
+	// historyChanged indicates whether the history needs to be saved, and
+	// historyPruneHeight is the height at which the history is pruned next.
+	history            map[types.FileContractID]*modules.ContractHistory
+	unresolvedHistory  map[types.FileContractID]struct{}
+	historyChanged     bool
+	historyPruneHeight types.BlockHeight
 }
 
 // Allowance returns the current allowance.
@@ -70,6 +82,35 @@ func (c *Contractor) Allowance() modules.Allowance {
 	return c.allowance
 }
 
+// inCurrentPeriod returns whether an archived contract belongs to the current
+// period. That is the case if the contract started in the current period or if
+// it was refreshed into a contract that belongs to the current period.
+// Refreshing doesn't change the end height of a contract, which means a
+// contract that was renewed right before the period started and then
+// refreshed still covers the current period.
+func (c *Contractor) inCurrentPeriod(contract modules.RenterContract) bool {
+	for i := 0; i < 10e3; i++ { // prevent an infinite loop if there's an [impossible] contract cycle
+		if contract.StartHeight >= c.currentPeriod {
+			return true
+		}
+		newID, renewed := c.renewedTo[contract.ID]
+		if !renewed {
+			return false
+		}
+		newContract, exists := c.oldContracts[newID]
+		if !exists {
+			// The contract was renewed into an active contract.
+			active, exists := c.staticContracts.View(newID)
+			return exists && active.EndHeight == contract.EndHeight
+		}
+		if newContract.EndHeight != contract.EndHeight {
+			return false
+		}
+		contract = newContract
+	}
+	return false
+}
+
 // PeriodSpending returns the amount spent on contracts during the current
 // billing period.
 func (c *Contractor) PeriodSpending() modules.ContractorSpending {
@@ -95,7 +136,7 @@ func (c *Contractor) PeriodSpending() modules.ContractorSpending {
 	// Calculate needed spending to be reported from old contracts
 	for _, contract := range c.oldContracts {
 		host, exist := c.hdb.Host(contract.HostPublicKey)
-		if contract.StartHeight >= c.currentPeriod {
+		if c.inCurrentPeriod(contract) {
 			// Calculate spending from contracts that were renewed during the current period
 			// Calculate ContractFees
 			spending.ContractFees = spending.ContractFees.Add(contract.ContractFee)
@@ -217,6 +258,8 @@ func NewCustomContractor(cs consensusSet, w wallet, tp transactionPool, hdb host
 		staticContracts:     contractSet,
 		downloaders:         make(map[types.FileContractID]*hostDownloader),
 		editors:             make(map[types.FileContractID]*hostEditor),
+		history:             make(map[types.FileContractID]*modules.ContractHistory),
+		unresolvedHistory:   make(map[types.FileContractID]struct{}),
 		oldContracts:        make(map[types.FileContractID]modules.RenterContract),
 		contractIDToPubKey:  make(map[types.FileContractID]types.SiaPublicKey),
 		pubKeysToContractID: make(map[string]types.FileContractID),
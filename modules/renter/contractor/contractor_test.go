@@ -9,6 +9,7 @@ import (
 
 	"gitlab.com/NebulousLabs/Sia/build"
 	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/modules/renter/proto"
 	"gitlab.com/NebulousLabs/Sia/types"
 )
 
@@ -265,6 +266,65 @@ func TestAllowanceSpending(t *testing.T) {
 	}
 }
 
+// TestPeriodSpendingRefreshedContracts tests that contracts which were
+// refreshed are attributed to the same period as the contract they were
+// refreshed into.
+func TestPeriodSpendingRefreshedContracts(t *testing.T) {
+	cs, err := proto.NewContractSet(build.TempDir("contractor", t.Name()), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	// a was renewed into b at the end of the previous period, b was refreshed
+	// into c. d was renewed into an active contract that doesn't exist.
+	a := modules.RenterContract{ID: types.FileContractID{1}, StartHeight: 50, EndHeight: 110, TotalCost: types.NewCurrency64(1), ContractFee: types.NewCurrency64(1)}
+	b := modules.RenterContract{ID: types.FileContractID{2}, StartHeight: 95, EndHeight: 200, TotalCost: types.NewCurrency64(10), ContractFee: types.NewCurrency64(2)}
+	c := modules.RenterContract{ID: types.FileContractID{3}, StartHeight: 120, EndHeight: 200, TotalCost: types.NewCurrency64(100), ContractFee: types.NewCurrency64(4)}
+	d := modules.RenterContract{ID: types.FileContractID{4}, StartHeight: 90, EndHeight: 200, TotalCost: types.NewCurrency64(1000), ContractFee: types.NewCurrency64(8)}
+	contractor := &Contractor{
+		blockHeight:   150,
+		currentPeriod: 100,
+		hdb:           newStub{},
+		oldContracts: map[types.FileContractID]modules.RenterContract{
+			a.ID: a,
+			b.ID: b,
+			c.ID: c,
+			d.ID: d,
+		},
+		renewedTo: map[types.FileContractID]types.FileContractID{
+			a.ID: b.ID,
+			b.ID: c.ID,
+			d.ID: {5},
+		},
+		staticContracts: cs,
+	}
+
+	if contractor.inCurrentPeriod(a) {
+		t.Error("a should belong to the previous period")
+	}
+	if !contractor.inCurrentPeriod(b) {
+		t.Error("b was refreshed into c and should belong to the current period")
+	}
+	if !contractor.inCurrentPeriod(c) {
+		t.Error("c started in the current period")
+	}
+	if contractor.inCurrentPeriod(d) {
+		t.Error("d should belong to the previous period")
+	}
+
+	spending := contractor.PeriodSpending()
+	if !spending.TotalAllocated.Equals64(110) {
+		t.Errorf("expected 110 allocated, got %v", spending.TotalAllocated)
+	}
+	if !spending.ContractFees.Equals64(6) {
+		t.Errorf("expected 6 in contract fees, got %v", spending.ContractFees)
+	}
+	if !spending.PreviousSpending.Equals64(9) {
+		t.Errorf("expected 9 previous spending, got %v", spending.PreviousSpending)
+	}
+}
+
 // TestIntegrationSetAllowance tests the SetAllowance method.
 func TestIntegrationSetAllowance(t *testing.T) {
 	if testing.Short() {
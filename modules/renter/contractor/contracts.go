@@ -1,8 +1,38 @@
 package contractor
 
 import (
+	"reflect"
+
 	"gitlab.com/NebulousLabs/Sia/modules"
 	"gitlab.com/NebulousLabs/Sia/types"
+
+	"gitlab.com/NebulousLabs/errors"
+)
+
+var (
+	// errAllowanceExceeded is returned when a manual contract action would
+	// allocate more funds than remain in the current allowance.
+	errAllowanceExceeded = errors.New("contract funding exceeds the funds remaining in the allowance")
+
+	// errContractExists is returned when trying to manually form a contract
+	// with a host that the contractor already has a contract with.
+	errContractExists = errors.New("the contractor already has a contract with that host")
+
+	// errContractNotFound is returned when a manual contract action refers to
+	// a contract that is not part of the current contract set.
+	errContractNotFound = errors.New("contract not found in the current contract set")
+
+	// errEndHeightTooLow is returned when a manual contract action specifies
+	// an end height that has already passed or would shorten a contract.
+	errEndHeightTooLow = errors.New("end height must be in the future and must not shorten the contract")
+
+	// errHostNotFound is returned when trying to manually form a contract
+	// with a host that is not in the hostdb.
+	errHostNotFound = errors.New("host not found in the hostdb")
+
+	// errNoAllowance is returned when trying to manage contracts manually
+	// without an allowance.
+	errNoAllowance = errors.New("an allowance must be set before contracts can be managed manually")
 )
 
 // contractEndHeight returns the height at which the Contractor's contracts
@@ -22,6 +52,71 @@ func (c *Contractor) managedCancelContract(cid types.FileContractID) error {
 	})
 }
 
+// managedFundsRemaining returns the amount of money in the allowance that has
+// not been allocated to contracts in the current period yet.
+func (c *Contractor) managedFundsRemaining() types.Currency {
+	spending := c.PeriodSpending()
+	c.mu.RLock()
+	funds := c.allowance.Funds
+	c.mu.RUnlock()
+	// Check for an underflow. This can happen if the user reduced their
+	// allowance at some point to less than what we've already spent.
+	if spending.TotalAllocated.Cmp(funds) >= 0 {
+		return types.ZeroCurrency
+	}
+	return funds.Sub(spending.TotalAllocated)
+}
+
+// managedPauseMaintenance interrupts any running contract maintenance and
+// acquires the maintenance lock, so that manual contract actions don't race
+// with the contractor's automatic renewals. The caller must unlock
+// c.maintenanceLock when done.
+func (c *Contractor) managedPauseMaintenance() {
+	c.managedInterruptContractMaintenance()
+	c.maintenanceLock.Lock()
+}
+
+// managedRenewContractManually renews the contract with the given id using
+// the provided funds and end height. It shares managedRenewContract with the
+// automatic contract maintenance, which means the old contract is archived
+// and linked to the new one in exactly the same way.
+func (c *Contractor) managedRenewContractManually(id types.FileContractID, funds types.Currency, endHeight types.BlockHeight) (modules.RenterContract, error) {
+	c.mu.RLock()
+	allowance := c.allowance
+	blockHeight := c.blockHeight
+	currentPeriod := c.currentPeriod
+	c.mu.RUnlock()
+	if reflect.DeepEqual(allowance, modules.Allowance{}) {
+		return modules.RenterContract{}, errNoAllowance
+	}
+	contract, exists := c.staticContracts.View(id)
+	if !exists {
+		return modules.RenterContract{}, errContractNotFound
+	}
+	if endHeight <= blockHeight || endHeight < contract.EndHeight {
+		return modules.RenterContract{}, errEndHeightTooLow
+	}
+
+	c.managedPauseMaintenance()
+	defer c.maintenanceLock.Unlock()
+	if funds.Cmp(c.managedFundsRemaining()) > 0 {
+		return modules.RenterContract{}, errAllowanceExceeded
+	}
+	renewal := fileContractRenewal{
+		id:        id,
+		amount:    funds,
+		endHeight: endHeight,
+	}
+	if _, err := c.managedRenewContract(renewal, currentPeriod, allowance, blockHeight, renewal.endHeight); err != nil {
+		return modules.RenterContract{}, err
+	}
+	newContract, exists := c.ContractByPublicKey(contract.HostPublicKey)
+	if !exists || newContract.ID == id {
+		return modules.RenterContract{}, errors.New("renewed contract could not be found")
+	}
+	return newContract, nil
+}
+
 // managedContractUtility returns the ContractUtility for a contract with a given id.
 func (c *Contractor) managedContractUtility(id types.FileContractID) (modules.ContractUtility, bool) {
 	rc, exists := c.staticContracts.View(id)
@@ -51,6 +146,123 @@ func (c *Contractor) CancelContract(id types.FileContractID) error {
 	return c.managedCancelContract(id)
 }
 
+// FormContract forms a new contract with the host that has the given public
+// key, bypassing the contractor's automatic host selection. The contract is
+// funded with 'funds' and ends at 'endHeight'. If endHeight is zero, the end
+// height of the current period is used. The funds are taken from the
+// allowance just like the funds of automatically formed contracts.
+func (c *Contractor) FormContract(pk types.SiaPublicKey, funds types.Currency, endHeight types.BlockHeight) (modules.RenterContract, error) {
+	if err := c.tg.Add(); err != nil {
+		return modules.RenterContract{}, err
+	}
+	defer c.tg.Done()
+
+	c.mu.RLock()
+	allowance := c.allowance
+	blockHeight := c.blockHeight
+	if endHeight == 0 {
+		endHeight = c.contractEndHeight()
+	}
+	_, exists := c.pubKeysToContractID[string(pk.Key)]
+	c.mu.RUnlock()
+	if reflect.DeepEqual(allowance, modules.Allowance{}) {
+		return modules.RenterContract{}, errNoAllowance
+	}
+	if exists {
+		return modules.RenterContract{}, errContractExists
+	}
+	if endHeight <= blockHeight {
+		return modules.RenterContract{}, errEndHeightTooLow
+	}
+	host, exists := c.hdb.Host(pk)
+	if !exists {
+		return modules.RenterContract{}, errHostNotFound
+	}
+
+	c.managedPauseMaintenance()
+	defer c.maintenanceLock.Unlock()
+	if funds.Cmp(c.managedFundsRemaining()) > 0 {
+		return modules.RenterContract{}, errAllowanceExceeded
+	}
+	_, newContract, err := c.managedNewContract(host, funds, endHeight)
+	if err != nil {
+		return modules.RenterContract{}, errors.AddContext(err, "unable to form contract")
+	}
+
+	// Add this contract to the contractor and save.
+	err = c.managedUpdateContractUtility(newContract.ID, modules.ContractUtility{
+		GoodForUpload: true,
+		GoodForRenew:  true,
+	})
+	if err != nil {
+		return modules.RenterContract{}, errors.AddContext(err, "failed to update the contract utility")
+	}
+	c.mu.Lock()
+	err = c.saveSync()
+	c.mu.Unlock()
+	if err != nil {
+		c.log.Println("Unable to save the contractor:", err)
+	}
+	c.log.Printf("Manually formed contract %v with %v\n", newContract.ID, host.NetAddress)
+	newContract, _ = c.staticContracts.View(newContract.ID)
+	return newContract, nil
+}
+
+// RenewContract renews the contract with the given id right away, regardless
+// of whether it has entered its renew window. The new contract is funded with
+// 'funds' and ends at 'endHeight'. If endHeight is zero, the end height of the
+// current period is used.
+func (c *Contractor) RenewContract(id types.FileContractID, funds types.Currency, endHeight types.BlockHeight) (modules.RenterContract, error) {
+	if err := c.tg.Add(); err != nil {
+		return modules.RenterContract{}, err
+	}
+	defer c.tg.Done()
+	if endHeight == 0 {
+		c.mu.RLock()
+		endHeight = c.contractEndHeight()
+		c.mu.RUnlock()
+	}
+	return c.managedRenewContractManually(id, funds, endHeight)
+}
+
+// RefreshContract renews the contract with the given id using the same end
+// height, effectively adding 'funds' to a contract that is running low on
+// money without extending it.
+func (c *Contractor) RefreshContract(id types.FileContractID, funds types.Currency) (modules.RenterContract, error) {
+	if err := c.tg.Add(); err != nil {
+		return modules.RenterContract{}, err
+	}
+	defer c.tg.Done()
+	contract, exists := c.staticContracts.View(id)
+	if !exists {
+		return modules.RenterContract{}, errContractNotFound
+	}
+	return c.managedRenewContractManually(id, funds, contract.EndHeight)
+}
+
+// LockContractUtility locks or unlocks the utility of a contract. The utility
+// fields of a locked contract can only be set to false by the contractor,
+// which means a locked contract that is !GoodForRenew will never be renewed.
+// Unlocking a contract allows the contractor to reevaluate its utility.
+func (c *Contractor) LockContractUtility(id types.FileContractID, locked bool) error {
+	safeContract, ok := c.staticContracts.Acquire(id)
+	if !ok {
+		return errContractNotFound
+	}
+	utility := safeContract.Utility()
+	utility.Locked = locked
+	err := safeContract.UpdateUtility(utility)
+	c.staticContracts.Return(safeContract)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		// Reevaluate the utility of the contract right away.
+		go c.threadedContractMaintenance()
+	}
+	return nil
+}
+
 // Contracts returns the contracts formed by the contractor in the current
 // allowance period. Only contracts formed with currently online hosts are
 // returned.
@@ -62,6 +62,8 @@ type (
 	persister interface {
 		save(contractorPersist) error
 		load(*contractorPersist) error
+		saveHistory([]modules.ContractHistory) error
+		loadHistory(*[]modules.ContractHistory) error
 	}
 )
 
@@ -79,10 +81,11 @@ func (ws *WalletBridge) NextAddress() (types.UnlockConditions, error) { return w
 // and sign a transaction.
 func (ws *WalletBridge) StartTransaction() (transactionBuilder, error) { return ws.W.StartTransaction() }
 
-// stdPersist implements the persister interface. The filename required by
-// these functions is internal to stdPersist.
+// stdPersist implements the persister interface. The filenames required by
+// these functions are internal to stdPersist.
 type stdPersist struct {
-	filename string
+	filename        string
+	historyFilename string
 }
 
 var persistMeta = persist.Metadata{
@@ -90,6 +93,13 @@ var persistMeta = persist.Metadata{
 	Version: "1.3.1",
 }
 
+// historyPersistMeta is the metadata of the contract history, which is saved
+// separately from the rest of the contractor because it can grow large.
+var historyPersistMeta = persist.Metadata{
+	Header:  "Contractor History",
+	Version: "1.3.7",
+}
+
 func (p *stdPersist) save(data contractorPersist) error {
 	return persist.SaveJSON(persistMeta, data, p.filename)
 }
@@ -98,9 +108,18 @@ func (p *stdPersist) load(data *contractorPersist) error {
 	return persist.LoadJSON(persistMeta, &data, p.filename)
 }
 
+func (p *stdPersist) saveHistory(history []modules.ContractHistory) error {
+	return persist.SaveJSON(historyPersistMeta, history, p.historyFilename)
+}
+
+func (p *stdPersist) loadHistory(history *[]modules.ContractHistory) error {
+	return persist.LoadJSON(historyPersistMeta, history, p.historyFilename)
+}
+
 // NewPersist create a new stdPersist.
 func NewPersist(dir string) *stdPersist {
 	return &stdPersist{
-		filename: filepath.Join(dir, "contractor.json"),
+		filename:        filepath.Join(dir, "contractor.json"),
+		historyFilename: filepath.Join(dir, "contracthistory.json"),
 	}
 }
@@ -92,10 +92,11 @@ func (hd *hostDownloader) Sector(root crypto.Hash) ([]byte, error) {
 	}
 
 	// Download the sector.
-	_, sector, err := hd.downloader.Sector(root)
+	contract, sector, err := hd.downloader.Sector(root)
 	if err != nil {
 		return nil, err
 	}
+	hd.contractor.managedRecordRevision(contract)
 	return sector, nil
 }
 
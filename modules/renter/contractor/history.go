@@ -0,0 +1,187 @@
+package contractor
+
+import (
+	"sort"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// contractTransaction returns the latest transaction of a contract, whether it
+// is active or archived.
+func (c *Contractor) contractTransaction(id types.FileContractID) (types.Transaction, bool) {
+	if contract, ok := c.oldContracts[id]; ok {
+		return contract.Transaction, true
+	}
+	if contract, ok := c.staticContracts.View(id); ok {
+		return contract.Transaction, true
+	}
+	return types.Transaction{}, false
+}
+
+// recordRevision adds the current state of a contract's finances to its
+// ledger. Revisions of the same block height are merged into a single entry
+// to keep the ledger of heavily used contracts small.
+func (c *Contractor) recordRevision(contract modules.RenterContract) {
+	h, exists := c.history[contract.ID]
+	if !exists {
+		h = &modules.ContractHistory{
+			ID:            contract.ID,
+			HostPublicKey: contract.HostPublicKey,
+			StartHeight:   contract.StartHeight,
+			EndHeight:     contract.EndHeight,
+			TotalCost:     contract.TotalCost,
+			Fees:          contract.TxnFee.Add(contract.SiafundFee).Add(contract.ContractFee),
+		}
+		c.history[contract.ID] = h
+		c.unresolvedHistory[contract.ID] = struct{}{}
+	}
+	c.historyChanged = true
+	var revisionNumber uint64
+	if len(contract.Transaction.FileContractRevisions) != 0 {
+		revisionNumber = contract.Transaction.FileContractRevisions[0].NewRevisionNumber
+	}
+	entry := modules.ContractLedgerEntry{
+		BlockHeight:      c.blockHeight,
+		RevisionNumber:   revisionNumber,
+		DownloadSpending: contract.DownloadSpending,
+		StorageSpending:  contract.StorageSpending,
+		UploadSpending:   contract.UploadSpending,
+		RenterFunds:      contract.RenterFunds,
+	}
+	if n := len(h.Ledger); n > 0 && h.Ledger[n-1].BlockHeight == entry.BlockHeight {
+		h.Ledger[n-1] = entry
+		return
+	}
+	h.Ledger = append(h.Ledger, entry)
+}
+
+// managedRecordRevision adds the current state of a contract's finances to its
+// ledger. The ledger is persisted the next time the contractor saves.
+func (c *Contractor) managedRecordRevision(contract modules.RenterContract) {
+	c.mu.Lock()
+	c.recordRevision(contract)
+	c.mu.Unlock()
+}
+
+// updateContractOutcomes resolves the contracts of the history using the
+// storage proofs of the applied and reverted blocks of a consensus change.
+// Contracts without a storage proof are resolved once their proof window
+// closes. Only the unresolved contracts are checked for every block, and
+// resolved contracts are pruned from the history contractHistoryRetention
+// blocks after they resolved. The height of each block is derived from
+// c.blockHeight, which needs to be updated before calling
+// updateContractOutcomes.
+func (c *Contractor) updateContractOutcomes(cc modules.ConsensusChange) {
+	// Undo the outcomes of reverted storage proofs.
+	for _, block := range cc.RevertedBlocks {
+		for _, txn := range block.Transactions {
+			for _, sp := range txn.StorageProofs {
+				if h, exists := c.history[sp.ParentID]; exists && h.ProofSubmitted {
+					h.Resolved = false
+					h.ProofSubmitted = false
+					h.RenterPayout = types.ZeroCurrency
+					c.unresolvedHistory[h.ID] = struct{}{}
+					c.historyChanged = true
+				}
+			}
+		}
+	}
+
+	// Reopen the contracts whose proof window was reopened by a reorg. The
+	// resolution height of a contract without a storage proof is the end of
+	// its proof window.
+	if len(cc.RevertedBlocks) != 0 {
+		for _, h := range c.history {
+			if h.Resolved && !h.ProofSubmitted && c.blockHeight < h.ResolutionHeight {
+				h.Resolved = false
+				h.RenterPayout = types.ZeroCurrency
+				c.unresolvedHistory[h.ID] = struct{}{}
+				c.historyChanged = true
+			}
+		}
+	}
+
+	// Apply the outcomes of new storage proofs.
+	height := c.blockHeight - types.BlockHeight(len(cc.AppliedBlocks))
+	for _, block := range cc.AppliedBlocks {
+		height++
+		for _, txn := range block.Transactions {
+			for _, sp := range txn.StorageProofs {
+				h, exists := c.history[sp.ParentID]
+				if !exists {
+					continue
+				}
+				h.Resolved = true
+				h.ResolutionHeight = height
+				h.ProofSubmitted = true
+				h.RenterPayout = types.ZeroCurrency
+				if txn, ok := c.contractTransaction(h.ID); ok && len(txn.FileContractRevisions) != 0 {
+					if outputs := txn.FileContractRevisions[0].NewValidProofOutputs; len(outputs) != 0 {
+						h.RenterPayout = outputs[0].Value
+					}
+				}
+				delete(c.unresolvedHistory, h.ID)
+				c.historyChanged = true
+			}
+		}
+	}
+
+	// Resolve the contracts whose proof window closed without a storage
+	// proof.
+	for id := range c.unresolvedHistory {
+		h, exists := c.history[id]
+		if !exists {
+			delete(c.unresolvedHistory, id)
+			continue
+		}
+		txn, ok := c.contractTransaction(id)
+		if !ok || len(txn.FileContractRevisions) == 0 {
+			continue
+		}
+		rev := txn.FileContractRevisions[0]
+		if c.blockHeight < rev.NewWindowEnd {
+			continue
+		}
+		h.Resolved = true
+		h.ResolutionHeight = rev.NewWindowEnd
+		if len(rev.NewMissedProofOutputs) != 0 {
+			h.RenterPayout = rev.NewMissedProofOutputs[0].Value
+		}
+		delete(c.unresolvedHistory, id)
+		c.historyChanged = true
+	}
+
+	// Periodically prune the contracts that resolved more than
+	// contractHistoryRetention blocks ago.
+	if c.blockHeight < c.historyPruneHeight {
+		return
+	}
+	c.historyPruneHeight = c.blockHeight + contractHistoryPruneInterval
+	for id, h := range c.history {
+		if h.Resolved && h.ResolutionHeight+contractHistoryRetention < c.blockHeight {
+			delete(c.history, id)
+			c.historyChanged = true
+		}
+	}
+}
+
+// ContractHistory returns the financial history of every contract formed by
+// the contractor that hasn't been pruned yet, sorted by start height.
+func (c *Contractor) ContractHistory() []modules.ContractHistory {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	history := make([]modules.ContractHistory, 0, len(c.history))
+	for _, h := range c.history {
+		hc := *h
+		hc.Ledger = append([]modules.ContractLedgerEntry(nil), h.Ledger...)
+		history = append(history, hc)
+	}
+	sort.Slice(history, func(i, j int) bool {
+		if history[i].StartHeight != history[j].StartHeight {
+			return history[i].StartHeight < history[j].StartHeight
+		}
+		return history[i].ID.String() < history[j].ID.String()
+	})
+	return history
+}
@@ -0,0 +1,109 @@
+package contractor
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/modules/renter/proto"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// historyTestContract returns an archived contract with a single revision
+// that ends its proof window at windowEnd.
+func historyTestContract(id byte, windowEnd types.BlockHeight) modules.RenterContract {
+	return modules.RenterContract{
+		ID:          types.FileContractID{id},
+		StartHeight: 10,
+		EndHeight:   windowEnd - 5,
+		TotalCost:   types.NewCurrency64(100),
+		RenterFunds: types.NewCurrency64(90),
+		Transaction: types.Transaction{
+			FileContractRevisions: []types.FileContractRevision{{
+				NewRevisionNumber:     1,
+				NewWindowEnd:          windowEnd,
+				NewValidProofOutputs:  []types.SiacoinOutput{{Value: types.NewCurrency64(90)}},
+				NewMissedProofOutputs: []types.SiacoinOutput{{Value: types.NewCurrency64(80)}},
+			}},
+		},
+	}
+}
+
+// TestContractHistory tests that revisions are recorded in the ledger and that
+// contracts are resolved by storage proofs and closing proof windows.
+func TestContractHistory(t *testing.T) {
+	cs, err := proto.NewContractSet(build.TempDir("contractor", t.Name()), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	proven := historyTestContract(1, 30)
+	missed := historyTestContract(2, 30)
+	c := &Contractor{
+		blockHeight:       10,
+		history:           make(map[types.FileContractID]*modules.ContractHistory),
+		unresolvedHistory: make(map[types.FileContractID]struct{}),
+		oldContracts: map[types.FileContractID]modules.RenterContract{
+			proven.ID: proven,
+			missed.ID: missed,
+		},
+		staticContracts: cs,
+	}
+
+	// Revisions of the same height should be merged.
+	c.recordRevision(proven)
+	proven.UploadSpending = types.NewCurrency64(5)
+	c.recordRevision(proven)
+	c.blockHeight++
+	proven.DownloadSpending = types.NewCurrency64(3)
+	c.recordRevision(proven)
+	c.recordRevision(missed)
+	if ledger := c.history[proven.ID].Ledger; len(ledger) != 2 {
+		t.Fatalf("expected 2 ledger entries, got %v", len(ledger))
+	} else if !ledger[0].UploadSpending.Equals64(5) || !ledger[1].DownloadSpending.Equals64(3) {
+		t.Fatal("ledger entries don't match the revisions", ledger)
+	}
+
+	// Apply a block with a storage proof for one of the contracts.
+	proofBlock := types.Block{
+		Transactions: []types.Transaction{{
+			StorageProofs: []types.StorageProof{{ParentID: proven.ID}},
+		}},
+	}
+	c.blockHeight = 20
+	c.updateContractOutcomes(modules.ConsensusChange{AppliedBlocks: []types.Block{{}, proofBlock}})
+	if h := c.history[proven.ID]; !h.Resolved || !h.ProofSubmitted || h.ResolutionHeight != 20 || !h.RenterPayout.Equals64(90) {
+		t.Fatal("contract wasn't resolved by the storage proof", h)
+	}
+	if c.history[missed.ID].Resolved {
+		t.Fatal("contract without proof shouldn't be resolved before its window closes")
+	}
+
+	// Close the proof window of the other contract.
+	c.blockHeight = 30
+	c.updateContractOutcomes(modules.ConsensusChange{AppliedBlocks: []types.Block{{}}})
+	if h := c.history[missed.ID]; !h.Resolved || h.ProofSubmitted || h.ResolutionHeight != 30 || !h.RenterPayout.Equals64(80) {
+		t.Fatal("contract wasn't resolved by the closing proof window", h)
+	}
+
+	// Revert the blocks again.
+	c.blockHeight = 19
+	c.updateContractOutcomes(modules.ConsensusChange{RevertedBlocks: []types.Block{proofBlock}})
+	if h := c.history[proven.ID]; h.Resolved || h.ProofSubmitted {
+		t.Fatal("reverted storage proof should unresolve the contract", h)
+	}
+	if h := c.history[missed.ID]; h.Resolved {
+		t.Fatal("reopened proof window should unresolve the contract", h)
+	}
+	if len(c.unresolvedHistory) != 2 {
+		t.Fatal("unresolved contracts should be indexed again", c.unresolvedHistory)
+	}
+
+	// Resolved contracts should be pruned once the retention period passed.
+	c.blockHeight = 30 + contractHistoryRetention + 1
+	c.updateContractOutcomes(modules.ConsensusChange{AppliedBlocks: []types.Block{{}}})
+	if len(c.history) != 0 || len(c.unresolvedHistory) != 0 {
+		t.Fatal("resolved contracts weren't pruned", c.history, c.unresolvedHistory)
+	}
+}
@@ -73,17 +73,52 @@ func (c *Contractor) load() error {
 		c.oldContracts[contract.ID] = contract
 	}
 
+	// Load the contract history, which is missing if no contract has been
+	// formed yet.
+	var history []modules.ContractHistory
+	if err := c.persist.loadHistory(&history); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := range history {
+		c.history[history[i].ID] = &history[i]
+		if !history[i].Resolved {
+			c.unresolvedHistory[history[i].ID] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// saveHistory saves the contract history to disk if it changed since it was
+// last saved.
+func (c *Contractor) saveHistory() error {
+	if !c.historyChanged {
+		return nil
+	}
+	history := make([]modules.ContractHistory, 0, len(c.history))
+	for _, h := range c.history {
+		history = append(history, *h)
+	}
+	if err := c.persist.saveHistory(history); err != nil {
+		return err
+	}
+	c.historyChanged = false
 	return nil
 }
 
 // save saves the Contractor persistence data to disk.
 func (c *Contractor) save() error {
-	return c.persist.save(c.persistData())
+	if err := c.persist.save(c.persistData()); err != nil {
+		return err
+	}
+	return c.saveHistory()
 }
 
 // saveSync saves the Contractor persistence data to disk and then syncs to disk.
 func (c *Contractor) saveSync() error {
-	return c.persist.save(c.persistData())
+	if err := c.persist.save(c.persistData()); err != nil {
+		return err
+	}
+	return c.saveHistory()
 }
 
 // convertPersist converts the pre-v1.3.1 contractor persist formats to the new
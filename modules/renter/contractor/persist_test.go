@@ -13,16 +13,29 @@ import (
 )
 
 // memPersist implements the persister interface in-memory.
-type memPersist contractorPersist
+type memPersist struct {
+	data    contractorPersist
+	history []modules.ContractHistory
+}
 
-func (m *memPersist) save(data contractorPersist) error { *m = memPersist(data); return nil }
-func (m memPersist) load(data *contractorPersist) error { *data = contractorPersist(m); return nil }
+func (m *memPersist) save(data contractorPersist) error  { m.data = data; return nil }
+func (m *memPersist) load(data *contractorPersist) error { *data = m.data; return nil }
+func (m *memPersist) saveHistory(history []modules.ContractHistory) error {
+	m.history = history
+	return nil
+}
+func (m *memPersist) loadHistory(history *[]modules.ContractHistory) error {
+	*history = m.history
+	return nil
+}
 
 // TestSaveLoad tests that the contractor can save and load itself.
 func TestSaveLoad(t *testing.T) {
 	// create contractor with mocked persist dependency
 	c := &Contractor{
-		persist: new(memPersist),
+		persist:           new(memPersist),
+		history:           make(map[types.FileContractID]*modules.ContractHistory),
+		unresolvedHistory: make(map[types.FileContractID]struct{}),
 	}
 
 	c.oldContracts = map[types.FileContractID]modules.RenterContract{
@@ -37,6 +50,7 @@ func TestSaveLoad(t *testing.T) {
 	c.renewedTo = map[types.FileContractID]types.FileContractID{
 		{1}: {2},
 	}
+	c.recordRevision(c.oldContracts[types.FileContractID{0}])
 
 	// save, clear, and reload
 	err := c.save()
@@ -47,6 +61,8 @@ func TestSaveLoad(t *testing.T) {
 	c.oldContracts = make(map[types.FileContractID]modules.RenterContract)
 	c.renewedFrom = make(map[types.FileContractID]types.FileContractID)
 	c.renewedTo = make(map[types.FileContractID]types.FileContractID)
+	c.history = make(map[types.FileContractID]*modules.ContractHistory)
+	c.unresolvedHistory = make(map[types.FileContractID]struct{})
 	err = c.load()
 	if err != nil {
 		t.Fatal(err)
@@ -65,8 +81,12 @@ func TestSaveLoad(t *testing.T) {
 	if c.renewedTo[types.FileContractID{1}] != id {
 		t.Fatal("renewedTo not restored properly:", c.renewedTo)
 	}
+	if _, ok := c.unresolvedHistory[types.FileContractID{0}]; !ok || len(c.history) != 1 {
+		t.Fatal("history not restored properly:", c.history)
+	}
 	// use stdPersist instead of mock
 	c.persist = NewPersist(build.TempDir("contractor", t.Name()))
+	c.historyChanged = true
 	os.MkdirAll(build.TempDir("contractor", t.Name()), 0700)
 
 	// save, clear, and reload
@@ -77,6 +97,8 @@ func TestSaveLoad(t *testing.T) {
 	c.oldContracts = make(map[types.FileContractID]modules.RenterContract)
 	c.renewedFrom = make(map[types.FileContractID]types.FileContractID)
 	c.renewedTo = make(map[types.FileContractID]types.FileContractID)
+	c.history = make(map[types.FileContractID]*modules.ContractHistory)
+	c.unresolvedHistory = make(map[types.FileContractID]struct{})
 	err = c.load()
 	if err != nil {
 		t.Fatal(err)
@@ -94,6 +116,9 @@ func TestSaveLoad(t *testing.T) {
 	if c.renewedTo[types.FileContractID{1}] != id {
 		t.Fatal("renewedTo not restored properly:", c.renewedTo)
 	}
+	if _, ok := c.unresolvedHistory[types.FileContractID{0}]; !ok || len(c.history) != 1 {
+		t.Fatal("history not restored properly:", c.history)
+	}
 }
 
 // TestConvertPersist tests that contracts previously stored in the
@@ -69,6 +69,9 @@ func (c *Contractor) ProcessConsensusChange(cc modules.ConsensusChange) {
 		delete(c.oldContracts, metricsContractID)
 	}
 
+	// Update the outcomes of the contracts in the history.
+	c.updateContractOutcomes(cc)
+
 	c.lastChange = cc.ID
 	err := c.save()
 	if err != nil {
@@ -0,0 +1,148 @@
+package renter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"gitlab.com/NebulousLabs/errors"
+)
+
+var (
+	// errNoDataPieces is returned by a detailed price estimation if parity
+	// pieces are specified without data pieces.
+	errNoDataPieces = errors.New("data pieces must be specified if parity pieces are specified")
+
+	// errNoDataSize is returned by a detailed price estimation if the data
+	// size is zero.
+	errNoDataSize = errors.New("data size must be specified for a detailed price estimation")
+)
+
+// estimationAllowance returns the allowance that is used for a price
+// estimation. If no allowance is provided the renter's current allowance is
+// used. If no allowance is set, a sane default allowance is used.
+func (r *Renter) estimationAllowance(allowance modules.Allowance) modules.Allowance {
+	if !reflect.DeepEqual(allowance, modules.Allowance{}) {
+		return allowance
+	}
+	allowance = r.Settings().Allowance
+	if reflect.DeepEqual(allowance, modules.Allowance{}) {
+		allowance = modules.DefaultAllowance
+	}
+	return allowance
+}
+
+// priceHostSelection prices the contracts that are required to store, upload
+// and download the data described by params with the given hosts.
+func priceHostSelection(hosts []modules.HostDBEntry, params modules.RenterPriceEstimationParams, period, height types.BlockHeight, txnFee types.Currency) modules.RenterDetailedPriceEstimation {
+	est := modules.RenterDetailedPriceEstimation{
+		RenterPriceEstimationParams: params,
+	}
+
+	// Every host stores one padded piece of every chunk. Downloading a chunk
+	// requires fetching a full sector from DataPieces hosts, which on average
+	// spreads the downloads evenly across all hosts.
+	chunkSize := pieceSize * params.DataPieces
+	numChunks := params.DataSize / chunkSize
+	if params.DataSize%chunkSize != 0 {
+		numChunks++
+	}
+	downloadChunks := params.DownloadSize / chunkSize
+	if params.DownloadSize%chunkSize != 0 {
+		downloadChunks++
+	}
+	storedBytes := numChunks * modules.SectorSize
+	downloadBytes := downloadChunks * params.DataPieces * modules.SectorSize / uint64(len(hosts))
+
+	for _, host := range hosts {
+		he := modules.RenterHostPriceEstimation{
+			NetAddress:     host.NetAddress,
+			PublicKey:      host.PublicKey,
+			StoredBytes:    storedBytes,
+			ContractPrice:  host.ContractPrice,
+			DownloadCost:   host.DownloadBandwidthPrice.Mul64(downloadBytes),
+			StorageCost:    host.StoragePrice.Mul64(storedBytes).Mul64(uint64(period)),
+			TransactionFee: txnFee,
+			UploadCost:     host.UploadBandwidthPrice.Mul64(storedBytes),
+		}
+		// The contract needs to be funded with enough money to pay for the
+		// contract, the fees and all the data operations. The payouts can
+		// only be computed if there is money left for storage, otherwise
+		// the host won't put up any collateral.
+		funding := he.ContractPrice.Add(he.TransactionFee).Add(he.DownloadCost).Add(he.StorageCost).Add(he.UploadCost)
+		renterPayout, hostPayout, hostCollateral, err := modules.RenterPayoutsPreTax(host, funding, txnFee, types.ZeroCurrency, types.ZeroCurrency, period, storedBytes)
+		if err == nil {
+			he.HostCollateral = hostCollateral
+			he.SiafundFee = types.Tax(height, renterPayout.Add(hostPayout))
+		}
+		he.Total = funding.Add(he.SiafundFee)
+
+		est.Hosts = append(est.Hosts, he)
+		est.ContractCost = est.ContractCost.Add(he.ContractPrice)
+		est.DownloadCost = est.DownloadCost.Add(he.DownloadCost)
+		est.SiafundFees = est.SiafundFees.Add(he.SiafundFee)
+		est.StorageCost = est.StorageCost.Add(he.StorageCost)
+		est.TransactionFees = est.TransactionFees.Add(he.TransactionFee)
+		est.UploadCost = est.UploadCost.Add(he.UploadCost)
+		est.Total = est.Total.Add(he.Total)
+	}
+	return est
+}
+
+// DetailedPriceEstimation estimates the cost of storing, uploading and
+// downloading the data described by params for the period of the allowance.
+// Instead of averaging the prices of the best hosts, the hosts are selected
+// the same way the contractor selects them when forming contracts. Since that
+// selection is random, multiple selections are priced. The median selection
+// is returned together with bounds that 90% of the sampled selections lie
+// within. The estimation will be done using the provided allowance, if an
+// empty allowance is provided then the renter's current allowance will be used
+// if one is set. The final allowance used will be returned.
+func (r *Renter) DetailedPriceEstimation(params modules.RenterPriceEstimationParams, allowance modules.Allowance) (modules.RenterDetailedPriceEstimation, modules.Allowance, error) {
+	allowance = r.estimationAllowance(allowance)
+	if params.DataSize == 0 {
+		return modules.RenterDetailedPriceEstimation{}, allowance, errNoDataSize
+	}
+	if params.DataPieces == 0 && params.ParityPieces == 0 {
+		params.DataPieces = uint64(defaultDataPieces)
+		params.ParityPieces = uint64(defaultParityPieces)
+	} else if params.DataPieces == 0 {
+		return modules.RenterDetailedPriceEstimation{}, allowance, errNoDataPieces
+	}
+	numHosts := int(params.DataPieces + params.ParityPieces)
+
+	_, feePerByte := r.tpool.FeeEstimation()
+	txnFee := feePerByte.Mul64(modules.EstimatedFileContractTransactionSetSize)
+	height := r.cs.Height()
+
+	estimates := make([]modules.RenterDetailedPriceEstimation, 0, priceEstimationSamples)
+	for i := 0; i < priceEstimationSamples; i++ {
+		hosts, err := r.hostDB.RandomHostsWithAllowance(numHosts, nil, nil, allowance)
+		if err != nil {
+			return modules.RenterDetailedPriceEstimation{}, allowance, errors.AddContext(err, "could not generate estimate, could not get random hosts")
+		}
+		if len(hosts) < numHosts {
+			return modules.RenterDetailedPriceEstimation{}, allowance, fmt.Errorf("estimate cannot be made, need %v hosts but only %v are available", numHosts, len(hosts))
+		}
+		estimates = append(estimates, priceHostSelection(hosts, params, allowance.Period, height, txnFee))
+	}
+
+	// Use the median selection as the estimate and determine the 5th and
+	// 95th percentiles of the totals.
+	sort.Slice(estimates, func(i, j int) bool {
+		return estimates[i].Total.Cmp(estimates[j].Total) < 0
+	})
+	est := estimates[len(estimates)/2]
+	est.Samples = len(estimates)
+	lower := (len(estimates)*5+99)/100 - 1
+	if lower < 0 {
+		lower = 0
+	}
+	upper := (len(estimates)*95+99)/100 - 1
+	est.TotalLowerBound = estimates[lower].Total
+	est.TotalUpperBound = estimates[upper].Total
+	return est, allowance, nil
+}
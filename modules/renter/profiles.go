@@ -0,0 +1,238 @@
+package renter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/modules/renter/contractor"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"gitlab.com/NebulousLabs/errors"
+)
+
+var (
+	// errInvalidProfileName is returned when creating a profile with a name
+	// that can't be used as a directory name on every platform.
+	errInvalidProfileName = errors.New("profile names must be 1-64 characters long and may only contain letters, numbers, '-' and '_'")
+
+	// errProfileExists is returned when creating a profile that already
+	// exists.
+	errProfileExists = errors.New("a profile with that name already exists")
+
+	// errProfileInUse is returned when deleting a profile that still has files
+	// or contracts.
+	errProfileInUse = errors.New("profile still has files or contracts")
+
+	// errProfileNotFound is returned when a profile doesn't exist.
+	errProfileNotFound = errors.New("profile not found")
+
+	// errProfilesUnsupported is returned when trying to manage profiles of a
+	// renter that can't create them, e.g. because it is a profile itself.
+	errProfilesUnsupported = errors.New("this renter doesn't support profiles")
+
+	// profileNameRegexp matches valid profile names.
+	profileNameRegexp = regexp.MustCompile("^[a-zA-Z0-9_-]{1,64}$")
+)
+
+type (
+	// sharedHostDB is a hostdb that can be shared between the renter and its
+	// profiles. It needs to implement the methods required by the contractor
+	// on top of the methods required by the renter.
+	sharedHostDB interface {
+		hostDB
+		CheckForIPViolations([]types.SiaPublicKey) []types.SiaPublicKey
+		IncrementSuccessfulInteractions(types.SiaPublicKey)
+		IncrementFailedInteractions(types.SiaPublicKey)
+	}
+
+	// profileHostDB wraps the hostdb of the renter for use by a profile. The
+	// hostdb weighs hosts using the allowance of the renter, so profileHostDB
+	// keeps track of the profile's allowance and uses it to select hosts
+	// instead. Closing a profileHostDB doesn't close the shared hostdb.
+	profileHostDB struct {
+		sharedHostDB
+		allowance modules.Allowance
+		mu        sync.Mutex
+	}
+
+	// profileSet contains the profiles of a renter.
+	profileSet struct {
+		dir      string
+		profiles map[string]*Renter
+		wallet   modules.Wallet
+		mu       sync.Mutex
+	}
+)
+
+// Close is a no-op since the hostdb is closed by the renter that owns it.
+func (phdb *profileHostDB) Close() error { return nil }
+
+// RandomHosts returns a set of random hosts, weighted by their usefulness
+// according to the profile's allowance.
+func (phdb *profileHostDB) RandomHosts(n int, blacklist, addressBlacklist []types.SiaPublicKey) ([]modules.HostDBEntry, error) {
+	phdb.mu.Lock()
+	allowance := phdb.allowance
+	phdb.mu.Unlock()
+	if allowance.Hosts == 0 {
+		return phdb.sharedHostDB.RandomHosts(n, blacklist, addressBlacklist)
+	}
+	return phdb.RandomHostsWithAllowance(n, blacklist, addressBlacklist, allowance)
+}
+
+// SetAllowance sets the allowance that is used to select hosts for the
+// profile.
+func (phdb *profileHostDB) SetAllowance(allowance modules.Allowance) error {
+	phdb.mu.Lock()
+	phdb.allowance = allowance
+	phdb.mu.Unlock()
+	return nil
+}
+
+// managedNewProfile creates the renter of the profile with the given name,
+// loading its persisted data if it exists.
+func (r *Renter) managedNewProfile(name string) (*Renter, error) {
+	hdb, ok := r.hostDB.(sharedHostDB)
+	if !ok {
+		return nil, errProfilesUnsupported
+	}
+	phdb := &profileHostDB{sharedHostDB: hdb}
+	dir := filepath.Join(r.staticProfiles.dir, name)
+	hc, err := contractor.New(r.cs, r.staticProfiles.wallet, r.tpool, phdb, dir)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := NewCustomRenter(r.g, r.cs, r.tpool, phdb, nil, hc, dir, r.deps)
+	if err != nil {
+		return nil, errors.Compose(err, hc.Close())
+	}
+	return profile, nil
+}
+
+// managedLoadProfiles loads the profiles found in the profiles directory.
+func (r *Renter) managedLoadProfiles() error {
+	infos, err := ioutil.ReadDir(r.staticProfiles.dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if !info.IsDir() || !profileNameRegexp.MatchString(info.Name()) {
+			continue
+		}
+		profile, err := r.managedNewProfile(info.Name())
+		if err != nil {
+			return errors.AddContext(err, "unable to load profile "+info.Name())
+		}
+		r.staticProfiles.mu.Lock()
+		r.staticProfiles.profiles[info.Name()] = profile
+		r.staticProfiles.mu.Unlock()
+	}
+	return nil
+}
+
+// managedCloseProfiles closes all profiles of the renter.
+func (r *Renter) managedCloseProfiles() error {
+	if r.staticProfiles == nil {
+		return nil
+	}
+	r.staticProfiles.mu.Lock()
+	defer r.staticProfiles.mu.Unlock()
+	var errs []error
+	for _, profile := range r.staticProfiles.profiles {
+		errs = append(errs, profile.Close())
+	}
+	return errors.Compose(errs...)
+}
+
+// CreateProfile creates a new renter profile with the given name.
+func (r *Renter) CreateProfile(name string) (modules.Renter, error) {
+	if err := r.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer r.tg.Done()
+	if r.staticProfiles == nil {
+		return nil, errProfilesUnsupported
+	}
+	if !profileNameRegexp.MatchString(name) {
+		return nil, errInvalidProfileName
+	}
+
+	// Hold the lock while creating the profile to prevent creating the same
+	// profile twice.
+	r.staticProfiles.mu.Lock()
+	defer r.staticProfiles.mu.Unlock()
+	if _, exists := r.staticProfiles.profiles[name]; exists {
+		return nil, errProfileExists
+	}
+	profile, err := r.managedNewProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	r.staticProfiles.profiles[name] = profile
+	r.log.Println("Created renter profile", name)
+	return profile, nil
+}
+
+// DeleteProfile closes the renter profile with the given name and deletes its
+// persisted data. To avoid losing data or money, the profile must not have any
+// files or contracts left.
+func (r *Renter) DeleteProfile(name string) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	if r.staticProfiles == nil {
+		return errProfilesUnsupported
+	}
+
+	r.staticProfiles.mu.Lock()
+	defer r.staticProfiles.mu.Unlock()
+	profile, exists := r.staticProfiles.profiles[name]
+	if !exists {
+		return errProfileNotFound
+	}
+	if len(profile.FileList()) != 0 || len(profile.Contracts()) != 0 {
+		return errProfileInUse
+	}
+	if err := profile.Close(); err != nil {
+		return errors.AddContext(err, "unable to close profile")
+	}
+	delete(r.staticProfiles.profiles, name)
+	r.log.Println("Deleted renter profile", name)
+	return os.RemoveAll(filepath.Join(r.staticProfiles.dir, name))
+}
+
+// Profile returns the renter profile with the given name.
+func (r *Renter) Profile(name string) (modules.Renter, bool) {
+	if r.staticProfiles == nil {
+		return nil, false
+	}
+	r.staticProfiles.mu.Lock()
+	defer r.staticProfiles.mu.Unlock()
+	profile, exists := r.staticProfiles.profiles[name]
+	if !exists {
+		return nil, false
+	}
+	return profile, true
+}
+
+// Profiles returns the names of all renter profiles in alphabetical order.
+func (r *Renter) Profiles() []string {
+	if r.staticProfiles == nil {
+		return nil
+	}
+	r.staticProfiles.mu.Lock()
+	defer r.staticProfiles.mu.Unlock()
+	names := make([]string, 0, len(r.staticProfiles.profiles))
+	for name := range r.staticProfiles.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
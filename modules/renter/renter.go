@@ -23,6 +23,7 @@ package renter
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"sync"
@@ -116,6 +117,19 @@ type hostContractor interface {
 	// CancelContract cancels the Renter's contract
 	CancelContract(id types.FileContractID) error
 
+	// FormContract forms a contract with a specific host.
+	FormContract(pk types.SiaPublicKey, funds types.Currency, endHeight types.BlockHeight) (modules.RenterContract, error)
+
+	// LockContractUtility locks or unlocks the utility of a contract.
+	LockContractUtility(id types.FileContractID, locked bool) error
+
+	// RefreshContract adds funds to a contract without changing its end
+	// height.
+	RefreshContract(id types.FileContractID, funds types.Currency) (modules.RenterContract, error)
+
+	// RenewContract renews a contract right away.
+	RenewContract(id types.FileContractID, funds types.Currency, endHeight types.BlockHeight) (modules.RenterContract, error)
+
 	// Contracts returns the staticContracts of the renter's hostContractor.
 	Contracts() []modules.RenterContract
 
@@ -125,6 +139,9 @@ type hostContractor interface {
 	// ContractByPublicKey returns the contract associated with the host key.
 	ContractByPublicKey(types.SiaPublicKey) (modules.RenterContract, bool)
 
+	// ContractHistory returns the financial history of all contracts.
+	ContractHistory() []modules.ContractHistory
+
 	// ContractUtility returns the utility field for a given contract, along
 	// with a bool indicating if it exists.
 	ContractUtility(types.SiaPublicKey) (modules.ContractUtility, bool)
@@ -219,6 +236,7 @@ type Renter struct {
 	log               *persist.Logger
 	persist           persistence
 	persistDir        string
+	staticProfiles    *profileSet
 	mu                *siasync.RWMutex
 	tg                threadgroup.ThreadGroup
 	tpool             modules.TransactionPool
@@ -227,8 +245,9 @@ type Renter struct {
 // Close closes the Renter and its dependencies
 func (r *Renter) Close() error {
 	r.tg.Stop()
+	err := r.managedCloseProfiles()
 	r.hostDB.Close()
-	return r.hostContractor.Close()
+	return errors.Compose(err, r.hostContractor.Close())
 }
 
 // PriceEstimation estimates the cost in siacoins of performing various storage
@@ -239,13 +258,7 @@ func (r *Renter) Close() error {
 func (r *Renter) PriceEstimation(allowance modules.Allowance) (modules.RenterPriceEstimation, modules.Allowance, error) {
 	// Use provide allowance. If no allowance provided use the existing
 	// allowance. If no allowance exists, use a sane default allowance.
-	if reflect.DeepEqual(allowance, modules.Allowance{}) {
-		rs := r.Settings()
-		allowance = rs.Allowance
-		if reflect.DeepEqual(allowance, modules.Allowance{}) {
-			allowance = modules.DefaultAllowance
-		}
-	}
+	allowance = r.estimationAllowance(allowance)
 
 	// Get hosts for estimate
 	var hosts []modules.HostDBEntry
@@ -555,9 +568,37 @@ func (r *Renter) CancelContract(id types.FileContractID) error {
 	return r.hostContractor.CancelContract(id)
 }
 
+// FormContract forms a contract with the specified host, bypassing the
+// contractor's host selection.
+func (r *Renter) FormContract(pk types.SiaPublicKey, funds types.Currency, endHeight types.BlockHeight) (modules.RenterContract, error) {
+	return r.hostContractor.FormContract(pk, funds, endHeight)
+}
+
+// LockContractUtility locks or unlocks the utility of a renter's contract.
+func (r *Renter) LockContractUtility(id types.FileContractID, locked bool) error {
+	return r.hostContractor.LockContractUtility(id, locked)
+}
+
+// RefreshContract adds funds to a renter's contract by renewing it with the
+// same end height.
+func (r *Renter) RefreshContract(id types.FileContractID, funds types.Currency) (modules.RenterContract, error) {
+	return r.hostContractor.RefreshContract(id, funds)
+}
+
+// RenewContract renews a renter's contract right away.
+func (r *Renter) RenewContract(id types.FileContractID, funds types.Currency, endHeight types.BlockHeight) (modules.RenterContract, error) {
+	return r.hostContractor.RenewContract(id, funds, endHeight)
+}
+
 // Contracts returns an array of host contractor's staticContracts
 func (r *Renter) Contracts() []modules.RenterContract { return r.hostContractor.Contracts() }
 
+// ContractHistory returns the financial history of all of the host
+// contractor's contracts.
+func (r *Renter) ContractHistory() []modules.ContractHistory {
+	return r.hostContractor.ContractHistory()
+}
+
 // OldContracts returns an array of host contractor's oldContracts
 func (r *Renter) OldContracts() []modules.RenterContract {
 	return r.hostContractor.OldContracts()
@@ -639,8 +680,9 @@ func validateSiapath(siapath string) error {
 // Enforce that Renter satisfies the modules.Renter interface.
 var _ modules.Renter = (*Renter)(nil)
 
-// NewCustomRenter initializes a renter and returns it.
-func NewCustomRenter(g modules.Gateway, cs modules.ConsensusSet, tpool modules.TransactionPool, hdb hostDB, hc hostContractor, persistDir string, deps modules.Dependencies) (*Renter, error) {
+// NewCustomRenter initializes a renter and returns it. If wallet is nil, the
+// renter won't support profiles.
+func NewCustomRenter(g modules.Gateway, cs modules.ConsensusSet, tpool modules.TransactionPool, hdb hostDB, wallet modules.Wallet, hc hostContractor, persistDir string, deps modules.Dependencies) (*Renter, error) {
 	if g == nil {
 		return nil, errNilGateway
 	}
@@ -684,6 +726,13 @@ func NewCustomRenter(g modules.Gateway, cs modules.ConsensusSet, tpool modules.T
 		mu:             siasync.New(modules.SafeMutexDelay, 1),
 		tpool:          tpool,
 	}
+	if wallet != nil {
+		r.staticProfiles = &profileSet{
+			dir:      filepath.Join(filepath.Dir(persistDir), modules.RenterProfilesDir),
+			profiles: make(map[string]*Renter),
+			wallet:   wallet,
+		}
+	}
 	r.memoryManager = newMemoryManager(defaultMemory, r.tg.StopChan())
 
 	// Load all saved data.
@@ -725,6 +774,13 @@ func NewCustomRenter(g modules.Gateway, cs modules.ConsensusSet, tpool modules.T
 		return nil
 	})
 
+	// Load the renter profiles.
+	if r.staticProfiles != nil {
+		if err := r.managedLoadProfiles(); err != nil {
+			return nil, errors.Compose(err, r.managedCloseProfiles())
+		}
+	}
+
 	return r, nil
 }
 
@@ -739,5 +795,5 @@ func New(g modules.Gateway, cs modules.ConsensusSet, wallet modules.Wallet, tpoo
 		return nil, err
 	}
 
-	return NewCustomRenter(g, cs, tpool, hdb, hc, persistDir, modules.ProdDependencies)
+	return NewCustomRenter(g, cs, tpool, hdb, wallet, hc, persistDir, modules.ProdDependencies)
 }
@@ -198,6 +198,84 @@ func TestRenterPricesVolatility(t *testing.T) {
 	}
 }
 
+// TestRenterDetailedPriceEstimation checks that a detailed price estimation
+// prices the contracts with the selected hosts correctly.
+func TestRenterDetailedPriceEstimation(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+
+	hdb := &pricesStub{}
+	id := rt.renter.mu.Lock()
+	rt.renter.hostDB = hdb
+	rt.renter.mu.Unlock(id)
+	for i := 0; i < 3; i++ {
+		dbe := modules.HostDBEntry{}
+		dbe.ContractPrice = types.SiacoinPrecision
+		dbe.DownloadBandwidthPrice = types.NewCurrency64(uint64(i + 1))
+		dbe.StoragePrice = types.NewCurrency64(uint64(i + 1))
+		dbe.UploadBandwidthPrice = types.NewCurrency64(uint64(i + 1))
+		dbe.Collateral = types.NewCurrency64(1)
+		dbe.MaxCollateral = types.SiacoinPrecision
+		hdb.dbEntries = append(hdb.dbEntries, dbe)
+	}
+
+	// Estimate the cost of storing a single chunk with 3 hosts.
+	allowance := modules.DefaultAllowance
+	params := modules.RenterPriceEstimationParams{
+		DataSize:     1,
+		DataPieces:   1,
+		ParityPieces: 2,
+		DownloadSize: 1,
+	}
+	est, _, err := rt.renter.DetailedPriceEstimation(params, allowance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(est.Hosts) != 3 {
+		t.Fatal("expected 3 hosts in the breakdown, got", len(est.Hosts))
+	}
+	var total types.Currency
+	for i, h := range est.Hosts {
+		if h.StoredBytes != modules.SectorSize {
+			t.Fatal("host should store a single sector", h.StoredBytes)
+		}
+		price := uint64(i + 1)
+		if h.StorageCost.Cmp(types.NewCurrency64(price*modules.SectorSize).Mul64(uint64(allowance.Period))) != 0 {
+			t.Fatal("wrong storage cost", h.StorageCost)
+		}
+		if h.UploadCost.Cmp(types.NewCurrency64(price*modules.SectorSize)) != 0 {
+			t.Fatal("wrong upload cost", h.UploadCost)
+		}
+		if h.DownloadCost.Cmp(types.NewCurrency64(price*(modules.SectorSize/3))) != 0 {
+			t.Fatal("wrong download cost", h.DownloadCost)
+		}
+		if h.SiafundFee.IsZero() {
+			t.Fatal("siafund fee should be paid")
+		}
+		total = total.Add(h.Total)
+	}
+	if est.Total.Cmp(total) != 0 {
+		t.Fatal("total doesn't match the sum of the hosts", est.Total, total)
+	}
+	// The stub always returns the same hosts, so the bounds should match the
+	// estimate.
+	if est.TotalLowerBound.Cmp(est.Total) != 0 || est.TotalUpperBound.Cmp(est.Total) != 0 {
+		t.Fatal("bounds should match the total", est.TotalLowerBound, est.TotalUpperBound, est.Total)
+	}
+
+	// An estimate that requires more hosts than available should fail.
+	params.ParityPieces = 3
+	if _, _, err := rt.renter.DetailedPriceEstimation(params, allowance); err == nil {
+		t.Fatal("expected estimation to fail without enough hosts")
+	}
+}
+
 // TestRenterSiapathValidate verifies that the validateSiapath function correctly validates SiaPaths.
 func TestRenterSiapathValidate(t *testing.T) {
 	var pathtests = []struct {
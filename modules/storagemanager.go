@@ -1,6 +1,8 @@
 package modules
 
 import (
+	"time"
+
 	"gitlab.com/NebulousLabs/Sia/crypto"
 )
 
@@ -34,6 +36,16 @@ type (
 		SuccessfulReads  uint64 `json:"successfulreads"`
 		SuccessfulWrites uint64 `json:"successfulwrites"`
 
+		// CorruptSectors is the number of sectors in the storage folder that
+		// the sector scrubber found to no longer match their sector root.
+		CorruptSectors uint64 `json:"corruptsectors"`
+
+		// Degraded indicates that the failure rate of the storage folder has
+		// crossed the health thresholds. A degraded storage folder does not
+		// receive new sectors, and its sectors are moved to healthy storage
+		// folders in the background.
+		Degraded bool `json:"degraded"`
+
 		// Certain operations on a storage folder can take a long time (Add,
 		// Remove, and Resize). The fields below indicate the progress of any
 		// long running operations that might be under way in the storage
@@ -42,6 +54,67 @@ type (
 		ProgressDenominator uint64
 	}
 
+	// SectorInfo describes how a sector is stored by the storage manager.
+	// Count is the number of times the sector was added, because the same
+	// sector can be shared by several storage obligations. A count of zero
+	// means that the sector is not stored. ID is the id under which the
+	// storage manager stores the sector, see StoredSector.
+	SectorInfo struct {
+		Root        crypto.Hash `json:"root"`
+		ID          string      `json:"id"`
+		Count       uint16      `json:"count"`
+		FolderIndex uint16      `json:"folderindex"`
+		FolderPath  string      `json:"folderpath"`
+		Index       uint32      `json:"index"`
+	}
+
+	// StoredSector describes a physical sector stored by the storage
+	// manager. The storage manager doesn't store the Merkle roots of its
+	// sectors, so stored sectors are identified by ID, a salted hash of the
+	// root.
+	StoredSector struct {
+		ID          string `json:"id"`
+		Count       uint16 `json:"count"`
+		FolderIndex uint16 `json:"folderindex"`
+		FolderPath  string `json:"folderpath"`
+		Index       uint32 `json:"index"`
+	}
+
+	// StorageFolderHealthThresholds determine when a storage folder is
+	// considered degraded. A storage folder is degraded once the fraction of
+	// its reads or writes that failed exceeds MaxFailedReadRate or
+	// MaxFailedWriteRate. The rates are only checked once at least
+	// MinOperations reads or writes have been performed, so that a single
+	// failure does not degrade a storage folder. A rate of zero disables the
+	// respective check.
+	StorageFolderHealthThresholds struct {
+		MinOperations      uint64  `json:"minoperations"`
+		MaxFailedReadRate  float64 `json:"maxfailedreadrate"`
+		MaxFailedWriteRate float64 `json:"maxfailedwriterate"`
+	}
+
+	// SectorScrubStatus reports the progress of the sector scrubber, which
+	// continuously reads all sectors and verifies that they still match their
+	// sector root.
+	SectorScrubStatus struct {
+		// Rate is the number of bytes per second that are read by the
+		// scrubber. A rate of zero means that scrubbing is disabled.
+		Rate uint64 `json:"rate"` // bytes per second
+
+		// Progress of the current pass over all sectors.
+		SectorsScrubbed uint64 `json:"sectorsscrubbed"`
+		SectorsTotal    uint64 `json:"sectorstotal"`
+
+		// Passes is the number of completed passes since startup.
+		// LastPassCompleted is the time the last pass was completed.
+		Passes            uint64    `json:"passes"`
+		LastPassCompleted time.Time `json:"lastpasscompleted"`
+
+		// CorruptSectors is the number of sectors that are currently known to
+		// be corrupt.
+		CorruptSectors uint64 `json:"corruptsectors"`
+	}
+
 	// A StorageManager is responsible for managing storage folders and
 	// sectors. Sectors are the base unit of storage that gets moved between
 	// renters and hosts, and primarily is stored on the hosts.
@@ -72,6 +145,10 @@ type (
 		// The storage manager needs to be able to shut down.
 		Close() error
 
+		// CorruptSectors returns the roots of the provided sectors that were
+		// found to be corrupt by the sector scrubber.
+		CorruptSectors(sectorRoots []crypto.Hash) []crypto.Hash
+
 		// DeleteSector deletes a sector, meaning that the manager will be
 		// unable to upload that sector and be unable to provide a storage
 		// proof on that sector. DeleteSector is for removing the data
@@ -80,6 +157,12 @@ type (
 		// requests to remove data.
 		DeleteSector(sectorRoot crypto.Hash) error
 
+		// MoveStorageFolder will move a storage folder to a new path, keeping
+		// its index. All data in the storage folder is copied to the new path
+		// before the storage folder is switched over, meaning that no data
+		// will be lost if the move fails.
+		MoveStorageFolder(index uint16, newPath string) error
+
 		// ReadSector will read a sector from the storage manager, returning the
 		// bytes that match the input sector root.
 		ReadSector(sectorRoot crypto.Hash) ([]byte, error)
@@ -103,7 +186,7 @@ type (
 		RemoveStorageFolder(index uint16, force bool) error
 
 		// ResetStorageFolderHealth will reset the health statistics on a
-		// storage folder.
+		// storage folder and clear its degraded state.
 		ResetStorageFolderHealth(index uint16) error
 
 		// ResizeStorageFolder will grow or shrink a storage folder in the
@@ -118,8 +201,28 @@ type (
 		// that data will be lost.
 		ResizeStorageFolder(index uint16, newSize uint64, force bool) error
 
+		// ScrubStatus returns the progress of the sector scrubber.
+		ScrubStatus() SectorScrubStatus
+
+		// SectorInfo returns the location and the number of virtual sectors
+		// of each of the provided sectors. Sectors that are not stored are
+		// reported with a count of zero.
+		SectorInfo(sectorRoots []crypto.Hash) []SectorInfo
+
+		// SetFolderHealthThresholds sets the thresholds at which storage
+		// folders are considered degraded.
+		SetFolderHealthThresholds(StorageFolderHealthThresholds)
+
+		// SetScrubRate sets the number of bytes per second that the sector
+		// scrubber reads. A rate of zero disables scrubbing.
+		SetScrubRate(bytesPerSecond uint64)
+
 		// StorageFolders will return a list of storage folders tracked by the
 		// manager.
 		StorageFolders() []StorageFolderMetadata
+
+		// StoredSectors returns the physical sectors stored by the manager,
+		// sorted by ID.
+		StoredSectors() ([]StoredSector, error)
 	}
 )
@@ -3,10 +3,13 @@ package modules
 import (
 	"bytes"
 	"errors"
+	"io"
 
+	"github.com/tyler-smith/go-bip39"
 	"gitlab.com/NebulousLabs/entropy-mnemonics"
 
 	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/encoding"
 	"gitlab.com/NebulousLabs/Sia/types"
 )
 
@@ -15,14 +18,113 @@ const (
 	// for a seed at startup when searching for balances in the blockchain.
 	PublicKeysPerSeed = 2500
 
+	// PSTVersion is the version of the PartiallySignedTransaction format.
+	PSTVersion = 1
+
 	// SeedChecksumSize is the number of bytes that are used to checksum
 	// addresses to prevent accidental spending.
 	SeedChecksumSize = 6
 
+	// BIP39 is the dictionary ID that selects the BIP-39 encoding of a seed
+	// instead of a Sia mnemonic. The 32 bytes of the seed are used as the
+	// entropy of a 24 word BIP-39 phrase from the English wordlist.
+	BIP39 mnemonics.DictionaryID = "bip39"
+
 	// WalletDir is the directory that contains the wallet persistence.
 	WalletDir = "wallet"
+
+	// WalletsDir is the directory that contains the persistence of the named
+	// wallets. It is located next to the WalletDir.
+	WalletsDir = "wallets"
+)
+
+const (
+	// SelectLargestFirst funds transactions with the largest outputs of the
+	// wallet first. It is the default coin selection strategy and uses as
+	// few inputs as possible.
+	SelectLargestFirst CoinSelectionStrategy = "largest-first"
+
+	// SelectSmallestFirst funds transactions with the smallest outputs of the
+	// wallet first, consolidating small outputs as a side effect.
+	SelectSmallestFirst CoinSelectionStrategy = "smallest-first"
+
+	// SelectBranchAndBound searches for the set of outputs whose value
+	// exceeds the amount by the least, so that the transaction needs as
+	// little change as possible, ideally none.
+	SelectBranchAndBound CoinSelectionStrategy = "branch-and-bound"
+
+	// SelectPrivacy funds transactions with all outputs of as few addresses
+	// as possible, so that the addresses of the wallet are not linked to
+	// each other and no address is left partially spent.
+	SelectPrivacy CoinSelectionStrategy = "privacy"
+)
+
+const (
+	// CategoryContractFormation is the category of transactions that form
+	// file contracts.
+	CategoryContractFormation TransactionCategory = "contractformation"
+
+	// CategoryContractRevision is the category of transactions that revise
+	// file contracts.
+	CategoryContractRevision TransactionCategory = "contractrevision"
+
+	// CategoryStorageProof is the category of transactions that submit
+	// storage proofs.
+	CategoryStorageProof TransactionCategory = "storageproof"
+
+	// CategoryMinerPayout is the category of the miner payouts of blocks.
+	CategoryMinerPayout TransactionCategory = "minerpayout"
+
+	// CategorySiafundClaim is the category of transactions that spend
+	// siafunds and thereby claim their share of the siafund pool.
+	CategorySiafundClaim TransactionCategory = "siafundclaim"
+
+	// CategoryDefrag is the category of transactions that merge outputs of
+	// the wallet into a single output of the wallet.
+	CategoryDefrag TransactionCategory = "defrag"
+
+	// CategorySend is the category of all other transactions, which send
+	// coins to or from the wallet.
+	CategorySend TransactionCategory = "send"
 )
 
+// TransactionCategories are all categories of wallet transactions.
+var TransactionCategories = []TransactionCategory{
+	CategoryContractFormation,
+	CategoryContractRevision,
+	CategoryStorageProof,
+	CategoryMinerPayout,
+	CategorySiafundClaim,
+	CategoryDefrag,
+	CategorySend,
+}
+
+const (
+	// WebhookPending is the event of an unconfirmed transaction that pays
+	// to a watched address.
+	WebhookPending WebhookEventType = "pending"
+
+	// WebhookReceived is the event of a confirmed transaction that pays to
+	// a watched address without spending from one.
+	WebhookReceived WebhookEventType = "received"
+
+	// WebhookConfirmed is the event of a transaction related to a watched
+	// address reaching the confirmations of the webhook.
+	WebhookConfirmed WebhookEventType = "confirmed"
+
+	// WebhookReverted is the event of a confirmed transaction related to a
+	// watched address being reverted by a reorg.
+	WebhookReverted WebhookEventType = "reverted"
+)
+
+// WebhookEventTypes are all types of webhook events.
+var WebhookEventTypes = []WebhookEventType{
+	WebhookPending,
+	WebhookReceived,
+	WebhookConfirmed,
+	WebhookReverted,
+}
+
 var (
 	// ErrBadEncryptionKey is returned if the incorrect encryption key to a
 	// file is provided.
@@ -48,6 +150,21 @@ var (
 )
 
 type (
+	// CoinSelectionStrategy determines which outputs the wallet selects to
+	// fund a transaction.
+	CoinSelectionStrategy string
+
+	// CoinControl gives the caller control over the outputs that fund a
+	// transaction. Pinned outputs are always spent; if they are not
+	// sufficient, further outputs are selected using Strategy. Excluded
+	// outputs are never selected, and neither are outputs that were frozen
+	// with FreezeOutputs. An empty Strategy means SelectLargestFirst.
+	CoinControl struct {
+		Strategy CoinSelectionStrategy   `json:"strategy"`
+		Pinned   []types.SiacoinOutputID `json:"pinned"`
+		Excluded []types.SiacoinOutputID `json:"excluded"`
+	}
+
 	// Seed is cryptographic entropy that is used to derive spendable wallet
 	// addresses.
 	Seed [crypto.EntropySize]byte
@@ -94,6 +211,9 @@ type (
 	// Because of the block subsidy, a block is considered as a transaction.
 	// Since there is technically no transaction id for the block subsidy, the
 	// block id is used instead.
+	//
+	// Category is inferred from the contents of the transaction and Note is
+	// set by the user. Neither is part of the encoding of the transaction.
 	ProcessedTransaction struct {
 		Transaction           types.Transaction   `json:"transaction"`
 		TransactionID         types.TransactionID `json:"transactionid"`
@@ -102,8 +222,14 @@ type (
 
 		Inputs  []ProcessedInput  `json:"inputs"`
 		Outputs []ProcessedOutput `json:"outputs"`
+
+		Category TransactionCategory `json:"category"`
+		Note     string              `json:"note"`
 	}
 
+	// TransactionCategory describes what a wallet transaction does.
+	TransactionCategory string
+
 	// A UnspentOutput is a SiacoinOutput or SiafundOutput that the wallet
 	// is tracking.
 	UnspentOutput struct {
@@ -113,6 +239,147 @@ type (
 		Value              types.Currency    `json:"value"`
 		ConfirmationHeight types.BlockHeight `json:"confirmationheight"`
 		IsWatchOnly        bool              `json:"iswatchonly"`
+		Frozen             bool              `json:"frozen"`
+	}
+
+	// MultisigAddress is an M-of-N address registered with the wallet. The
+	// wallet tracks the outputs of the address and signs for the public keys
+	// that it holds the secret keys of.
+	MultisigAddress struct {
+		Address          types.UnlockHash       `json:"address"`
+		UnlockConditions types.UnlockConditions `json:"unlockconditions"`
+
+		// OwnedKeys are the indices of the public keys in the unlock
+		// conditions that the wallet can sign for.
+		OwnedKeys []uint64 `json:"ownedkeys"`
+
+		SiacoinBalance types.Currency `json:"siacoinbalance"`
+		SiafundBalance types.Currency `json:"siafundbalance"`
+	}
+
+	// ScheduledPayment is a payment that the wallet broadcasts once the
+	// blockchain reaches Height. A recurring payment has a non-zero Interval
+	// and is broadcast every Interval blocks, Remaining more times or, if
+	// Remaining is zero, until it is cancelled.
+	ScheduledPayment struct {
+		ID        crypto.Hash           `json:"id"`
+		Outputs   []types.SiacoinOutput `json:"outputs"`
+		Height    types.BlockHeight     `json:"height"`
+		Interval  types.BlockHeight     `json:"interval"`
+		Remaining uint64                `json:"remaining"`
+
+		// Executions is the number of times the payment was broadcast, and
+		// LastTransactionID the ID of the last transaction that paid the
+		// outputs. LastError is the reason the last attempt failed, if it
+		// did; a failed payment is retried at the next block.
+		Executions        uint64              `json:"executions"`
+		LastTransactionID types.TransactionID `json:"lasttransactionid"`
+		LastError         string              `json:"lasterror"`
+	}
+
+	// WebhookEventType is the type of a wallet event that is delivered to
+	// webhooks.
+	WebhookEventType string
+
+	// A Webhook is a URL that the wallet POSTs its events to. Events is the
+	// set of event types that are delivered, all of them if it is empty.
+	// Addresses are the watched addresses; if it is empty, all addresses of
+	// the wallet are watched. Confirmations is the number of confirmations
+	// after which a WebhookConfirmed event is delivered. Secret is the key
+	// used to sign the events. Height is the height at which the webhook was
+	// added; the transactions confirmed before it are never delivered.
+	Webhook struct {
+		ID            crypto.Hash        `json:"id"`
+		URL           string             `json:"url"`
+		Events        []WebhookEventType `json:"events"`
+		Addresses     []types.UnlockHash `json:"addresses"`
+		Confirmations types.BlockHeight  `json:"confirmations"`
+		Secret        string             `json:"secret"`
+		Height        types.BlockHeight  `json:"height"`
+	}
+
+	// A WebhookEvent is the JSON body that is POSTed to a webhook. Inputs and
+	// Outputs are the inputs and outputs of the transaction that are related
+	// to the watched addresses. The ID of an event is the same for every
+	// webhook it is delivered to.
+	WebhookEvent struct {
+		ID                 crypto.Hash         `json:"id"`
+		Type               WebhookEventType    `json:"type"`
+		TransactionID      types.TransactionID `json:"transactionid"`
+		ConfirmationHeight types.BlockHeight   `json:"confirmationheight"`
+		Confirmations      types.BlockHeight   `json:"confirmations"`
+		Inputs             []ProcessedInput    `json:"inputs"`
+		Outputs            []ProcessedOutput   `json:"outputs"`
+		Timestamp          types.Timestamp     `json:"timestamp"`
+	}
+
+	// A WebhookDelivery is an entry of the delivery log of a webhook.
+	// Attempts is the number of times the event was POSTed, and StatusCode
+	// and LastError the outcome of the last attempt. An event that is not
+	// Delivered is retried with exponential backoff until the wallet gives
+	// up after a maximum number of attempts.
+	WebhookDelivery struct {
+		WebhookID   crypto.Hash     `json:"webhookid"`
+		Event       WebhookEvent    `json:"event"`
+		Attempts    uint64          `json:"attempts"`
+		Delivered   bool            `json:"delivered"`
+		StatusCode  int             `json:"statuscode"`
+		LastAttempt types.Timestamp `json:"lastattempt"`
+		LastError   string          `json:"lasterror"`
+	}
+
+	// WatchKeys is the public key material of a range of indices of a seed.
+	// It is exported by the holder of the seed to initialize or extend a
+	// watch-only wallet, which never sees the seed itself. Signature is the
+	// signature of the key at index 0 of the seed over StartIndex and
+	// PublicKeys, so that a watch-only wallet can verify that keys exported
+	// later belong to the same seed.
+	WatchKeys struct {
+		StartIndex uint64               `json:"startindex"`
+		PublicKeys []types.SiaPublicKey `json:"publickeys"`
+		Signature  []byte               `json:"signature"`
+	}
+
+	// A PartiallySignedTransaction (PST) is a self-describing container that
+	// is used to pass a transaction between the wallet that creates it and
+	// the signers of its inputs, which may be offline. Besides the
+	// transaction, it holds everything a signer needs to know about the
+	// inputs: the unspent parent transactions, the height to sign at and
+	// which inputs are still missing signatures.
+	PartiallySignedTransaction struct {
+		Version uint64 `json:"version"`
+
+		// Height is the height at which the signatures are created. It
+		// determines the replay protection of the signatures.
+		Height types.BlockHeight `json:"height"`
+
+		Transaction types.Transaction   `json:"transaction"`
+		Parents     []types.Transaction `json:"parents"`
+		Inputs      []PSTInput          `json:"inputs"`
+	}
+
+	// A PSTInput describes a siacoin or siafund input of a
+	// PartiallySignedTransaction. Signatures is the number of valid
+	// signatures of the input; the input is signed once it has
+	// UnlockConditions.SignaturesRequired of them.
+	PSTInput struct {
+		ParentID         types.OutputID         `json:"parentid"`
+		FundType         types.Specifier        `json:"fundtype"`
+		Value            types.Currency         `json:"value"`
+		UnlockConditions types.UnlockConditions `json:"unlockconditions"`
+		KeyHints         []PSTKeyHint           `json:"keyhints"`
+		Signatures       uint64                 `json:"signatures"`
+		Signed           bool                   `json:"signed"`
+	}
+
+	// A PSTKeyHint tells a seed-based signer that the public key at
+	// PublicKeyIndex of the unlock conditions of an input is derived from
+	// one of the wallet's seeds at SeedIndex, so it doesn't have to search
+	// for the key. The hint doesn't name the seed; signers check that the
+	// key derived from their seed matches the public key.
+	PSTKeyHint struct {
+		PublicKeyIndex uint64 `json:"publickeyindex"`
+		SeedIndex      uint64 `json:"seedindex"`
 	}
 
 	// TransactionBuilder is used to construct custom transactions. A transaction
@@ -135,6 +402,10 @@ type (
 		// transaction failed.
 		FundSiacoins(amount types.Currency) error
 
+		// FundSiacoinsWithCoinControl is like FundSiacoins, but selects the
+		// outputs that fund the transaction according to cc.
+		FundSiacoinsWithCoinControl(amount types.Currency, cc CoinControl) error
+
 		// FundSiafunds will add a siafund input of exactly 'amount' to the
 		// transaction. A parent transaction may be needed to achieve an input
 		// with the correct value. The siafund input will not be signed until
@@ -262,6 +533,12 @@ type (
 		// until the blockchain is fully synced.
 		InitFromSeed(masterKey crypto.TwofishKey, seed Seed) error
 
+		// InitWatchOnly initializes a watch-only wallet from the public keys
+		// of a seed, starting at index 0. The wallet tracks the addresses of
+		// the keys and can create unsigned transactions, but it can't sign
+		// them.
+		InitWatchOnly(masterKey crypto.TwofishKey, wk WatchKeys) error
+
 		// Lock deletes all keys in memory and prevents the wallet from being
 		// used to spend coins or extract keys until 'Unlock' is called.
 		Lock() error
@@ -339,8 +616,15 @@ type (
 		// SignTransaction signs txn using secret keys known to the wallet.
 		// The transaction should be complete with the exception of the
 		// Signature fields of each TransactionSignature referenced by toSign.
+		// If toSign is empty, the wallet also adds its signatures to the
+		// inputs of registered multisig addresses.
 		SignTransaction(txn *types.Transaction, toSign []crypto.Hash) error
 
+		// SignPST adds the signatures of the wallet to the inputs of the
+		// partially signed transaction that are still missing signatures and
+		// updates their signing status.
+		SignPST(pst *PartiallySignedTransaction) error
+
 		// SweepSeed scans the blockchain for outputs generated from seed and
 		// creates a transaction that transfers them to the wallet. Note that
 		// this incurs a transaction fee. It returns the total value of the
@@ -359,6 +643,13 @@ type (
 		// AddUnlockConditions adds a set of UnlockConditions to the wallet database.
 		AddUnlockConditions(uc types.UnlockConditions) error
 
+		// AddMultisigAddress registers the M-of-N address of the unlock
+		// conditions with the wallet, which must hold the secret key of at
+		// least one of its public keys. If the address has not appeared in
+		// the blockchain, the unused flag may be set to true. Otherwise, the
+		// wallet must rescan the blockchain to search for its outputs.
+		AddMultisigAddress(uc types.UnlockConditions, unused bool) (types.UnlockHash, error)
+
 		// AddWatchAddresses instructs the wallet to begin tracking a set of
 		// addresses, in addition to the addresses it was previously tracking.
 		// If none of the addresses have appeared in the blockchain, the
@@ -366,9 +657,48 @@ type (
 		// the blockchain to search for transactions containing the addresses.
 		AddWatchAddresses(addrs []types.UnlockHash, unused bool) error
 
+		// AddWatchKeys extends the keys of a watch-only wallet with more
+		// public keys of the same seed. If none of the new keys have
+		// appeared in the blockchain, the unused flag may be set to true.
+		// Otherwise, the wallet must rescan the blockchain.
+		AddWatchKeys(wk WatchKeys, unused bool) error
+
+		// BuildMultisigTransaction creates an unsigned transaction that
+		// spends outputs of a registered multisig address to the provided
+		// outputs. The change is returned to the multisig address. The
+		// transaction has to be signed by enough co-signers before it can be
+		// broadcast.
+		BuildMultisigTransaction(addr types.UnlockHash, outputs []types.SiacoinOutput) (types.Transaction, error)
+
+		// NewTimelockedAddress returns the unlock conditions of a new
+		// address of the wallet that can't be spent from before
+		// unlockHeight.
+		NewTimelockedAddress(unlockHeight types.BlockHeight) (types.UnlockConditions, error)
+
+		// TimelockedAddresses returns the unlock conditions of the
+		// timelocked addresses of the wallet.
+		TimelockedAddresses() ([]types.UnlockConditions, error)
+
+		// SweepTimelocked sends the outputs of the timelocked addresses
+		// whose timelock has expired to a new address of the wallet.
+		SweepTimelocked() ([]types.Transaction, error)
+
+		// BumpFee raises the fee of an unconfirmed transaction to newFee by
+		// submitting a child transaction that spends a wallet output of the
+		// transaction or of its unconfirmed parents (child-pays-for-parent).
+		// newFee is the total fee of the transaction, its unconfirmed
+		// parents and the child. The returned replacement set contains all
+		// of them, with the child last.
+		BumpFee(txid types.TransactionID, newFee types.Currency) ([]types.Transaction, error)
+
 		// Close permits clean shutdown during testing and serving.
 		Close() error
 
+		// CreatePST wraps a transaction and its unconfirmed parents in a
+		// partially signed transaction. The wallet fills in what it knows
+		// about the inputs, including the key hints of its own addresses.
+		CreatePST(txn types.Transaction, parents []types.Transaction) (PartiallySignedTransaction, error)
+
 		// ConfirmedBalance returns the confirmed balance of the wallet, minus
 		// any outgoing transactions. ConfirmedBalance will include unconfirmed
 		// refund transactions.
@@ -381,6 +711,22 @@ type (
 		// not considered in the unconfirmed balance.
 		UnconfirmedBalance() (outgoingSiacoins types.Currency, incomingSiacoins types.Currency, err error)
 
+		// FreezeOutputs prevents the wallet from spending the provided
+		// outputs until they are unfrozen. Frozen outputs are persisted.
+		FreezeOutputs(ids []types.SiacoinOutputID) error
+
+		// FrozenOutputs returns the unspent outputs of the wallet that are
+		// frozen.
+		FrozenOutputs() ([]types.SiacoinOutputID, error)
+
+		// UnfreezeOutputs allows the wallet to spend frozen outputs again.
+		UnfreezeOutputs(ids []types.SiacoinOutputID) error
+
+		// FundPST creates an unsigned partially signed transaction that sends
+		// siacoins from the wallet to the provided outputs. The change is
+		// returned to a new address of the wallet.
+		FundPST(outputs []types.SiacoinOutput) (PartiallySignedTransaction, error)
+
 		// Height returns the wallet's internal processed consensus height
 		Height() (types.BlockHeight, error)
 
@@ -397,6 +743,19 @@ type (
 		// wallet only stores transactions that are related to the wallet.
 		Transaction(types.TransactionID) (ProcessedTransaction, bool, error)
 
+		// AddressLabels returns the labels of the addresses that were
+		// labeled with SetAddressLabel.
+		AddressLabels() (map[types.UnlockHash]string, error)
+
+		// SetAddressLabel labels an address, which doesn't need to belong to
+		// the wallet. An empty label removes the label of the address.
+		SetAddressLabel(addr types.UnlockHash, label string) error
+
+		// SetTransactionNote attaches a note to a transaction of the wallet.
+		// The note is returned as part of the ProcessedTransaction. An empty
+		// note removes the note of the transaction.
+		SetTransactionNote(txid types.TransactionID, note string) error
+
 		// Transactions returns all of the transactions that were confirmed at
 		// heights [startHeight, endHeight]. Unconfirmed transactions are not
 		// included.
@@ -406,6 +765,10 @@ type (
 		// relative to the wallet.
 		UnconfirmedTransactions() ([]ProcessedTransaction, error)
 
+		// MultisigAddresses returns the multisig addresses registered with
+		// the wallet.
+		MultisigAddresses() ([]MultisigAddress, error)
+
 		// RegisterTransaction takes a transaction and its parents and returns
 		// a TransactionBuilder which can be used to expand the transaction.
 		RegisterTransaction(t types.Transaction, parents []types.Transaction) (TransactionBuilder, error)
@@ -440,6 +803,27 @@ type (
 		// SendSiacoinsMulti sends coins to multiple addresses.
 		SendSiacoinsMulti(outputs []types.SiacoinOutput) ([]types.Transaction, error)
 
+		// SendSiacoinsWithCoinControl sends coins to multiple addresses,
+		// funding the transaction with outputs selected according to cc.
+		SendSiacoinsWithCoinControl(outputs []types.SiacoinOutput, cc CoinControl) ([]types.Transaction, error)
+
+		// SchedulePayment persists a payment that the wallet broadcasts with
+		// SendSiacoins or SendSiacoinsMulti once the blockchain reaches its
+		// height. The ID of the payment is chosen by the wallet.
+		SchedulePayment(sp ScheduledPayment) (ScheduledPayment, error)
+
+		// ScheduledPayments returns the payments that are scheduled, ordered
+		// by height.
+		ScheduledPayments() ([]ScheduledPayment, error)
+
+		// CancelScheduledPayment removes a scheduled payment.
+		CancelScheduledPayment(id crypto.Hash) error
+
+		// DryRunPayment funds a transaction paying outputs like SendSiacoins
+		// or SendSiacoinsMulti would, without broadcasting it, and returns
+		// the miner fee it would pay.
+		DryRunPayment(outputs []types.SiacoinOutput) (types.Currency, error)
+
 		// SendSiafunds is a tool for sending siafunds from the wallet to an
 		// address. Sending money usually results in multiple transactions. The
 		// transactions are automatically given to the transaction pool, and
@@ -457,9 +841,45 @@ type (
 		// address, if they are known to the wallet.
 		UnlockConditions(addr types.UnlockHash) (types.UnlockConditions, error)
 
+		// CreateNamedWallet creates a new named wallet. A named wallet has
+		// its own seed, encryption key and database, and shares the
+		// consensus set and transaction pool with the wallet that created
+		// it.
+		CreateNamedWallet(name string) (Wallet, error)
+
+		// DeleteNamedWallet deletes a named wallet. Only wallets without
+		// coins can be deleted.
+		DeleteNamedWallet(name string) error
+
+		// NamedWallet returns the named wallet with the given name.
+		NamedWallet(name string) (Wallet, bool)
+
+		// NamedWallets returns the names of all named wallets.
+		NamedWallets() []string
+
+		// AddWebhook registers a webhook that the wallet POSTs its events
+		// to. The ID of the webhook is chosen by the wallet, and so is its
+		// secret if none is provided.
+		AddWebhook(wh Webhook) (Webhook, error)
+
+		// Webhooks returns the registered webhooks.
+		Webhooks() ([]Webhook, error)
+
+		// RemoveWebhook removes a webhook along with its delivery log.
+		RemoveWebhook(id crypto.Hash) error
+
+		// WebhookDeliveries returns the delivery log of a webhook, ordered by
+		// the height of the events.
+		WebhookDeliveries(id crypto.Hash) ([]WebhookDelivery, error)
+
 		// WatchAddresses returns the set of addresses that the wallet is
 		// currently watching.
 		WatchAddresses() ([]types.UnlockHash, error)
+
+		// WatchOnly returns whether the wallet is a watch-only wallet, along
+		// with the number of its public keys that have not been used to
+		// generate addresses yet.
+		WatchOnly() (watchOnly bool, remainingKeys uint64, err error)
 	}
 
 	// WalletSettings control the behavior of the Wallet.
@@ -474,8 +894,24 @@ func CalculateWalletTransactionID(tid types.TransactionID, oid types.OutputID) W
 	return WalletTransactionID(crypto.HashAll(tid, oid))
 }
 
+// MarshalSia implements the encoding.SiaMarshaler interface. The category
+// and note are left out, so that the encoding matches that of older versions.
+func (pt ProcessedTransaction) MarshalSia(w io.Writer) error {
+	return encoding.NewEncoder(w).EncodeAll(pt.Transaction, pt.TransactionID, pt.ConfirmationHeight,
+		pt.ConfirmationTimestamp, pt.Inputs, pt.Outputs)
+}
+
+// UnmarshalSia implements the encoding.SiaUnmarshaler interface.
+func (pt *ProcessedTransaction) UnmarshalSia(r io.Reader) error {
+	return encoding.NewDecoder(r).DecodeAll(&pt.Transaction, &pt.TransactionID, &pt.ConfirmationHeight,
+		&pt.ConfirmationTimestamp, &pt.Inputs, &pt.Outputs)
+}
+
 // SeedToString converts a wallet seed to a human friendly string.
 func SeedToString(seed Seed, did mnemonics.DictionaryID) (string, error) {
+	if did == BIP39 {
+		return bip39.NewMnemonic(seed[:])
+	}
 	fullChecksum := crypto.HashObject(seed)
 	checksumSeed := append(seed[:], fullChecksum[:SeedChecksumSize]...)
 	phrase, err := mnemonics.ToPhrase(checksumSeed, did)
@@ -487,6 +923,19 @@ func SeedToString(seed Seed, did mnemonics.DictionaryID) (string, error) {
 
 // StringToSeed converts a string to a wallet seed.
 func StringToSeed(str string, did mnemonics.DictionaryID) (Seed, error) {
+	if did == BIP39 {
+		entropy, err := bip39.EntropyFromMnemonic(str)
+		if err != nil {
+			return Seed{}, err
+		}
+		var seed Seed
+		if len(entropy) != len(seed) {
+			return Seed{}, errors.New("BIP-39 phrase must have 24 words")
+		}
+		copy(seed[:], entropy)
+		return seed, nil
+	}
+
 	// Decode the string into the checksummed byte slice.
 	checksumSeedBytes, err := mnemonics.FromString(str, did)
 	if err != nil {
@@ -0,0 +1,131 @@
+package wallet
+
+import (
+	"errors"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errBumpFeeTooLow is returned when the new fee of a transaction does not
+	// exceed the fee it already pays.
+	errBumpFeeTooLow = errors.New("new fee must be higher than the current fee of the transaction and its unconfirmed parents")
+
+	// errBumpNoOutput is returned when a transaction has no wallet output
+	// that can pay for the fee of a child transaction.
+	errBumpNoOutput = errors.New("transaction has no spendable wallet output that covers the fee increase")
+
+	// errBumpNotFound is returned when the transaction to bump is not in the
+	// transaction pool.
+	errBumpNotFound = errors.New("transaction is not in the transaction pool")
+)
+
+// BumpFee raises the fee of an unconfirmed transaction to newFee by
+// submitting a child transaction that spends a wallet output of the
+// transaction or of its unconfirmed parents. Since the transaction pool merges
+// the child with the set it depends on, miners can only collect the fee of the
+// child by mining the whole set.
+func (w *Wallet) BumpFee(txid types.TransactionID, newFee types.Currency) ([]types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.RLock()
+	unlocked, watchOnly := w.unlocked, w.watchOnly
+	w.mu.RUnlock()
+	if !unlocked {
+		return nil, modules.ErrLockedWallet
+	} else if watchOnly {
+		return nil, errWatchOnly
+	}
+
+	// The transaction pool must not be called with the wallet lock held.
+	txn, parents, exists := w.tpool.Transaction(txid)
+	if !exists {
+		return nil, errBumpNotFound
+	}
+	set := append(append([]types.Transaction(nil), parents...), txn)
+	var currentFee types.Currency
+	for _, t := range set {
+		for _, fee := range t.MinerFees {
+			currentFee = currentFee.Add(fee)
+		}
+	}
+	if newFee.Cmp(currentFee) <= 0 {
+		return nil, errBumpFeeTooLow
+	}
+	childFee := newFee.Sub(currentFee)
+	dustThreshold, err := w.DustThreshold()
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	child, err := w.buildBumpChild(set, childFee, dustThreshold)
+	w.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.tpool.AcceptTransactionSet([]types.Transaction{child}); err != nil {
+		w.mu.Lock()
+		dbDeleteSpentOutput(w.dbTx, types.OutputID(child.SiacoinInputs[0].ParentID))
+		w.mu.Unlock()
+		return nil, build.ExtendErr("unable to get child transaction accepted", err)
+	}
+	w.log.Printf("Bumped fee of transaction %v to %v with child transaction %v", txid, newFee.HumanString(), child.ID())
+	return append(set, child), nil
+}
+
+// buildBumpChild creates and signs a transaction that spends the largest
+// wallet output of set that covers fee, paying fee to the miners and the rest
+// back to the wallet. The wallet lock must be held.
+func (w *Wallet) buildBumpChild(set []types.Transaction, fee, dustThreshold types.Currency) (types.Transaction, error) {
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	var id types.SiacoinOutputID
+	var output types.SiacoinOutput
+	found := false
+	for _, t := range set {
+		for i, sco := range t.SiacoinOutputs {
+			scoid := t.SiacoinOutputID(uint64(i))
+			if w.checkOutput(w.dbTx, height, scoid, sco, dustThreshold) != nil || sco.Value.Cmp(fee) < 0 {
+				continue
+			}
+			if !found || sco.Value.Cmp(output.Value) > 0 {
+				id, output, found = scoid, sco, true
+			}
+		}
+	}
+	if !found {
+		return types.Transaction{}, errBumpNoOutput
+	}
+
+	key := w.keys[output.UnlockHash]
+	child := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{{
+			ParentID:         id,
+			UnlockConditions: key.UnlockConditions,
+		}},
+		MinerFees: []types.Currency{fee},
+	}
+	if change := output.Value.Sub(fee); !change.IsZero() {
+		uc, err := w.nextPrimarySeedAddress(w.dbTx)
+		if err != nil {
+			return types.Transaction{}, err
+		}
+		child.SiacoinOutputs = append(child.SiacoinOutputs, types.SiacoinOutput{
+			Value:      change,
+			UnlockHash: uc.UnlockHash(),
+		})
+	}
+	addSignatures(&child, types.FullCoveredFields, key.UnlockConditions, crypto.Hash(id), key, height)
+	if err := dbPutSpentOutput(w.dbTx, types.OutputID(id), height); err != nil {
+		return types.Transaction{}, err
+	}
+	return child, nil
+}
@@ -0,0 +1,81 @@
+package wallet
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestBumpFee tests raising the fee of an unconfirmed transaction with a child
+// transaction.
+func TestBumpFee(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	txns, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(100), types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txid := txns[len(txns)-1].ID()
+	var currentFee types.Currency
+	for _, txn := range txns {
+		for _, fee := range txn.MinerFees {
+			currentFee = currentFee.Add(fee)
+		}
+	}
+
+	// The new fee must be higher than the current fee, and the transaction
+	// must be in the transaction pool.
+	if _, err := wt.wallet.BumpFee(txid, currentFee); err != errBumpFeeTooLow {
+		t.Fatal("expected errBumpFeeTooLow, got", err)
+	}
+	newFee := currentFee.Add(types.SiacoinPrecision)
+	if _, err := wt.wallet.BumpFee(types.TransactionID{}, newFee); err != errBumpNotFound {
+		t.Fatal("expected errBumpNotFound, got", err)
+	}
+	if _, err := wt.wallet.BumpFee(txid, types.SiacoinPrecision.Mul64(1e9)); err != errBumpNoOutput {
+		t.Fatal("expected errBumpNoOutput, got", err)
+	}
+
+	// Bump the fee. The replacement set contains the original transactions
+	// and the child, which pays the difference.
+	set, err := wt.wallet.BumpFee(txid, newFee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set) != len(txns)+1 {
+		t.Fatalf("expected %v transactions in the replacement set, got %v", len(txns)+1, len(set))
+	}
+	child := set[len(set)-1]
+	if len(child.MinerFees) != 1 || !child.MinerFees[0].Equals(types.SiacoinPrecision) {
+		t.Fatal("child pays the wrong fee", child.MinerFees)
+	}
+	if _, _, exists := wt.tpool.Transaction(child.ID()); !exists {
+		t.Fatal("child transaction is not in the transaction pool")
+	}
+
+	// The change output spent by the child can't be spent by another bump.
+	if _, err := wt.wallet.BumpFee(txid, newFee.Add(types.SiacoinPrecision)); err != errBumpNoOutput {
+		t.Fatal("expected errBumpNoOutput, got", err)
+	}
+
+	// Mining a block confirms the whole set.
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	for _, txn := range set {
+		confirmed, err := wt.tpool.TransactionConfirmed(txn.ID())
+		if err != nil {
+			t.Fatal(err)
+		} else if !confirmed {
+			t.Fatal("transaction of the replacement set was not confirmed", txn.ID())
+		}
+	}
+}
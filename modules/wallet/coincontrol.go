@@ -0,0 +1,314 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// bnbMaxTries is the maximum number of selections that the branch-and-bound
+// strategy considers before settling for the best one found so far.
+const bnbMaxTries = 100e3
+
+var (
+	// errOutputNotFrozen is returned when unfreezing an output that is not
+	// frozen.
+	errOutputNotFrozen = errors.New("output is not frozen")
+
+	// errPinnedExcluded is returned when an output is both pinned and
+	// excluded.
+	errPinnedExcluded = errors.New("output cannot be both pinned and excluded")
+
+	// errUnknownOutput is returned when the wallet is asked to control an
+	// output that does not belong to it.
+	errUnknownOutput = errors.New("output does not belong to the wallet")
+
+	// errUnknownStrategy is returned when a coin selection strategy is not
+	// supported.
+	errUnknownStrategy = errors.New("unknown coin selection strategy")
+)
+
+// selectOutputs selects the outputs of so that fund amount according to cc.
+// Pinned outputs are always selected; the remaining outputs are selected from
+// the outputs that are spendable and neither excluded nor frozen.
+func (w *Wallet) selectOutputs(tx *bolt.Tx, height types.BlockHeight, so sortedOutputs, amount, dustThreshold types.Currency, cc modules.CoinControl) (sortedOutputs, error) {
+	strategy := cc.Strategy
+	if strategy == "" {
+		strategy = modules.SelectLargestFirst
+	}
+	switch strategy {
+	case modules.SelectLargestFirst, modules.SelectSmallestFirst, modules.SelectBranchAndBound, modules.SelectPrivacy:
+	default:
+		return sortedOutputs{}, errUnknownStrategy
+	}
+	excluded := make(map[types.SiacoinOutputID]struct{})
+	for _, id := range cc.Excluded {
+		excluded[id] = struct{}{}
+	}
+	pinned := make(map[types.SiacoinOutputID]struct{})
+	for _, id := range cc.Pinned {
+		if _, ok := excluded[id]; ok {
+			return sortedOutputs{}, errPinnedExcluded
+		}
+		pinned[id] = struct{}{}
+	}
+
+	// potentialFund tracks the balance of the wallet including outputs that
+	// have been spent in other unconfirmed transactions recently. This is to
+	// provide the user with a more useful error message in the event that
+	// they are overspending.
+	var selected, candidates sortedOutputs
+	var fund, potentialFund types.Currency
+	for i, scoid := range so.ids {
+		sco := so.outputs[i]
+		if _, ok := excluded[scoid]; ok {
+			continue
+		}
+		err := w.checkOutput(tx, height, scoid, sco, dustThreshold)
+		if _, ok := pinned[scoid]; ok {
+			if err != nil {
+				return sortedOutputs{}, fmt.Errorf("cannot spend pinned output %v: %v", scoid, err)
+			}
+			delete(pinned, scoid)
+			selected.ids = append(selected.ids, scoid)
+			selected.outputs = append(selected.outputs, sco)
+			fund = fund.Add(sco.Value)
+			potentialFund = potentialFund.Add(sco.Value)
+			continue
+		}
+		if err == errSpendHeightTooHigh {
+			potentialFund = potentialFund.Add(sco.Value)
+		}
+		if err != nil {
+			continue
+		}
+		candidates.ids = append(candidates.ids, scoid)
+		candidates.outputs = append(candidates.outputs, sco)
+		potentialFund = potentialFund.Add(sco.Value)
+	}
+	for scoid := range pinned {
+		return sortedOutputs{}, fmt.Errorf("cannot spend pinned output %v: %v", scoid, errUnknownOutput)
+	}
+
+	if fund.Cmp(amount) < 0 {
+		target := amount.Sub(fund)
+		var chosen sortedOutputs
+		switch strategy {
+		case modules.SelectLargestFirst:
+			sort.Sort(sort.Reverse(candidates))
+			chosen = selectInOrder(candidates, target)
+		case modules.SelectSmallestFirst:
+			sort.Sort(candidates)
+			chosen = selectInOrder(candidates, target)
+		case modules.SelectBranchAndBound:
+			chosen = selectBranchAndBound(candidates, target)
+		case modules.SelectPrivacy:
+			chosen = selectPrivacy(candidates, target)
+		}
+		for i, scoid := range chosen.ids {
+			selected.ids = append(selected.ids, scoid)
+			selected.outputs = append(selected.outputs, chosen.outputs[i])
+			fund = fund.Add(chosen.outputs[i].Value)
+		}
+	}
+	if potentialFund.Cmp(amount) >= 0 && fund.Cmp(amount) < 0 {
+		return sortedOutputs{}, modules.ErrIncompleteTransactions
+	}
+	if fund.Cmp(amount) < 0 {
+		return sortedOutputs{}, modules.ErrLowBalance
+	}
+	return selected, nil
+}
+
+// selectInOrder selects outputs of so in order until their value reaches
+// target.
+func selectInOrder(so sortedOutputs, target types.Currency) (selected sortedOutputs) {
+	var fund types.Currency
+	for i := 0; i < len(so.ids) && fund.Cmp(target) < 0; i++ {
+		selected.ids = append(selected.ids, so.ids[i])
+		selected.outputs = append(selected.outputs, so.outputs[i])
+		fund = fund.Add(so.outputs[i].Value)
+	}
+	return selected
+}
+
+// selectBranchAndBound searches for the outputs of so whose value exceeds
+// target by the least. The search is a depth-first search over the outputs,
+// largest first, which stops at the first exact match or after bnbMaxTries
+// selections.
+func selectBranchAndBound(so sortedOutputs, target types.Currency) sortedOutputs {
+	sort.Sort(sort.Reverse(so))
+	var total types.Currency
+	for _, sco := range so.outputs {
+		total = total.Add(sco.Value)
+	}
+	if total.Cmp(target) < 0 {
+		return so
+	}
+
+	current := make([]bool, len(so.ids))
+	var best []bool
+	var bestExcess types.Currency
+	tries := 0
+	var search func(i int, value, remaining types.Currency)
+	search = func(i int, value, remaining types.Currency) {
+		if tries >= bnbMaxTries || (best != nil && bestExcess.IsZero()) {
+			return
+		}
+		tries++
+		if value.Cmp(target) >= 0 {
+			// Adding more outputs can only increase the excess.
+			if excess := value.Sub(target); best == nil || excess.Cmp(bestExcess) < 0 {
+				best = append([]bool(nil), current...)
+				bestExcess = excess
+			}
+			return
+		}
+		if i == len(so.ids) || value.Add(remaining).Cmp(target) < 0 {
+			return
+		}
+		v := so.outputs[i].Value
+		current[i] = true
+		search(i+1, value.Add(v), remaining.Sub(v))
+		current[i] = false
+		search(i+1, value, remaining.Sub(v))
+	}
+	search(0, types.ZeroCurrency, total)
+
+	var selected sortedOutputs
+	for i, ok := range best {
+		if ok {
+			selected.ids = append(selected.ids, so.ids[i])
+			selected.outputs = append(selected.outputs, so.outputs[i])
+		}
+	}
+	return selected
+}
+
+// selectPrivacy selects all outputs of as few addresses as possible. If the
+// outputs of a single address can fund target, the address with the smallest
+// sufficient balance is used. Otherwise, the addresses with the largest
+// balances are used.
+func selectPrivacy(so sortedOutputs, target types.Currency) (selected sortedOutputs) {
+	type addressOutputs struct {
+		outputs sortedOutputs
+		value   types.Currency
+	}
+	var groups []*addressOutputs
+	byAddress := make(map[types.UnlockHash]*addressOutputs)
+	for i, scoid := range so.ids {
+		sco := so.outputs[i]
+		g, ok := byAddress[sco.UnlockHash]
+		if !ok {
+			g = new(addressOutputs)
+			byAddress[sco.UnlockHash] = g
+			groups = append(groups, g)
+		}
+		g.outputs.ids = append(g.outputs.ids, scoid)
+		g.outputs.outputs = append(g.outputs.outputs, sco)
+		g.value = g.value.Add(sco.Value)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].value.Cmp(groups[j].value) < 0
+	})
+
+	for _, g := range groups {
+		if g.value.Cmp(target) >= 0 {
+			return g.outputs
+		}
+	}
+	var fund types.Currency
+	for i := len(groups) - 1; i >= 0 && fund.Cmp(target) < 0; i-- {
+		selected.ids = append(selected.ids, groups[i].outputs.ids...)
+		selected.outputs = append(selected.outputs, groups[i].outputs.outputs...)
+		fund = fund.Add(groups[i].value)
+	}
+	return selected
+}
+
+// isWalletOutput returns whether a siacoin output is a confirmed or
+// unconfirmed output of the wallet.
+func (w *Wallet) isWalletOutput(id types.SiacoinOutputID) bool {
+	if _, err := dbGetSiacoinOutput(w.dbTx, id); err == nil {
+		return true
+	}
+	for _, upt := range w.unconfirmedProcessedTransactions {
+		for _, o := range upt.Outputs {
+			if o.FundType == types.SpecifierSiacoinOutput && o.WalletAddress && o.ID == types.OutputID(id) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FreezeOutputs prevents the wallet from spending the provided outputs until
+// they are unfrozen. Frozen outputs are never selected to fund transactions,
+// not even when they are pinned.
+func (w *Wallet) FreezeOutputs(ids []types.SiacoinOutputID) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, id := range ids {
+		if !w.isWalletOutput(id) {
+			return fmt.Errorf("cannot freeze output %v: %v", id, errUnknownOutput)
+		}
+	}
+	for _, id := range ids {
+		if err := dbPutFrozenOutput(w.dbTx, id); err != nil {
+			return err
+		}
+	}
+	return w.syncDB()
+}
+
+// UnfreezeOutputs allows the wallet to spend frozen outputs again.
+func (w *Wallet) UnfreezeOutputs(ids []types.SiacoinOutputID) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, id := range ids {
+		if !dbIsFrozenOutput(w.dbTx, id) {
+			return fmt.Errorf("cannot unfreeze output %v: %v", id, errOutputNotFrozen)
+		}
+	}
+	for _, id := range ids {
+		if err := dbDeleteFrozenOutput(w.dbTx, id); err != nil {
+			return err
+		}
+	}
+	return w.syncDB()
+}
+
+// FrozenOutputs returns the unspent outputs of the wallet that are frozen.
+func (w *Wallet) FrozenOutputs() ([]types.SiacoinOutputID, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Outputs that were spent since they were frozen remain in the bucket,
+	// in case the block that spent them is reverted.
+	var ids []types.SiacoinOutputID
+	err := dbForEachFrozenOutput(w.dbTx, func(id types.SiacoinOutputID, _ bool) {
+		if w.isWalletOutput(id) {
+			ids = append(ids, id)
+		}
+	})
+	return ids, err
+}
@@ -0,0 +1,180 @@
+package wallet
+
+import (
+	"strings"
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestSelectionStrategies tests the coin selection strategies on a fixed set
+// of outputs.
+func TestSelectionStrategies(t *testing.T) {
+	var addrA, addrB, addrC types.UnlockHash
+	addrA[0], addrB[0], addrC[0] = 1, 2, 3
+	newOutputs := func() sortedOutputs {
+		var so sortedOutputs
+		for i, o := range []types.SiacoinOutput{
+			{Value: types.NewCurrency64(4), UnlockHash: addrA},
+			{Value: types.NewCurrency64(10), UnlockHash: addrB},
+			{Value: types.NewCurrency64(3), UnlockHash: addrC},
+			{Value: types.NewCurrency64(4), UnlockHash: addrA},
+		} {
+			so.ids = append(so.ids, types.SiacoinOutputID{byte(i)})
+			so.outputs = append(so.outputs, o)
+		}
+		return so
+	}
+	values := func(so sortedOutputs) (vs []uint64) {
+		for _, o := range so.outputs {
+			v, _ := o.Value.Uint64()
+			vs = append(vs, v)
+		}
+		return vs
+	}
+	equal := func(a, b []uint64) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	tests := []struct {
+		name   string
+		target uint64
+		sel    func(sortedOutputs, types.Currency) sortedOutputs
+		want   []uint64
+	}{
+		{"in order", 7, selectInOrder, []uint64{4, 10}},
+		{"branch-and-bound exact", 7, selectBranchAndBound, []uint64{4, 3}},
+		{"branch-and-bound least change", 12, selectBranchAndBound, []uint64{10, 3}},
+		{"branch-and-bound insufficient", 30, selectBranchAndBound, []uint64{10, 4, 4, 3}},
+		{"privacy single address", 7, selectPrivacy, []uint64{4, 4}},
+		{"privacy multiple addresses", 20, selectPrivacy, []uint64{10, 4, 4, 3}},
+	}
+	for _, test := range tests {
+		got := values(test.sel(newOutputs(), types.NewCurrency64(test.target)))
+		if !equal(got, test.want) {
+			t.Errorf("%v: expected %v, got %v", test.name, test.want, got)
+		}
+	}
+}
+
+// TestCoinControl tests funding transactions with pinned, excluded and frozen
+// outputs.
+func TestCoinControl(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+	for i := 0; i < 3; i++ {
+		if _, err := wt.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	uos, err := wt.wallet.UnspentOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []types.SiacoinOutputID
+	for _, uo := range uos {
+		if uo.FundType == types.SpecifierSiacoinOutput {
+			ids = append(ids, types.SiacoinOutputID(uo.ID))
+		}
+	}
+	if len(ids) < 2 {
+		t.Fatal("expected at least two outputs, got", len(ids))
+	}
+	frozen, pinned := ids[0], ids[1]
+
+	// Freeze an output. It is persisted and reported by UnspentOutputs.
+	if err := wt.wallet.FreezeOutputs([]types.SiacoinOutputID{{}}); err == nil || !strings.Contains(err.Error(), errUnknownOutput.Error()) {
+		t.Fatal("expected errUnknownOutput, got", err)
+	}
+	if err := wt.wallet.FreezeOutputs([]types.SiacoinOutputID{frozen}); err != nil {
+		t.Fatal(err)
+	}
+	fos, err := wt.wallet.FrozenOutputs()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(fos) != 1 || fos[0] != frozen {
+		t.Fatal("wrong frozen outputs", fos)
+	}
+	uos, err = wt.wallet.UnspentOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, uo := range uos {
+		if uo.Frozen != (types.SiacoinOutputID(uo.ID) == frozen) {
+			t.Fatal("wrong frozen status of output", uo.ID)
+		}
+	}
+
+	// Invalid coin control is rejected.
+	output := []types.SiacoinOutput{{Value: types.SiacoinPrecision}}
+	if _, err := wt.wallet.SendSiacoinsWithCoinControl(output, modules.CoinControl{Strategy: "random"}); err == nil || !strings.Contains(err.Error(), errUnknownStrategy.Error()) {
+		t.Fatal("expected errUnknownStrategy, got", err)
+	}
+	cc := modules.CoinControl{Pinned: []types.SiacoinOutputID{pinned}, Excluded: []types.SiacoinOutputID{pinned}}
+	if _, err := wt.wallet.SendSiacoinsWithCoinControl(output, cc); err == nil || !strings.Contains(err.Error(), errPinnedExcluded.Error()) {
+		t.Fatal("expected errPinnedExcluded, got", err)
+	}
+	cc = modules.CoinControl{Pinned: []types.SiacoinOutputID{frozen}}
+	if _, err := wt.wallet.SendSiacoinsWithCoinControl(output, cc); err == nil || !strings.Contains(err.Error(), errOutputFrozen.Error()) {
+		t.Fatal("expected errOutputFrozen, got", err)
+	}
+
+	// Sending with a pinned output spends it and no other output.
+	cc = modules.CoinControl{Pinned: []types.SiacoinOutputID{pinned}}
+	txns, err := wt.wallet.SendSiacoinsWithCoinControl(output, cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := txns[0]
+	if len(parent.SiacoinInputs) != 1 || parent.SiacoinInputs[0].ParentID != pinned {
+		t.Fatal("pinned output was not the only input", parent.SiacoinInputs)
+	}
+
+	// Excluding all other outputs, including the change of the last
+	// transaction, leaves the wallet without spendable funds.
+	uos, err = wt.wallet.UnspentOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var excluded []types.SiacoinOutputID
+	for _, uo := range uos {
+		if id := types.SiacoinOutputID(uo.ID); uo.FundType == types.SpecifierSiacoinOutput && id != frozen && id != pinned {
+			excluded = append(excluded, id)
+		}
+	}
+	cc = modules.CoinControl{Strategy: modules.SelectSmallestFirst, Excluded: excluded}
+	if _, err := wt.wallet.SendSiacoinsWithCoinControl(output, cc); err == nil || !strings.Contains(err.Error(), modules.ErrIncompleteTransactions.Error()) {
+		t.Fatal("expected ErrIncompleteTransactions, got", err)
+	}
+
+	// Unfreezing the output allows the wallet to spend it.
+	if err := wt.wallet.UnfreezeOutputs([]types.SiacoinOutputID{frozen}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.UnfreezeOutputs([]types.SiacoinOutputID{frozen}); err == nil || !strings.Contains(err.Error(), errOutputNotFrozen.Error()) {
+		t.Fatal("expected errOutputNotFrozen, got", err)
+	}
+	txns, err = wt.wallet.SendSiacoinsWithCoinControl(output, cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txns[0].SiacoinInputs) != 1 || txns[0].SiacoinInputs[0].ParentID != frozen {
+		t.Fatal("expected the unfrozen output to be spent", txns[0].SiacoinInputs)
+	}
+}
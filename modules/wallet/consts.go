@@ -1,7 +1,10 @@
 package wallet
 
 import (
+	"time"
+
 	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/types"
 )
 
 const (
@@ -15,6 +18,24 @@ const (
 	// defragThreshold is the number of outputs a wallet is allowed before it is
 	// defragmented.
 	defragThreshold = 50
+
+	// multisigBaseSize is the estimated size in bytes of a multisig
+	// transaction without its inputs and signatures, used to calculate the
+	// fee of the transaction.
+	multisigBaseSize = 300
+
+	// multisigSignatureSize is the estimated size in bytes of a single
+	// transaction signature of a multisig input.
+	multisigSignatureSize = 200
+
+	// webhookDefaultConfirmations is the number of confirmations after
+	// which a WebhookConfirmed event is delivered, if the webhook doesn't
+	// specify it.
+	webhookDefaultConfirmations = 6
+
+	// webhookTimeout is the time after which a POST to a webhook is
+	// considered failed.
+	webhookTimeout = 30 * time.Second
 )
 
 var (
@@ -33,6 +54,32 @@ var (
 		Standard: uint64(1000),
 		Testing:  uint64(10),
 	}).(uint64)
+
+	// webhookDeliveryRetention is the number of blocks for which the
+	// finished deliveries of webhook events are kept in the delivery log
+	// after their transaction reached the confirmations of every webhook.
+	webhookDeliveryRetention = build.Select(build.Var{
+		Dev:      types.BlockHeight(144),
+		Standard: types.BlockHeight(1008),
+		Testing:  types.BlockHeight(10),
+	}).(types.BlockHeight)
+
+	// webhookMaxAttempts is the number of times the wallet tries to deliver
+	// an event to a webhook before giving up.
+	webhookMaxAttempts = build.Select(build.Var{
+		Dev:      uint64(8),
+		Standard: uint64(12),
+		Testing:  uint64(4),
+	}).(uint64)
+
+	// webhookRetryInterval is the time the wallet waits before retrying the
+	// first failed delivery of an event. The interval doubles with every
+	// failed attempt.
+	webhookRetryInterval = build.Select(build.Var{
+		Dev:      10 * time.Second,
+		Standard: time.Minute,
+		Testing:  100 * time.Millisecond,
+	}).(time.Duration)
 )
 
 func init() {
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"time"
 
+	"gitlab.com/NebulousLabs/Sia/crypto"
 	"gitlab.com/NebulousLabs/Sia/encoding"
 	"gitlab.com/NebulousLabs/Sia/modules"
 	"gitlab.com/NebulousLabs/Sia/types"
@@ -16,6 +17,9 @@ import (
 )
 
 var (
+	// bucketMultisigAddresses maps the UnlockHash of a multisig address
+	// registered with the wallet to its UnlockConditions.
+	bucketMultisigAddresses = []byte("bucketMultisigAddresses")
 	// bucketProcessedTransactions stores ProcessedTransactions in
 	// chronological order. Only transactions relevant to the wallet are
 	// stored. The key of this bucket is an autoincrementing integer.
@@ -26,6 +30,16 @@ var (
 	// bucketAddrTransactions maps an UnlockHash to the
 	// ProcessedTransactions that it appears in.
 	bucketAddrTransactions = []byte("bucketAddrTransactions")
+	// bucketAddressLabels maps an UnlockHash to the label that the user gave
+	// the address.
+	bucketAddressLabels = []byte("bucketAddressLabels")
+	// bucketFrozenOutputs contains the IDs of the SiacoinOutputs that the
+	// user has frozen. The wallet never selects frozen outputs to fund
+	// transactions.
+	bucketFrozenOutputs = []byte("bucketFrozenOutputs")
+	// bucketScheduledPayments maps the ID of a scheduled payment to the
+	// ScheduledPayment.
+	bucketScheduledPayments = []byte("bucketScheduledPayments")
 	// bucketSiacoinOutputs maps a SiacoinOutputID to its SiacoinOutput. Only
 	// outputs that the wallet controls are stored. The wallet uses these
 	// outputs to fund transactions.
@@ -39,6 +53,13 @@ var (
 	// these outputs so that it can reuse them if they are not confirmed on
 	// the blockchain.
 	bucketSpentOutputs = []byte("bucketSpentOutputs")
+	// bucketTimelockedAddresses maps the UnlockHash of a timelocked address
+	// of the wallet to its UnlockConditions. The keys of the addresses are
+	// keys of the primary seed.
+	bucketTimelockedAddresses = []byte("bucketTimelockedAddresses")
+	// bucketTransactionNotes maps a TransactionID to the note that the user
+	// attached to the transaction.
+	bucketTransactionNotes = []byte("bucketTransactionNotes")
 	// bucketUnlockConditions maps an UnlockHash to its UnlockConditions. It
 	// is used to track UnlockConditions manually stored by the user,
 	// typically with an offline wallet.
@@ -46,16 +67,29 @@ var (
 	// bucketWallet contains various fields needed by the wallet, such as its
 	// UID, EncryptionVerification, and PrimarySeedFile.
 	bucketWallet = []byte("bucketWallet")
+	// bucketWebhookDeliveries maps the hash of a webhook ID and an event ID
+	// to the WebhookDelivery of the event to the webhook.
+	bucketWebhookDeliveries = []byte("bucketWebhookDeliveries")
+	// bucketWebhooks maps the ID of a webhook to the Webhook.
+	bucketWebhooks = []byte("bucketWebhooks")
 
 	dbBuckets = [][]byte{
+		bucketMultisigAddresses,
 		bucketProcessedTransactions,
 		bucketProcessedTxnIndex,
 		bucketAddrTransactions,
+		bucketAddressLabels,
+		bucketFrozenOutputs,
+		bucketScheduledPayments,
 		bucketSiacoinOutputs,
 		bucketSiafundOutputs,
 		bucketSpentOutputs,
+		bucketTimelockedAddresses,
+		bucketTransactionNotes,
 		bucketUnlockConditions,
 		bucketWallet,
+		bucketWebhookDeliveries,
+		bucketWebhooks,
 	}
 
 	errNoKey = errors.New("key does not exist")
@@ -71,6 +105,8 @@ var (
 	keySpendableKeyFiles      = []byte("keySpendableKeyFiles")
 	keyUID                    = []byte("keyUID")
 	keyWatchedAddrs           = []byte("keyWatchedAddrs")
+	keyWatchKeys              = []byte("keyWatchKeys")
+	keyWebhookPruneHeight     = []byte("keyWebhookPruneHeight")
 )
 
 // threadedDBUpdate commits the active database transaction and starts a new
@@ -255,6 +291,159 @@ func dbGetUnlockConditions(tx *bolt.Tx, addr types.UnlockHash) (uc types.UnlockC
 	return
 }
 
+// dbPutMultisigAddress stores the UnlockConditions of a multisig address.
+func dbPutMultisigAddress(tx *bolt.Tx, uc types.UnlockConditions) error {
+	return dbPut(tx.Bucket(bucketMultisigAddresses), uc.UnlockHash(), uc)
+}
+
+// dbForEachMultisigAddress iterates over the multisig addresses registered
+// with the wallet.
+func dbForEachMultisigAddress(tx *bolt.Tx, fn func(types.UnlockHash, types.UnlockConditions)) error {
+	return dbForEach(tx.Bucket(bucketMultisigAddresses), fn)
+}
+
+// dbPutFrozenOutput marks a siacoin output as frozen.
+func dbPutFrozenOutput(tx *bolt.Tx, id types.SiacoinOutputID) error {
+	return dbPut(tx.Bucket(bucketFrozenOutputs), id, true)
+}
+
+// dbDeleteFrozenOutput unfreezes a siacoin output.
+func dbDeleteFrozenOutput(tx *bolt.Tx, id types.SiacoinOutputID) error {
+	return dbDelete(tx.Bucket(bucketFrozenOutputs), id)
+}
+
+// dbIsFrozenOutput returns whether a siacoin output is frozen.
+func dbIsFrozenOutput(tx *bolt.Tx, id types.SiacoinOutputID) bool {
+	return tx.Bucket(bucketFrozenOutputs).Get(encoding.Marshal(id)) != nil
+}
+
+// dbForEachFrozenOutput iterates over the frozen siacoin outputs.
+func dbForEachFrozenOutput(tx *bolt.Tx, fn func(types.SiacoinOutputID, bool)) error {
+	return dbForEach(tx.Bucket(bucketFrozenOutputs), fn)
+}
+
+// dbPutTimelockedAddress stores the UnlockConditions of a timelocked address.
+func dbPutTimelockedAddress(tx *bolt.Tx, uc types.UnlockConditions) error {
+	return dbPut(tx.Bucket(bucketTimelockedAddresses), uc.UnlockHash(), uc)
+}
+
+// dbForEachTimelockedAddress iterates over the timelocked addresses of the
+// wallet.
+func dbForEachTimelockedAddress(tx *bolt.Tx, fn func(types.UnlockHash, types.UnlockConditions)) error {
+	return dbForEach(tx.Bucket(bucketTimelockedAddresses), fn)
+}
+
+// dbPutScheduledPayment stores a scheduled payment.
+func dbPutScheduledPayment(tx *bolt.Tx, sp modules.ScheduledPayment) error {
+	return dbPut(tx.Bucket(bucketScheduledPayments), sp.ID, sp)
+}
+
+// dbGetScheduledPayment retrieves a scheduled payment.
+func dbGetScheduledPayment(tx *bolt.Tx, id crypto.Hash) (sp modules.ScheduledPayment, err error) {
+	err = dbGet(tx.Bucket(bucketScheduledPayments), id, &sp)
+	return
+}
+
+// dbDeleteScheduledPayment removes a scheduled payment.
+func dbDeleteScheduledPayment(tx *bolt.Tx, id crypto.Hash) error {
+	return dbDelete(tx.Bucket(bucketScheduledPayments), id)
+}
+
+// dbForEachScheduledPayment iterates over the scheduled payments.
+func dbForEachScheduledPayment(tx *bolt.Tx, fn func(crypto.Hash, modules.ScheduledPayment)) error {
+	return dbForEach(tx.Bucket(bucketScheduledPayments), fn)
+}
+
+// dbPutWebhook stores a webhook.
+func dbPutWebhook(tx *bolt.Tx, wh modules.Webhook) error {
+	return dbPut(tx.Bucket(bucketWebhooks), wh.ID, wh)
+}
+
+// dbGetWebhook retrieves a webhook.
+func dbGetWebhook(tx *bolt.Tx, id crypto.Hash) (wh modules.Webhook, err error) {
+	err = dbGet(tx.Bucket(bucketWebhooks), id, &wh)
+	return
+}
+
+// dbDeleteWebhook removes a webhook.
+func dbDeleteWebhook(tx *bolt.Tx, id crypto.Hash) error {
+	return dbDelete(tx.Bucket(bucketWebhooks), id)
+}
+
+// dbForEachWebhook iterates over the webhooks.
+func dbForEachWebhook(tx *bolt.Tx, fn func(crypto.Hash, modules.Webhook)) error {
+	return dbForEach(tx.Bucket(bucketWebhooks), fn)
+}
+
+// dbPutWebhookDelivery stores the delivery of an event to a webhook.
+func dbPutWebhookDelivery(tx *bolt.Tx, key crypto.Hash, wd modules.WebhookDelivery) error {
+	return dbPut(tx.Bucket(bucketWebhookDeliveries), key, wd)
+}
+
+// dbGetWebhookDelivery retrieves the delivery of an event to a webhook.
+func dbGetWebhookDelivery(tx *bolt.Tx, key crypto.Hash) (wd modules.WebhookDelivery, err error) {
+	err = dbGet(tx.Bucket(bucketWebhookDeliveries), key, &wd)
+	return
+}
+
+// dbDeleteWebhookDelivery removes the delivery of an event to a webhook.
+func dbDeleteWebhookDelivery(tx *bolt.Tx, key crypto.Hash) error {
+	return dbDelete(tx.Bucket(bucketWebhookDeliveries), key)
+}
+
+// dbForEachWebhookDelivery iterates over the deliveries of all webhooks.
+func dbForEachWebhookDelivery(tx *bolt.Tx, fn func(crypto.Hash, modules.WebhookDelivery)) error {
+	return dbForEach(tx.Bucket(bucketWebhookDeliveries), fn)
+}
+
+// dbGetWebhookPruneHeight returns the height at or below which the delivery
+// logs of the webhooks were pruned.
+func dbGetWebhookPruneHeight(tx *bolt.Tx) (height types.BlockHeight, err error) {
+	heightBytes := tx.Bucket(bucketWallet).Get(keyWebhookPruneHeight)
+	if heightBytes == nil {
+		return 0, nil
+	}
+	err = encoding.Unmarshal(heightBytes, &height)
+	return
+}
+
+// dbPutWebhookPruneHeight stores the height at or below which the delivery
+// logs of the webhooks were pruned.
+func dbPutWebhookPruneHeight(tx *bolt.Tx, height types.BlockHeight) error {
+	return tx.Bucket(bucketWallet).Put(keyWebhookPruneHeight, encoding.Marshal(height))
+}
+
+// dbPutAddressLabel stores the label of an address.
+func dbPutAddressLabel(tx *bolt.Tx, addr types.UnlockHash, label string) error {
+	return dbPut(tx.Bucket(bucketAddressLabels), addr, label)
+}
+
+// dbDeleteAddressLabel removes the label of an address.
+func dbDeleteAddressLabel(tx *bolt.Tx, addr types.UnlockHash) error {
+	return dbDelete(tx.Bucket(bucketAddressLabels), addr)
+}
+
+// dbForEachAddressLabel iterates over the labeled addresses.
+func dbForEachAddressLabel(tx *bolt.Tx, fn func(types.UnlockHash, string)) error {
+	return dbForEach(tx.Bucket(bucketAddressLabels), fn)
+}
+
+// dbPutTransactionNote stores the note of a transaction.
+func dbPutTransactionNote(tx *bolt.Tx, txid types.TransactionID, note string) error {
+	return dbPut(tx.Bucket(bucketTransactionNotes), txid, note)
+}
+
+// dbGetTransactionNote retrieves the note of a transaction.
+func dbGetTransactionNote(tx *bolt.Tx, txid types.TransactionID) (note string, err error) {
+	err = dbGet(tx.Bucket(bucketTransactionNotes), txid, &note)
+	return
+}
+
+// dbDeleteTransactionNote removes the note of a transaction.
+func dbDeleteTransactionNote(tx *bolt.Tx, txid types.TransactionID) error {
+	return dbDelete(tx.Bucket(bucketTransactionNotes), txid)
+}
+
 // dbAddAddrTransaction appends a single transaction index to the set of
 // transactions associated with addr. If the index is already in the set, it is
 // not added again.
@@ -399,6 +588,27 @@ func dbGetProcessedTransaction(tx *bolt.Tx, index uint64) (pt modules.ProcessedT
 	return
 }
 
+// dbProcessedTransactionsSince returns the processed transactions that were
+// confirmed at or after height, in the order in which they were confirmed.
+func dbProcessedTransactionsSince(tx *bolt.Tx, height types.BlockHeight) ([]modules.ProcessedTransaction, error) {
+	var pts []modules.ProcessedTransaction
+	c := tx.Bucket(bucketProcessedTransactions).Cursor()
+	for key, val := c.Last(); key != nil; key, val = c.Prev() {
+		var pt modules.ProcessedTransaction
+		if err := decodeProcessedTransaction(val, &pt); err != nil {
+			return nil, err
+		}
+		if pt.ConfirmationHeight < height {
+			break
+		}
+		pts = append(pts, pt)
+	}
+	for i, j := 0, len(pts)-1; i < j; i, j = i+1, j-1 {
+		pts[i], pts[j] = pts[j], pts[i]
+	}
+	return pts, nil
+}
+
 // A processedTransactionsIter iterates through the ProcessedTransactions bucket.
 type processedTransactionsIter struct {
 	c   *bolt.Cursor
@@ -497,6 +707,22 @@ func dbPutWatchedAddresses(tx *bolt.Tx, addrs []types.UnlockHash) error {
 	return tx.Bucket(bucketWallet).Put(keyWatchedAddrs, encoding.Marshal(addrs))
 }
 
+// dbGetWatchKeys returns the public keys of a watch-only wallet. It returns
+// nil if the wallet is not a watch-only wallet.
+func dbGetWatchKeys(tx *bolt.Tx) (pks []types.SiaPublicKey, err error) {
+	b := tx.Bucket(bucketWallet).Get(keyWatchKeys)
+	if b == nil {
+		return nil, nil
+	}
+	err = encoding.Unmarshal(b, &pks)
+	return
+}
+
+// dbPutWatchKeys stores the public keys of a watch-only wallet.
+func dbPutWatchKeys(tx *bolt.Tx, pks []types.SiaPublicKey) error {
+	return tx.Bucket(bucketWallet).Put(keyWatchKeys, encoding.Marshal(pks))
+}
+
 // COMPATv121: these types were stored in the db in v1.2.2 and earlier.
 type (
 	v121ProcessedInput struct {
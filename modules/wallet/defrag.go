@@ -148,9 +148,10 @@ func (w *Wallet) threadedDefragWallet() {
 	// Check that a defrag makes sense.
 	w.mu.RLock()
 	unlocked := w.unlocked
+	watchOnly := w.watchOnly
 	w.mu.RUnlock()
-	if !unlocked {
-		// Can't defrag if the wallet is locked.
+	if !unlocked || watchOnly {
+		// Can't defrag if the wallet is locked or can't sign.
 		return
 	}
 
@@ -110,6 +110,9 @@ func (w *Wallet) managedUnlock(masterKey crypto.TwofishKey) error {
 	var auxiliarySeedFiles []seedFile
 	var unseededKeyFiles []spendableKeyFile
 	var watchedAddrs []types.UnlockHash
+	var watchKeys []types.SiaPublicKey
+	multisigAddrs := make(map[types.UnlockHash]types.UnlockConditions)
+	var timelockedAddrs []types.UnlockConditions
 	err := func() error {
 		w.mu.Lock()
 		defer w.mu.Unlock()
@@ -123,12 +126,20 @@ func (w *Wallet) managedUnlock(masterKey crypto.TwofishKey) error {
 		// lastChange
 		lastChange = dbGetConsensusChangeID(w.dbTx)
 
-		// primarySeedFile + primarySeedProgress
-		wb := w.dbTx.Bucket(bucketWallet)
-		err = encoding.Unmarshal(wb.Get(keyPrimarySeedFile), &primarySeedFile)
+		// watchKeys; a watch-only wallet has no primarySeedFile
+		watchKeys, err = dbGetWatchKeys(w.dbTx)
 		if err != nil {
 			return err
 		}
+
+		// primarySeedFile + primarySeedProgress
+		wb := w.dbTx.Bucket(bucketWallet)
+		if watchKeys == nil {
+			err = encoding.Unmarshal(wb.Get(keyPrimarySeedFile), &primarySeedFile)
+			if err != nil {
+				return err
+			}
+		}
 		err = encoding.Unmarshal(wb.Get(keyPrimarySeedProgress), &primarySeedProgress)
 		if err != nil {
 			return err
@@ -152,7 +163,18 @@ func (w *Wallet) managedUnlock(masterKey crypto.TwofishKey) error {
 			return err
 		}
 
-		return nil
+		// multisigAddrs
+		err = dbForEachMultisigAddress(w.dbTx, func(addr types.UnlockHash, uc types.UnlockConditions) {
+			multisigAddrs[addr] = uc
+		})
+		if err != nil {
+			return err
+		}
+
+		// timelockedAddrs
+		return dbForEachTimelockedAddress(w.dbTx, func(_ types.UnlockHash, uc types.UnlockConditions) {
+			timelockedAddrs = append(timelockedAddrs, uc)
+		})
 	}()
 	if err != nil {
 		return err
@@ -163,13 +185,18 @@ func (w *Wallet) managedUnlock(masterKey crypto.TwofishKey) error {
 		w.mu.Lock()
 		defer w.mu.Unlock()
 
-		// primarySeedFile
-		primarySeed, err := decryptSeedFile(masterKey, primarySeedFile)
-		if err != nil {
-			return err
+		// primarySeedFile or watchKeys
+		if watchKeys != nil {
+			w.watchOnly = true
+			w.watchKeys = watchKeys
+		} else {
+			primarySeed, err := decryptSeedFile(masterKey, primarySeedFile)
+			if err != nil {
+				return err
+			}
+			w.primarySeed = primarySeed
 		}
-		w.integrateSeed(primarySeed, primarySeedProgress)
-		w.primarySeed = primarySeed
+		w.integratePrimarySeedKeys(0, primarySeedProgress)
 		w.regenerateLookahead(primarySeedProgress)
 
 		// auxiliarySeedFiles
@@ -196,6 +223,16 @@ func (w *Wallet) managedUnlock(masterKey crypto.TwofishKey) error {
 			w.watchedAddrs[addr] = struct{}{}
 		}
 
+		// multisigAddrs
+		for addr, uc := range multisigAddrs {
+			w.multisigAddrs[addr] = uc
+		}
+
+		// timelockedAddrs; their keys are keys of the primary seed
+		for _, uc := range timelockedAddrs {
+			w.integrateTimelockedAddress(uc)
+		}
+
 		return nil
 	}()
 	if err != nil {
@@ -215,7 +252,7 @@ func (w *Wallet) managedUnlock(masterKey crypto.TwofishKey) error {
 		go w.rescanMessage(done)
 		defer close(done)
 
-		err = w.cs.ConsensusSetSubscribe(w, lastChange, w.tg.StopChan())
+		err = w.managedSubscribe(lastChange)
 		if err == modules.ErrInvalidConsensusChangeID {
 			// something went wrong; resubscribe from the beginning
 			err = dbPutConsensusChangeID(w.dbTx, modules.ConsensusChangeBeginning)
@@ -226,12 +263,11 @@ func (w *Wallet) managedUnlock(masterKey crypto.TwofishKey) error {
 			if err != nil {
 				return fmt.Errorf("failed to reset db during rescan: %v", err)
 			}
-			err = w.cs.ConsensusSetSubscribe(w, modules.ConsensusChangeBeginning, w.tg.StopChan())
+			err = w.managedSubscribe(modules.ConsensusChangeBeginning)
 		}
 		if err != nil {
 			return fmt.Errorf("wallet subscription failed: %v", err)
 		}
-		w.tpool.TransactionPoolSubscribe(w)
 	}
 
 	w.mu.Lock()
@@ -344,8 +380,7 @@ func (w *Wallet) Reset() error {
 		return errUnencryptedWallet
 	}
 
-	w.cs.Unsubscribe(w)
-	w.tpool.Unsubscribe(w)
+	w.managedUnsubscribe()
 
 	err := dbReset(w.dbTx)
 	if err != nil {
@@ -354,7 +389,10 @@ func (w *Wallet) Reset() error {
 	w.wipeSecrets()
 	w.keys = make(map[types.UnlockHash]spendableKey)
 	w.lookahead = make(map[types.UnlockHash]uint64)
+	w.seedIndices = make(map[types.UnlockHash]uint64)
 	w.seeds = []modules.Seed{}
+	w.watchOnly = false
+	w.watchKeys = nil
 	w.unconfirmedProcessedTransactions = []modules.ProcessedTransaction{}
 	w.unlocked = false
 	w.encrypted = false
@@ -478,6 +516,7 @@ func (w *Wallet) managedChangeKey(masterKey crypto.TwofishKey, newKey crypto.Two
 	var primarySeedFile seedFile
 	var auxiliarySeedFiles []seedFile
 	var unseededKeyFiles []spendableKeyFile
+	var watchOnly bool
 
 	err := func() error {
 		w.mu.Lock()
@@ -491,10 +530,13 @@ func (w *Wallet) managedChangeKey(masterKey crypto.TwofishKey, newKey crypto.Two
 
 		wb := w.dbTx.Bucket(bucketWallet)
 
-		// primarySeedFile
-		err = encoding.Unmarshal(wb.Get(keyPrimarySeedFile), &primarySeedFile)
-		if err != nil {
-			return err
+		// primarySeedFile; a watch-only wallet has none
+		watchOnly = wb.Get(keyWatchKeys) != nil
+		if !watchOnly {
+			err = encoding.Unmarshal(wb.Get(keyPrimarySeedFile), &primarySeedFile)
+			if err != nil {
+				return err
+			}
 		}
 
 		// auxiliarySeedFiles
@@ -520,9 +562,11 @@ func (w *Wallet) managedChangeKey(masterKey crypto.TwofishKey, newKey crypto.Two
 	var auxiliarySeeds []modules.Seed
 	var spendableKeys []spendableKey
 
-	primarySeed, err = decryptSeedFile(masterKey, primarySeedFile)
-	if err != nil {
-		return err
+	if !watchOnly {
+		primarySeed, err = decryptSeedFile(masterKey, primarySeedFile)
+		if err != nil {
+			return err
+		}
 	}
 	for _, sf := range auxiliarySeedFiles {
 		auxSeed, err := decryptSeedFile(masterKey, sf)
@@ -566,9 +610,11 @@ func (w *Wallet) managedChangeKey(masterKey crypto.TwofishKey, newKey crypto.Two
 
 		wb := w.dbTx.Bucket(bucketWallet)
 
-		err = wb.Put(keyPrimarySeedFile, encoding.Marshal(newPrimarySeedFile))
-		if err != nil {
-			return err
+		if !watchOnly {
+			err = wb.Put(keyPrimarySeedFile, encoding.Marshal(newPrimarySeedFile))
+			if err != nil {
+				return err
+			}
 		}
 		err = wb.Put(keyAuxiliarySeedFiles, encoding.Marshal(newAuxiliarySeedFiles))
 		if err != nil {
@@ -0,0 +1,125 @@
+package wallet
+
+import (
+	"errors"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// errUnknownTransaction is returned when attaching a note to a transaction
+// that is not related to the wallet.
+var errUnknownTransaction = errors.New("transaction is not related to the wallet")
+
+// transactionCategory infers the category of a processed transaction from its
+// contents.
+func transactionCategory(pt modules.ProcessedTransaction) modules.TransactionCategory {
+	txn := pt.Transaction
+	for _, output := range pt.Outputs {
+		if output.FundType == types.SpecifierMinerPayout {
+			return modules.CategoryMinerPayout
+		}
+	}
+	switch {
+	case len(txn.StorageProofs) > 0:
+		return modules.CategoryStorageProof
+	case len(txn.FileContracts) > 0:
+		return modules.CategoryContractFormation
+	case len(txn.FileContractRevisions) > 0:
+		return modules.CategoryContractRevision
+	case len(txn.SiafundInputs) > 0:
+		return modules.CategorySiafundClaim
+	}
+
+	// A transaction that only moves the coins of the wallet into a single
+	// output of the wallet is a defrag.
+	defrag := len(txn.SiacoinInputs) > 0 && len(txn.SiacoinOutputs) == 1 && len(txn.SiafundOutputs) == 0
+	for _, input := range pt.Inputs {
+		defrag = defrag && input.WalletAddress
+	}
+	for _, output := range pt.Outputs {
+		if output.FundType == types.SpecifierSiacoinOutput {
+			defrag = defrag && output.WalletAddress
+		}
+	}
+	if defrag {
+		return modules.CategoryDefrag
+	}
+	return modules.CategorySend
+}
+
+// annotateTransaction sets the category and the note of a processed
+// transaction. The wallet lock must be held.
+func (w *Wallet) annotateTransaction(pt *modules.ProcessedTransaction) {
+	pt.Category = transactionCategory(*pt)
+	pt.Note, _ = dbGetTransactionNote(w.dbTx, pt.TransactionID)
+}
+
+// AddressLabels returns the labels of the addresses that were labeled with
+// SetAddressLabel.
+func (w *Wallet) AddressLabels() (map[types.UnlockHash]string, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	labels := make(map[types.UnlockHash]string)
+	err := dbForEachAddressLabel(w.dbTx, func(addr types.UnlockHash, label string) {
+		labels[addr] = label
+	})
+	return labels, err
+}
+
+// SetAddressLabel labels an address. The address doesn't need to belong to
+// the wallet, so that the recipients of payments can be labeled as well. An
+// empty label removes the label of the address.
+func (w *Wallet) SetAddressLabel(addr types.UnlockHash, label string) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var err error
+	if label == "" {
+		err = dbDeleteAddressLabel(w.dbTx, addr)
+	} else {
+		err = dbPutAddressLabel(w.dbTx, addr, label)
+	}
+	if err != nil {
+		return err
+	}
+	return w.syncDB()
+}
+
+// SetTransactionNote attaches a note to a confirmed or unconfirmed transaction
+// of the wallet. An empty note removes the note of the transaction.
+func (w *Wallet) SetTransactionNote(txid types.TransactionID, note string) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err := dbGetTransactionIndex(w.dbTx, txid)
+	known := err == nil
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		known = known || pt.TransactionID == txid
+	}
+	if !known {
+		return errUnknownTransaction
+	}
+	if note == "" {
+		err = dbDeleteTransactionNote(w.dbTx, txid)
+	} else {
+		err = dbPutTransactionNote(w.dbTx, txid, note)
+	}
+	if err != nil {
+		return err
+	}
+	return w.syncDB()
+}
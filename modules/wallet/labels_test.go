@@ -0,0 +1,190 @@
+package wallet
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/encoding"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestTransactionCategory probes the categories inferred from the contents of
+// processed transactions.
+func TestTransactionCategory(t *testing.T) {
+	walletInput := modules.ProcessedInput{FundType: types.SpecifierSiacoinInput, WalletAddress: true}
+	walletOutput := modules.ProcessedOutput{FundType: types.SpecifierSiacoinOutput, WalletAddress: true}
+	foreignOutput := modules.ProcessedOutput{FundType: types.SpecifierSiacoinOutput}
+	fee := modules.ProcessedOutput{FundType: types.SpecifierMinerFee}
+
+	tests := []struct {
+		pt       modules.ProcessedTransaction
+		category modules.TransactionCategory
+	}{
+		{modules.ProcessedTransaction{
+			Outputs: []modules.ProcessedOutput{{FundType: types.SpecifierMinerPayout, WalletAddress: true}},
+		}, modules.CategoryMinerPayout},
+		{modules.ProcessedTransaction{
+			Transaction: types.Transaction{StorageProofs: make([]types.StorageProof, 1)},
+		}, modules.CategoryStorageProof},
+		{modules.ProcessedTransaction{
+			Transaction: types.Transaction{FileContracts: make([]types.FileContract, 1)},
+		}, modules.CategoryContractFormation},
+		{modules.ProcessedTransaction{
+			Transaction: types.Transaction{FileContractRevisions: make([]types.FileContractRevision, 1)},
+		}, modules.CategoryContractRevision},
+		{modules.ProcessedTransaction{
+			Transaction: types.Transaction{SiafundInputs: make([]types.SiafundInput, 1)},
+		}, modules.CategorySiafundClaim},
+		{modules.ProcessedTransaction{
+			Transaction: types.Transaction{
+				SiacoinInputs:  make([]types.SiacoinInput, 2),
+				SiacoinOutputs: make([]types.SiacoinOutput, 1),
+			},
+			Inputs:  []modules.ProcessedInput{walletInput, walletInput},
+			Outputs: []modules.ProcessedOutput{walletOutput, fee},
+		}, modules.CategoryDefrag},
+		{modules.ProcessedTransaction{
+			Transaction: types.Transaction{
+				SiacoinInputs:  make([]types.SiacoinInput, 1),
+				SiacoinOutputs: make([]types.SiacoinOutput, 2),
+			},
+			Inputs:  []modules.ProcessedInput{walletInput},
+			Outputs: []modules.ProcessedOutput{foreignOutput, walletOutput, fee},
+		}, modules.CategorySend},
+		{modules.ProcessedTransaction{
+			Transaction: types.Transaction{
+				SiacoinInputs:  make([]types.SiacoinInput, 1),
+				SiacoinOutputs: make([]types.SiacoinOutput, 1),
+			},
+			Inputs:  []modules.ProcessedInput{{FundType: types.SpecifierSiacoinInput}},
+			Outputs: []modules.ProcessedOutput{walletOutput},
+		}, modules.CategorySend},
+	}
+	for i, test := range tests {
+		if c := transactionCategory(test.pt); c != test.category {
+			t.Errorf("%v: expected category %v, got %v", i, test.category, c)
+		}
+	}
+}
+
+// TestProcessedTransactionEncoding checks that the category and note of a
+// processed transaction are not part of its encoding.
+func TestProcessedTransactionEncoding(t *testing.T) {
+	pt := modules.ProcessedTransaction{
+		TransactionID:      types.TransactionID{1},
+		ConfirmationHeight: 5,
+		Outputs:            []modules.ProcessedOutput{{FundType: types.SpecifierMinerPayout}},
+		Category:           modules.CategoryMinerPayout,
+		Note:               "note",
+	}
+	b := encoding.Marshal(pt)
+	expected := encoding.MarshalAll(pt.Transaction, pt.TransactionID, pt.ConfirmationHeight,
+		pt.ConfirmationTimestamp, pt.Inputs, pt.Outputs)
+	if string(b) != string(expected) {
+		t.Fatal("encoding of processed transaction changed")
+	}
+	var decoded modules.ProcessedTransaction
+	if err := encoding.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.TransactionID != pt.TransactionID || decoded.ConfirmationHeight != pt.ConfirmationHeight ||
+		len(decoded.Outputs) != 1 || decoded.Category != "" || decoded.Note != "" {
+		t.Fatalf("wrong decoded transaction: %+v", decoded)
+	}
+}
+
+// TestLabelsAndNotes tests labeling addresses and attaching notes to
+// transactions.
+func TestLabelsAndNotes(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Label a foreign address and send coins to it.
+	var dest types.UnlockHash
+	dest[0] = 1
+	if err := wt.wallet.SetAddressLabel(dest, "landlord"); err != nil {
+		t.Fatal(err)
+	}
+	txns, err := wt.wallet.SendSiacoins(types.SiacoinPrecision, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txid := txns[len(txns)-1].ID()
+
+	// Notes can only be attached to transactions of the wallet.
+	if err := wt.wallet.SetTransactionNote(types.TransactionID{1}, "rent"); err != errUnknownTransaction {
+		t.Fatal("expected errUnknownTransaction, got", err)
+	}
+	if err := wt.wallet.SetTransactionNote(txid, "rent"); err != nil {
+		t.Fatal(err)
+	}
+	upts, err := wt.wallet.UnconfirmedTransactions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upts[len(upts)-1].Note != "rent" || upts[len(upts)-1].Category != modules.CategorySend {
+		t.Fatalf("wrong unconfirmed transaction: %v %v", upts[len(upts)-1].Category, upts[len(upts)-1].Note)
+	}
+
+	// The note and labels survive confirmation and restarting the wallet.
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.Close(); err != nil {
+		t.Fatal(err)
+	}
+	w, err := New(wt.cs, wt.tpool, filepath.Join(wt.persistDir, modules.WalletDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet = w
+	if err := wt.wallet.Unlock(wt.walletMasterKey); err != nil {
+		t.Fatal(err)
+	}
+	pt, found, err := wt.wallet.Transaction(txid)
+	if err != nil || !found {
+		t.Fatal("transaction not found", err)
+	} else if pt.Note != "rent" || pt.Category != modules.CategorySend {
+		t.Fatalf("wrong transaction: %v %v", pt.Category, pt.Note)
+	}
+	labels, err := wt.wallet.AddressLabels()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(labels) != 1 || labels[dest] != "landlord" {
+		t.Fatal("wrong labels", labels)
+	}
+
+	// The blocks mined by the tester are miner payouts, the payment is a send.
+	pts, err := wt.wallet.Transactions(0, wt.cs.Height())
+	if err != nil {
+		t.Fatal(err)
+	}
+	categories := make(map[modules.TransactionCategory]int)
+	for _, pt := range pts {
+		categories[pt.Category]++
+	}
+	if categories[modules.CategoryMinerPayout] == 0 || categories[modules.CategorySend] == 0 {
+		t.Fatal("wrong categories", categories)
+	}
+
+	// Empty labels and notes are removed.
+	if err := wt.wallet.SetAddressLabel(dest, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.SetTransactionNote(txid, ""); err != nil {
+		t.Fatal(err)
+	}
+	if labels, err := wt.wallet.AddressLabels(); err != nil || len(labels) != 0 {
+		t.Fatal("label was not removed", labels, err)
+	}
+	if pt, _, err := wt.wallet.Transaction(txid); err != nil || pt.Note != "" {
+		t.Fatal("note was not removed", pt.Note, err)
+	}
+}
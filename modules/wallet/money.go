@@ -27,6 +27,18 @@ func (w *Wallet) DustThreshold() (types.Currency, error) {
 	return minFee.Mul64(3), nil
 }
 
+// sendFee returns the estimated miner fee of a transaction sending siacoins
+// to outputs. Transactions created by SendSiacoinsMulti pay a higher fee per
+// byte, since we don't want send-to-many transactions to fail.
+func (w *Wallet) sendFee(outputs []types.SiacoinOutput, multi bool) types.Currency {
+	_, tpoolFee := w.tpool.FeeEstimation()
+	if !multi {
+		return tpoolFee.Mul64(750) // Estimated transaction size in bytes
+	}
+	tpoolFee = tpoolFee.Mul64(2)
+	return tpoolFee.Mul64(1000 + 60*uint64(len(outputs))) // Estimated transaction size in bytes
+}
+
 // ConfirmedBalance returns the balance of the wallet according to all of the
 // confirmed transactions.
 func (w *Wallet) ConfirmedBalance() (siacoinBalance types.Currency, siafundBalance types.Currency, siafundClaimBalance types.Currency, err error) {
@@ -49,7 +61,12 @@ func (w *Wallet) ConfirmedBalance() (siacoinBalance types.Currency, siafundBalan
 		return
 	}
 
+	// Outputs of multisig addresses are reported separately by
+	// MultisigAddresses, since the wallet can't spend them alone.
 	dbForEachSiacoinOutput(w.dbTx, func(_ types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if _, multisig := w.multisigAddrs[sco.UnlockHash]; multisig {
+			return
+		}
 		if sco.Value.Cmp(dustThreshold) > 0 {
 			siacoinBalance = siacoinBalance.Add(sco.Value)
 		}
@@ -60,6 +77,9 @@ func (w *Wallet) ConfirmedBalance() (siacoinBalance types.Currency, siafundBalan
 		return
 	}
 	dbForEachSiafundOutput(w.dbTx, func(_ types.SiafundOutputID, sfo types.SiafundOutput) {
+		if _, multisig := w.multisigAddrs[sfo.UnlockHash]; multisig {
+			return
+		}
 		siafundBalance = siafundBalance.Add(sfo.Value)
 		if sfo.ClaimStart.Cmp(siafundPool) > 0 {
 			// Skip claims larger than the siafund pool. This should only
@@ -122,12 +142,11 @@ func (w *Wallet) SendSiacoins(amount types.Currency, dest types.UnlockHash) (txn
 		return nil, modules.ErrLockedWallet
 	}
 
-	_, tpoolFee := w.tpool.FeeEstimation()
-	tpoolFee = tpoolFee.Mul64(750) // Estimated transaction size in bytes
 	output := types.SiacoinOutput{
 		Value:      amount,
 		UnlockHash: dest,
 	}
+	tpoolFee := w.sendFee([]types.SiacoinOutput{output}, false)
 
 	txnBuilder, err := w.StartTransaction()
 	if err != nil {
@@ -169,6 +188,13 @@ func (w *Wallet) SendSiacoins(amount types.Currency, dest types.UnlockHash) (txn
 // outputs. The transaction is submitted to the transaction pool and is also
 // returned.
 func (w *Wallet) SendSiacoinsMulti(outputs []types.SiacoinOutput) (txns []types.Transaction, err error) {
+	return w.SendSiacoinsWithCoinControl(outputs, modules.CoinControl{})
+}
+
+// SendSiacoinsWithCoinControl creates a transaction that includes the
+// specified outputs and is funded by outputs selected according to cc. The
+// transaction is submitted to the transaction pool and is also returned.
+func (w *Wallet) SendSiacoinsWithCoinControl(outputs []types.SiacoinOutput, cc modules.CoinControl) (txns []types.Transaction, err error) {
 	w.log.Println("Beginning call to SendSiacoinsMulti")
 	if err := w.tg.Add(); err != nil {
 		err = modules.ErrWalletShutdown
@@ -194,9 +220,7 @@ func (w *Wallet) SendSiacoinsMulti(outputs []types.SiacoinOutput) (txns []types.
 	}()
 
 	// Add estimated transaction fee.
-	_, tpoolFee := w.tpool.FeeEstimation()
-	tpoolFee = tpoolFee.Mul64(2)                              // We don't want send-to-many transactions to fail.
-	tpoolFee = tpoolFee.Mul64(1000 + 60*uint64(len(outputs))) // Estimated transaction size in bytes
+	tpoolFee := w.sendFee(outputs, true)
 	txnBuilder.AddMinerFee(tpoolFee)
 
 	// Calculate total cost to wallet.
@@ -208,7 +232,7 @@ func (w *Wallet) SendSiacoinsMulti(outputs []types.SiacoinOutput) (txns []types.
 	for _, sco := range outputs {
 		totalCost = totalCost.Add(sco.Value)
 	}
-	err = txnBuilder.FundSiacoins(totalCost)
+	err = txnBuilder.FundSiacoinsWithCoinControl(totalCost, cc)
 	if err != nil {
 		return nil, build.ExtendErr("unable to fund transaction", err)
 	}
@@ -0,0 +1,424 @@
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/encoding"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errMismatchedTransactions is returned when combining the signatures of
+	// transactions that differ in more than their signatures.
+	errMismatchedTransactions = errors.New("transactions differ in more than their signatures")
+
+	// errMultisigAddressExists is returned when registering a multisig
+	// address that is already registered.
+	errMultisigAddressExists = errors.New("multisig address is already registered")
+
+	// errMultisigKeyAlgorithm is returned when registering a multisig address
+	// with public keys the wallet can't sign for.
+	errMultisigKeyAlgorithm = errors.New("multisig public keys must be ed25519 keys")
+
+	// errMultisigNoOutputs is returned when building a multisig transaction
+	// without outputs.
+	errMultisigNoOutputs = errors.New("multisig transaction needs at least one output")
+
+	// errMultisigNotCosigner is returned when registering a multisig address
+	// that the wallet doesn't hold any of the secret keys of.
+	errMultisigNotCosigner = errors.New("wallet does not own any of the multisig public keys")
+
+	// errMultisigSignaturesRequired is returned when registering a multisig
+	// address with an invalid number of required signatures.
+	errMultisigSignaturesRequired = errors.New("signatures required must be between 1 and the number of public keys")
+
+	// errMultisigTooFewKeys is returned when registering a multisig address
+	// with less than two public keys.
+	errMultisigTooFewKeys = errors.New("multisig address needs at least two public keys")
+
+	// errNoTransactions is returned when combining the signatures of zero
+	// transactions.
+	errNoTransactions = errors.New("no transactions to combine")
+
+	// errUnknownMultisigAddress is returned when building a transaction for
+	// a multisig address that is not registered with the wallet.
+	errUnknownMultisigAddress = errors.New("multisig address is not registered with the wallet")
+)
+
+// checkMultisigConditions checks that uc describe a valid M-of-N address.
+func checkMultisigConditions(uc types.UnlockConditions) error {
+	if len(uc.PublicKeys) < 2 {
+		return errMultisigTooFewKeys
+	}
+	if uc.SignaturesRequired == 0 || uc.SignaturesRequired > uint64(len(uc.PublicKeys)) {
+		return errMultisigSignaturesRequired
+	}
+	for _, pk := range uc.PublicKeys {
+		if pk.Algorithm != types.SignatureEd25519 || len(pk.Key) != crypto.PublicKeySize {
+			return errMultisigKeyAlgorithm
+		}
+	}
+	return nil
+}
+
+// estimatedInputSize returns the estimated size in bytes that an input with
+// unlock conditions uc and its signatures add to a transaction.
+func estimatedInputSize(uc types.UnlockConditions) uint64 {
+	return uint64(crypto.HashSize+len(encoding.Marshal(uc))) + uc.SignaturesRequired*multisigSignatureSize
+}
+
+// estimatedMultisigSize returns the estimated size in bytes of a transaction
+// spending numInputs outputs of the multisig address with unlock conditions
+// uc.
+func estimatedMultisigSize(uc types.UnlockConditions, numInputs int) uint64 {
+	return multisigBaseSize + uint64(numInputs)*estimatedInputSize(uc)
+}
+
+// secretKey returns the secret key of the wallet that belongs to pk.
+func (w *Wallet) secretKey(pk types.SiaPublicKey) (crypto.SecretKey, bool) {
+	for _, key := range w.keys {
+		for _, upk := range key.UnlockConditions.PublicKeys {
+			if upk.Algorithm != pk.Algorithm || !bytes.Equal(upk.Key, pk.Key) {
+				continue
+			}
+			for _, sk := range key.SecretKeys {
+				pubKey := sk.PublicKey()
+				if bytes.Equal(pubKey[:], pk.Key) {
+					return sk, true
+				}
+			}
+		}
+	}
+	return crypto.SecretKey{}, false
+}
+
+// multisigKey returns a spendable key holding the secret keys of the wallet
+// that can sign for the multisig unlock conditions, along with the indices of
+// their public keys.
+func (w *Wallet) multisigKey(uc types.UnlockConditions) (spendableKey, []uint64) {
+	mk := spendableKey{UnlockConditions: uc}
+	var owned []uint64
+	for i, pk := range uc.PublicKeys {
+		if sk, ok := w.secretKey(pk); ok {
+			mk.SecretKeys = append(mk.SecretKeys, sk)
+			owned = append(owned, uint64(i))
+		}
+	}
+	return mk, owned
+}
+
+// signingKeys returns the spendable keys that can sign the inputs of txn,
+// including the keys of registered multisig addresses.
+func (w *Wallet) signingKeys(txn types.Transaction) map[types.UnlockHash]spendableKey {
+	keys := make(map[types.UnlockHash]spendableKey)
+	addKey := func(uh types.UnlockHash) {
+		if sk, ok := w.keys[uh]; ok {
+			keys[uh] = sk
+		} else if uc, ok := w.multisigAddrs[uh]; ok {
+			keys[uh], _ = w.multisigKey(uc)
+		}
+	}
+	for _, sci := range txn.SiacoinInputs {
+		addKey(sci.UnlockConditions.UnlockHash())
+	}
+	for _, sfi := range txn.SiafundInputs {
+		addKey(sfi.UnlockConditions.UnlockHash())
+	}
+	return keys
+}
+
+// addMultisigSignatures adds a TransactionSignature for every key the wallet
+// owns to the inputs of txn that spend registered multisig addresses, as long
+// as the inputs need more signatures. It returns the parent IDs of the inputs
+// that the wallet can sign.
+func (w *Wallet) addMultisigSignatures(txn *types.Transaction) (toSign []crypto.Hash) {
+	addSignatures := func(parentID crypto.Hash, uh types.UnlockHash) {
+		uc, ok := w.multisigAddrs[uh]
+		if !ok {
+			return
+		}
+		present := make(map[uint64]struct{})
+		for _, sig := range txn.TransactionSignatures {
+			if sig.ParentID == parentID {
+				present[sig.PublicKeyIndex] = struct{}{}
+			}
+		}
+		numSigs := uint64(len(present))
+		canSign := false
+		_, owned := w.multisigKey(uc)
+		for _, i := range owned {
+			if _, exists := present[i]; exists {
+				canSign = true
+				continue
+			}
+			if numSigs >= uc.SignaturesRequired {
+				continue
+			}
+			txn.TransactionSignatures = append(txn.TransactionSignatures, types.TransactionSignature{
+				ParentID:       parentID,
+				CoveredFields:  types.FullCoveredFields,
+				PublicKeyIndex: i,
+			})
+			numSigs++
+			canSign = true
+		}
+		if canSign {
+			toSign = append(toSign, parentID)
+		}
+	}
+	for _, sci := range txn.SiacoinInputs {
+		addSignatures(crypto.Hash(sci.ParentID), sci.UnlockConditions.UnlockHash())
+	}
+	for _, sfi := range txn.SiafundInputs {
+		addSignatures(crypto.Hash(sfi.ParentID), sfi.UnlockConditions.UnlockHash())
+	}
+	return toSign
+}
+
+// AddMultisigAddress registers the M-of-N address of the unlock conditions
+// with the wallet, which must hold the secret key of at least one of its
+// public keys. If the address has not appeared in the blockchain, the unused
+// flag may be set to true. Otherwise, the wallet must rescan the blockchain to
+// search for its outputs.
+func (w *Wallet) AddMultisigAddress(uc types.UnlockConditions, unused bool) (types.UnlockHash, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.UnlockHash{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if err := checkMultisigConditions(uc); err != nil {
+		return types.UnlockHash{}, err
+	}
+	addr := uc.UnlockHash()
+
+	err := func() error {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if !w.unlocked {
+			return modules.ErrLockedWallet
+		}
+		if _, exists := w.multisigAddrs[addr]; exists {
+			return errMultisigAddressExists
+		}
+		if _, owned := w.multisigKey(uc); len(owned) == 0 {
+			return errMultisigNotCosigner
+		}
+
+		if err := dbPutMultisigAddress(w.dbTx, uc); err != nil {
+			return err
+		}
+		if err := dbPutUnlockConditions(w.dbTx, uc); err != nil {
+			return err
+		}
+		w.multisigAddrs[addr] = uc
+		if !unused {
+			if err := w.prepareRescan(); err != nil {
+				return err
+			}
+		}
+		return w.syncDB()
+	}()
+	if err != nil {
+		return types.UnlockHash{}, err
+	}
+	w.log.Printf("Registered %v-of-%v multisig address %v\n", uc.SignaturesRequired, len(uc.PublicKeys), addr)
+
+	if !unused {
+		return addr, w.managedRescan()
+	}
+	return addr, nil
+}
+
+// MultisigAddresses returns the multisig addresses registered with the wallet
+// and their confirmed balances.
+func (w *Wallet) MultisigAddresses() ([]modules.MultisigAddress, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return nil, modules.ErrLockedWallet
+	}
+
+	addrs := make(map[types.UnlockHash]*modules.MultisigAddress)
+	for addr, uc := range w.multisigAddrs {
+		_, owned := w.multisigKey(uc)
+		addrs[addr] = &modules.MultisigAddress{
+			Address:          addr,
+			UnlockConditions: uc,
+			OwnedKeys:        owned,
+		}
+	}
+	err := dbForEachSiacoinOutput(w.dbTx, func(_ types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if ma, ok := addrs[sco.UnlockHash]; ok {
+			ma.SiacoinBalance = ma.SiacoinBalance.Add(sco.Value)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = dbForEachSiafundOutput(w.dbTx, func(_ types.SiafundOutputID, sfo types.SiafundOutput) {
+		if ma, ok := addrs[sfo.UnlockHash]; ok {
+			ma.SiafundBalance = ma.SiafundBalance.Add(sfo.Value)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mas := make([]modules.MultisigAddress, 0, len(addrs))
+	for _, ma := range addrs {
+		mas = append(mas, *ma)
+	}
+	sort.Slice(mas, func(i, j int) bool {
+		return bytes.Compare(mas[i].Address[:], mas[j].Address[:]) < 0
+	})
+	return mas, nil
+}
+
+// BuildMultisigTransaction creates an unsigned transaction that spends
+// confirmed outputs of a registered multisig address to the provided outputs.
+// The change is returned to the multisig address. The spent outputs are not
+// reserved, so building another transaction for the same address before the
+// first one is broadcast results in a double spend.
+func (w *Wallet) BuildMultisigTransaction(addr types.UnlockHash, outputs []types.SiacoinOutput) (types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.Transaction{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if len(outputs) == 0 {
+		return types.Transaction{}, errMultisigNoOutputs
+	}
+	var amount types.Currency
+	for _, sco := range outputs {
+		amount = amount.Add(sco.Value)
+	}
+	_, tpoolFee := w.tpool.FeeEstimation()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return types.Transaction{}, modules.ErrLockedWallet
+	}
+	uc, ok := w.multisigAddrs[addr]
+	if !ok {
+		return types.Transaction{}, errUnknownMultisigAddress
+	}
+	consensusHeight, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	if consensusHeight < uc.Timelock {
+		return types.Transaction{}, errOutputTimelock
+	}
+
+	// Collect the confirmed outputs of the address that are not spent by an
+	// unconfirmed transaction, largest first.
+	pending := make(map[types.OutputID]struct{})
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		for _, input := range pt.Inputs {
+			pending[input.ParentID] = struct{}{}
+		}
+	}
+	var so sortedOutputs
+	err = dbForEachSiacoinOutput(w.dbTx, func(scoid types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if _, spent := pending[types.OutputID(scoid)]; sco.UnlockHash == addr && !spent {
+			so.ids = append(so.ids, scoid)
+			so.outputs = append(so.outputs, sco)
+		}
+	})
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	sort.Sort(sort.Reverse(so))
+
+	// Add inputs until they cover the outputs and the fee, which grows with
+	// every input.
+	txn := types.Transaction{
+		SiacoinOutputs: append([]types.SiacoinOutput(nil), outputs...),
+	}
+	var fund, fee types.Currency
+	for i := range so.ids {
+		txn.SiacoinInputs = append(txn.SiacoinInputs, types.SiacoinInput{
+			ParentID:         so.ids[i],
+			UnlockConditions: uc,
+		})
+		fund = fund.Add(so.outputs[i].Value)
+		fee = tpoolFee.Mul64(estimatedMultisigSize(uc, len(txn.SiacoinInputs)))
+		if fund.Cmp(amount.Add(fee)) >= 0 {
+			break
+		}
+	}
+	if fund.Cmp(amount.Add(fee)) < 0 {
+		return types.Transaction{}, modules.ErrLowBalance
+	}
+	txn.MinerFees = []types.Currency{fee}
+	if change := fund.Sub(amount).Sub(fee); !change.IsZero() {
+		txn.SiacoinOutputs = append(txn.SiacoinOutputs, types.SiacoinOutput{
+			Value:      change,
+			UnlockHash: addr,
+		})
+	}
+	return txn, nil
+}
+
+// CombineSignatures merges the signatures of copies of a transaction that
+// were signed by different co-signers. All copies must be identical apart
+// from their signatures. Empty signatures and signatures in excess of the
+// number required by an input are dropped.
+func CombineSignatures(txns []types.Transaction) (types.Transaction, error) {
+	if len(txns) == 0 {
+		return types.Transaction{}, errNoTransactions
+	}
+	unsigned := func(txn types.Transaction) []byte {
+		txn.TransactionSignatures = nil
+		return encoding.Marshal(txn)
+	}
+	base := unsigned(txns[0])
+	for _, txn := range txns[1:] {
+		if !bytes.Equal(unsigned(txn), base) {
+			return types.Transaction{}, errMismatchedTransactions
+		}
+	}
+
+	// Determine how many signatures each input needs.
+	required := make(map[crypto.Hash]uint64)
+	for _, sci := range txns[0].SiacoinInputs {
+		required[crypto.Hash(sci.ParentID)] = sci.UnlockConditions.SignaturesRequired
+	}
+	for _, sfi := range txns[0].SiafundInputs {
+		required[crypto.Hash(sfi.ParentID)] = sfi.UnlockConditions.SignaturesRequired
+	}
+	for _, fcr := range txns[0].FileContractRevisions {
+		required[crypto.Hash(fcr.ParentID)] = fcr.UnlockConditions.SignaturesRequired
+	}
+
+	type sigID struct {
+		parentID       crypto.Hash
+		publicKeyIndex uint64
+	}
+	seen := make(map[sigID]struct{})
+	numSigs := make(map[crypto.Hash]uint64)
+	combined := txns[0]
+	combined.TransactionSignatures = nil
+	for _, txn := range txns {
+		for _, sig := range txn.TransactionSignatures {
+			id := sigID{sig.ParentID, sig.PublicKeyIndex}
+			if _, exists := seen[id]; exists || len(sig.Signature) == 0 {
+				continue
+			}
+			if req, ok := required[sig.ParentID]; ok && numSigs[sig.ParentID] >= req {
+				continue
+			}
+			seen[id] = struct{}{}
+			numSigs[sig.ParentID]++
+			combined.TransactionSignatures = append(combined.TransactionSignatures, sig)
+		}
+	}
+	return combined, nil
+}
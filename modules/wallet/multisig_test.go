@@ -0,0 +1,189 @@
+package wallet
+
+import (
+	"strings"
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/fastrand"
+)
+
+// TestMultisig registers a 2-of-3 address with two wallets, funds it, and
+// spends from it by combining the signatures of both wallets.
+func TestMultisig(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Create a second wallet that acts as the co-signer.
+	w2, err := New(wt.cs, wt.tpool, build.TempDir(modules.WalletDir, t.Name()+"2", modules.WalletDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w2.Close()
+	var masterKey crypto.TwofishKey
+	fastrand.Read(masterKey[:])
+	if _, err := w2.Encrypt(masterKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := w2.Unlock(masterKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// Build the unlock conditions from one key of each wallet and a third
+	// key that is not used.
+	uc1, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc2, err := w2.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, pk3 := crypto.GenerateKeyPair()
+	uc := types.UnlockConditions{
+		PublicKeys:         []types.SiaPublicKey{uc1.PublicKeys[0], uc2.PublicKeys[0], types.Ed25519PublicKey(pk3)},
+		SignaturesRequired: 2,
+	}
+
+	// Invalid unlock conditions should be rejected.
+	invalid := uc
+	invalid.PublicKeys = uc.PublicKeys[:1]
+	if _, err := wt.wallet.AddMultisigAddress(invalid, true); err != errMultisigTooFewKeys {
+		t.Fatal("expected errMultisigTooFewKeys, got", err)
+	}
+	invalid = uc
+	invalid.SignaturesRequired = 4
+	if _, err := wt.wallet.AddMultisigAddress(invalid, true); err != errMultisigSignaturesRequired {
+		t.Fatal("expected errMultisigSignaturesRequired, got", err)
+	}
+	invalid = uc
+	invalid.PublicKeys = []types.SiaPublicKey{uc2.PublicKeys[0], types.Ed25519PublicKey(pk3)}
+	if _, err := wt.wallet.AddMultisigAddress(invalid, true); err != errMultisigNotCosigner {
+		t.Fatal("expected errMultisigNotCosigner, got", err)
+	}
+
+	// Register the address with both wallets.
+	addr, err := wt.wallet.AddMultisigAddress(uc, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr != uc.UnlockHash() {
+		t.Fatal("wrong multisig address", addr)
+	}
+	if _, err := wt.wallet.AddMultisigAddress(uc, true); err != errMultisigAddressExists {
+		t.Fatal("expected errMultisigAddressExists, got", err)
+	}
+	if _, err := w2.AddMultisigAddress(uc, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fund the address. The outputs of the address should be tracked, but
+	// not count towards the balance of the co-signer, which has no funds of
+	// its own.
+	funds := types.SiacoinPrecision.Mul64(100)
+	if _, err := wt.wallet.SendSiacoins(funds, addr); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	balance, _, _, err := w2.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !balance.IsZero() {
+		t.Fatal("wallet balance includes the multisig outputs", balance)
+	}
+	mas, err := wt.wallet.MultisigAddresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mas) != 1 || mas[0].Address != addr || !mas[0].SiacoinBalance.Equals(funds) {
+		t.Fatal("multisig address not tracked", mas)
+	}
+	if len(mas[0].OwnedKeys) != 1 || mas[0].OwnedKeys[0] != 0 {
+		t.Fatal("wrong owned keys", mas[0].OwnedKeys)
+	}
+
+	// The wallet should not use the multisig outputs to fund its own
+	// transactions.
+	if _, err := w2.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{}); err == nil || !strings.Contains(err.Error(), modules.ErrLowBalance.Error()) {
+		t.Fatal("expected ErrLowBalance, got", err)
+	}
+
+	// Build a transaction that spends from the address.
+	amount := types.SiacoinPrecision.Mul64(10)
+	txn, err := wt.wallet.BuildMultisigTransaction(addr, []types.SiacoinOutput{{Value: amount}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.BuildMultisigTransaction(addr, []types.SiacoinOutput{{Value: funds}}); err != modules.ErrLowBalance {
+		t.Fatal("expected ErrLowBalance, got", err)
+	}
+
+	// Each wallet signs its own copy of the transaction. A single signature
+	// is not enough.
+	txn1, txn2 := txn, txn
+	if err := wt.wallet.SignTransaction(&txn1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := w2.SignTransaction(&txn2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(txn1.TransactionSignatures) != 1 || len(txn2.TransactionSignatures) != 1 {
+		t.Fatal("expected a single signature per wallet", txn1.TransactionSignatures, txn2.TransactionSignatures)
+	}
+	height, _ := wt.wallet.Height()
+	if err := txn1.StandaloneValid(height); err == nil {
+		t.Fatal("transaction with a single signature should be invalid")
+	}
+
+	// Combining the signatures results in a valid transaction.
+	if _, err := CombineSignatures([]types.Transaction{txn1, {}}); err != errMismatchedTransactions {
+		t.Fatal("expected errMismatchedTransactions, got", err)
+	}
+	combined, err := CombineSignatures([]types.Transaction{txn1, txn2, txn1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(combined.TransactionSignatures) != 2 {
+		t.Fatal("expected two signatures, got", len(combined.TransactionSignatures))
+	}
+	if err := combined.StandaloneValid(height); err != nil {
+		t.Fatal(err)
+	}
+
+	// Signing sequentially results in the same transaction.
+	if err := w2.SignTransaction(&txn1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn1.StandaloneValid(height); err != nil {
+		t.Fatal(err)
+	}
+
+	// Broadcast the transaction; the change should be returned to the
+	// multisig address.
+	if err := wt.tpool.AcceptTransactionSet([]types.Transaction{combined}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	mas, err = w2.MultisigAddresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := funds.Sub(amount).Sub(combined.MinerFees[0])
+	if len(mas) != 1 || !mas[0].SiacoinBalance.Equals(expected) {
+		t.Fatal("wrong multisig balance after spending", mas, expected)
+	}
+}
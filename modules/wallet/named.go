@@ -0,0 +1,408 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"gitlab.com/NebulousLabs/errors"
+)
+
+var (
+	// errInvalidWalletName is returned when creating a named wallet with a
+	// name that can't be used as a directory name on every platform.
+	errInvalidWalletName = errors.New("wallet names must be 1-64 characters long and may only contain letters, numbers, '-' and '_'")
+
+	// errNamedWalletExists is returned when creating a named wallet that
+	// already exists.
+	errNamedWalletExists = errors.New("a wallet with that name already exists")
+
+	// errNamedWalletInUse is returned when deleting a named wallet that still
+	// has coins or unconfirmed transactions.
+	errNamedWalletInUse = errors.New("wallet still has coins or unconfirmed transactions")
+
+	// errNamedWalletNotFound is returned when a named wallet doesn't exist.
+	errNamedWalletNotFound = errors.New("wallet not found")
+
+	// errReservedWalletName is returned when creating a named wallet whose
+	// name is reserved.
+	errReservedWalletName = errors.New("wallet name is reserved by a wallet endpoint")
+
+	// errNamedWalletsUnsupported is returned when trying to manage the named
+	// wallets of a wallet that can't create them, e.g. because it is a named
+	// wallet itself.
+	errNamedWalletsUnsupported = errors.New("this wallet doesn't support named wallets")
+
+	// walletNameRegexp matches valid wallet names.
+	walletNameRegexp = regexp.MustCompile("^[a-zA-Z0-9_-]{1,64}$")
+)
+
+// ReservedWalletNames contains the first path segments of the wallet API
+// endpoints, e.g. "seeds" for /wallet/seeds. Named wallets can't use them as
+// their name since they couldn't be selected using the /wallet/<name> path
+// prefix.
+var ReservedWalletNames = map[string]struct{}{
+	"033x":             {},
+	"address":          {},
+	"addresses":        {},
+	"backup":           {},
+	"bump":             {},
+	"changepassword":   {},
+	"freeze":           {},
+	"frozen":           {},
+	"init":             {},
+	"labels":           {},
+	"lock":             {},
+	"multisig":         {},
+	"note":             {},
+	"pst":              {},
+	"schedule":         {},
+	"seed":             {},
+	"seeds":            {},
+	"siacoins":         {},
+	"siafunds":         {},
+	"siagkey":          {},
+	"sign":             {},
+	"sweep":            {},
+	"timelocked":       {},
+	"transaction":      {},
+	"transactions":     {},
+	"unfreeze":         {},
+	"unlock":           {},
+	"unlockconditions": {},
+	"unspent":          {},
+	"verify":           {},
+	"watch":            {},
+	"watchkeys":        {},
+	"webhooks":         {},
+}
+
+// namedWalletSet contains the named wallets of a wallet. The set subscribes
+// to the consensus set and the transaction pool once for all named wallets,
+// when the first named wallet has caught up, and passes the updates on to the
+// named wallets that follow it.
+type namedWalletSet struct {
+	dir     string
+	wallets map[string]*Wallet
+	mu      sync.Mutex
+
+	followers map[*namedWalletFeed]struct{}
+	followMu  sync.Mutex
+
+	subscribed  bool
+	subscribeMu sync.Mutex
+}
+
+// namedWalletFeed passes the consensus changes and transaction pool updates
+// to a named wallet. While the named wallet catches up, it is subscribed
+// itself until it follows the named wallet set, so it can receive an update
+// from both. The feed drops the second copy, which always arrives right after
+// the first one because the consensus set and the transaction pool send each
+// update to all of their subscribers before sending the next one.
+type namedWalletFeed struct {
+	lastChange modules.ConsensusChangeID
+	lastDiff   *modules.TransactionPoolDiff
+	mu         sync.Mutex
+
+	staticSet    *namedWalletSet
+	staticWallet *Wallet
+}
+
+// ProcessConsensusChange passes the consensus change on to the named wallet,
+// unless the wallet has already received it.
+func (f *namedWalletFeed) ProcessConsensusChange(cc modules.ConsensusChange) {
+	f.mu.Lock()
+	received := cc.ID == f.lastChange
+	f.lastChange = cc.ID
+	f.mu.Unlock()
+	if !received {
+		f.staticWallet.ProcessConsensusChange(cc)
+	}
+}
+
+// ReceiveUpdatedUnconfirmedTransactions passes the transaction pool update on
+// to the named wallet, unless the wallet has already received it.
+func (f *namedWalletFeed) ReceiveUpdatedUnconfirmedTransactions(diff *modules.TransactionPoolDiff) {
+	f.mu.Lock()
+	received := diff == f.lastDiff
+	f.lastDiff = diff
+	f.mu.Unlock()
+	if !received {
+		f.staticWallet.ReceiveUpdatedUnconfirmedTransactions(diff)
+	}
+}
+
+// managedCatchUp subscribes the feed to the consensus set, starting after the
+// given consensus change, and to the transaction pool. Once the named wallet
+// has caught up, the feed follows the named wallet set and unsubscribes
+// again.
+func (f *namedWalletFeed) managedCatchUp(start modules.ConsensusChangeID) error {
+	f.mu.Lock()
+	f.lastChange = modules.ConsensusChangeID{}
+	f.lastDiff = nil
+	f.mu.Unlock()
+
+	w := f.staticWallet
+	if err := w.cs.ConsensusSetSubscribe(f, start, w.tg.StopChan()); err != nil {
+		return err
+	}
+	w.tpool.TransactionPoolSubscribe(f)
+	err := f.staticSet.managedFollow(f)
+	w.cs.Unsubscribe(f)
+	w.tpool.Unsubscribe(f)
+	return err
+}
+
+// ProcessConsensusChange passes the consensus change on to the named wallets
+// that follow the set.
+func (s *namedWalletSet) ProcessConsensusChange(cc modules.ConsensusChange) {
+	for _, f := range s.managedFollowers() {
+		f.ProcessConsensusChange(cc)
+	}
+}
+
+// ReceiveUpdatedUnconfirmedTransactions passes the transaction pool update on
+// to the named wallets that follow the set.
+func (s *namedWalletSet) ReceiveUpdatedUnconfirmedTransactions(diff *modules.TransactionPoolDiff) {
+	for _, f := range s.managedFollowers() {
+		f.ReceiveUpdatedUnconfirmedTransactions(diff)
+	}
+}
+
+// managedFollow adds a feed to the followers of the set, subscribing the set
+// if it isn't subscribed yet. The subscription starts at the most recent
+// consensus change since the named wallets catch up on their own.
+func (s *namedWalletSet) managedFollow(f *namedWalletFeed) error {
+	s.subscribeMu.Lock()
+	defer s.subscribeMu.Unlock()
+	if !s.subscribed {
+		w := f.staticWallet
+		if err := w.cs.ConsensusSetSubscribe(s, modules.ConsensusChangeRecent, w.tg.StopChan()); err != nil {
+			return err
+		}
+		w.tpool.TransactionPoolSubscribe(s)
+		s.subscribed = true
+	}
+
+	s.followMu.Lock()
+	defer s.followMu.Unlock()
+	s.followers[f] = struct{}{}
+	return nil
+}
+
+// managedUnfollow removes a feed from the followers of the set.
+func (s *namedWalletSet) managedUnfollow(f *namedWalletFeed) {
+	s.followMu.Lock()
+	defer s.followMu.Unlock()
+	delete(s.followers, f)
+}
+
+// managedFollowers returns the followers of the set. The followers are copied
+// so that the named wallets aren't updated while holding followMu.
+func (s *namedWalletSet) managedFollowers() []*namedWalletFeed {
+	s.followMu.Lock()
+	defer s.followMu.Unlock()
+	followers := make([]*namedWalletFeed, 0, len(s.followers))
+	for f := range s.followers {
+		followers = append(followers, f)
+	}
+	return followers
+}
+
+// NamedWallet returns the named wallet of w with the given name, creating it
+// if it doesn't exist yet. If name is empty, w itself is returned.
+func NamedWallet(w modules.Wallet, name string) (modules.Wallet, error) {
+	if name == "" {
+		return w, nil
+	}
+	// Guard against a nil *Wallet, which doesn't compare equal to nil once
+	// it is stored in the interface.
+	if pw, ok := w.(*Wallet); w == nil || (ok && pw == nil) {
+		return nil, errNamedWalletsUnsupported
+	}
+	if nw, exists := w.NamedWallet(name); exists {
+		return nw, nil
+	}
+	return w.CreateNamedWallet(name)
+}
+
+// managedNewNamedWallet creates the named wallet with the given name, loading
+// its persisted data if it exists.
+func (w *Wallet) managedNewNamedWallet(name string) (*Wallet, error) {
+	nw, err := newWallet(w.cs, w.tpool, filepath.Join(w.staticNamed.dir, name), w.deps)
+	if err != nil {
+		return nil, err
+	}
+	nw.staticFeed = &namedWalletFeed{
+		staticSet:    w.staticNamed,
+		staticWallet: nw,
+	}
+	return nw, nil
+}
+
+// managedLoadNamedWallets loads the named wallets found in the named wallets
+// directory.
+func (w *Wallet) managedLoadNamedWallets() error {
+	infos, err := ioutil.ReadDir(w.staticNamed.dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if !info.IsDir() || !walletNameRegexp.MatchString(info.Name()) {
+			continue
+		}
+		nw, err := w.managedNewNamedWallet(info.Name())
+		if err != nil {
+			return errors.AddContext(err, "unable to load wallet "+info.Name())
+		}
+		w.staticNamed.mu.Lock()
+		w.staticNamed.wallets[info.Name()] = nw
+		w.staticNamed.mu.Unlock()
+	}
+	return nil
+}
+
+// managedCloseNamedWallets closes all named wallets of the wallet and
+// unsubscribes the named wallet set.
+func (w *Wallet) managedCloseNamedWallets() error {
+	if w.staticNamed == nil {
+		return nil
+	}
+	w.staticNamed.mu.Lock()
+	var errs []error
+	for _, nw := range w.staticNamed.wallets {
+		errs = append(errs, nw.Close())
+	}
+	w.staticNamed.mu.Unlock()
+
+	// The named wallets are closed, so they can't subscribe the set again.
+	w.staticNamed.subscribeMu.Lock()
+	defer w.staticNamed.subscribeMu.Unlock()
+	if w.staticNamed.subscribed {
+		w.cs.Unsubscribe(w.staticNamed)
+		w.tpool.Unsubscribe(w.staticNamed)
+		w.staticNamed.subscribed = false
+	}
+	return errors.Compose(errs...)
+}
+
+// managedInUse returns true if the wallet tracks any outputs, including dust
+// and multisig outputs, or has unconfirmed transactions.
+func (w *Wallet) managedInUse() (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.syncDB(); err != nil {
+		return false, err
+	}
+	var outputs bool
+	dbForEachSiacoinOutput(w.dbTx, func(types.SiacoinOutputID, types.SiacoinOutput) {
+		outputs = true
+	})
+	dbForEachSiafundOutput(w.dbTx, func(types.SiafundOutputID, types.SiafundOutput) {
+		outputs = true
+	})
+	return outputs || len(w.unconfirmedProcessedTransactions) != 0, nil
+}
+
+// CreateNamedWallet creates a new named wallet with the given name. The
+// named wallet has its own seed, encryption key and database, and needs to
+// be initialized and unlocked like the primary wallet.
+func (w *Wallet) CreateNamedWallet(name string) (modules.Wallet, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if w.staticNamed == nil {
+		return nil, errNamedWalletsUnsupported
+	}
+	if !walletNameRegexp.MatchString(name) {
+		return nil, errInvalidWalletName
+	}
+	if _, reserved := ReservedWalletNames[name]; reserved {
+		return nil, errReservedWalletName
+	}
+
+	// Hold the lock while creating the wallet to prevent creating the same
+	// wallet twice.
+	w.staticNamed.mu.Lock()
+	defer w.staticNamed.mu.Unlock()
+	if _, exists := w.staticNamed.wallets[name]; exists {
+		return nil, errNamedWalletExists
+	}
+	nw, err := w.managedNewNamedWallet(name)
+	if err != nil {
+		return nil, err
+	}
+	w.staticNamed.wallets[name] = nw
+	w.log.Println("Created named wallet", name)
+	return nw, nil
+}
+
+// DeleteNamedWallet closes the named wallet with the given name and deletes
+// its persisted data, including its seed. To avoid losing money, the wallet
+// must not have any outputs or unconfirmed transactions left.
+func (w *Wallet) DeleteNamedWallet(name string) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if w.staticNamed == nil {
+		return errNamedWalletsUnsupported
+	}
+
+	w.staticNamed.mu.Lock()
+	defer w.staticNamed.mu.Unlock()
+	nw, exists := w.staticNamed.wallets[name]
+	if !exists {
+		return errNamedWalletNotFound
+	}
+	inUse, err := nw.managedInUse()
+	if err != nil {
+		return err
+	} else if inUse {
+		return errNamedWalletInUse
+	}
+	if err := nw.Close(); err != nil {
+		return errors.AddContext(err, "unable to close wallet")
+	}
+	delete(w.staticNamed.wallets, name)
+	w.log.Println("Deleted named wallet", name)
+	return os.RemoveAll(filepath.Join(w.staticNamed.dir, name))
+}
+
+// NamedWallet returns the named wallet with the given name.
+func (w *Wallet) NamedWallet(name string) (modules.Wallet, bool) {
+	if w.staticNamed == nil {
+		return nil, false
+	}
+	w.staticNamed.mu.Lock()
+	defer w.staticNamed.mu.Unlock()
+	nw, exists := w.staticNamed.wallets[name]
+	if !exists {
+		return nil, false
+	}
+	return nw, true
+}
+
+// NamedWallets returns the names of all named wallets in alphabetical order.
+func (w *Wallet) NamedWallets() []string {
+	if w.staticNamed == nil {
+		return nil
+	}
+	w.staticNamed.mu.Lock()
+	defer w.staticNamed.mu.Unlock()
+	names := make([]string, 0, len(w.staticNamed.wallets))
+	for name := range w.staticNamed.wallets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,155 @@
+package wallet
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/fastrand"
+)
+
+// TestNamedWallets tests creating, using, reloading and deleting named
+// wallets.
+func TestNamedWallets(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	if _, err := wt.wallet.CreateNamedWallet("in/valid"); err != errInvalidWalletName {
+		t.Fatal("expected errInvalidWalletName, got", err)
+	}
+	if _, err := wt.wallet.CreateNamedWallet("seeds"); err != errReservedWalletName {
+		t.Fatal("expected errReservedWalletName, got", err)
+	}
+	var nilWallet *Wallet
+	if _, err := NamedWallet(nilWallet, "cold"); err != errNamedWalletsUnsupported {
+		t.Fatal("expected errNamedWalletsUnsupported, got", err)
+	}
+	nw, err := wt.wallet.CreateNamedWallet("cold")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.CreateNamedWallet("cold"); err != errNamedWalletExists {
+		t.Fatal("expected errNamedWalletExists, got", err)
+	}
+	if _, err := nw.CreateNamedWallet("nested"); err != errNamedWalletsUnsupported {
+		t.Fatal("expected errNamedWalletsUnsupported, got", err)
+	}
+	if _, err := NamedWallet(wt.wallet, "empty"); err != nil {
+		t.Fatal(err)
+	}
+	if names := wt.wallet.NamedWallets(); !reflect.DeepEqual(names, []string{"cold", "empty"}) {
+		t.Fatal("wrong named wallets", names)
+	}
+
+	// The named wallet has its own seed and lock state.
+	if encrypted, err := nw.Encrypted(); err != nil || encrypted {
+		t.Fatal("new named wallet shouldn't be encrypted", err)
+	}
+	var key crypto.TwofishKey
+	fastrand.Read(key[:])
+	seed, err := nw.Encrypt(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	primarySeed, _, err := wt.wallet.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seed == primarySeed {
+		t.Fatal("named wallet uses the primary seed")
+	}
+	if err := nw.Unlock(key); err != nil {
+		t.Fatal(err)
+	}
+	if err := nw.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if unlocked, err := wt.wallet.Unlocked(); err != nil || !unlocked {
+		t.Fatal("locking the named wallet locked the primary wallet", err)
+	}
+	if err := nw.Unlock(key); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once it has caught up, the named wallet receives the updates through
+	// the subscriptions of the primary wallet.
+	if _, following := wt.wallet.staticNamed.followers[nw.(*Wallet).staticFeed]; !following {
+		t.Fatal("named wallet doesn't follow the primary wallet")
+	}
+
+	// Pay the named wallet from the primary wallet.
+	uc, err := nw.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision, uc.UnlockHash()); err != nil {
+		t.Fatal(err)
+	}
+	if txns, err := nw.UnconfirmedTransactions(); err != nil || len(txns) != 1 {
+		t.Fatal("named wallet should have one unconfirmed transaction", len(txns), err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	if height, err := nw.Height(); err != nil || height != wt.cs.Height() {
+		t.Fatal("named wallet didn't process the block", height, err)
+	}
+	sc, _, _, err := nw.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sc.Equals(types.SiacoinPrecision) {
+		t.Fatal("wrong balance of the named wallet", sc)
+	}
+
+	// Only wallets without coins can be deleted.
+	if err := wt.wallet.DeleteNamedWallet("cold"); err != errNamedWalletInUse {
+		t.Fatal("expected errNamedWalletInUse, got", err)
+	}
+	if err := wt.wallet.DeleteNamedWallet("empty"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(wt.persistDir, modules.WalletsDir, "empty")); !os.IsNotExist(err) {
+		t.Fatal("persist dir of the deleted wallet wasn't removed", err)
+	}
+	if err := wt.wallet.DeleteNamedWallet("empty"); err != errNamedWalletNotFound {
+		t.Fatal("expected errNamedWalletNotFound, got", err)
+	}
+
+	// The named wallet is loaded when the wallet is reopened.
+	if err := wt.wallet.Close(); err != nil {
+		t.Fatal(err)
+	}
+	w, err := New(wt.cs, wt.tpool, filepath.Join(wt.persistDir, modules.WalletDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet = w
+	nw, exists := w.NamedWallet("cold")
+	if !exists {
+		t.Fatal("named wallet wasn't loaded")
+	}
+	if err := nw.Unlock(key); err != nil {
+		t.Fatal(err)
+	}
+	if height, err := nw.Height(); err != nil || height != wt.cs.Height() {
+		t.Fatal("reloaded named wallet didn't catch up", height, err)
+	}
+	sc, _, _, err = nw.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sc.Equals(types.SiacoinPrecision) {
+		t.Fatal("wrong balance of the reloaded named wallet", sc)
+	}
+}
@@ -0,0 +1,649 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"github.com/coreos/bbolt"
+)
+
+// WebhookSignatureHeader is the HTTP header that contains the signature of a
+// webhook event: the hex-encoded HMAC-SHA256 of the body, keyed with the
+// secret of the webhook.
+const WebhookSignatureHeader = "Sia-Webhook-Signature"
+
+var (
+	// errUnknownWebhook is returned when referring to a webhook that is not
+	// registered.
+	errUnknownWebhook = errors.New("no webhook with that ID is registered")
+
+	// errWebhookURL is returned when registering a webhook without an HTTP
+	// or HTTPS URL.
+	errWebhookURL = errors.New("webhook needs an http or https URL")
+)
+
+// webhookDeliveryKey returns the key of the delivery of an event to a webhook.
+func webhookDeliveryKey(webhookID, eventID crypto.Hash) crypto.Hash {
+	return crypto.HashAll(webhookID, eventID)
+}
+
+// webhookEventID returns the ID of the event of type typ about the
+// transaction txid confirmed at height.
+func webhookEventID(typ modules.WebhookEventType, txid types.TransactionID, height types.BlockHeight) crypto.Hash {
+	return crypto.HashAll(typ, txid, height)
+}
+
+// webhookWants returns whether events of type typ are delivered to wh.
+func webhookWants(wh modules.Webhook, typ modules.WebhookEventType) bool {
+	if len(wh.Events) == 0 {
+		return true
+	}
+	for _, t := range wh.Events {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookMatches returns the inputs and outputs of a transaction that are
+// related to the addresses watched by wh.
+func webhookMatches(wh modules.Webhook, pt modules.ProcessedTransaction) (inputs []modules.ProcessedInput, outputs []modules.ProcessedOutput) {
+	watched := func(addr types.UnlockHash, walletAddress bool) bool {
+		if len(wh.Addresses) == 0 {
+			return walletAddress
+		}
+		for _, a := range wh.Addresses {
+			if a == addr {
+				return true
+			}
+		}
+		return false
+	}
+	for _, input := range pt.Inputs {
+		if watched(input.RelatedAddress, input.WalletAddress) {
+			inputs = append(inputs, input)
+		}
+	}
+	for _, output := range pt.Outputs {
+		if output.FundType != types.SpecifierMinerFee && watched(output.RelatedAddress, output.WalletAddress) {
+			outputs = append(outputs, output)
+		}
+	}
+	return inputs, outputs
+}
+
+// webhookSignature returns the signature of an event body.
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// dbWebhooks returns the registered webhooks.
+func dbWebhooks(tx *bolt.Tx) ([]modules.Webhook, error) {
+	var whs []modules.Webhook
+	err := dbForEachWebhook(tx, func(_ crypto.Hash, wh modules.Webhook) {
+		whs = append(whs, wh)
+	})
+	return whs, err
+}
+
+// queueWebhookEvent adds an event about pt to the delivery log of every
+// webhook that watches an address related to it. Events that were queued
+// before, for example while the blockchain is rescanned, are not queued
+// again, and neither are events of transactions whose deliveries may have
+// been pruned. The wallet lock must be held.
+func (w *Wallet) queueWebhookEvent(tx *bolt.Tx, whs []modules.Webhook, typ modules.WebhookEventType, pt modules.ProcessedTransaction, confirmations types.BlockHeight) error {
+	pruneHeight, err := dbGetWebhookPruneHeight(tx)
+	if err != nil {
+		return err
+	}
+	if typ != modules.WebhookPending && pt.ConfirmationHeight <= pruneHeight {
+		return nil
+	}
+	event := modules.WebhookEvent{
+		ID:                 webhookEventID(typ, pt.TransactionID, pt.ConfirmationHeight),
+		Type:               typ,
+		TransactionID:      pt.TransactionID,
+		ConfirmationHeight: pt.ConfirmationHeight,
+		Confirmations:      confirmations,
+		Timestamp:          types.CurrentTimestamp(),
+	}
+	for _, wh := range whs {
+		if !webhookWants(wh, typ) {
+			continue
+		} else if typ != modules.WebhookPending && pt.ConfirmationHeight <= wh.Height {
+			continue
+		}
+		inputs, outputs := webhookMatches(wh, pt)
+		if len(inputs) == 0 && len(outputs) == 0 {
+			continue
+		}
+		// Only payments to the watched addresses are pending or received.
+		incoming := len(inputs) == 0 && len(outputs) > 0
+		if (typ == modules.WebhookPending || typ == modules.WebhookReceived) && !incoming {
+			continue
+		}
+
+		key := webhookDeliveryKey(wh.ID, event.ID)
+		if _, err := dbGetWebhookDelivery(tx, key); err == nil {
+			continue
+		} else if err != errNoKey {
+			return err
+		}
+		event.Inputs, event.Outputs = inputs, outputs
+		err := dbPutWebhookDelivery(tx, key, modules.WebhookDelivery{
+			WebhookID: wh.ID,
+			Event:     event,
+		})
+		if err != nil {
+			return err
+		}
+		w.log.Debugln("Queued", typ, "event of transaction", pt.TransactionID, "for webhook", wh.URL)
+		select {
+		case w.webhookWake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// retireWebhookEvents moves the deliveries of the events of the given types
+// about pt to new keys, so that the events are queued again if they occur
+// again. The deliveries stay in the delivery logs and are still delivered if
+// they weren't yet. The wallet lock must be held.
+func (w *Wallet) retireWebhookEvents(tx *bolt.Tx, whs []modules.Webhook, pt modules.ProcessedTransaction, typs ...modules.WebhookEventType) error {
+	for _, typ := range typs {
+		id := webhookEventID(typ, pt.TransactionID, pt.ConfirmationHeight)
+		for _, wh := range whs {
+			key := webhookDeliveryKey(wh.ID, id)
+			wd, err := dbGetWebhookDelivery(tx, key)
+			if err == errNoKey {
+				continue
+			} else if err != nil {
+				return err
+			}
+			var newKey crypto.Hash
+			fastrand.Read(newKey[:])
+			if err := dbPutWebhookDelivery(tx, newKey, wd); err != nil {
+				return err
+			}
+			if err := dbDeleteWebhookDelivery(tx, key); err != nil {
+				return err
+			}
+			if retry, ok := w.webhookRetries[key]; ok {
+				w.webhookRetries[newKey] = retry
+				delete(w.webhookRetries, key)
+			}
+		}
+	}
+	return nil
+}
+
+// webhookRevertedTransactions returns the transactions that are reverted by
+// the reverted blocks of a consensus change. It must be called before the
+// history of the blocks is reverted.
+func (w *Wallet) webhookRevertedTransactions(tx *bolt.Tx, reverted []types.Block) ([]modules.ProcessedTransaction, error) {
+	var blocks types.BlockHeight
+	for _, block := range reverted {
+		if block.ID() != types.GenesisID {
+			blocks++
+		}
+	}
+	if blocks == 0 {
+		return nil, nil
+	}
+	height, err := dbGetConsensusHeight(tx)
+	if err != nil {
+		return nil, err
+	}
+	return dbProcessedTransactionsSince(tx, height-blocks+1)
+}
+
+// queueWebhookEvents queues the events caused by a consensus change, after
+// the history of its blocks was applied. reverted are the transactions
+// returned by webhookRevertedTransactions.
+func (w *Wallet) queueWebhookEvents(tx *bolt.Tx, cc modules.ConsensusChange, reverted []modules.ProcessedTransaction) error {
+	whs, err := dbWebhooks(tx)
+	if err != nil || len(whs) == 0 {
+		return err
+	}
+	// A reverted transaction may be confirmed again at the same height, and
+	// be reverted again after that. The events of its previous confirmation
+	// are retired so that both are delivered again.
+	for _, pt := range reverted {
+		if err := w.retireWebhookEvents(tx, whs, pt, modules.WebhookReceived, modules.WebhookConfirmed); err != nil {
+			return err
+		}
+		if err := w.queueWebhookEvent(tx, whs, modules.WebhookReverted, pt, 0); err != nil {
+			return err
+		}
+	}
+
+	// The transactions of the applied blocks were received.
+	height, err := dbGetConsensusHeight(tx)
+	if err != nil {
+		return err
+	}
+	var applied types.BlockHeight
+	for _, block := range cc.AppliedBlocks {
+		if block.ID() != types.GenesisID {
+			applied++
+		}
+	}
+	prevHeight := height - applied
+	received, err := dbProcessedTransactionsSince(tx, prevHeight+1)
+	if err != nil {
+		return err
+	}
+	for _, pt := range received {
+		if err := w.retireWebhookEvents(tx, whs, pt, modules.WebhookReverted); err != nil {
+			return err
+		}
+		if err := w.queueWebhookEvent(tx, whs, modules.WebhookReceived, pt, 1); err != nil {
+			return err
+		}
+	}
+
+	// The transactions confirmed at heights [prevHeight+2-N, height+1-N]
+	// reached the N confirmations of a webhook with the applied blocks.
+	for _, wh := range whs {
+		n := wh.Confirmations
+		if applied == 0 || height+1 < n {
+			continue
+		}
+		start := types.BlockHeight(0)
+		if prevHeight+2 > n {
+			start = prevHeight + 2 - n
+		}
+		end := height + 1 - n
+		pts, err := dbProcessedTransactionsSince(tx, start)
+		if err != nil {
+			return err
+		}
+		for _, pt := range pts {
+			if pt.ConfirmationHeight > end {
+				break
+			}
+			err := w.queueWebhookEvent(tx, []modules.Webhook{wh}, modules.WebhookConfirmed, pt, n)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return w.pruneWebhookDeliveries(tx, whs, height)
+}
+
+// pruneWebhookDeliveries removes the finished deliveries, which succeeded or
+// were given up on, of events about transactions that reached the
+// confirmations of every webhook more than webhookDeliveryRetention blocks
+// ago, and of pending events that are older than that. Events of transactions
+// confirmed at or below the prune height are never queued again, so that a
+// rescan doesn't deliver them twice.
+func (w *Wallet) pruneWebhookDeliveries(tx *bolt.Tx, whs []modules.Webhook, height types.BlockHeight) error {
+	retention := webhookDeliveryRetention
+	for _, wh := range whs {
+		if retention < webhookDeliveryRetention+wh.Confirmations {
+			retention = webhookDeliveryRetention + wh.Confirmations
+		}
+	}
+	pruneHeight, err := dbGetWebhookPruneHeight(tx)
+	if err != nil {
+		return err
+	}
+	if height <= retention || height-retention <= pruneHeight {
+		return nil
+	}
+	pruneHeight = height - retention
+	pendingCutoff := types.CurrentTimestamp() - types.Timestamp(retention*types.BlockFrequency)
+
+	var keys []crypto.Hash
+	err = dbForEachWebhookDelivery(tx, func(key crypto.Hash, wd modules.WebhookDelivery) {
+		if !wd.Delivered && wd.Attempts < webhookMaxAttempts {
+			return
+		}
+		if wd.Event.Type == modules.WebhookPending {
+			if wd.Event.Timestamp < pendingCutoff {
+				keys = append(keys, key)
+			}
+		} else if wd.Event.ConfirmationHeight <= pruneHeight {
+			keys = append(keys, key)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := dbDeleteWebhookDelivery(tx, key); err != nil {
+			return err
+		}
+	}
+	return dbPutWebhookPruneHeight(tx, pruneHeight)
+}
+
+// queueWebhookPendingEvents queues the events of new unconfirmed
+// transactions.
+func (w *Wallet) queueWebhookPendingEvents(tx *bolt.Tx, pts []modules.ProcessedTransaction) error {
+	if len(pts) == 0 {
+		return nil
+	}
+	whs, err := dbWebhooks(tx)
+	if err != nil {
+		return err
+	}
+	for _, pt := range pts {
+		if err := w.queueWebhookEvent(tx, whs, modules.WebhookPending, pt, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddWebhook registers a webhook that the wallet POSTs its events to. Only
+// transactions confirmed after the webhook was added are delivered. The
+// watched addresses need to be tracked by the wallet, either as addresses of
+// the wallet or as watch addresses.
+func (w *Wallet) AddWebhook(wh modules.Webhook) (modules.Webhook, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.Webhook{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if u, err := url.Parse(wh.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return modules.Webhook{}, errWebhookURL
+	}
+	for _, typ := range wh.Events {
+		known := false
+		for _, t := range modules.WebhookEventTypes {
+			known = known || t == typ
+		}
+		if !known {
+			return modules.Webhook{}, fmt.Errorf("unknown event type %v", typ)
+		}
+	}
+	if wh.Confirmations == 0 {
+		wh.Confirmations = webhookDefaultConfirmations
+	}
+	if wh.Secret == "" {
+		wh.Secret = hex.EncodeToString(fastrand.Bytes(32))
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return modules.Webhook{}, err
+	}
+	wh.Height = height
+	wh.ID = crypto.Hash{}
+	fastrand.Read(wh.ID[:])
+	if err := dbPutWebhook(w.dbTx, wh); err != nil {
+		return modules.Webhook{}, err
+	}
+	return wh, w.syncDB()
+}
+
+// Webhooks returns the registered webhooks, ordered by the height at which
+// they were added.
+func (w *Wallet) Webhooks() ([]modules.Webhook, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	whs, err := dbWebhooks(w.dbTx)
+	sort.SliceStable(whs, func(i, j int) bool {
+		return whs[i].Height < whs[j].Height
+	})
+	return whs, err
+}
+
+// RemoveWebhook removes a webhook along with its delivery log. Events that
+// were not delivered yet are dropped.
+func (w *Wallet) RemoveWebhook(id crypto.Hash) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := dbGetWebhook(w.dbTx, id); err == errNoKey {
+		return errUnknownWebhook
+	} else if err != nil {
+		return err
+	}
+	var keys []crypto.Hash
+	err := dbForEachWebhookDelivery(w.dbTx, func(key crypto.Hash, wd modules.WebhookDelivery) {
+		if wd.WebhookID == id {
+			keys = append(keys, key)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := dbDeleteWebhookDelivery(w.dbTx, key); err != nil {
+			return err
+		}
+		delete(w.webhookRetries, key)
+	}
+	if err := dbDeleteWebhook(w.dbTx, id); err != nil {
+		return err
+	}
+	return w.syncDB()
+}
+
+// WebhookDeliveries returns the delivery log of a webhook, ordered by the
+// time at which the events occurred.
+func (w *Wallet) WebhookDeliveries(id crypto.Hash) ([]modules.WebhookDelivery, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := dbGetWebhook(w.dbTx, id); err == errNoKey {
+		return nil, errUnknownWebhook
+	} else if err != nil {
+		return nil, err
+	}
+	var wds []modules.WebhookDelivery
+	err := dbForEachWebhookDelivery(w.dbTx, func(_ crypto.Hash, wd modules.WebhookDelivery) {
+		if wd.WebhookID == id {
+			wds = append(wds, wd)
+		}
+	})
+	sortWebhookDeliveries(wds)
+	return wds, err
+}
+
+// sortWebhookDeliveries sorts deliveries by the time and height of their
+// events.
+func sortWebhookDeliveries(wds []modules.WebhookDelivery) {
+	sort.SliceStable(wds, func(i, j int) bool {
+		if wds[i].Event.Timestamp != wds[j].Event.Timestamp {
+			return wds[i].Event.Timestamp < wds[j].Event.Timestamp
+		}
+		return wds[i].Event.ConfirmationHeight < wds[j].Event.ConfirmationHeight
+	})
+}
+
+// postWebhookEvent POSTs an event to a webhook and returns the status code of
+// the response.
+func postWebhookEvent(ctx context.Context, client *http.Client, wh modules.Webhook, event modules.WebhookEvent) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequest("POST", wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, webhookSignature(wh.Secret, body))
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %v", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// webhookDelivery is a delivery that is due, together with its key.
+type webhookDelivery struct {
+	key crypto.Hash
+	modules.WebhookDelivery
+}
+
+// managedDeliverWebhooks starts delivering the events that are due to their
+// webhooks. Every webhook is delivered to by its own thread, so that a slow
+// webhook doesn't hold up the others. Webhooks that are still being delivered
+// to are skipped. Failed deliveries are retried after webhookRetryInterval,
+// doubling the interval with every attempt, until webhookMaxAttempts is
+// reached. The retry times are not persisted; after a restart, all
+// undelivered events are due.
+func (w *Wallet) managedDeliverWebhooks() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	whs, err := dbWebhooks(w.dbTx)
+	if err != nil {
+		w.log.Println("ERROR: could not load webhooks:", err)
+		return
+	}
+	webhooks := make(map[crypto.Hash]modules.Webhook)
+	for _, wh := range whs {
+		if _, delivering := w.webhooksDelivering[wh.ID]; !delivering {
+			webhooks[wh.ID] = wh
+		}
+	}
+	now := time.Now()
+	due := make(map[crypto.Hash][]webhookDelivery)
+	err = dbForEachWebhookDelivery(w.dbTx, func(key crypto.Hash, wd modules.WebhookDelivery) {
+		if _, ok := webhooks[wd.WebhookID]; !ok || wd.Delivered || wd.Attempts >= webhookMaxAttempts || now.Before(w.webhookRetries[key]) {
+			return
+		}
+		due[wd.WebhookID] = append(due[wd.WebhookID], webhookDelivery{key, wd})
+	})
+	if err != nil {
+		w.log.Println("ERROR: could not load webhook deliveries:", err)
+		return
+	}
+	for id, deliveries := range due {
+		sort.SliceStable(deliveries, func(i, j int) bool {
+			return deliveries[i].Event.Timestamp < deliveries[j].Event.Timestamp
+		})
+		w.webhooksDelivering[id] = struct{}{}
+		go w.threadedDeliverWebhookEvents(webhooks[id], deliveries)
+	}
+}
+
+// threadedDeliverWebhookEvents POSTs the events of deliveries to a webhook in
+// order and logs the outcome of every attempt.
+func (w *Wallet) threadedDeliverWebhookEvents(wh modules.Webhook, deliveries []webhookDelivery) {
+	defer func() {
+		w.mu.Lock()
+		delete(w.webhooksDelivering, wh.ID)
+		w.mu.Unlock()
+		// Deliver the events that were queued in the meantime.
+		select {
+		case w.webhookWake <- struct{}{}:
+		default:
+		}
+	}()
+	if err := w.tg.Add(); err != nil {
+		return
+	}
+	defer w.tg.Done()
+
+	// Cancel the requests when the wallet shuts down.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-w.tg.StopChan():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	client := &http.Client{Timeout: webhookTimeout}
+	for _, d := range deliveries {
+		status, err := postWebhookEvent(ctx, client, wh, d.Event)
+		select {
+		case <-w.tg.StopChan():
+			return
+		default:
+		}
+
+		w.mu.Lock()
+		// Skip the delivery if it was removed in the meantime.
+		if _, dbErr := dbGetWebhookDelivery(w.dbTx, d.key); dbErr != nil {
+			w.mu.Unlock()
+			continue
+		}
+		wd := d.WebhookDelivery
+		wd.Attempts++
+		wd.StatusCode = status
+		wd.LastAttempt = types.CurrentTimestamp()
+		if err == nil {
+			wd.Delivered = true
+			wd.LastError = ""
+			delete(w.webhookRetries, d.key)
+		} else {
+			wd.LastError = err.Error()
+			w.webhookRetries[d.key] = time.Now().Add(webhookRetryInterval << (wd.Attempts - 1))
+			w.log.Debugln("Delivery of event", d.Event.ID, "to webhook", wh.URL, "failed:", err)
+		}
+		if dbErr := dbPutWebhookDelivery(w.dbTx, d.key, wd); dbErr != nil {
+			w.log.Println("ERROR: could not update webhook delivery:", dbErr)
+		}
+		w.mu.Unlock()
+	}
+
+	w.mu.Lock()
+	if err := w.syncDB(); err != nil {
+		w.log.Println("ERROR: could not sync webhook deliveries:", err)
+	}
+	w.mu.Unlock()
+}
+
+// threadedDeliverWebhooks delivers the queued webhook events until the wallet
+// shuts down.
+func (w *Wallet) threadedDeliverWebhooks() {
+	if err := w.tg.Add(); err != nil {
+		return
+	}
+	defer w.tg.Done()
+
+	for {
+		w.managedDeliverWebhooks()
+		select {
+		case <-w.tg.StopChan():
+			return
+		case <-w.webhookWake:
+		case <-time.After(webhookRetryInterval):
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestWebhooks tests delivering the events of a watched address to a webhook.
+func TestWebhooks(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// The webhook fails the first request and records the events of the
+	// others, checking their signatures.
+	var mu sync.Mutex
+	var requests int
+	events := make(map[modules.WebhookEventType]modules.WebhookEvent)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		if req.Header.Get(WebhookSignatureHeader) != webhookSignature("secret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var event modules.WebhookEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		events[event.Type] = event
+	}))
+	defer srv.Close()
+
+	if _, err := wt.wallet.AddWebhook(modules.Webhook{URL: "localhost"}); err != errWebhookURL {
+		t.Fatal("expected errWebhookURL, got", err)
+	}
+	if _, err := wt.wallet.AddWebhook(modules.Webhook{URL: srv.URL, Events: []modules.WebhookEventType{"foo"}}); err == nil {
+		t.Fatal("expected error for unknown event type")
+	}
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := uc.UnlockHash()
+	wh, err := wt.wallet.AddWebhook(modules.Webhook{
+		URL:           srv.URL,
+		Addresses:     []types.UnlockHash{addr},
+		Confirmations: 2,
+		Secret:        "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wh.Height != wt.cs.Height() {
+		t.Fatal("wrong webhook height", wh.Height)
+	}
+
+	// Pay the address and confirm the payment twice, then revert both
+	// blocks.
+	txns, err := wt.wallet.SendSiacoins(types.SiacoinPrecision, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txid := txns[len(txns)-1].ID()
+	var blocks []types.Block
+	for i := 0; i < 2; i++ {
+		if err := wt.addBlockNoPayout(); err != nil {
+			t.Fatal(err)
+		}
+		blocks = append([]types.Block{wt.cs.CurrentBlock()}, blocks...)
+	}
+	wt.wallet.ProcessConsensusChange(modules.ConsensusChange{
+		RevertedBlocks: blocks,
+	})
+
+	// Every event is delivered, the first one after a retry.
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, typ := range modules.WebhookEventTypes {
+			if _, ok := events[typ]; !ok {
+				return fmt.Errorf("%v event was not delivered", typ)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	for typ, event := range events {
+		if event.TransactionID != txid {
+			t.Errorf("%v event has wrong transaction", typ)
+		}
+		if len(event.Inputs) != 0 || len(event.Outputs) != 1 || event.Outputs[0].RelatedAddress != addr {
+			t.Errorf("%v event has wrong inputs or outputs", typ)
+		}
+	}
+	if events[modules.WebhookConfirmed].Confirmations != 2 {
+		t.Error("wrong confirmations", events[modules.WebhookConfirmed].Confirmations)
+	}
+	mu.Unlock()
+
+	var wds []modules.WebhookDelivery
+	err = build.Retry(50, 100*time.Millisecond, func() error {
+		wds, err = wt.wallet.WebhookDeliveries(wh.ID)
+		if err != nil {
+			return err
+		} else if len(wds) != len(modules.WebhookEventTypes) {
+			return fmt.Errorf("expected %v deliveries, got %v", len(modules.WebhookEventTypes), len(wds))
+		}
+		for _, wd := range wds {
+			if !wd.Delivered {
+				return errors.New("delivery not logged")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attempts uint64
+	for _, wd := range wds {
+		attempts += wd.Attempts
+	}
+	if attempts != uint64(len(wds))+1 {
+		t.Fatal("expected one retry, got", attempts-uint64(len(wds)))
+	}
+
+	// Removing the webhook removes its delivery log.
+	if err := wt.wallet.RemoveWebhook(wh.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.WebhookDeliveries(wh.ID); err != errUnknownWebhook {
+		t.Fatal("expected errUnknownWebhook, got", err)
+	}
+	if whs, err := wt.wallet.Webhooks(); err != nil || len(whs) != 0 {
+		t.Fatal("webhook was not removed", whs, err)
+	}
+}
+
+// TestWebhookReorg checks that the events of a transaction that is reverted
+// and confirmed again at the same height are delivered again, and that the
+// delivery log is pruned once the transaction is old enough.
+func TestWebhookReorg(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	var mu sync.Mutex
+	counts := make(map[modules.WebhookEventType]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var event modules.WebhookEvent
+		if err := json.NewDecoder(req.Body).Decode(&event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		counts[event.Type]++
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	waitForCounts := func(want map[modules.WebhookEventType]int) {
+		t.Helper()
+		err := build.Retry(100, 100*time.Millisecond, func() error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, typ := range modules.WebhookEventTypes {
+				if counts[typ] != want[typ] {
+					return fmt.Errorf("expected %v %v events, got %v", want[typ], typ, counts[typ])
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wh, err := wt.wallet.AddWebhook(modules.Webhook{
+		URL:           srv.URL,
+		Addresses:     []types.UnlockHash{uc.UnlockHash()},
+		Confirmations: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision, uc.UnlockHash()); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	waitForCounts(map[modules.WebhookEventType]int{
+		modules.WebhookPending:   1,
+		modules.WebhookReceived:  1,
+		modules.WebhookConfirmed: 1,
+	})
+
+	// Revert the block and apply it again.
+	block := wt.cs.CurrentBlock()
+	wt.wallet.ProcessConsensusChange(modules.ConsensusChange{
+		RevertedBlocks: []types.Block{block},
+	})
+	wt.wallet.ProcessConsensusChange(modules.ConsensusChange{
+		AppliedBlocks: []types.Block{block},
+	})
+	waitForCounts(map[modules.WebhookEventType]int{
+		modules.WebhookPending:   1,
+		modules.WebhookReceived:  2,
+		modules.WebhookConfirmed: 2,
+		modules.WebhookReverted:  1,
+	})
+
+	// The events of the transaction are pruned once it is old enough.
+	for i := types.BlockHeight(0); i <= webhookDeliveryRetention+wh.Confirmations; i++ {
+		if err := wt.addBlockNoPayout(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wds, err := wt.wallet.WebhookDeliveries(wh.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, wd := range wds {
+		if wd.Event.Type != modules.WebhookPending {
+			t.Fatalf("%v event was not pruned", wd.Event.Type)
+		}
+	}
+	wt.wallet.mu.Lock()
+	pruneHeight, err := dbGetWebhookPruneHeight(wt.wallet.dbTx)
+	wt.wallet.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	} else if pruneHeight <= wh.Height {
+		t.Fatal("wrong prune height", pruneHeight)
+	}
+}
+
+// TestWebhookSlowEndpoint checks that a webhook that doesn't respond doesn't
+// hold up the deliveries to other webhooks.
+func TestWebhookSlowEndpoint(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+	var mu sync.Mutex
+	var delivered int
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		delivered++
+		mu.Unlock()
+	}))
+	defer fast.Close()
+
+	for _, url := range []string{slow.URL, fast.URL} {
+		if _, err := wt.wallet.AddWebhook(modules.Webhook{URL: url, Confirmations: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision, uc.UnlockHash()); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(50, 100*time.Millisecond, func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		if delivered == 0 {
+			return errors.New("no event was delivered to the fast webhook")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -97,10 +97,11 @@ outer:
 		}
 	}
 
-	// mark the watch-only outputs
+	// mark the watch-only and frozen outputs
 	for i, o := range outputs {
 		_, ok := w.watchedAddrs[o.UnlockHash]
 		outputs[i].IsWatchOnly = ok
+		outputs[i].Frozen = o.FundType == types.SpecifierSiacoinOutput && dbIsFrozenOutput(w.dbTx, types.SiacoinOutputID(o.ID))
 	}
 
 	return outputs, nil
@@ -149,7 +150,9 @@ func (w *Wallet) AddUnlockConditions(uc types.UnlockConditions) error {
 // SignTransaction signs txn using secret keys known to the wallet. The
 // transaction should be complete with the exception of the Signature fields
 // of each TransactionSignature referenced by toSign. For convenience, if
-// toSign is empty, SignTransaction signs everything that it can.
+// toSign is empty, SignTransaction signs everything that it can, adding the
+// TransactionSignatures of the wallet to inputs of registered multisig
+// addresses if they are missing.
 func (w *Wallet) SignTransaction(txn *types.Transaction, toSign []crypto.Hash) error {
 	if err := w.tg.Add(); err != nil {
 		return err
@@ -160,6 +163,8 @@ func (w *Wallet) SignTransaction(txn *types.Transaction, toSign []crypto.Hash) e
 	defer w.mu.Unlock()
 	if !w.unlocked {
 		return modules.ErrLockedWallet
+	} else if w.watchOnly {
+		return errWatchOnly
 	}
 	consensusHeight, err := dbGetConsensusHeight(w.dbTx)
 	if err != nil {
@@ -178,8 +183,9 @@ func (w *Wallet) SignTransaction(txn *types.Transaction, toSign []crypto.Hash) e
 				toSign = append(toSign, crypto.Hash(sfi.ParentID))
 			}
 		}
+		toSign = append(toSign, w.addMultisigSignatures(txn)...)
 	}
-	return signTransaction(txn, w.keys, toSign, consensusHeight)
+	return signTransaction(txn, w.signingKeys(*txn), toSign, consensusHeight)
 }
 
 // SignTransaction signs txn using secret keys derived from seed. The
@@ -250,15 +256,15 @@ func signTransaction(txn *types.Transaction, keys map[types.UnlockHash]spendable
 	}
 
 	for _, id := range toSign {
-		// find associated txn signature
-		sigIndex := -1
+		// find associated txn signatures; a multisig input has one per
+		// co-signer
+		var sigIndices []int
 		for i, sig := range txn.TransactionSignatures {
 			if sig.ParentID == id {
-				sigIndex = i
-				break
+				sigIndices = append(sigIndices, i)
 			}
 		}
-		if sigIndex == -1 {
+		if len(sigIndices) == 0 {
 			return errors.New("toSign references signatures not present in transaction")
 		}
 		// find associated input
@@ -266,20 +272,27 @@ func signTransaction(txn *types.Transaction, keys map[types.UnlockHash]spendable
 		if !ok {
 			return errors.New("toSign references IDs not present in transaction")
 		}
-		// lookup the signing key
-		sk, ok := findSigningKey(uc, txn.TransactionSignatures[sigIndex].PublicKeyIndex)
-		if !ok {
+		signed := false
+		for _, sigIndex := range sigIndices {
+			// lookup the signing key
+			sk, ok := findSigningKey(uc, txn.TransactionSignatures[sigIndex].PublicKeyIndex)
+			if !ok {
+				continue
+			}
+			// add signature
+			//
+			// NOTE: it's possible that the Signature field will already be
+			// filled out. Although we could save a bit of work by not signing
+			// it, in practice it's probably best to overwrite any existing
+			// signatures, since we know that ours will be valid.
+			sigHash := txn.SigHash(sigIndex, height)
+			encodedSig := crypto.SignHash(sigHash, sk)
+			txn.TransactionSignatures[sigIndex].Signature = encodedSig[:]
+			signed = true
+		}
+		if !signed {
 			return errors.New("could not locate signing key for " + id.String())
 		}
-		// add signature
-		//
-		// NOTE: it's possible that the Signature field will already be filled
-		// out. Although we could save a bit of work by not signing it, in
-		// practice it's probably best to overwrite any existing signatures,
-		// since we know that ours will be valid.
-		sigHash := txn.SigHash(sigIndex, height)
-		encodedSig := crypto.SignHash(sigHash, sk)
-		txn.TransactionSignatures[sigIndex].Signature = encodedSig[:]
 	}
 
 	return nil
@@ -317,18 +330,7 @@ func (w *Wallet) AddWatchAddresses(addrs []types.UnlockHash, unused bool) error
 		}
 
 		if !unused {
-			// prepare to rescan
-			if err := w.dbTx.DeleteBucket(bucketProcessedTransactions); err != nil {
-				return err
-			}
-			if _, err := w.dbTx.CreateBucket(bucketProcessedTransactions); err != nil {
-				return err
-			}
-			w.unconfirmedProcessedTransactions = nil
-			if err := dbPutConsensusChangeID(w.dbTx, modules.ConsensusChangeBeginning); err != nil {
-				return err
-			}
-			if err := dbPutConsensusHeight(w.dbTx, 0); err != nil {
+			if err := w.prepareRescan(); err != nil {
 				return err
 			}
 		}
@@ -339,19 +341,8 @@ func (w *Wallet) AddWatchAddresses(addrs []types.UnlockHash, unused bool) error
 	}
 
 	if !unused {
-		// rescan the blockchain
-		w.cs.Unsubscribe(w)
-		w.tpool.Unsubscribe(w)
-
-		done := make(chan struct{})
-		go w.rescanMessage(done)
-		defer close(done)
-		if err := w.cs.ConsensusSetSubscribe(w, modules.ConsensusChangeBeginning, w.tg.StopChan()); err != nil {
-			return err
-		}
-		w.tpool.TransactionPoolSubscribe(w)
+		return w.managedRescan()
 	}
-
 	return nil
 }
 
@@ -401,18 +392,7 @@ func (w *Wallet) RemoveWatchAddresses(addrs []types.UnlockHash, unused bool) err
 				}
 			}
 
-			// prepare to rescan
-			if err := w.dbTx.DeleteBucket(bucketProcessedTransactions); err != nil {
-				return err
-			}
-			if _, err := w.dbTx.CreateBucket(bucketProcessedTransactions); err != nil {
-				return err
-			}
-			w.unconfirmedProcessedTransactions = nil
-			if err := dbPutConsensusChangeID(w.dbTx, modules.ConsensusChangeBeginning); err != nil {
-				return err
-			}
-			if err := dbPutConsensusHeight(w.dbTx, 0); err != nil {
+			if err := w.prepareRescan(); err != nil {
 				return err
 			}
 		}
@@ -423,19 +403,8 @@ func (w *Wallet) RemoveWatchAddresses(addrs []types.UnlockHash, unused bool) err
 	}
 
 	if !unused {
-		// rescan the blockchain
-		w.cs.Unsubscribe(w)
-		w.tpool.Unsubscribe(w)
-
-		done := make(chan struct{})
-		go w.rescanMessage(done)
-		defer close(done)
-		if err := w.cs.ConsensusSetSubscribe(w, modules.ConsensusChangeBeginning, w.tg.StopChan()); err != nil {
-			return err
-		}
-		w.tpool.TransactionPoolSubscribe(w)
+		return w.managedRescan()
 	}
-
 	return nil
 }
 
@@ -456,3 +425,32 @@ func (w *Wallet) WatchAddresses() ([]types.UnlockHash, error) {
 	}
 	return addrs, nil
 }
+
+// prepareRescan deletes the processed transactions of the wallet and resets
+// its consensus change ID, so that the next subscription to the consensus set
+// rebuilds them from the beginning of the blockchain. It must be called with a
+// write-lock.
+func (w *Wallet) prepareRescan() error {
+	if err := w.dbTx.DeleteBucket(bucketProcessedTransactions); err != nil {
+		return err
+	}
+	if _, err := w.dbTx.CreateBucket(bucketProcessedTransactions); err != nil {
+		return err
+	}
+	w.unconfirmedProcessedTransactions = nil
+	if err := dbPutConsensusChangeID(w.dbTx, modules.ConsensusChangeBeginning); err != nil {
+		return err
+	}
+	return dbPutConsensusHeight(w.dbTx, 0)
+}
+
+// managedRescan resubscribes the wallet to the consensus set and the
+// transaction pool, rescanning the blockchain from the beginning.
+func (w *Wallet) managedRescan() error {
+	w.managedUnsubscribe()
+
+	done := make(chan struct{})
+	go w.rescanMessage(done)
+	defer close(done)
+	return w.managedSubscribe(modules.ConsensusChangeBeginning)
+}
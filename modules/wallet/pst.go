@@ -0,0 +1,475 @@
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+const (
+	// maxPSTSeedSearch is the number of keys that are derived from a seed
+	// when searching for the keys of inputs without key hints.
+	maxPSTSeedSearch = 1e6
+
+	// pstSearchBatch is the number of keys that are derived at once when
+	// searching for the keys of inputs without key hints.
+	pstSearchBatch = 1000
+)
+
+var (
+	// errNoPSTs is returned when combining zero partially signed
+	// transactions.
+	errNoPSTs = errors.New("no partially signed transactions to combine")
+
+	// errPSTInputs is returned when the inputs of a partially signed
+	// transaction don't match the inputs of its transaction.
+	errPSTInputs = errors.New("inputs of partially signed transaction don't match its transaction")
+
+	// errPSTNothingToSign is returned when none of the inputs of a partially
+	// signed transaction could be signed.
+	errPSTNothingToSign = errors.New("no signing keys for the unsigned inputs of the partially signed transaction")
+
+	// errPSTNoOutputs is returned when funding a partially signed
+	// transaction without outputs.
+	errPSTNoOutputs = errors.New("partially signed transaction needs at least one output")
+
+	// errPSTUnknownOutput is returned when creating a partially signed
+	// transaction with an input that spends an output that is neither
+	// tracked by the wallet nor created by one of the parents.
+	errPSTUnknownOutput = errors.New("input spends an output that is unknown to the wallet and not created by a parent")
+
+	// errPSTVersion is returned when decoding a partially signed transaction
+	// of an unsupported version.
+	errPSTVersion = errors.New("unsupported partially signed transaction version")
+)
+
+// checkPST checks that pst has a supported version and that its inputs
+// describe the inputs of its transaction, siacoin inputs first.
+func checkPST(pst modules.PartiallySignedTransaction) error {
+	if pst.Version != modules.PSTVersion {
+		return errPSTVersion
+	}
+	txn := pst.Transaction
+	if len(pst.Inputs) != len(txn.SiacoinInputs)+len(txn.SiafundInputs) {
+		return errPSTInputs
+	}
+	for i, sci := range txn.SiacoinInputs {
+		in := pst.Inputs[i]
+		if in.FundType != types.SpecifierSiacoinOutput || in.ParentID != types.OutputID(sci.ParentID) || in.UnlockConditions.UnlockHash() != sci.UnlockConditions.UnlockHash() {
+			return errPSTInputs
+		}
+	}
+	for i, sfi := range txn.SiafundInputs {
+		in := pst.Inputs[len(txn.SiacoinInputs)+i]
+		if in.FundType != types.SpecifierSiafundOutput || in.ParentID != types.OutputID(sfi.ParentID) || in.UnlockConditions.UnlockHash() != sfi.UnlockConditions.UnlockHash() {
+			return errPSTInputs
+		}
+	}
+	return nil
+}
+
+// validPSTSignature reports whether the TransactionSignature at index i of
+// txn is a valid signature of the public key of uc that it references. Only
+// signatures that cover the whole transaction are considered, since those are
+// the signatures created by the signers of partially signed transactions.
+func validPSTSignature(txn types.Transaction, i int, uc types.UnlockConditions, height types.BlockHeight) bool {
+	sig := txn.TransactionSignatures[i]
+	if !sig.CoveredFields.WholeTransaction || len(sig.CoveredFields.TransactionSignatures) != 0 {
+		return false
+	}
+	if sig.PublicKeyIndex >= uint64(len(uc.PublicKeys)) {
+		return false
+	}
+	pk := uc.PublicKeys[sig.PublicKeyIndex]
+	if pk.Algorithm != types.SignatureEd25519 || len(pk.Key) != crypto.PublicKeySize || len(sig.Signature) != crypto.SignatureSize {
+		return false
+	}
+	var edPK crypto.PublicKey
+	copy(edPK[:], pk.Key)
+	var edSig crypto.Signature
+	copy(edSig[:], sig.Signature)
+	return crypto.VerifyHash(txn.SigHash(i, height), edPK, edSig) == nil
+}
+
+// UpdatePSTStatus checks pst and recounts the valid signatures of each of its
+// inputs.
+func UpdatePSTStatus(pst *modules.PartiallySignedTransaction) error {
+	if err := checkPST(*pst); err != nil {
+		return err
+	}
+	for i := range pst.Inputs {
+		in := &pst.Inputs[i]
+		signed := make(map[uint64]struct{})
+		for j, sig := range pst.Transaction.TransactionSignatures {
+			if sig.ParentID == crypto.Hash(in.ParentID) && validPSTSignature(pst.Transaction, j, in.UnlockConditions, pst.Height) {
+				signed[sig.PublicKeyIndex] = struct{}{}
+			}
+		}
+		in.Signatures = uint64(len(signed))
+		in.Signed = in.Signatures >= in.UnlockConditions.SignaturesRequired
+	}
+	return nil
+}
+
+// signPST adds the signatures of keys to the inputs of pst that are missing
+// signatures, adding TransactionSignatures that cover the whole transaction
+// where necessary. It returns the number of signatures that were created.
+func signPST(pst *modules.PartiallySignedTransaction, keys map[types.UnlockHash]spendableKey) int {
+	txn := &pst.Transaction
+	var created int
+	for _, in := range pst.Inputs {
+		sk, ok := keys[in.UnlockConditions.UnlockHash()]
+		if !ok || in.Signed {
+			continue
+		}
+		parentID := crypto.Hash(in.ParentID)
+		numSigs := in.Signatures
+		for pkIndex, pk := range in.UnlockConditions.PublicKeys {
+			if numSigs >= in.UnlockConditions.SignaturesRequired {
+				break
+			}
+			var key crypto.SecretKey
+			var found bool
+			for _, k := range sk.SecretKeys {
+				pubKey := k.PublicKey()
+				if bytes.Equal(pubKey[:], pk.Key) {
+					key, found = k, true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+
+			// Reuse an existing TransactionSignature for the key unless it
+			// already holds a valid signature.
+			sigIndex := -1
+			for i, sig := range txn.TransactionSignatures {
+				if sig.ParentID == parentID && sig.PublicKeyIndex == uint64(pkIndex) {
+					sigIndex = i
+					break
+				}
+			}
+			if sigIndex >= 0 && (validPSTSignature(*txn, sigIndex, in.UnlockConditions, pst.Height) || !txn.TransactionSignatures[sigIndex].CoveredFields.WholeTransaction) {
+				continue
+			}
+			if sigIndex < 0 {
+				sigIndex = len(txn.TransactionSignatures)
+				txn.TransactionSignatures = append(txn.TransactionSignatures, types.TransactionSignature{
+					ParentID:       parentID,
+					CoveredFields:  types.FullCoveredFields,
+					PublicKeyIndex: uint64(pkIndex),
+				})
+			}
+			encodedSig := crypto.SignHash(txn.SigHash(sigIndex, pst.Height), key)
+			txn.TransactionSignatures[sigIndex].Signature = encodedSig[:]
+			numSigs++
+			created++
+		}
+	}
+	return created
+}
+
+// seedKeys returns the keys derived from seed that can sign the unsigned
+// inputs of pst. The key hints of the inputs are used to derive the keys
+// directly. The keys of inputs without hints are searched for among the
+// first million keys of the seed.
+func seedKeys(pst modules.PartiallySignedTransaction, seed modules.Seed) map[types.UnlockHash]spendableKey {
+	keys := make(map[types.UnlockHash]spendableKey)
+	addKey := func(uc types.UnlockConditions, sk crypto.SecretKey) {
+		key := keys[uc.UnlockHash()]
+		key.UnlockConditions = uc
+		key.SecretKeys = append(key.SecretKeys, sk)
+		keys[uc.UnlockHash()] = key
+	}
+
+	// unhinted maps the public keys of the inputs without hints to their
+	// unlock conditions. The search stops once a key was found for each of
+	// the inputs in missing.
+	unhinted := make(map[string][]types.UnlockConditions)
+	missing := make(map[types.UnlockHash]struct{})
+	for _, in := range pst.Inputs {
+		if in.Signed {
+			continue
+		}
+		if len(in.KeyHints) == 0 {
+			for _, pk := range in.UnlockConditions.PublicKeys {
+				unhinted[string(pk.Key)] = append(unhinted[string(pk.Key)], in.UnlockConditions)
+			}
+			missing[in.UnlockConditions.UnlockHash()] = struct{}{}
+			continue
+		}
+		for _, hint := range in.KeyHints {
+			if hint.PublicKeyIndex >= uint64(len(in.UnlockConditions.PublicKeys)) {
+				continue
+			}
+			sk := generateSpendableKey(seed, hint.SeedIndex)
+			if bytes.Equal(sk.UnlockConditions.PublicKeys[0].Key, in.UnlockConditions.PublicKeys[hint.PublicKeyIndex].Key) {
+				addKey(in.UnlockConditions, sk.SecretKeys[0])
+			}
+		}
+	}
+
+	for start := uint64(0); len(missing) > 0 && start < maxPSTSeedSearch; start += pstSearchBatch {
+		for _, sk := range generateKeys(seed, start, pstSearchBatch) {
+			for _, uc := range unhinted[string(sk.UnlockConditions.PublicKeys[0].Key)] {
+				addKey(uc, sk.SecretKeys[0])
+				delete(missing, uc.UnlockHash())
+			}
+		}
+	}
+	return keys
+}
+
+// SignPST signs the inputs of pst that are missing signatures using secret
+// keys derived from seed. It does not need a wallet, so it can be used by
+// signers that are fully offline.
+func SignPST(pst *modules.PartiallySignedTransaction, seed modules.Seed) error {
+	if err := UpdatePSTStatus(pst); err != nil {
+		return err
+	}
+	if signPST(pst, seedKeys(*pst, seed)) == 0 {
+		return errPSTNothingToSign
+	}
+	return UpdatePSTStatus(pst)
+}
+
+// CombinePSTs merges the signatures of copies of a partially signed
+// transaction that were signed by different signers.
+func CombinePSTs(psts []modules.PartiallySignedTransaction) (modules.PartiallySignedTransaction, error) {
+	if len(psts) == 0 {
+		return modules.PartiallySignedTransaction{}, errNoPSTs
+	}
+	txns := make([]types.Transaction, 0, len(psts))
+	for _, pst := range psts {
+		if err := checkPST(pst); err != nil {
+			return modules.PartiallySignedTransaction{}, err
+		}
+		txns = append(txns, pst.Transaction)
+	}
+	combined := psts[0]
+	txn, err := CombineSignatures(txns)
+	if err != nil {
+		return modules.PartiallySignedTransaction{}, err
+	}
+	combined.Transaction = txn
+	return combined, UpdatePSTStatus(&combined)
+}
+
+// pstKeyHints returns the key hints of the public keys of uc that belong to
+// one of the seeds of the wallet. Keys that weren't derived from a seed, like
+// imported siag keys, have no hints.
+func (w *Wallet) pstKeyHints(uc types.UnlockConditions) (hints []modules.PSTKeyHint) {
+	for i, pk := range uc.PublicKeys {
+		addr := types.UnlockConditions{
+			PublicKeys:         []types.SiaPublicKey{pk},
+			SignaturesRequired: 1,
+		}.UnlockHash()
+		if index, ok := w.seedIndices[addr]; ok {
+			hints = append(hints, modules.PSTKeyHint{
+				PublicKeyIndex: uint64(i),
+				SeedIndex:      index,
+			})
+		}
+	}
+	return hints
+}
+
+// createPST wraps txn and its parents in a partially signed
+// transaction. The caller must hold the lock.
+func (w *Wallet) createPST(txn types.Transaction, parents []types.Transaction) (modules.PartiallySignedTransaction, error) {
+	consensusHeight, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return modules.PartiallySignedTransaction{}, err
+	}
+	pst := modules.PartiallySignedTransaction{
+		Version:     modules.PSTVersion,
+		Height:      consensusHeight,
+		Transaction: txn,
+		Parents:     parents,
+	}
+
+	// Look up the values of the spent outputs in the wallet database and in
+	// the parents.
+	parentSiacoinOutputs := make(map[types.SiacoinOutputID]types.SiacoinOutput)
+	parentSiafundOutputs := make(map[types.SiafundOutputID]types.SiafundOutput)
+	for _, p := range parents {
+		for i, sco := range p.SiacoinOutputs {
+			parentSiacoinOutputs[p.SiacoinOutputID(uint64(i))] = sco
+		}
+		for i, sfo := range p.SiafundOutputs {
+			parentSiafundOutputs[p.SiafundOutputID(uint64(i))] = sfo
+		}
+	}
+	for _, sci := range txn.SiacoinInputs {
+		sco, err := dbGetSiacoinOutput(w.dbTx, sci.ParentID)
+		if err != nil {
+			var ok bool
+			if sco, ok = parentSiacoinOutputs[sci.ParentID]; !ok {
+				return modules.PartiallySignedTransaction{}, errPSTUnknownOutput
+			}
+		}
+		pst.Inputs = append(pst.Inputs, modules.PSTInput{
+			ParentID:         types.OutputID(sci.ParentID),
+			FundType:         types.SpecifierSiacoinOutput,
+			Value:            sco.Value,
+			UnlockConditions: sci.UnlockConditions,
+			KeyHints:         w.pstKeyHints(sci.UnlockConditions),
+		})
+	}
+	for _, sfi := range txn.SiafundInputs {
+		sfo, err := dbGetSiafundOutput(w.dbTx, sfi.ParentID)
+		if err != nil {
+			var ok bool
+			if sfo, ok = parentSiafundOutputs[sfi.ParentID]; !ok {
+				return modules.PartiallySignedTransaction{}, errPSTUnknownOutput
+			}
+		}
+		pst.Inputs = append(pst.Inputs, modules.PSTInput{
+			ParentID:         types.OutputID(sfi.ParentID),
+			FundType:         types.SpecifierSiafundOutput,
+			Value:            sfo.Value,
+			UnlockConditions: sfi.UnlockConditions,
+			KeyHints:         w.pstKeyHints(sfi.UnlockConditions),
+		})
+	}
+	return pst, UpdatePSTStatus(&pst)
+}
+
+// CreatePST wraps a transaction and its unconfirmed parents in a partially
+// signed transaction. The wallet fills in the values of the spent outputs and
+// the key hints of its own addresses.
+func (w *Wallet) CreatePST(txn types.Transaction, parents []types.Transaction) (modules.PartiallySignedTransaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.PartiallySignedTransaction{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return modules.PartiallySignedTransaction{}, modules.ErrLockedWallet
+	}
+	return w.createPST(txn, parents)
+}
+
+// FundPST creates an unsigned partially signed transaction that sends
+// siacoins from the confirmed outputs of the wallet to the provided outputs.
+// The change is returned to a new address of the wallet. The spent outputs
+// are marked as spent, so the wallet won't use them again until
+// RespendTimeout blocks have passed.
+func (w *Wallet) FundPST(outputs []types.SiacoinOutput) (modules.PartiallySignedTransaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.PartiallySignedTransaction{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if len(outputs) == 0 {
+		return modules.PartiallySignedTransaction{}, errPSTNoOutputs
+	}
+	var amount types.Currency
+	for _, sco := range outputs {
+		amount = amount.Add(sco.Value)
+	}
+	dustThreshold, err := w.DustThreshold()
+	if err != nil {
+		return modules.PartiallySignedTransaction{}, err
+	}
+	_, tpoolFee := w.tpool.FeeEstimation()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return modules.PartiallySignedTransaction{}, modules.ErrLockedWallet
+	}
+	consensusHeight, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return modules.PartiallySignedTransaction{}, err
+	}
+
+	// Collect the spendable confirmed outputs, largest first.
+	var so sortedOutputs
+	err = dbForEachSiacoinOutput(w.dbTx, func(scoid types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if w.checkOutput(w.dbTx, consensusHeight, scoid, sco, dustThreshold) == nil {
+			so.ids = append(so.ids, scoid)
+			so.outputs = append(so.outputs, sco)
+		}
+	})
+	if err != nil {
+		return modules.PartiallySignedTransaction{}, err
+	}
+	sort.Sort(sort.Reverse(so))
+
+	// Add inputs until they cover the outputs and the fee, which grows with
+	// every input.
+	txn := types.Transaction{
+		SiacoinOutputs: append([]types.SiacoinOutput(nil), outputs...),
+	}
+	var fund, fee types.Currency
+	size := uint64(multisigBaseSize)
+	for i := range so.ids {
+		uc := w.keys[so.outputs[i].UnlockHash].UnlockConditions
+		txn.SiacoinInputs = append(txn.SiacoinInputs, types.SiacoinInput{
+			ParentID:         so.ids[i],
+			UnlockConditions: uc,
+		})
+		fund = fund.Add(so.outputs[i].Value)
+		size += estimatedInputSize(uc)
+		fee = tpoolFee.Mul64(size)
+		if fund.Cmp(amount.Add(fee)) >= 0 {
+			break
+		}
+	}
+	if fund.Cmp(amount.Add(fee)) < 0 {
+		return modules.PartiallySignedTransaction{}, modules.ErrLowBalance
+	}
+	txn.MinerFees = []types.Currency{fee}
+	if change := fund.Sub(amount).Sub(fee); !change.IsZero() {
+		uc, err := w.nextPrimarySeedAddress(w.dbTx)
+		if err != nil {
+			return modules.PartiallySignedTransaction{}, err
+		}
+		txn.SiacoinOutputs = append(txn.SiacoinOutputs, types.SiacoinOutput{
+			Value:      change,
+			UnlockHash: uc.UnlockHash(),
+		})
+	}
+
+	pst, err := w.createPST(txn, nil)
+	if err != nil {
+		return modules.PartiallySignedTransaction{}, err
+	}
+	for _, sci := range txn.SiacoinInputs {
+		if err := dbPutSpentOutput(w.dbTx, types.OutputID(sci.ParentID), consensusHeight); err != nil {
+			return modules.PartiallySignedTransaction{}, err
+		}
+	}
+	return pst, nil
+}
+
+// SignPST adds the signatures of the wallet to the inputs of pst that are
+// still missing signatures, including the inputs of registered multisig
+// addresses, and updates their signing status.
+func (w *Wallet) SignPST(pst *modules.PartiallySignedTransaction) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if err := UpdatePSTStatus(pst); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return modules.ErrLockedWallet
+	} else if w.watchOnly {
+		return errWatchOnly
+	}
+	if signPST(pst, w.signingKeys(pst.Transaction)) == 0 {
+		return errPSTNothingToSign
+	}
+	return UpdatePSTStatus(pst)
+}
@@ -0,0 +1,212 @@
+package wallet
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/errors"
+)
+
+// TestPST creates a partially signed transaction, signs it offline with the
+// seed of the wallet and broadcasts it.
+func TestPST(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Mine past the ASIC hardfork, so that the signatures created at the
+	// height of the PST are still valid when the transactions are broadcast.
+	for wt.cs.Height() <= types.ASICHardforkHeight {
+		if _, err := wt.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	amount := types.SiacoinPrecision.Mul64(100)
+	pst, err := wt.wallet.FundPST([]types.SiacoinOutput{{Value: amount}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pst.Inputs) == 0 || len(pst.Transaction.TransactionSignatures) != 0 {
+		t.Fatal("expected an unsigned PST with inputs", pst)
+	}
+	for _, in := range pst.Inputs {
+		if in.Signed || in.Signatures != 0 || in.Value.IsZero() || len(in.KeyHints) != 1 {
+			t.Fatal("wrong input metadata", in)
+		}
+	}
+
+	// The spent outputs must not be used again by the wallet.
+	pst2, err := wt.wallet.FundPST([]types.SiacoinOutput{{Value: amount}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, in := range pst.Inputs {
+		for _, in2 := range pst2.Inputs {
+			if in.ParentID == in2.ParentID {
+				t.Fatal("output used by two PSTs", in.ParentID)
+			}
+		}
+	}
+
+	// PSTs of other versions or with mismatched inputs are rejected.
+	invalid := pst
+	invalid.Version++
+	if err := UpdatePSTStatus(&invalid); err != errPSTVersion {
+		t.Fatal("expected errPSTVersion, got", err)
+	}
+	invalid = pst
+	invalid.Inputs = invalid.Inputs[1:]
+	if err := UpdatePSTStatus(&invalid); err != errPSTInputs {
+		t.Fatal("expected errPSTInputs, got", err)
+	}
+
+	// Sign the PST offline, once using the key hints and once searching the
+	// seed for the keys.
+	seed, _, err := wt.wallet.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := pst
+	signed.Inputs = append([]modules.PSTInput(nil), pst.Inputs...)
+	if err := SignPST(&signed, seed); err != nil {
+		t.Fatal(err)
+	}
+	unhinted := pst
+	unhinted.Inputs = append([]modules.PSTInput(nil), pst.Inputs...)
+	for i := range unhinted.Inputs {
+		unhinted.Inputs[i].KeyHints = nil
+	}
+	if err := SignPST(&unhinted, seed); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []modules.PartiallySignedTransaction{signed, unhinted} {
+		for _, in := range p.Inputs {
+			if !in.Signed || in.Signatures != 1 {
+				t.Fatal("input was not signed", in)
+			}
+		}
+		if err := p.Transaction.StandaloneValid(p.Height); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := SignPST(&signed, seed); err != errPSTNothingToSign {
+		t.Fatal("expected errPSTNothingToSign, got", err)
+	}
+
+	// Combining the unsigned PST with the signed PST results in the signed
+	// PST.
+	combined, err := CombinePSTs([]modules.PartiallySignedTransaction{pst, signed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if combined.Transaction.ID() != signed.Transaction.ID() || len(combined.Transaction.TransactionSignatures) != len(signed.Transaction.TransactionSignatures) {
+		t.Fatal("combined PST differs from the signed PST")
+	}
+
+	// The wallet can sign the second PST itself.
+	if err := wt.wallet.SignPST(&pst2); err != nil {
+		t.Fatal(err)
+	}
+	if err := pst2.Transaction.StandaloneValid(pst2.Height); err != nil {
+		t.Fatal(err)
+	}
+
+	// Broadcast both transactions.
+	for _, p := range []modules.PartiallySignedTransaction{combined, pst2} {
+		if err := wt.tpool.AcceptTransactionSet(append(p.Parents, p.Transaction)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Wrapping the confirmed transaction fails, since its outputs are spent.
+	if _, err := wt.wallet.CreatePST(combined.Transaction, nil); err != errPSTUnknownOutput {
+		t.Fatal("expected errPSTUnknownOutput, got", err)
+	}
+}
+
+// TestPSTLoadedSeed checks that the inputs of a PST that are funded by a loaded
+// seed have key hints and can be signed offline with that seed.
+func TestPSTLoadedSeed(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+	for wt.cs.Height() <= types.ASICHardforkHeight {
+		if _, err := wt.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Load the seed of the wallet tester into a new wallet, whose coins then
+	// all belong to the loaded seed.
+	seed, _, err := wt.wallet.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet.Close()
+	dir := filepath.Join(build.TempDir(modules.WalletDir, t.Name()+"1"), modules.WalletDir)
+	w, err := New(wt.cs, wt.tpool, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	newSeed, err := w.Encrypt(crypto.TwofishKey{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	masterKey := crypto.TwofishKey(crypto.HashObject(newSeed))
+	if err := w.Unlock(masterKey); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100e6, func() error {
+		if !wt.cs.Synced() {
+			return errors.New("consensus set is not synced")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.LoadSeed(masterKey, seed); err != nil {
+		t.Fatal(err)
+	}
+
+	pst, err := w.FundPST([]types.SiacoinOutput{{Value: types.SiacoinPrecision.Mul64(100)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, in := range pst.Inputs {
+		if len(in.KeyHints) != 1 {
+			t.Fatal("input of the loaded seed has no key hint", in)
+		}
+	}
+	if err := SignPST(&pst, newSeed); err != errPSTNothingToSign {
+		t.Fatal("expected errPSTNothingToSign, got", err)
+	}
+	if err := SignPST(&pst, seed); err != nil {
+		t.Fatal(err)
+	}
+	if err := pst.Transaction.StandaloneValid(pst.Height); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.tpool.AcceptTransactionSet(append(pst.Parents, pst.Transaction)); err != nil {
+		t.Fatal(err)
+	}
+}
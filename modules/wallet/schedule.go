@@ -0,0 +1,234 @@
+package wallet
+
+import (
+	"errors"
+	"sort"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/fastrand"
+)
+
+var (
+	// errScheduleInPast is returned when scheduling a payment at a height
+	// that has already been reached.
+	errScheduleInPast = errors.New("payment height must be greater than the current height")
+
+	// errScheduleNoOutputs is returned when scheduling a payment without
+	// outputs.
+	errScheduleNoOutputs = errors.New("payment needs at least one output")
+
+	// errScheduleNotRecurring is returned when scheduling a payment that is
+	// repeated but has no interval.
+	errScheduleNotRecurring = errors.New("only payments with an interval can be repeated")
+
+	// errUnknownScheduledPayment is returned when cancelling a payment that
+	// is not scheduled.
+	errUnknownScheduledPayment = errors.New("no payment with that ID is scheduled")
+)
+
+// SchedulePayment persists a payment that the wallet broadcasts once the
+// blockchain reaches its height. Payments are only broadcast while the wallet
+// is unlocked and synced; a payment that is due while the wallet is locked is
+// broadcast at the first block after it is unlocked again.
+func (w *Wallet) SchedulePayment(sp modules.ScheduledPayment) (modules.ScheduledPayment, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.ScheduledPayment{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if len(sp.Outputs) == 0 {
+		return modules.ScheduledPayment{}, errScheduleNoOutputs
+	} else if sp.Interval == 0 && sp.Remaining > 0 {
+		return modules.ScheduledPayment{}, errScheduleNotRecurring
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return modules.ScheduledPayment{}, err
+	}
+	if sp.Height <= height {
+		return modules.ScheduledPayment{}, errScheduleInPast
+	}
+	sp.ID = crypto.Hash{}
+	fastrand.Read(sp.ID[:])
+	sp.Executions = 0
+	sp.LastTransactionID = types.TransactionID{}
+	sp.LastError = ""
+	if err := dbPutScheduledPayment(w.dbTx, sp); err != nil {
+		return modules.ScheduledPayment{}, err
+	}
+	return sp, w.syncDB()
+}
+
+// ScheduledPayments returns the payments that are scheduled, ordered by
+// height.
+func (w *Wallet) ScheduledPayments() ([]modules.ScheduledPayment, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var sps []modules.ScheduledPayment
+	err := dbForEachScheduledPayment(w.dbTx, func(_ crypto.Hash, sp modules.ScheduledPayment) {
+		sps = append(sps, sp)
+	})
+	sort.SliceStable(sps, func(i, j int) bool {
+		return sps[i].Height < sps[j].Height
+	})
+	return sps, err
+}
+
+// CancelScheduledPayment removes a scheduled payment.
+func (w *Wallet) CancelScheduledPayment(id crypto.Hash) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := dbGetScheduledPayment(w.dbTx, id); err == errNoKey {
+		return errUnknownScheduledPayment
+	} else if err != nil {
+		return err
+	}
+	if err := dbDeleteScheduledPayment(w.dbTx, id); err != nil {
+		return err
+	}
+	return w.syncDB()
+}
+
+// DryRunPayment checks that the wallet could fund a transaction paying
+// outputs like SendSiacoins or SendSiacoinsMulti would, without broadcasting
+// it, and returns the miner fee it would pay. It selects the funding outputs
+// the same way, but neither reserves them nor uses up any addresses for the
+// change.
+func (w *Wallet) DryRunPayment(outputs []types.SiacoinOutput) (types.Currency, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.Currency{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if len(outputs) == 0 {
+		return types.Currency{}, errScheduleNoOutputs
+	}
+	dustThreshold, err := w.DustThreshold()
+	if err != nil {
+		return types.Currency{}, err
+	}
+
+	fee := w.sendFee(outputs, len(outputs) > 1)
+	totalCost := fee
+	for _, sco := range outputs {
+		totalCost = totalCost.Add(sco.Value)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return types.Currency{}, modules.ErrLockedWallet
+	}
+	consensusHeight, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return types.Currency{}, err
+	}
+	so, err := w.siacoinOutputs()
+	if err != nil {
+		return types.Currency{}, err
+	}
+	if _, err := w.selectOutputs(w.dbTx, consensusHeight, so, totalCost, dustThreshold, modules.CoinControl{}); err != nil {
+		return types.Currency{}, err
+	}
+	return fee, nil
+}
+
+// threadedExecuteScheduledPayments broadcasts the scheduled payments that are
+// due. Payments that fail are retried at the next block. Occurrences of a
+// recurring payment that were missed, because the wallet was offline or
+// locked, are skipped rather than paid all at once.
+func (w *Wallet) threadedExecuteScheduledPayments() {
+	if err := w.tg.Add(); err != nil {
+		return
+	}
+	defer w.tg.Done()
+
+	// Collect the payments that are due. Only one thread executes payments
+	// at a time, so that no payment is sent twice.
+	w.mu.Lock()
+	if !w.unlocked || w.watchOnly || w.executingPayments {
+		w.mu.Unlock()
+		return
+	}
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		w.mu.Unlock()
+		return
+	}
+	var due []crypto.Hash
+	dbForEachScheduledPayment(w.dbTx, func(id crypto.Hash, sp modules.ScheduledPayment) {
+		if sp.Height <= height {
+			due = append(due, id)
+		}
+	})
+	w.executingPayments = len(due) > 0
+	w.mu.Unlock()
+	if len(due) == 0 {
+		return
+	}
+	defer func() {
+		w.mu.Lock()
+		w.executingPayments = false
+		w.mu.Unlock()
+	}()
+
+	for _, id := range due {
+		// The payment may have been cancelled in the meantime.
+		w.mu.Lock()
+		sp, err := dbGetScheduledPayment(w.dbTx, id)
+		w.mu.Unlock()
+		if err != nil {
+			continue
+		}
+
+		var txns []types.Transaction
+		if len(sp.Outputs) == 1 {
+			txns, err = w.SendSiacoins(sp.Outputs[0].Value, sp.Outputs[0].UnlockHash)
+		} else {
+			txns, err = w.SendSiacoinsMulti(sp.Outputs)
+		}
+
+		// A payment is done after it has been broadcast for the last time.
+		done := false
+		w.mu.Lock()
+		if err != nil {
+			w.log.Printf("WARN: scheduled payment %v failed: %v", sp.ID, err)
+			sp.LastError = err.Error()
+		} else {
+			w.log.Printf("Broadcast scheduled payment %v in transaction %v", sp.ID, txns[len(txns)-1].ID())
+			done = sp.Interval == 0 || sp.Remaining == 1
+			sp.Executions++
+			sp.LastTransactionID = txns[len(txns)-1].ID()
+			sp.LastError = ""
+			if sp.Remaining > 0 {
+				sp.Remaining--
+			}
+			for sp.Interval > 0 && sp.Height <= height {
+				sp.Height += sp.Interval
+			}
+		}
+		if done {
+			err = dbDeleteScheduledPayment(w.dbTx, id)
+		} else {
+			err = dbPutScheduledPayment(w.dbTx, sp)
+		}
+		if err != nil {
+			w.log.Println("WARN: could not update scheduled payment:", err)
+		}
+		w.syncDB()
+		w.mu.Unlock()
+	}
+}
@@ -0,0 +1,249 @@
+package wallet
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/errors"
+)
+
+// TestTimelockedAddress tests sending coins to a timelocked address of the
+// wallet and sweeping them once the timelock has expired.
+func TestTimelockedAddress(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	if _, err := wt.wallet.NewTimelockedAddress(wt.cs.Height()); err != errTimelockInPast {
+		t.Fatal("expected errTimelockInPast, got", err)
+	}
+	unlockHeight := wt.cs.Height() + 5
+	uc, err := wt.wallet.NewTimelockedAddress(unlockHeight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uc.Timelock != unlockHeight {
+		t.Fatal("wrong timelock", uc.Timelock)
+	}
+	amount := types.SiacoinPrecision.Mul64(100)
+	if _, err := wt.wallet.SendSiacoins(amount, uc.UnlockHash()); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The wallet tracks the output, but can't spend it yet.
+	var found bool
+	uos, err := wt.wallet.UnspentOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, uo := range uos {
+		found = found || (uo.UnlockHash == uc.UnlockHash() && uo.Value.Equals(amount))
+	}
+	if !found {
+		t.Fatal("wallet doesn't track the output of the timelocked address")
+	}
+	if _, err := wt.wallet.SweepTimelocked(); err != errNoTimelockedOutputs {
+		t.Fatal("expected errNoTimelockedOutputs, got", err)
+	}
+
+	// The address survives locking and unlocking the wallet.
+	if err := wt.wallet.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.Unlock(wt.walletMasterKey); err != nil {
+		t.Fatal(err)
+	}
+	ucs, err := wt.wallet.TimelockedAddresses()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(ucs) != 1 || ucs[0].UnlockHash() != uc.UnlockHash() {
+		t.Fatal("wrong timelocked addresses", ucs)
+	}
+
+	// Once the timelock expires, the output can be swept.
+	for wt.cs.Height() < unlockHeight {
+		if err := wt.addBlockNoPayout(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	txns, err := wt.wallet.SweepTimelocked()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	if confirmed, err := wt.tpool.TransactionConfirmed(txns[len(txns)-1].ID()); err != nil || !confirmed {
+		t.Fatal("sweep transaction was not confirmed", err)
+	}
+	if _, err := wt.wallet.SweepTimelocked(); err != errNoTimelockedOutputs {
+		t.Fatal("expected errNoTimelockedOutputs, got", err)
+	}
+}
+
+// TestScheduledPayments tests scheduling, broadcasting and cancelling
+// payments.
+func TestScheduledPayments(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Payments are only broadcast while the consensus set is synced.
+	err = build.Retry(100, 100e6, func() error {
+		if !wt.cs.Synced() {
+			return errors.New("consensus set is not synced")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dest types.UnlockHash
+	dest[0] = 1
+	outputs := []types.SiacoinOutput{{Value: types.SiacoinPrecision, UnlockHash: dest}}
+	height := wt.cs.Height()
+
+	// Invalid payments are rejected.
+	if _, err := wt.wallet.SchedulePayment(modules.ScheduledPayment{Height: height + 1}); err != errScheduleNoOutputs {
+		t.Fatal("expected errScheduleNoOutputs, got", err)
+	}
+	if _, err := wt.wallet.SchedulePayment(modules.ScheduledPayment{Outputs: outputs, Height: height}); err != errScheduleInPast {
+		t.Fatal("expected errScheduleInPast, got", err)
+	}
+	if _, err := wt.wallet.SchedulePayment(modules.ScheduledPayment{Outputs: outputs, Height: height + 1, Remaining: 2}); err != errScheduleNotRecurring {
+		t.Fatal("expected errScheduleNotRecurring, got", err)
+	}
+
+	// A dry run reports the fee without spending anything or using up
+	// addresses.
+	wt.wallet.mu.Lock()
+	progress, err := dbGetPrimarySeedProgress(wt.wallet.dbTx)
+	wt.wallet.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.DryRunPayment([]types.SiacoinOutput{{Value: types.SiacoinPrecision.Mul64(1e12)}}); !errors.Contains(err, modules.ErrLowBalance) {
+		t.Fatal("expected ErrLowBalance, got", err)
+	}
+	fee, err := wt.wallet.DryRunPayment(outputs)
+	if err != nil {
+		t.Fatal(err)
+	} else if fee.IsZero() {
+		t.Fatal("dry run reported no fee")
+	}
+	if len(wt.tpool.TransactionList()) != 0 {
+		t.Fatal("dry run broadcast a transaction")
+	}
+	wt.wallet.mu.Lock()
+	dryRunProgress, err := dbGetPrimarySeedProgress(wt.wallet.dbTx)
+	wt.wallet.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	} else if dryRunProgress != progress {
+		t.Fatal("dry run used up addresses of the primary seed")
+	}
+
+	// Schedule a one-off payment, a payment that is repeated twice and a
+	// payment that is cancelled.
+	once, err := wt.wallet.SchedulePayment(modules.ScheduledPayment{Outputs: outputs, Height: height + 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recurring, err := wt.wallet.SchedulePayment(modules.ScheduledPayment{Outputs: append(outputs, outputs...), Height: height + 1, Interval: 2, Remaining: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelled, err := wt.wallet.SchedulePayment(modules.ScheduledPayment{Outputs: outputs, Height: height + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.CancelScheduledPayment(cancelled.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.CancelScheduledPayment(cancelled.ID); err != errUnknownScheduledPayment {
+		t.Fatal("expected errUnknownScheduledPayment, got", err)
+	}
+	sps, err := wt.wallet.ScheduledPayments()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(sps) != 2 || sps[0].ID != recurring.ID || sps[1].ID != once.ID {
+		t.Fatal("wrong scheduled payments", sps)
+	}
+
+	// scheduled returns the scheduled payment with the provided ID, waiting
+	// for the wallet to broadcast the payments that are due.
+	scheduled := func(id crypto.Hash) (sp modules.ScheduledPayment, exists bool) {
+		err := build.Retry(50, 100e6, func() error {
+			sps, err := wt.wallet.ScheduledPayments()
+			if err != nil {
+				return err
+			}
+			exists = false
+			for _, s := range sps {
+				if s.ID == id {
+					sp, exists = s, true
+				}
+				if s.Height <= wt.cs.Height() {
+					return errors.New("payment is still due")
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sp, exists
+	}
+
+	// Mine blocks and check that the payments are broadcast at their heights.
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	sp, exists := scheduled(recurring.ID)
+	if !exists || sp.Executions != 1 || sp.Remaining != 1 || sp.Height != height+3 {
+		t.Fatalf("recurring payment wasn't broadcast: %+v", sp)
+	}
+	if _, exists := scheduled(once.ID); !exists {
+		t.Fatal("one-off payment was broadcast too early")
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := scheduled(once.ID); exists {
+		t.Fatal("one-off payment wasn't broadcast")
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := scheduled(recurring.ID); exists {
+		t.Fatal("recurring payment wasn't removed after its last payment")
+	}
+
+	// All payments were confirmed by the following block.
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	if confirmed, err := wt.tpool.TransactionConfirmed(sp.LastTransactionID); err != nil || !confirmed {
+		t.Fatal("recurring payment was not confirmed", err)
+	}
+	if txns := wt.tpool.TransactionList(); len(txns) != 0 {
+		t.Fatal("payments were not confirmed", len(txns))
+	}
+}
@@ -99,7 +99,7 @@ func (w *Wallet) regenerateLookahead(start uint64) {
 	maxKeys := maxLookahead(start)
 	existingKeys := uint64(len(w.lookahead))
 
-	for i, k := range generateKeys(w.primarySeed, start+existingKeys, maxKeys-existingKeys) {
+	for i, k := range w.primarySeedKeys(start+existingKeys, maxKeys-existingKeys) {
 		w.lookahead[k.UnlockConditions.UnlockHash()] = start + existingKeys + uint64(i)
 	}
 }
@@ -107,11 +107,48 @@ func (w *Wallet) regenerateLookahead(start uint64) {
 // integrateSeed generates n spendableKeys from the seed and loads them into
 // the wallet.
 func (w *Wallet) integrateSeed(seed modules.Seed, n uint64) {
-	for _, sk := range generateKeys(seed, 0, n) {
-		w.keys[sk.UnlockConditions.UnlockHash()] = sk
+	for i, sk := range generateKeys(seed, 0, n) {
+		uh := sk.UnlockConditions.UnlockHash()
+		w.keys[uh] = sk
+		w.seedIndices[uh] = uint64(i)
 	}
 }
 
+// primarySeedKeys generates n spendableKeys from the primary seed, starting
+// at index start. A watch-only wallet has no primary seed; it returns keys
+// without secret keys for the public keys it was given instead, which may be
+// fewer than n.
+func (w *Wallet) primarySeedKeys(start, n uint64) []spendableKey {
+	if !w.watchOnly {
+		return generateKeys(w.primarySeed, start, n)
+	}
+	numKeys := uint64(len(w.watchKeys))
+	if start >= numKeys {
+		return nil
+	} else if start+n > numKeys {
+		n = numKeys - start
+	}
+	keys := make([]spendableKey, n)
+	for i := range keys {
+		keys[i].UnlockConditions = watchKeyUnlockConditions(w.watchKeys[start+uint64(i)])
+	}
+	return keys
+}
+
+// integratePrimarySeedKeys generates n spendableKeys from the primary seed,
+// starting at index start, and loads them into the wallet. The keys are
+// removed from the lookahead.
+func (w *Wallet) integratePrimarySeedKeys(start, n uint64) []spendableKey {
+	spendableKeys := w.primarySeedKeys(start, n)
+	for i, sk := range spendableKeys {
+		uh := sk.UnlockConditions.UnlockHash()
+		w.keys[uh] = sk
+		w.seedIndices[uh] = start + uint64(i)
+		delete(w.lookahead, uh)
+	}
+	return spendableKeys
+}
+
 // nextPrimarySeedAddress fetches the next n addresses from the primary seed.
 func (w *Wallet) nextPrimarySeedAddresses(tx *bolt.Tx, n uint64) ([]types.UnlockConditions, error) {
 	// Check that the wallet has been unlocked.
@@ -124,17 +161,18 @@ func (w *Wallet) nextPrimarySeedAddresses(tx *bolt.Tx, n uint64) ([]types.Unlock
 	if err != nil {
 		return []types.UnlockConditions{}, err
 	}
+	if w.watchOnly && progress+n > uint64(len(w.watchKeys)) {
+		return []types.UnlockConditions{}, errWatchKeysExhausted
+	}
 	if err = dbPutPrimarySeedProgress(tx, progress+n); err != nil {
 		return []types.UnlockConditions{}, err
 	}
 	// Integrate the next keys into the wallet, and return the unlock
 	// conditions. Also remove new keys from the future keys and update them
 	// according to new progress
-	spendableKeys := generateKeys(w.primarySeed, progress, n)
+	spendableKeys := w.integratePrimarySeedKeys(progress, n)
 	ucs := make([]types.UnlockConditions, 0, len(spendableKeys))
 	for _, spendableKey := range spendableKeys {
-		w.keys[spendableKey.UnlockConditions.UnlockHash()] = spendableKey
-		delete(w.lookahead, spendableKey.UnlockConditions.UnlockHash())
 		ucs = append(ucs, spendableKey.UnlockConditions)
 	}
 	w.regenerateLookahead(progress + n)
@@ -157,6 +195,8 @@ func (w *Wallet) AllSeeds() ([]modules.Seed, error) {
 	defer w.mu.Unlock()
 	if !w.unlocked {
 		return nil, modules.ErrLockedWallet
+	} else if w.watchOnly {
+		return nil, errWatchOnly
 	}
 	return append([]modules.Seed{w.primarySeed}, w.seeds...), nil
 }
@@ -168,6 +208,8 @@ func (w *Wallet) PrimarySeed() (modules.Seed, uint64, error) {
 	defer w.mu.Unlock()
 	if !w.unlocked {
 		return modules.Seed{}, 0, modules.ErrLockedWallet
+	} else if w.watchOnly {
+		return modules.Seed{}, 0, errWatchOnly
 	}
 	progress, err := dbGetPrimarySeedProgress(w.dbTx)
 	if err != nil {
@@ -245,6 +287,9 @@ func (w *Wallet) LoadSeed(masterKey crypto.TwofishKey, seed modules.Seed) error
 	if !w.unlocked {
 		w.mu.RUnlock()
 		return modules.ErrLockedWallet
+	} else if w.watchOnly {
+		w.mu.RUnlock()
+		return errWatchOnly
 	}
 	for _, wSeed := range append([]modules.Seed{w.primarySeed}, w.seeds...) {
 		if seed == wSeed {
@@ -314,19 +359,13 @@ func (w *Wallet) LoadSeed(masterKey crypto.TwofishKey, seed modules.Seed) error
 	}
 
 	// rescan the blockchain
-	w.cs.Unsubscribe(w)
-	w.tpool.Unsubscribe(w)
+	w.managedUnsubscribe()
 
 	done := make(chan struct{})
 	go w.rescanMessage(done)
 	defer close(done)
 
-	err = w.cs.ConsensusSetSubscribe(w, modules.ConsensusChangeBeginning, w.tg.StopChan())
-	if err != nil {
-		return err
-	}
-	w.tpool.TransactionPoolSubscribe(w)
-	return nil
+	return w.managedSubscribe(modules.ConsensusChangeBeginning)
 }
 
 // SweepSeed scans the blockchain for outputs generated from seed and creates
@@ -346,8 +385,11 @@ func (w *Wallet) SweepSeed(seed modules.Seed) (coins, funds types.Currency, err
 
 	w.mu.RLock()
 	match := seed == w.primarySeed
+	watchOnly := w.watchOnly
 	w.mu.RUnlock()
-	if match {
+	if watchOnly {
+		return types.Currency{}, types.Currency{}, errWatchOnly
+	} else if match {
 		return types.Currency{}, types.Currency{}, errors.New("cannot sweep primary seed")
 	}
 
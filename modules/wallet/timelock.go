@@ -0,0 +1,178 @@
+package wallet
+
+import (
+	"errors"
+	"sort"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errNoTimelockedOutputs is returned when sweeping timelocked addresses
+	// that have no spendable outputs.
+	errNoTimelockedOutputs = errors.New("no outputs of timelocked addresses are spendable yet")
+
+	// errTimelockInPast is returned when creating a timelocked address with
+	// an unlock height that has already been reached.
+	errTimelockInPast = errors.New("unlock height must be greater than the current height")
+
+	// errTimelockedDust is returned when the spendable outputs of the
+	// timelocked addresses don't cover the fee of sweeping them.
+	errTimelockedDust = errors.New("timelocked outputs don't cover the transaction fee")
+)
+
+// integrateTimelockedAddress adds the key of a timelocked address to the
+// wallet. The address uses the key of a primary seed address, which must
+// already be integrated.
+func (w *Wallet) integrateTimelockedAddress(uc types.UnlockConditions) {
+	base := uc
+	base.Timelock = 0
+	key, exists := w.keys[base.UnlockHash()]
+	if !exists {
+		w.log.Println("WARN: key of timelocked address", uc.UnlockHash(), "is missing")
+		return
+	}
+	w.keys[uc.UnlockHash()] = spendableKey{
+		UnlockConditions: uc,
+		SecretKeys:       key.SecretKeys,
+	}
+}
+
+// NewTimelockedAddress returns the unlock conditions of a new address of the
+// wallet that can't be spent from before unlockHeight. The wallet tracks the
+// outputs of the address like those of its other addresses, and uses them to
+// fund transactions once the timelock has expired.
+func (w *Wallet) NewTimelockedAddress(unlockHeight types.BlockHeight) (types.UnlockConditions, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.UnlockConditions{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return types.UnlockConditions{}, err
+	}
+	if unlockHeight <= height {
+		return types.UnlockConditions{}, errTimelockInPast
+	}
+	uc, err := w.nextPrimarySeedAddress(w.dbTx)
+	if err != nil {
+		return types.UnlockConditions{}, err
+	}
+	uc.Timelock = unlockHeight
+	if err := dbPutTimelockedAddress(w.dbTx, uc); err != nil {
+		return types.UnlockConditions{}, err
+	}
+	w.integrateTimelockedAddress(uc)
+	return uc, w.syncDB()
+}
+
+// TimelockedAddresses returns the unlock conditions of the timelocked
+// addresses of the wallet, ordered by unlock height.
+func (w *Wallet) TimelockedAddresses() ([]types.UnlockConditions, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var ucs []types.UnlockConditions
+	err := dbForEachTimelockedAddress(w.dbTx, func(_ types.UnlockHash, uc types.UnlockConditions) {
+		ucs = append(ucs, uc)
+	})
+	sort.Slice(ucs, func(i, j int) bool {
+		return ucs[i].Timelock < ucs[j].Timelock
+	})
+	return ucs, err
+}
+
+// SweepTimelocked sends the confirmed outputs of the timelocked addresses
+// whose timelock has expired to a new address of the wallet.
+func (w *Wallet) SweepTimelocked() (txns []types.Transaction, err error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	dustThreshold, err := w.DustThreshold()
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the spendable outputs of the timelocked addresses.
+	var ids []types.SiacoinOutputID
+	var fund types.Currency
+	err = func() error {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if !w.unlocked {
+			return modules.ErrLockedWallet
+		}
+		height, err := dbGetConsensusHeight(w.dbTx)
+		if err != nil {
+			return err
+		}
+		timelocked := make(map[types.UnlockHash]struct{})
+		err = dbForEachTimelockedAddress(w.dbTx, func(addr types.UnlockHash, _ types.UnlockConditions) {
+			timelocked[addr] = struct{}{}
+		})
+		if err != nil {
+			return err
+		}
+		return dbForEachSiacoinOutput(w.dbTx, func(id types.SiacoinOutputID, sco types.SiacoinOutput) {
+			if _, ok := timelocked[sco.UnlockHash]; !ok {
+				return
+			}
+			if w.checkOutput(w.dbTx, height, id, sco, dustThreshold) == nil {
+				ids = append(ids, id)
+				fund = fund.Add(sco.Value)
+			}
+		})
+	}()
+	if err != nil {
+		return nil, err
+	} else if len(ids) == 0 {
+		return nil, errNoTimelockedOutputs
+	}
+	_, tpoolFee := w.tpool.FeeEstimation()
+	tpoolFee = tpoolFee.Mul64(750 + 250*uint64(len(ids))) // Estimated transaction size in bytes
+	if fund.Cmp(tpoolFee) <= 0 {
+		return nil, errTimelockedDust
+	}
+	uc, err := w.NextAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	txnBuilder, err := w.StartTransaction()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			txnBuilder.Drop()
+		}
+	}()
+	err = txnBuilder.FundSiacoinsWithCoinControl(fund, modules.CoinControl{Pinned: ids})
+	if err != nil {
+		return nil, build.ExtendErr("unable to fund transaction", err)
+	}
+	txnBuilder.AddMinerFee(tpoolFee)
+	txnBuilder.AddSiacoinOutput(types.SiacoinOutput{
+		Value:      fund.Sub(tpoolFee),
+		UnlockHash: uc.UnlockHash(),
+	})
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		return nil, build.ExtendErr("unable to sign transaction", err)
+	}
+	if err = w.tpool.AcceptTransactionSet(txnSet); err != nil {
+		return nil, build.ExtendErr("unable to get transaction accepted", err)
+	}
+	w.log.Printf("Swept %v from %v timelocked outputs to %v", fund.HumanString(), len(ids), uc.UnlockHash())
+	return txnSet, nil
+}
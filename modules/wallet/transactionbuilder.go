@@ -3,7 +3,6 @@ package wallet
 import (
 	"bytes"
 	"errors"
-	"sort"
 
 	"gitlab.com/NebulousLabs/Sia/crypto"
 	"gitlab.com/NebulousLabs/Sia/encoding"
@@ -22,6 +21,14 @@ var (
 	// errDustOutput indicates an output is not spendable because it is dust.
 	errDustOutput = errors.New("output is too small")
 
+	// errOutputFrozen indicates an output is not spendable because the user
+	// froze it.
+	errOutputFrozen = errors.New("output is frozen")
+
+	// errOutputNotSpendable indicates an output can't be spent by the wallet
+	// alone, because it belongs to a watched or multisig address.
+	errOutputNotSpendable = errors.New("output is not spendable by the wallet alone")
+
 	// errOutputTimelock indicates an output's timelock is still active.
 	errOutputTimelock = errors.New("wallet consensus set height is lower than the output timelock")
 
@@ -104,7 +111,15 @@ func (w *Wallet) checkOutput(tx *bolt.Tx, currentHeight types.BlockHeight, id ty
 			return errSpendHeightTooHigh
 		}
 	}
-	outputUnlockConditions := w.keys[output.UnlockHash].UnlockConditions
+	key, spendable := w.keys[output.UnlockHash]
+	if !spendable {
+		return errOutputNotSpendable
+	}
+	// Check that the user hasn't frozen the output.
+	if dbIsFrozenOutput(tx, id) {
+		return errOutputFrozen
+	}
+	outputUnlockConditions := key.UnlockConditions
 	if currentHeight < outputUnlockConditions.Timelock {
 		return errOutputTimelock
 	}
@@ -112,11 +127,44 @@ func (w *Wallet) checkOutput(tx *bolt.Tx, currentHeight types.BlockHeight, id ty
 	return nil
 }
 
+// siacoinOutputs returns the confirmed and unconfirmed siacoin outputs of
+// the wallet, including the ones that are not spendable. The caller must hold
+// the lock.
+func (w *Wallet) siacoinOutputs() (sortedOutputs, error) {
+	var so sortedOutputs
+	err := dbForEachSiacoinOutput(w.dbTx, func(scoid types.SiacoinOutputID, sco types.SiacoinOutput) {
+		so.ids = append(so.ids, scoid)
+		so.outputs = append(so.outputs, sco)
+	})
+	if err != nil {
+		return sortedOutputs{}, err
+	}
+	// Add all of the unconfirmed outputs as well.
+	for _, upt := range w.unconfirmedProcessedTransactions {
+		for i, sco := range upt.Transaction.SiacoinOutputs {
+			// Determine if the output belongs to the wallet.
+			_, exists := w.keys[sco.UnlockHash]
+			if !exists {
+				continue
+			}
+			so.ids = append(so.ids, upt.Transaction.SiacoinOutputID(uint64(i)))
+			so.outputs = append(so.outputs, sco)
+		}
+	}
+	return so, nil
+}
+
 // FundSiacoins will add a siacoin input of exactly 'amount' to the
 // transaction. A parent transaction may be needed to achieve an input with the
 // correct value. The siacoin input will not be signed until 'Sign' is called
 // on the transaction builder.
 func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
+	return tb.FundSiacoinsWithCoinControl(amount, modules.CoinControl{})
+}
+
+// FundSiacoinsWithCoinControl is like FundSiacoins, but selects the outputs
+// that fund the transaction according to cc.
+func (tb *transactionBuilder) FundSiacoinsWithCoinControl(amount types.Currency, cc modules.CoinControl) error {
 	// dustThreshold has to be obtained separate from the lock
 	dustThreshold, err := tb.wallet.DustThreshold()
 	if err != nil {
@@ -131,71 +179,33 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 		return err
 	}
 
-	// Collect a value-sorted set of siacoin outputs.
-	var so sortedOutputs
-	err = dbForEachSiacoinOutput(tb.wallet.dbTx, func(scoid types.SiacoinOutputID, sco types.SiacoinOutput) {
-		so.ids = append(so.ids, scoid)
-		so.outputs = append(so.outputs, sco)
-	})
+	// Collect the set of siacoin outputs.
+	so, err := tb.wallet.siacoinOutputs()
 	if err != nil {
 		return err
 	}
-	// Add all of the unconfirmed outputs as well.
-	for _, upt := range tb.wallet.unconfirmedProcessedTransactions {
-		for i, sco := range upt.Transaction.SiacoinOutputs {
-			// Determine if the output belongs to the wallet.
-			_, exists := tb.wallet.keys[sco.UnlockHash]
-			if !exists {
-				continue
-			}
-			so.ids = append(so.ids, upt.Transaction.SiacoinOutputID(uint64(i)))
-			so.outputs = append(so.outputs, sco)
-		}
-	}
-	sort.Sort(sort.Reverse(so))
 
-	// Create and fund a parent transaction that will add the correct amount of
-	// siacoins to the transaction.
+	// Select the outputs that fund a parent transaction that will add the
+	// correct amount of siacoins to the transaction.
+	selected, err := tb.wallet.selectOutputs(tb.wallet.dbTx, consensusHeight, so, amount, dustThreshold, cc)
+	if err != nil {
+		return err
+	}
 	var fund types.Currency
-	// potentialFund tracks the balance of the wallet including outputs that
-	// have been spent in other unconfirmed transactions recently. This is to
-	// provide the user with a more useful error message in the event that they
-	// are overspending.
-	var potentialFund types.Currency
 	parentTxn := types.Transaction{}
-	var spentScoids []types.SiacoinOutputID
-	for i := range so.ids {
-		scoid := so.ids[i]
-		sco := so.outputs[i]
-		// Check that the output can be spent.
-		if err := tb.wallet.checkOutput(tb.wallet.dbTx, consensusHeight, scoid, sco, dustThreshold); err != nil {
-			if err == errSpendHeightTooHigh {
-				potentialFund = potentialFund.Add(sco.Value)
-			}
-			continue
-		}
-
+	for i, scoid := range selected.ids {
+		sco := selected.outputs[i]
 		// Add a siacoin input for this output.
 		sci := types.SiacoinInput{
 			ParentID:         scoid,
 			UnlockConditions: tb.wallet.keys[sco.UnlockHash].UnlockConditions,
 		}
 		parentTxn.SiacoinInputs = append(parentTxn.SiacoinInputs, sci)
-		spentScoids = append(spentScoids, scoid)
 
 		// Add the output to the total fund
 		fund = fund.Add(sco.Value)
-		potentialFund = potentialFund.Add(sco.Value)
-		if fund.Cmp(amount) >= 0 {
-			break
-		}
-	}
-	if potentialFund.Cmp(amount) >= 0 && fund.Cmp(amount) < 0 {
-		return modules.ErrIncompleteTransactions
-	}
-	if fund.Cmp(amount) < 0 {
-		return modules.ErrLowBalance
 	}
+	spentScoids := selected.ids
 
 	// Create and add the output that will be used to fund the standard
 	// transaction.
@@ -298,7 +308,11 @@ func (tb *transactionBuilder) FundSiafunds(amount types.Currency) error {
 			potentialFund = potentialFund.Add(sfo.Value)
 			continue
 		}
-		outputUnlockConditions := tb.wallet.keys[sfo.UnlockHash].UnlockConditions
+		key, spendable := tb.wallet.keys[sfo.UnlockHash]
+		if !spendable {
+			continue
+		}
+		outputUnlockConditions := key.UnlockConditions
 		if consensusHeight < outputUnlockConditions.Timelock {
 			continue
 		}
@@ -677,6 +691,9 @@ func (w *Wallet) RegisterTransaction(t types.Transaction, parents []types.Transa
 	defer w.tg.Done()
 	w.mu.Lock()
 	defer w.mu.Unlock()
+	if w.watchOnly {
+		return nil, errWatchOnly
+	}
 	return w.registerTransaction(t, parents), nil
 }
 
@@ -36,6 +36,7 @@ func (w *Wallet) AddressTransactions(uh types.UnlockHash) (pts []modules.Process
 		if err != nil {
 			continue
 		}
+		w.annotateTransaction(&pt)
 		pts = append(pts, pt)
 	}
 	return pts, nil
@@ -72,6 +73,7 @@ func (w *Wallet) AddressUnconfirmedTransactions(uh types.UnlockHash) (pts []modu
 			}
 		}
 		if relevant {
+			w.annotateTransaction(&pt)
 			pts = append(pts, pt)
 		}
 	}
@@ -100,6 +102,9 @@ func (w *Wallet) Transaction(txid types.TransactionID) (pt modules.ProcessedTran
 
 	// Retrieve the transaction
 	found = encoding.Unmarshal(w.dbTx.Bucket(bucketProcessedTransactions).Get(keyBytes), &pt) == nil
+	if found {
+		w.annotateTransaction(&pt)
+	}
 	return
 }
 
@@ -191,6 +196,7 @@ func (w *Wallet) Transactions(startHeight, endHeight types.BlockHeight) (pts []m
 		if build.DEBUG && pt.ConfirmationHeight < startHeight {
 			build.Critical("wallet processed transactions are not sorted")
 		}
+		w.annotateTransaction(&pt)
 		pts = append(pts, pt)
 
 		// Get next processed transaction
@@ -214,7 +220,12 @@ func (w *Wallet) UnconfirmedTransactions() ([]modules.ProcessedTransaction, erro
 		return nil, err
 	}
 	defer w.tg.Done()
-	w.mu.RLock()
-	defer w.mu.RUnlock()
-	return w.unconfirmedProcessedTransactions, nil
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var pts []modules.ProcessedTransaction
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		w.annotateTransaction(&pt)
+		pts = append(pts, pt)
+	}
+	return pts, nil
 }
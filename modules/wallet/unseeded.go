@@ -87,6 +87,8 @@ func (w *Wallet) loadSpendableKey(masterKey crypto.TwofishKey, sk spendableKey)
 	// the wallet is locked, correct deduplication is uncertain.
 	if !w.unlocked {
 		return modules.ErrLockedWallet
+	} else if w.watchOnly {
+		return errWatchOnly
 	}
 
 	// Check for duplicates.
@@ -206,19 +208,13 @@ func (w *Wallet) LoadSiagKeys(masterKey crypto.TwofishKey, keyfiles []string) er
 	}
 
 	// rescan the blockchain
-	w.cs.Unsubscribe(w)
-	w.tpool.Unsubscribe(w)
+	w.managedUnsubscribe()
 
 	done := make(chan struct{})
 	go w.rescanMessage(done)
 	defer close(done)
 
-	err = w.cs.ConsensusSetSubscribe(w, modules.ConsensusChangeBeginning, w.tg.StopChan())
-	if err != nil {
-		return err
-	}
-	w.tpool.TransactionPoolSubscribe(w)
-	return nil
+	return w.managedSubscribe(modules.ConsensusChangeBeginning)
 }
 
 // Load033xWallet loads a v0.3.3.x wallet as an unseeded key, such that the
@@ -276,18 +272,11 @@ func (w *Wallet) Load033xWallet(masterKey crypto.TwofishKey, filepath033x string
 	}
 
 	// rescan the blockchain
-	w.cs.Unsubscribe(w)
-	w.tpool.Unsubscribe(w)
+	w.managedUnsubscribe()
 
 	done := make(chan struct{})
 	go w.rescanMessage(done)
 	defer close(done)
 
-	err = w.cs.ConsensusSetSubscribe(w, modules.ConsensusChangeBeginning, w.tg.StopChan())
-	if err != nil {
-		return err
-	}
-	w.tpool.TransactionPoolSubscribe(w)
-
-	return nil
+	return w.managedSubscribe(modules.ConsensusChangeBeginning)
 }
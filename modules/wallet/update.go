@@ -23,17 +23,38 @@ func (w *Wallet) threadedResetSubscriptions() error {
 	}
 	defer w.scanLock.Unlock()
 
-	w.cs.Unsubscribe(w)
-	w.tpool.Unsubscribe(w)
+	w.managedUnsubscribe()
 
-	err := w.cs.ConsensusSetSubscribe(w, modules.ConsensusChangeBeginning, w.tg.StopChan())
-	if err != nil {
+	return w.managedSubscribe(modules.ConsensusChangeBeginning)
+}
+
+// managedSubscribe subscribes the wallet to the consensus set, starting after
+// the given consensus change, and to the transaction pool. A named wallet is
+// only subscribed until it has caught up, after which it receives the updates
+// through the subscriptions of its primary wallet.
+func (w *Wallet) managedSubscribe(start modules.ConsensusChangeID) error {
+	if w.staticFeed != nil {
+		return w.staticFeed.managedCatchUp(start)
+	}
+	if err := w.cs.ConsensusSetSubscribe(w, start, w.tg.StopChan()); err != nil {
 		return err
 	}
 	w.tpool.TransactionPoolSubscribe(w)
 	return nil
 }
 
+// managedUnsubscribe unsubscribes the wallet from the consensus set and the
+// transaction pool. A named wallet stops receiving the updates of its primary
+// wallet instead.
+func (w *Wallet) managedUnsubscribe() {
+	if w.staticFeed != nil {
+		w.staticFeed.staticSet.managedUnfollow(w.staticFeed)
+		return
+	}
+	w.cs.Unsubscribe(w)
+	w.tpool.Unsubscribe(w)
+}
+
 // advanceSeedLookahead generates all keys from the current primary seed progress up to index
 // and adds them to the set of spendable keys.  Therefore the new primary seed progress will
 // be index+1 and new lookahead keys will be generated starting from index+1
@@ -46,11 +67,7 @@ func (w *Wallet) advanceSeedLookahead(index uint64) (bool, error) {
 	newProgress := index + 1
 
 	// Add spendable keys and remove them from lookahead
-	spendableKeys := generateKeys(w.primarySeed, progress, newProgress-progress)
-	for _, key := range spendableKeys {
-		w.keys[key.UnlockConditions.UnlockHash()] = key
-		delete(w.lookahead, key.UnlockConditions.UnlockHash())
-	}
+	spendableKeys := w.integratePrimarySeedKeys(progress, newProgress-progress)
 
 	// Update the primarySeedProgress
 	dbPutPrimarySeedProgress(w.dbTx, newProgress)
@@ -71,11 +88,13 @@ func (w *Wallet) advanceSeedLookahead(index uint64) (bool, error) {
 }
 
 // isWalletAddress is a helper function that checks if an UnlockHash is
-// derived from one of the wallet's spendable keys or is being explicitly watched.
+// derived from one of the wallet's spendable keys, is being explicitly
+// watched, or is a registered multisig address.
 func (w *Wallet) isWalletAddress(uh types.UnlockHash) bool {
 	_, spendable := w.keys[uh]
 	_, watchonly := w.watchedAddrs[uh]
-	return spendable || watchonly
+	_, multisig := w.multisigAddrs[uh]
+	return spendable || watchonly || multisig
 }
 
 // updateLookahead uses a consensus change to update the seed progress if one of the outputs
@@ -453,6 +472,11 @@ func (w *Wallet) ProcessConsensusChange(cc modules.ConsensusChange) {
 		w.log.Severe("ERROR: failed to update confirmed set:", err)
 		w.dbRollback = true
 	}
+	reverted, err := w.webhookRevertedTransactions(w.dbTx, cc.RevertedBlocks)
+	if err != nil {
+		w.log.Severe("ERROR: failed to get reverted transactions:", err)
+		w.dbRollback = true
+	}
 	if err := w.revertHistory(w.dbTx, cc.RevertedBlocks); err != nil {
 		w.log.Severe("ERROR: failed to revert consensus change:", err)
 		w.dbRollback = true
@@ -461,6 +485,10 @@ func (w *Wallet) ProcessConsensusChange(cc modules.ConsensusChange) {
 		w.log.Severe("ERROR: failed to apply consensus change:", err)
 		w.dbRollback = true
 	}
+	if err := w.queueWebhookEvents(w.dbTx, cc, reverted); err != nil {
+		w.log.Severe("ERROR: failed to queue webhook events:", err)
+		w.dbRollback = true
+	}
 	if err := dbPutConsensusChangeID(w.dbTx, cc.ID); err != nil {
 		w.log.Severe("ERROR: failed to update consensus change ID:", err)
 		w.dbRollback = true
@@ -468,6 +496,7 @@ func (w *Wallet) ProcessConsensusChange(cc modules.ConsensusChange) {
 
 	if cc.Synced {
 		go w.threadedDefragWallet()
+		go w.threadedExecuteScheduledPayments()
 	}
 }
 
@@ -516,6 +545,7 @@ func (w *Wallet) ReceiveUpdatedUnconfirmedTransactions(diff *modules.Transaction
 	}
 
 	// Scroll through all of the diffs and add any new transactions.
+	numUPT := len(w.unconfirmedProcessedTransactions)
 	for _, unconfirmedTxnSet := range diff.AppliedTransactions {
 		// Mark all of the transactions that appeared in this set.
 		//
@@ -583,4 +613,7 @@ func (w *Wallet) ReceiveUpdatedUnconfirmedTransactions(diff *modules.Transaction
 			w.unconfirmedProcessedTransactions = append(w.unconfirmedProcessedTransactions, pt)
 		}
 	}
+	if err := w.queueWebhookPendingEvents(w.dbTx, w.unconfirmedProcessedTransactions[numUPT:]); err != nil {
+		w.log.Println("ERROR: failed to queue webhook events:", err)
+	}
 }
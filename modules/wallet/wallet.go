@@ -6,8 +6,10 @@ package wallet
 import (
 	"bytes"
 	"fmt"
+	"path/filepath"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/coreos/bbolt"
 
@@ -60,6 +62,12 @@ type Wallet struct {
 	subscribed  bool
 	primarySeed modules.Seed
 
+	// watchOnly indicates that the wallet was initialized from the public
+	// keys in watchKeys instead of a primary seed. A watch-only wallet has no
+	// secret keys, so it can't sign transactions.
+	watchOnly bool
+	watchKeys []types.SiaPublicKey
+
 	// The wallet's dependencies.
 	cs    modules.ConsensusSet
 	tpool modules.TransactionPool
@@ -76,6 +84,17 @@ type Wallet struct {
 	lookahead    map[types.UnlockHash]uint64
 	watchedAddrs map[types.UnlockHash]struct{}
 
+	// seedIndices contains the derivation indices of the keys of the primary
+	// and auxiliary seeds. They are passed to offline signers as hints, so
+	// that they don't have to derive every key of the seed.
+	seedIndices map[types.UnlockHash]uint64
+
+	// multisigAddrs contains the unlock conditions of the multisig addresses
+	// registered with the wallet. Their outputs are tracked like the outputs
+	// of the wallet's own addresses, but they can't be used to fund
+	// transactions since they need the signatures of other co-signers.
+	multisigAddrs map[types.UnlockHash]types.UnlockConditions
+
 	// unconfirmedProcessedTransactions tracks unconfirmed transactions.
 	//
 	// TODO: Replace this field with a linked list. Currently when a new
@@ -112,6 +131,28 @@ type Wallet struct {
 	// defragDisabled determines if the wallet is set to defrag outputs once it
 	// reaches a certain threshold
 	defragDisabled bool
+
+	// executingPayments is set while a thread is broadcasting the scheduled
+	// payments that are due.
+	executingPayments bool
+
+	// webhookRetries contains the times at which failed deliveries of
+	// webhook events are retried, keyed by delivery. webhooksDelivering
+	// contains the webhooks whose events are being delivered. webhookWake
+	// wakes the thread that starts the deliveries when a new event is
+	// queued.
+	webhookRetries     map[crypto.Hash]time.Time
+	webhooksDelivering map[crypto.Hash]struct{}
+	webhookWake        chan struct{}
+
+	// staticNamed contains the named wallets that share the consensus set
+	// and transaction pool with this wallet. It is nil for named wallets.
+	staticNamed *namedWalletSet
+
+	// staticFeed passes the consensus changes and transaction pool updates
+	// of the primary wallet's subscriptions to a named wallet. It is nil for
+	// primary wallets.
+	staticFeed *namedWalletFeed
 }
 
 // Height return the internal processed consensus height of the wallet
@@ -143,8 +184,26 @@ func New(cs modules.ConsensusSet, tpool modules.TransactionPool, persistDir stri
 	return NewCustomWallet(cs, tpool, persistDir, modules.ProdDependencies)
 }
 
-// NewCustomWallet creates a new wallet using custom dependencies.
+// NewCustomWallet creates a new wallet using custom dependencies. The named
+// wallets of the wallet are persisted in a directory next to persistDir.
 func NewCustomWallet(cs modules.ConsensusSet, tpool modules.TransactionPool, persistDir string, deps modules.Dependencies) (*Wallet, error) {
+	w, err := newWallet(cs, tpool, persistDir, deps)
+	if err != nil {
+		return nil, err
+	}
+	w.staticNamed = &namedWalletSet{
+		dir:       filepath.Join(filepath.Dir(persistDir), modules.WalletsDir),
+		wallets:   make(map[string]*Wallet),
+		followers: make(map[*namedWalletFeed]struct{}),
+	}
+	if err := w.managedLoadNamedWallets(); err != nil {
+		return nil, errors.Compose(err, w.Close())
+	}
+	return w, nil
+}
+
+// newWallet creates a new wallet without named wallets.
+func newWallet(cs modules.ConsensusSet, tpool modules.TransactionPool, persistDir string, deps modules.Dependencies) (*Wallet, error) {
 	// Check for nil dependencies.
 	if cs == nil {
 		return nil, errNilConsensusSet
@@ -161,9 +220,16 @@ func NewCustomWallet(cs modules.ConsensusSet, tpool modules.TransactionPool, per
 		keys:         make(map[types.UnlockHash]spendableKey),
 		lookahead:    make(map[types.UnlockHash]uint64),
 		watchedAddrs: make(map[types.UnlockHash]struct{}),
+		seedIndices:  make(map[types.UnlockHash]uint64),
+
+		multisigAddrs: make(map[types.UnlockHash]types.UnlockConditions),
 
 		unconfirmedSets: make(map[modules.TransactionSetID][]types.TransactionID),
 
+		webhookRetries:     make(map[crypto.Hash]time.Time),
+		webhooksDelivering: make(map[crypto.Hash]struct{}),
+		webhookWake:        make(chan struct{}, 1),
+
 		persistDir: persistDir,
 
 		deps: deps,
@@ -172,6 +238,7 @@ func NewCustomWallet(cs modules.ConsensusSet, tpool modules.TransactionPool, per
 	if err != nil {
 		return nil, err
 	}
+	go w.threadedDeliverWebhooks()
 	return w, nil
 }
 
@@ -182,6 +249,9 @@ func (w *Wallet) Close() error {
 		return err
 	}
 	var errs []error
+	if err := w.managedCloseNamedWallets(); err != nil {
+		errs = append(errs, err)
+	}
 	// Lock the wallet outside of mu.Lock because Lock uses its own mu.Lock.
 	// Once the wallet is locked it cannot be unlocked except using the
 	// unexported unlock method (w.Unlock returns an error if the wallet's
@@ -192,8 +262,7 @@ func (w *Wallet) Close() error {
 		}
 	}
 
-	w.cs.Unsubscribe(w)
-	w.tpool.Unsubscribe(w)
+	w.managedUnsubscribe()
 
 	if err := w.log.Close(); err != nil {
 		errs = append(errs, fmt.Errorf("log.Close failed: %v", err))
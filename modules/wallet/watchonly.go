@@ -0,0 +1,272 @@
+package wallet
+
+import (
+	"bytes"
+	"errors"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// Sia derives the key at each index of a seed by hashing the seed with the
+// index, so unlike hierarchical deterministic wallets there is no way to
+// derive further public keys from public keys alone. A watch-only wallet is
+// instead initialized from the public keys of the first indices of a seed,
+// exported by the holder of the seed. When it runs low on keys, the holder of
+// the seed exports the public keys of the next indices, which are derived
+// deterministically, so the watch-only wallet and the seed always agree on
+// the key at every index. Each export is signed by the key at index 0 of the
+// seed, which lets the watch-only wallet verify that all of its keys belong
+// to the same seed.
+
+var (
+	// errNotWatchOnly is returned when adding watch keys to a wallet that
+	// was initialized with a seed.
+	errNotWatchOnly = errors.New("wallet is not a watch-only wallet")
+
+	// errWatchKeysEmpty is returned when watch keys contain no public keys.
+	errWatchKeysEmpty = errors.New("watch keys contain no public keys")
+
+	// errWatchKeysExhausted is returned when a watch-only wallet has run out
+	// of public keys to generate addresses from.
+	errWatchKeysExhausted = errors.New("watch-only wallet has run out of public keys; export more from the seed")
+
+	// errWatchKeysMismatch is returned when watch keys overlap with the keys
+	// of the wallet but differ from them.
+	errWatchKeysMismatch = errors.New("watch keys differ from the keys of the wallet at the same indices")
+
+	// errWatchKeysSignature is returned when the signature of watch keys is
+	// not valid for the key at index 0 of the seed.
+	errWatchKeysSignature = errors.New("watch keys were not signed by the key at index 0 of the seed")
+
+	// errWatchKeysStart is returned when watch keys don't start at the right
+	// index.
+	errWatchKeysStart = errors.New("watch keys leave a gap after the keys of the wallet")
+
+	// errWatchOnly is returned when a watch-only wallet is asked to do
+	// something that needs secret keys.
+	errWatchOnly = errors.New("watch-only wallet does not hold any secret keys")
+
+	// errWatchOnlyPassword is returned when initializing a watch-only wallet
+	// without an encryption password.
+	errWatchOnlyPassword = errors.New("watch-only wallet needs an encryption password")
+)
+
+// watchKeysHash returns the hash that is signed by the key at index 0 of the
+// seed when exporting watch keys.
+func watchKeysHash(wk modules.WatchKeys) crypto.Hash {
+	return crypto.HashAll(wk.StartIndex, wk.PublicKeys)
+}
+
+// watchKeyUnlockConditions returns the unlock conditions of the address of a
+// watch key, which match the unlock conditions generated from the seed.
+func watchKeyUnlockConditions(pk types.SiaPublicKey) types.UnlockConditions {
+	return types.UnlockConditions{
+		PublicKeys:         []types.SiaPublicKey{pk},
+		SignaturesRequired: 1,
+	}
+}
+
+// verifyWatchKeys checks that wk was signed by the root key, which is the key
+// at index 0 of the seed.
+func verifyWatchKeys(wk modules.WatchKeys, root types.SiaPublicKey) error {
+	if len(wk.PublicKeys) == 0 {
+		return errWatchKeysEmpty
+	}
+	if root.Algorithm != types.SignatureEd25519 || len(root.Key) != crypto.PublicKeySize {
+		return errWatchKeysSignature
+	}
+	if len(wk.Signature) != crypto.SignatureSize {
+		return errWatchKeysSignature
+	}
+	var pk crypto.PublicKey
+	copy(pk[:], root.Key)
+	var sig crypto.Signature
+	copy(sig[:], wk.Signature)
+	if crypto.VerifyHash(watchKeysHash(wk), pk, sig) != nil {
+		return errWatchKeysSignature
+	}
+	return nil
+}
+
+// ExportWatchKeys exports the public keys of n indices of seed, starting at
+// index start, for use by a watch-only wallet. It does not need a wallet, so
+// it can be used on the machine that holds the seed while it is offline.
+func ExportWatchKeys(seed modules.Seed, start, n uint64) modules.WatchKeys {
+	wk := modules.WatchKeys{
+		StartIndex: start,
+	}
+	for _, sk := range generateKeys(seed, start, n) {
+		wk.PublicKeys = append(wk.PublicKeys, sk.UnlockConditions.PublicKeys[0])
+	}
+	root := generateSpendableKey(seed, 0)
+	sig := crypto.SignHash(watchKeysHash(wk), root.SecretKeys[0])
+	wk.Signature = sig[:]
+	return wk
+}
+
+// InitWatchOnly initializes a watch-only wallet from the public keys of a
+// seed, which must start at index 0. The wallet tracks the addresses of the
+// keys and can create unsigned transactions for offline signers, but it never
+// holds the seed or any secret keys. Since the encryption key can't be derived
+// from a seed, masterKey must not be blank.
+func (w *Wallet) InitWatchOnly(masterKey crypto.TwofishKey, wk modules.WatchKeys) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+	if masterKey == (crypto.TwofishKey{}) {
+		return errWatchOnlyPassword
+	}
+	if wk.StartIndex != 0 {
+		return errWatchKeysStart
+	}
+	if len(wk.PublicKeys) == 0 {
+		return errWatchKeysEmpty
+	}
+	if err := verifyWatchKeys(wk, wk.PublicKeys[0]); err != nil {
+		return err
+	}
+	if !w.scanLock.TryLock() {
+		return errScanInProgress
+	}
+	defer w.scanLock.Unlock()
+
+	// estimate the primarySeedProgress by scanning the blockchain for the
+	// addresses of the keys, like InitFromSeed does for a seed.
+	s := newSeedScanner(modules.Seed{}, w.log)
+	for i, pk := range wk.PublicKeys {
+		s.keys[watchKeyUnlockConditions(pk).UnlockHash()] = uint64(i)
+	}
+	if err := w.cs.ConsensusSetSubscribe(s, modules.ConsensusChangeBeginning, w.tg.StopChan()); err != nil {
+		return err
+	}
+	w.cs.Unsubscribe(s)
+	progress := s.largestIndexSeen + 1
+	progress += progress / 10
+	if progress > uint64(len(wk.PublicKeys)) {
+		progress = uint64(len(wk.PublicKeys))
+	}
+	w.log.Printf("INFO: found key index %v in blockchain. Setting primary seed progress to %v", s.largestIndexSeen, progress)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	wb := w.dbTx.Bucket(bucketWallet)
+	if wb.Get(keyEncryptionVerification) != nil {
+		return errReencrypt
+	}
+	if err := dbPutWatchKeys(w.dbTx, wk.PublicKeys); err != nil {
+		return err
+	}
+	if err := dbPutPrimarySeedProgress(w.dbTx, progress); err != nil {
+		return err
+	}
+
+	// Establish the encryption verification using the masterKey. After this
+	// point, the wallet is encrypted.
+	uk := uidEncryptionKey(masterKey, dbGetWalletUID(w.dbTx))
+	if err := wb.Put(keyEncryptionVerification, uk.EncryptBytes(verificationPlaintext)); err != nil {
+		return err
+	}
+	w.encrypted = true
+	w.log.Printf("INFO: initialized watch-only wallet with %v public keys", len(wk.PublicKeys))
+	return w.syncDB()
+}
+
+// AddWatchKeys extends the keys of a watch-only wallet with more public keys
+// of the same seed. The keys may overlap with the keys the wallet already
+// has, but must not leave a gap. If none of the new keys have appeared in the
+// blockchain, the unused flag may be set to true. Otherwise, the wallet must
+// rescan the blockchain to search for their outputs.
+func (w *Wallet) AddWatchKeys(wk modules.WatchKeys, unused bool) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	var added int
+	err := func() error {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if !w.unlocked {
+			return modules.ErrLockedWallet
+		} else if !w.watchOnly {
+			return errNotWatchOnly
+		}
+		if err := verifyWatchKeys(wk, w.watchKeys[0]); err != nil {
+			return err
+		}
+		numKeys := uint64(len(w.watchKeys))
+		if wk.StartIndex > numKeys {
+			return errWatchKeysStart
+		}
+		for i, pk := range wk.PublicKeys {
+			index := wk.StartIndex + uint64(i)
+			if index >= numKeys {
+				w.watchKeys = append(w.watchKeys, pk)
+				added++
+			} else if !equalPublicKeys(pk, w.watchKeys[index]) {
+				return errWatchKeysMismatch
+			}
+		}
+		if added == 0 {
+			return nil
+		}
+		if err := dbPutWatchKeys(w.dbTx, w.watchKeys); err != nil {
+			return err
+		}
+
+		// The lookahead may have been cut short by the number of keys.
+		progress, err := dbGetPrimarySeedProgress(w.dbTx)
+		if err != nil {
+			return err
+		}
+		w.regenerateLookahead(progress)
+		if !unused {
+			if err := w.prepareRescan(); err != nil {
+				return err
+			}
+		}
+		return w.syncDB()
+	}()
+	if err != nil || added == 0 {
+		return err
+	}
+	w.log.Printf("INFO: added %v public keys to watch-only wallet", added)
+
+	if !unused {
+		return w.managedRescan()
+	}
+	return nil
+}
+
+// WatchOnly returns whether the wallet is a watch-only wallet, along with the
+// number of its public keys that have not been used to generate addresses
+// yet.
+func (w *Wallet) WatchOnly() (bool, uint64, error) {
+	if err := w.tg.Add(); err != nil {
+		return false, 0, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	pks, err := dbGetWatchKeys(w.dbTx)
+	if err != nil || pks == nil {
+		return false, 0, err
+	}
+	progress, err := dbGetPrimarySeedProgress(w.dbTx)
+	if err != nil {
+		return false, 0, err
+	}
+	if progress >= uint64(len(pks)) {
+		return true, 0, nil
+	}
+	return true, uint64(len(pks)) - progress, nil
+}
+
+// equalPublicKeys reports whether a and b are the same public key.
+func equalPublicKeys(a, b types.SiaPublicKey) bool {
+	return a.Algorithm == b.Algorithm && bytes.Equal(a.Key, b.Key)
+}
@@ -0,0 +1,182 @@
+package wallet
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+)
+
+// TestWatchOnlyWallet initializes a watch-only wallet from the public keys of
+// the seed of a funded wallet, spends from it with a PST that is signed
+// offline, and extends its keys.
+func TestWatchOnlyWallet(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Mine past the ASIC hardfork, so that the signatures created at the
+	// height of the PST are still valid when the transaction is broadcast.
+	for wt.cs.Height() <= types.ASICHardforkHeight {
+		if _, err := wt.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	seed, _, err := wt.wallet.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wk := ExportWatchKeys(seed, 0, 100)
+
+	w, err := New(wt.cs, wt.tpool, build.TempDir(modules.WalletDir, t.Name()+"2", modules.WalletDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	var masterKey crypto.TwofishKey
+	fastrand.Read(masterKey[:])
+
+	// Keys that don't start at index 0 or were tampered with are rejected.
+	if err := w.InitWatchOnly(masterKey, ExportWatchKeys(seed, 1, 10)); err != errWatchKeysStart {
+		t.Fatal("expected errWatchKeysStart, got", err)
+	}
+	invalid := wk
+	invalid.PublicKeys = append([]types.SiaPublicKey(nil), wk.PublicKeys[:10]...)
+	if err := w.InitWatchOnly(masterKey, invalid); err != errWatchKeysSignature {
+		t.Fatal("expected errWatchKeysSignature, got", err)
+	}
+	if err := w.InitWatchOnly(masterKey, wk); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Unlock(masterKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// The watch-only wallet sees the same balance as the seeded wallet.
+	sc1, _, _, err := wt.wallet.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc2, _, _, err := w.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc2.IsZero() || !sc1.Equals(sc2) {
+		t.Fatalf("balances differ: %v != %v", sc1, sc2)
+	}
+	watchOnly, remaining, err := w.WatchOnly()
+	if err != nil {
+		t.Fatal(err)
+	} else if !watchOnly || remaining == 0 || remaining > 100 {
+		t.Fatal("wrong watch-only status", watchOnly, remaining)
+	}
+	if watchOnly, _, err := wt.wallet.WatchOnly(); err != nil || watchOnly {
+		t.Fatal("seeded wallet reported as watch-only", err)
+	}
+
+	// Anything that needs secret keys fails.
+	if _, _, err := w.PrimarySeed(); err != errWatchOnly {
+		t.Fatal("expected errWatchOnly, got", err)
+	}
+	if _, err := w.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{}); err != errWatchOnly {
+		t.Fatal("expected errWatchOnly, got", err)
+	}
+
+	// Spend from the watch-only wallet with a PST that is signed offline.
+	pst, err := w.FundPST([]types.SiacoinOutput{{Value: types.SiacoinPrecision.Mul64(100)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SignPST(&pst); err != errWatchOnly {
+		t.Fatal("expected errWatchOnly, got", err)
+	}
+	if err := SignPST(&pst, seed); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.tpool.AcceptTransactionSet(append(pst.Parents, pst.Transaction)); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	sc1, _, _, err = wt.wallet.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc2, _, _, err = w.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sc1.Equals(sc2) {
+		t.Fatalf("balances differ after spending: %v != %v", sc1, sc2)
+	}
+
+	// The wallet runs out of addresses once it has used all of its keys.
+	_, remaining, err = w.WatchOnly()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.NextAddresses(remaining + 1); !errors.Contains(err, errWatchKeysExhausted) {
+		t.Fatal("expected errWatchKeysExhausted, got", err)
+	}
+	if _, err := w.NextAddresses(remaining); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.NextAddress(); !errors.Contains(err, errWatchKeysExhausted) {
+		t.Fatal("expected errWatchKeysExhausted, got", err)
+	}
+
+	// Add more keys. Keys of another seed, keys that leave a gap and keys
+	// that differ from the existing keys are rejected.
+	otherSeed := modules.Seed{}
+	fastrand.Read(otherSeed[:])
+	if err := w.AddWatchKeys(ExportWatchKeys(otherSeed, 100, 50), true); err != errWatchKeysSignature {
+		t.Fatal("expected errWatchKeysSignature, got", err)
+	}
+	if err := w.AddWatchKeys(ExportWatchKeys(seed, 101, 50), true); err != errWatchKeysStart {
+		t.Fatal("expected errWatchKeysStart, got", err)
+	}
+	if err := wt.wallet.AddWatchKeys(ExportWatchKeys(seed, 100, 50), true); err != errNotWatchOnly {
+		t.Fatal("expected errNotWatchOnly, got", err)
+	}
+	if err := w.AddWatchKeys(ExportWatchKeys(seed, 90, 60), false); err != nil {
+		t.Fatal(err)
+	}
+	if _, remaining, err = w.WatchOnly(); err != nil || remaining != 50 {
+		t.Fatal("expected 50 remaining keys, got", remaining, err)
+	}
+	uc, err := w.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uc.UnlockHash() != generateSpendableKey(seed, 100).UnlockConditions.UnlockHash() {
+		t.Fatal("watch-only wallet generated the wrong address")
+	}
+
+	// The keys persist across locking and unlocking.
+	if err := w.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Unlock(masterKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, remaining, err = w.WatchOnly(); err != nil || remaining != 49 {
+		t.Fatal("expected 49 remaining keys, got", remaining, err)
+	}
+	sc2, _, _, err = w.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sc1.Equals(sc2) {
+		t.Fatalf("balances differ after unlocking: %v != %v", sc1, sc2)
+	}
+}
@@ -0,0 +1,66 @@
+package modules
+
+import (
+	"strings"
+	"testing"
+
+	"gitlab.com/NebulousLabs/fastrand"
+)
+
+// TestBIP39Seed tests encoding and decoding seeds as BIP-39 phrases.
+func TestBIP39Seed(t *testing.T) {
+	// Test vectors of the BIP-39 reference implementation.
+	tests := []struct {
+		entropy byte
+		phrase  string
+	}{
+		{0x00, "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon " +
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art"},
+		{0x7f, "legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful " +
+			"legal winner thank year wave sausage worth title"},
+		{0xff, "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote"},
+	}
+	for _, test := range tests {
+		var seed Seed
+		for i := range seed {
+			seed[i] = test.entropy
+		}
+		phrase, err := SeedToString(seed, BIP39)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if phrase != test.phrase {
+			t.Errorf("wrong phrase for entropy %x: %v", test.entropy, phrase)
+		}
+		decoded, err := StringToSeed(test.phrase, BIP39)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded != seed {
+			t.Errorf("wrong seed for entropy %x", test.entropy)
+		}
+	}
+
+	// A random seed is unchanged after encoding and decoding it.
+	var seed Seed
+	fastrand.Read(seed[:])
+	phrase, err := SeedToString(seed, BIP39)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := StringToSeed(phrase, BIP39)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded != seed {
+		t.Fatal("seed changed after encoding and decoding")
+	}
+
+	// Phrases with a wrong checksum or less entropy than a seed are rejected.
+	if _, err := StringToSeed(strings.Repeat("abandon ", 24), BIP39); err == nil {
+		t.Error("expected error for phrase with wrong checksum")
+	}
+	if _, err := StringToSeed(strings.Repeat("abandon ", 11)+"about", BIP39); err == nil {
+		t.Error("expected error for 12 word phrase")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"sync"
 
 	"gitlab.com/NebulousLabs/Sia/build"
 	"gitlab.com/NebulousLabs/Sia/modules"
@@ -97,11 +98,41 @@ type API struct {
 	tpool    modules.TransactionPool
 	wallet   modules.Wallet
 
+	// profileAPIs caches the APIs that serve the requests for renter
+	// profiles. They are built on demand using the required user agent and
+	// password of this API.
+	profileAPIs       map[string]*API
+	profileMu         sync.Mutex
+	requiredPassword  string
+	requiredUserAgent string
+
+	// walletAPIs caches the APIs that serve the requests for named wallets.
+	walletAPIs map[string]*API
+	walletMu   sync.Mutex
+
 	router http.Handler
 }
 
 // api.ServeHTTP implements the http.Handler interface.
 func (api *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if name, ok := api.namedWalletName(r); ok {
+		walletAPI, err := api.managedWalletAPI(name)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		walletAPI.ServeHTTP(w, r)
+		return
+	}
+	if name, ok := renterProfileName(r); ok {
+		profileAPI, err := api.managedProfileAPI(name)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		profileAPI.router.ServeHTTP(w, r)
+		return
+	}
 	api.router.ServeHTTP(w, r)
 }
 
@@ -118,6 +149,12 @@ func New(requiredUserAgent string, requiredPassword string, cs modules.Consensus
 		renter:   r,
 		tpool:    tp,
 		wallet:   w,
+
+		profileAPIs:       make(map[string]*API),
+		requiredPassword:  requiredPassword,
+		requiredUserAgent: requiredUserAgent,
+
+		walletAPIs: make(map[string]*API),
 	}
 
 	// Register API handlers
@@ -24,6 +24,15 @@ type Client struct {
 	// UserAgent must match the User-Agent required by the siad server. If not
 	// set, it defaults to "Sia-Agent".
 	UserAgent string
+
+	// RenterProfile is the name of the renter profile that serves the
+	// client's requests. If not set, the requests are served by the default
+	// renter.
+	RenterProfile string
+
+	// NamedWallet is the name of the named wallet that serves the client's
+	// requests. If not set, the requests are served by the primary wallet.
+	NamedWallet string
 }
 
 // New creates a new Client using the provided address.
@@ -49,6 +58,12 @@ func (c *Client) NewRequest(method, resource string, body io.Reader) (*http.Requ
 	if c.Password != "" {
 		req.SetBasicAuth("", c.Password)
 	}
+	if c.RenterProfile != "" {
+		req.Header.Set("Sia-Renter-Profile", c.RenterProfile)
+	}
+	if c.NamedWallet != "" {
+		req.Header.Set("Sia-Wallet", c.NamedWallet)
+	}
 	return req, nil
 }
 
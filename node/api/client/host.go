@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"gitlab.com/NebulousLabs/Sia/crypto"
 	"gitlab.com/NebulousLabs/Sia/modules"
 	"gitlab.com/NebulousLabs/Sia/node/api"
+	"gitlab.com/NebulousLabs/Sia/types"
 )
 
 // HostParam is a parameter in the host's settings that can be changed via the
@@ -47,6 +50,42 @@ const (
 	HostParamMaxReviseBatchSize = HostParam("maxrevisebatchsize")
 	// HostParamNetAddress is the announced netaddress of the host.
 	HostParamNetAddress = HostParam("netaddress")
+	// HostParamSectorScrubRate is the rate at which the host scrubs its
+	// sectors in bytes per second.
+	HostParamSectorScrubRate = HostParam("sectorscrubrate")
+	// HostParamMaxConnections is the maximum number of concurrent
+	// connections to the host.
+	HostParamMaxConnections = HostParam("maxconnections")
+	// HostParamMaxConnectionsPerPeer is the maximum number of concurrent
+	// connections per IP address or renter key.
+	HostParamMaxConnectionsPerPeer = HostParam("maxconnectionsperpeer")
+	// HostParamMaxDownloadSpeed is the maximum speed at which renters can
+	// download from the host in bytes per second.
+	HostParamMaxDownloadSpeed = HostParam("maxdownloadspeed")
+	// HostParamMaxDownloadSpeedPerPeer is the maximum download speed per IP
+	// address or renter key in bytes per second.
+	HostParamMaxDownloadSpeedPerPeer = HostParam("maxdownloadspeedperpeer")
+	// HostParamMaxRPCRate is the maximum number of RPCs per minute.
+	HostParamMaxRPCRate = HostParam("maxrpcrate")
+	// HostParamMaxRPCRatePerPeer is the maximum number of RPCs per minute per
+	// IP address or renter key.
+	HostParamMaxRPCRatePerPeer = HostParam("maxrpcrateperpeer")
+	// HostParamMaxUploadSpeed is the maximum speed at which renters can
+	// upload to the host in bytes per second.
+	HostParamMaxUploadSpeed = HostParam("maxuploadspeed")
+	// HostParamMaxUploadSpeedPerPeer is the maximum upload speed per IP
+	// address or renter key in bytes per second.
+	HostParamMaxUploadSpeedPerPeer = HostParam("maxuploadspeedperpeer")
+	// HostParamFolderHealthMinOperations is the number of reads or writes a
+	// storage folder needs to have performed before its failure rate is
+	// checked.
+	HostParamFolderHealthMinOperations = HostParam("folderhealthminoperations")
+	// HostParamFolderMaxFailedReadRate is the fraction of failed reads at
+	// which a storage folder is considered degraded.
+	HostParamFolderMaxFailedReadRate = HostParam("foldermaxfailedreadrate")
+	// HostParamFolderMaxFailedWriteRate is the fraction of failed writes at
+	// which a storage folder is considered degraded.
+	HostParamFolderMaxFailedWriteRate = HostParam("foldermaxfailedwriterate")
 )
 
 // HostAnnouncePost uses the /host/announce endpoint to announce the host to
@@ -70,6 +109,42 @@ func (c *Client) HostContractInfoGet() (cg api.ContractInfoGET, err error) {
 	return
 }
 
+// HostContractInfoQueryGet uses the /host/contracts endpoint to get a page of
+// the contracts on the host that match the query.
+func (c *Client) HostContractInfoQueryGet(q modules.HostObligationQuery) (cg api.ContractInfoGET, err error) {
+	values := url.Values{}
+	if q.MaxExpiration != 0 {
+		values.Set("maxexpiration", fmt.Sprint(q.MaxExpiration))
+	}
+	if q.MinExpiration != 0 {
+		values.Set("minexpiration", fmt.Sprint(q.MinExpiration))
+	}
+	if q.ProofConfirmed != nil {
+		values.Set("proofconfirmed", strconv.FormatBool(*q.ProofConfirmed))
+	}
+	if q.ProofConstructed != nil {
+		values.Set("proofconstructed", strconv.FormatBool(*q.ProofConstructed))
+	}
+	if q.Descending {
+		values.Set("order", "desc")
+	}
+	if q.Limit != 0 {
+		values.Set("limit", strconv.Itoa(q.Limit))
+	}
+	values.Set("cursor", q.Cursor)
+	values.Set("sortby", q.SortBy)
+	values.Set("status", q.Status)
+	err = c.get("/host/contracts?"+values.Encode(), &cg)
+	return
+}
+
+// HostContractGet uses the /host/contracts/:id endpoint to get the details of
+// a single contract on the host.
+func (c *Client) HostContractGet(id types.FileContractID) (hcg api.HostContractGET, err error) {
+	err = c.get("/host/contracts/"+id.String(), &hcg)
+	return
+}
+
 // HostEstimateScoreGet requests the /host/estimatescore endpoint.
 func (c *Client) HostEstimateScoreGet(param, value string) (eg api.HostEstimateScoreGET, err error) {
 	err = c.get(fmt.Sprintf("/host/estimatescore?%v=%v", param, value), &eg)
@@ -89,6 +164,91 @@ func (c *Client) HostModifySettingPost(param HostParam, value interface{}) (err
 	return
 }
 
+// HostPricingGet requests the /host/pricing endpoint.
+func (c *Client) HostPricingGet() (hpg api.HostPricingGET, err error) {
+	err = c.get("/host/pricing", &hpg)
+	return
+}
+
+// HostPricingEstimateGet requests the /host/pricing/estimate endpoint to
+// perform a dry run of the host's pricing engine.
+func (c *Client) HostPricingEstimateGet() (hpeg api.HostPricingEstimateGET, err error) {
+	err = c.get("/host/pricing/estimate", &hpeg)
+	return
+}
+
+// HostMaintenanceGet requests the /host/maintenance endpoint.
+func (c *Client) HostMaintenanceGet() (hmg api.HostMaintenanceGET, err error) {
+	err = c.get("/host/maintenance", &hmg)
+	return
+}
+
+// HostMaintenancePost uses the /host/maintenance endpoint to schedule
+// maintenance of the host.
+func (c *Client) HostMaintenancePost(m modules.HostMaintenance) (err error) {
+	values := url.Values{}
+	values.Set("start", fmt.Sprint(m.Start))
+	values.Set("end", fmt.Sprint(m.End))
+	values.Set("announce", strconv.FormatBool(m.Announce))
+	err = c.post("/host/maintenance", values.Encode(), nil)
+	return
+}
+
+// HostMaintenanceCancelPost uses the /host/maintenance/cancel endpoint to
+// cancel the scheduled maintenance of the host.
+func (c *Client) HostMaintenanceCancelPost() (err error) {
+	err = c.post("/host/maintenance/cancel", "", nil)
+	return
+}
+
+// HostMetricsGet requests the /host/metrics endpoint for the finances of the
+// host between from and to, split into periods of the given interval.
+func (c *Client) HostMetricsGet(from, to time.Time, interval string) (hmg api.HostMetricsGET, err error) {
+	values := url.Values{}
+	values.Set("from", strconv.FormatInt(from.Unix(), 10))
+	values.Set("to", strconv.FormatInt(to.Unix(), 10))
+	values.Set("interval", interval)
+	err = c.get("/host/metrics?"+values.Encode(), &hmg)
+	return
+}
+
+// HostMetricsCSVGet requests the /host/metrics endpoint and returns either the
+// periods or the obligations of the host's financial report as CSV, depending
+// on data.
+func (c *Client) HostMetricsCSVGet(from, to time.Time, interval, data string) ([]byte, error) {
+	values := url.Values{}
+	values.Set("from", strconv.FormatInt(from.Unix(), 10))
+	values.Set("to", strconv.FormatInt(to.Unix(), 10))
+	values.Set("interval", interval)
+	values.Set("format", "csv")
+	values.Set("data", data)
+	return c.getRawResponse("/host/metrics?" + values.Encode())
+}
+
+// HostPricingHistoryGet requests the /host/pricing/history endpoint.
+func (c *Client) HostPricingHistoryGet() (hphg api.HostPricingHistoryGET, err error) {
+	err = c.get("/host/pricing/history", &hphg)
+	return
+}
+
+// HostPricingPost uses the /host/pricing endpoint to set the policy of the
+// host's pricing engine.
+func (c *Client) HostPricingPost(policy modules.HostPricingPolicy) (err error) {
+	rules := make([]string, 0, len(policy.FillRules))
+	for _, rule := range policy.FillRules {
+		rules = append(rules, fmt.Sprintf("%v:%v", rule.Utilization, rule.Multiplier))
+	}
+	values := url.Values{}
+	values.Set("enabled", strconv.FormatBool(policy.Enabled))
+	values.Set("fillrules", strings.Join(rules, ","))
+	values.Set("percentile", strconv.FormatFloat(policy.Percentile, 'f', -1, 64))
+	values.Set("updateinterval", fmt.Sprint(policy.UpdateInterval))
+	values.Set("maxstorageprice", policy.MaxStoragePrice.String())
+	values.Set("minstorageprice", policy.MinStoragePrice.String())
+	err = c.post("/host/pricing", values.Encode(), nil)
+	return
+}
+
 // HostStorageFoldersAddPost uses the /host/storage/folders/add api endpoint to
 // add a storage folder to a host
 func (c *Client) HostStorageFoldersAddPost(path string, size uint64) (err error) {
@@ -99,6 +259,16 @@ func (c *Client) HostStorageFoldersAddPost(path string, size uint64) (err error)
 	return
 }
 
+// HostStorageFoldersMovePost uses the /host/storage/folders/move api endpoint
+// to move an existing storage folder to a new path.
+func (c *Client) HostStorageFoldersMovePost(path, newPath string) (err error) {
+	values := url.Values{}
+	values.Set("path", path)
+	values.Set("newpath", newPath)
+	err = c.post("/host/storage/folders/move", values.Encode(), nil)
+	return
+}
+
 // HostStorageFoldersRemovePost uses the /host/storage/folders/remove api
 // endpoint to remove a storage folder from a host.
 func (c *Client) HostStorageFoldersRemovePost(path string) (err error) {
@@ -108,6 +278,16 @@ func (c *Client) HostStorageFoldersRemovePost(path string) (err error) {
 	return
 }
 
+// HostStorageFoldersResetHealthPost uses the
+// /host/storage/folders/resethealth api endpoint to reset the health
+// statistics of a storage folder.
+func (c *Client) HostStorageFoldersResetHealthPost(path string) (err error) {
+	values := url.Values{}
+	values.Set("path", path)
+	err = c.post("/host/storage/folders/resethealth", values.Encode(), nil)
+	return
+}
+
 // HostStorageFoldersResizePost uses the /host/storage/folders/resize api
 // endpoint to resize an existing storage folder.
 func (c *Client) HostStorageFoldersResizePost(path string, size uint64) (err error) {
@@ -130,3 +310,22 @@ func (c *Client) HostStorageSectorsDeletePost(root crypto.Hash) (err error) {
 	err = c.post("/host/storage/sectors/delete/"+root.String(), "", nil)
 	return
 }
+
+// HostStorageSectorsGet uses the /host/storage/sectors endpoint to get a page
+// of the sectors referenced by the host's storage obligations.
+func (c *Client) HostStorageSectorsGet(cursor string, limit int) (hsg api.HostSectorsGET, err error) {
+	values := url.Values{}
+	values.Set("cursor", cursor)
+	if limit != 0 {
+		values.Set("limit", strconv.Itoa(limit))
+	}
+	err = c.get("/host/storage/sectors?"+values.Encode(), &hsg)
+	return
+}
+
+// HostStorageSectorsAuditGet uses the /host/storage/sectors/audit endpoint to
+// cross-check the host's storage obligations against its stored sectors.
+func (c *Client) HostStorageSectorsAuditGet() (hsag api.HostSectorsAuditGET, err error) {
+	err = c.get("/host/storage/sectors/audit", &hsag)
+	return
+}
@@ -39,6 +39,61 @@ func (c *Client) RenterContractCancelPost(id types.FileContractID) error {
 	return err
 }
 
+// RenterContractFormPost uses the /renter/contract/form endpoint to form a
+// contract with a specific host. An endHeight of 0 uses the end of the current
+// period.
+func (c *Client) RenterContractFormPost(host types.SiaPublicKey, funds types.Currency, endHeight types.BlockHeight) (rc api.RenterContract, err error) {
+	values := url.Values{}
+	values.Set("host", host.String())
+	values.Set("funds", funds.String())
+	values.Set("endheight", fmt.Sprint(endHeight))
+	err = c.post("/renter/contract/form", values.Encode(), &rc)
+	return
+}
+
+// RenterContractRefreshPost uses the /renter/contract/refresh endpoint to add
+// funds to a contract without changing its end height.
+func (c *Client) RenterContractRefreshPost(id types.FileContractID, funds types.Currency) (rc api.RenterContract, err error) {
+	values := url.Values{}
+	values.Set("id", id.String())
+	values.Set("funds", funds.String())
+	err = c.post("/renter/contract/refresh", values.Encode(), &rc)
+	return
+}
+
+// RenterContractRenewPost uses the /renter/contract/renew endpoint to renew a
+// contract right away. An endHeight of 0 uses the end of the current period.
+func (c *Client) RenterContractRenewPost(id types.FileContractID, funds types.Currency, endHeight types.BlockHeight) (rc api.RenterContract, err error) {
+	values := url.Values{}
+	values.Set("id", id.String())
+	values.Set("funds", funds.String())
+	values.Set("endheight", fmt.Sprint(endHeight))
+	err = c.post("/renter/contract/renew", values.Encode(), &rc)
+	return
+}
+
+// RenterContractUtilityPost uses the /renter/contract/utility endpoint to lock
+// or unlock the utility of a contract.
+func (c *Client) RenterContractUtilityPost(id types.FileContractID, locked bool) error {
+	values := url.Values{}
+	values.Set("id", id.String())
+	values.Set("locked", fmt.Sprint(locked))
+	return c.post("/renter/contract/utility", values.Encode(), nil)
+}
+
+// RenterContractHistoryGet requests the /renter/contracts/history resource
+// and returns the financial history of the renter's contracts.
+func (c *Client) RenterContractHistoryGet() (rch api.RenterContractHistory, err error) {
+	err = c.get("/renter/contracts/history", &rch)
+	return
+}
+
+// RenterContractHistoryCSVGet requests the /renter/contracts/history resource
+// and returns the financial history of the renter's contracts as CSV.
+func (c *Client) RenterContractHistoryCSVGet() ([]byte, error) {
+	return c.getRawResponse("/renter/contracts/history?format=csv")
+}
+
 // RenterContractsGet requests the /renter/contracts resource and returns
 // Contracts and ActiveContracts
 func (c *Client) RenterContractsGet() (rc api.RenterContracts, err error) {
@@ -193,6 +248,41 @@ func (c *Client) RenterPricesGet(allowance modules.Allowance) (rpg api.RenterPri
 	return
 }
 
+// RenterPricesDetailedGet requests a detailed price estimation for the data
+// described by params from the /renter/prices endpoint.
+func (c *Client) RenterPricesDetailedGet(params modules.RenterPriceEstimationParams, allowance modules.Allowance) (rpg api.RenterPricesDetailedGET, err error) {
+	query := fmt.Sprintf("?detailed=true&datasize=%v&datapieces=%v&paritypieces=%v&downloadsize=%v&funds=%v&hosts=%v&period=%v&renewwindow=%v",
+		params.DataSize, params.DataPieces, params.ParityPieces, params.DownloadSize,
+		allowance.Funds, allowance.Hosts, allowance.Period, allowance.RenewWindow)
+	err = c.get("/renter/prices"+query, &rpg)
+	return
+}
+
+// RenterProfilesGet requests the /renter/profiles resource and returns the
+// names of the renter profiles.
+func (c *Client) RenterProfilesGet() (rp api.RenterProfiles, err error) {
+	err = c.get("/renter/profiles", &rp)
+	return
+}
+
+// RenterProfileCreatePost uses the /renter/profiles/create endpoint to create
+// a new renter profile.
+func (c *Client) RenterProfileCreatePost(name string) (err error) {
+	values := url.Values{}
+	values.Set("name", name)
+	err = c.post("/renter/profiles/create", values.Encode(), nil)
+	return
+}
+
+// RenterProfileDeletePost uses the /renter/profiles/delete endpoint to delete
+// a renter profile.
+func (c *Client) RenterProfileDeletePost(name string) (err error) {
+	values := url.Values{}
+	values.Set("name", name)
+	err = c.post("/renter/profiles/delete", values.Encode(), nil)
+	return
+}
+
 // RenterPostRateLimit uses the /renter endpoint to change the renter's bandwidth rate
 // limit.
 func (c *Client) RenterPostRateLimit(readBPS, writeBPS int64) (err error) {
@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
 	"gitlab.com/NebulousLabs/Sia/node/api"
 	"gitlab.com/NebulousLabs/Sia/types"
 )
@@ -107,6 +108,35 @@ func (c *Client) WalletSiacoinsPost(amount types.Currency, destination types.Unl
 	return
 }
 
+// WalletSiacoinsCoinControlPost uses the /wallet/siacoins api endpoint to send
+// money to multiple addresses, funding the transaction with outputs selected
+// according to cc.
+func (c *Client) WalletSiacoinsCoinControlPost(outputs []types.SiacoinOutput, cc modules.CoinControl) (wsp api.WalletSiacoinsPOST, err error) {
+	values := url.Values{}
+	marshaledOutputs, err := json.Marshal(outputs)
+	if err != nil {
+		return api.WalletSiacoinsPOST{}, err
+	}
+	values.Set("outputs", string(marshaledOutputs))
+	values.Set("strategy", string(cc.Strategy))
+	if len(cc.Pinned) > 0 {
+		pinned, err := json.Marshal(cc.Pinned)
+		if err != nil {
+			return api.WalletSiacoinsPOST{}, err
+		}
+		values.Set("pinned", string(pinned))
+	}
+	if len(cc.Excluded) > 0 {
+		excluded, err := json.Marshal(cc.Excluded)
+		if err != nil {
+			return api.WalletSiacoinsPOST{}, err
+		}
+		values.Set("excluded", string(excluded))
+	}
+	err = c.post("/wallet/siacoins", values.Encode(), &wsp)
+	return
+}
+
 // WalletSignPost uses the /wallet/sign api endpoint to sign a transaction.
 func (c *Client) WalletSignPost(txn types.Transaction, toSign []crypto.Hash) (wspr api.WalletSignPOSTResp, err error) {
 	json, err := json.Marshal(api.WalletSignPOSTParams{
@@ -157,6 +187,32 @@ func (c *Client) WalletTransactionsGet(startHeight types.BlockHeight, endHeight
 	return
 }
 
+// WalletTransactionsFilteredGet requests the /wallet/transactions api resource
+// for a certain startheight and endheight, returning only the transactions of
+// the provided category that are related to an address with the provided
+// label. An empty category or label matches all transactions.
+func (c *Client) WalletTransactionsFilteredGet(startHeight, endHeight types.BlockHeight, category modules.TransactionCategory, label string) (wtg api.WalletTransactionsGET, err error) {
+	values := url.Values{}
+	values.Set("startheight", fmt.Sprint(startHeight))
+	values.Set("endheight", fmt.Sprint(endHeight))
+	values.Set("category", string(category))
+	values.Set("label", label)
+	err = c.get("/wallet/transactions?"+values.Encode(), &wtg)
+	return
+}
+
+// WalletTransactionsCSVGet requests the /wallet/transactions api resource like
+// WalletTransactionsFilteredGet, but returns the confirmed transactions as CSV.
+func (c *Client) WalletTransactionsCSVGet(startHeight, endHeight types.BlockHeight, category modules.TransactionCategory, label string) ([]byte, error) {
+	values := url.Values{}
+	values.Set("startheight", fmt.Sprint(startHeight))
+	values.Set("endheight", fmt.Sprint(endHeight))
+	values.Set("category", string(category))
+	values.Set("label", label)
+	values.Set("format", "csv")
+	return c.getRawResponse("/wallet/transactions?" + values.Encode())
+}
+
 // WalletTransactionGet requests the /wallet/transaction/:id api resource for a
 // certain TransactionID.
 func (c *Client) WalletTransactionGet(id types.TransactionID) (wtg api.WalletTransactionGETid, err error) {
@@ -233,3 +289,381 @@ func (c *Client) Wallet033xPost(path, password string) (err error) {
 	err = c.post("/wallet/033x", values.Encode(), nil)
 	return
 }
+
+// WalletMultisigGet requests the /wallet/multisig endpoint and returns the
+// multisig addresses registered with the wallet.
+func (c *Client) WalletMultisigGet() (wmg api.WalletMultisigGET, err error) {
+	err = c.get("/wallet/multisig", &wmg)
+	return
+}
+
+// WalletMultisigAddPost uses the /wallet/multisig/add endpoint to register an
+// M-of-N address with the wallet. The unused flag should be set to true if
+// the address has never appeared in the blockchain.
+func (c *Client) WalletMultisigAddPost(pubkeys []types.SiaPublicKey, required uint64, unused bool) (wmap api.WalletMultisigAddPOST, err error) {
+	json, err := json.Marshal(api.WalletMultisigAddPOSTParams{
+		PublicKeys:         pubkeys,
+		SignaturesRequired: required,
+		Unused:             unused,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/multisig/add", string(json), &wmap)
+	return
+}
+
+// WalletMultisigBuildPost uses the /wallet/multisig/build endpoint to create
+// an unsigned transaction that spends from a multisig address.
+func (c *Client) WalletMultisigBuildPost(addr types.UnlockHash, outputs []types.SiacoinOutput) (wmt api.WalletMultisigTransaction, err error) {
+	json, err := json.Marshal(api.WalletMultisigBuildPOSTParams{
+		Address: addr,
+		Outputs: outputs,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/multisig/build", string(json), &wmt)
+	return
+}
+
+// WalletMultisigCombinePost uses the /wallet/multisig/combine endpoint to
+// merge the signatures of copies of a transaction signed by different
+// co-signers.
+func (c *Client) WalletMultisigCombinePost(txns []types.Transaction) (wmt api.WalletMultisigTransaction, err error) {
+	json, err := json.Marshal(api.WalletMultisigCombinePOSTParams{
+		Transactions: txns,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/multisig/combine", string(json), &wmt)
+	return
+}
+
+// WalletMultisigSignPost uses the /wallet/multisig/sign endpoint to add the
+// wallet's signatures to a multisig transaction.
+func (c *Client) WalletMultisigSignPost(txn types.Transaction) (wmt api.WalletMultisigTransaction, err error) {
+	json, err := json.Marshal(api.WalletMultisigTransaction{
+		Transaction: txn,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/multisig/sign", string(json), &wmt)
+	return
+}
+
+// WalletPSTCreatePost uses the /wallet/pst/create endpoint to create an
+// unsigned partially signed transaction that sends siacoins from the wallet to
+// the outputs.
+func (c *Client) WalletPSTCreatePost(outputs []types.SiacoinOutput) (wp api.WalletPST, err error) {
+	json, err := json.Marshal(api.WalletPSTCreatePOSTParams{
+		Outputs: outputs,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/pst/create", string(json), &wp)
+	return
+}
+
+// WalletPSTCreateFromTransactionPost uses the /wallet/pst/create endpoint to
+// wrap a transaction and its unconfirmed parents in a partially signed
+// transaction.
+func (c *Client) WalletPSTCreateFromTransactionPost(txn types.Transaction, parents []types.Transaction) (wp api.WalletPST, err error) {
+	json, err := json.Marshal(api.WalletPSTCreatePOSTParams{
+		Transaction: txn,
+		Parents:     parents,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/pst/create", string(json), &wp)
+	return
+}
+
+// WalletPSTSignPost uses the /wallet/pst/sign endpoint to add the wallet's
+// signatures to a partially signed transaction.
+func (c *Client) WalletPSTSignPost(pst modules.PartiallySignedTransaction) (wp api.WalletPST, err error) {
+	json, err := json.Marshal(api.WalletPST{
+		PST: pst,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/pst/sign", string(json), &wp)
+	return
+}
+
+// WalletPSTCombinePost uses the /wallet/pst/combine endpoint to merge the
+// signatures of copies of a partially signed transaction.
+func (c *Client) WalletPSTCombinePost(psts []modules.PartiallySignedTransaction) (wp api.WalletPST, err error) {
+	json, err := json.Marshal(api.WalletPSTCombinePOSTParams{
+		PSTs: psts,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/pst/combine", string(json), &wp)
+	return
+}
+
+// WalletPSTBroadcastPost uses the /wallet/pst/broadcast endpoint to broadcast
+// a fully signed partially signed transaction along with its parents.
+func (c *Client) WalletPSTBroadcastPost(pst modules.PartiallySignedTransaction) (wpbp api.WalletPSTBroadcastPOST, err error) {
+	json, err := json.Marshal(api.WalletPST{
+		PST: pst,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/pst/broadcast", string(json), &wpbp)
+	return
+}
+
+// WalletInitWatchOnlyPost uses the /wallet/init/watchonly endpoint to
+// initialize a watch-only wallet from the public keys exported from a seed.
+func (c *Client) WalletInitWatchOnlyPost(wk modules.WatchKeys, password string, force bool) (err error) {
+	json, err := json.Marshal(api.WalletInitWatchOnlyPOSTParams{
+		EncryptionPassword: password,
+		WatchKeys:          wk,
+		Force:              force,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/init/watchonly", string(json), nil)
+	return
+}
+
+// WalletWatchKeysPost uses the /wallet/watchkeys endpoint to add more public
+// keys of the seed to a watch-only wallet.
+func (c *Client) WalletWatchKeysPost(wk modules.WatchKeys, unused bool) (err error) {
+	json, err := json.Marshal(api.WalletWatchKeysPOSTParams{
+		WatchKeys: wk,
+		Unused:    unused,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/watchkeys", string(json), nil)
+	return
+}
+
+// WalletFrozenGet requests the /wallet/frozen endpoint to get the frozen
+// outputs of the wallet.
+func (c *Client) WalletFrozenGet() (wfg api.WalletFrozenGET, err error) {
+	err = c.get("/wallet/frozen", &wfg)
+	return
+}
+
+// WalletBumpPost uses the /wallet/bump endpoint to raise the fee of an
+// unconfirmed transaction to fee with a child transaction.
+func (c *Client) WalletBumpPost(txid types.TransactionID, fee types.Currency, rebroadcast bool) (wbp api.WalletBumpPOST, err error) {
+	json, err := json.Marshal(api.WalletBumpPOSTParams{
+		TransactionID: txid,
+		Fee:           fee,
+		Rebroadcast:   rebroadcast,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/bump", string(json), &wbp)
+	return
+}
+
+// WalletFreezePost uses the /wallet/freeze endpoint to prevent the wallet
+// from spending the provided outputs.
+func (c *Client) WalletFreezePost(ids []types.SiacoinOutputID) (err error) {
+	json, err := json.Marshal(api.WalletFreezePOSTParams{
+		OutputIDs: ids,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/freeze", string(json), nil)
+	return
+}
+
+// WalletUnfreezePost uses the /wallet/unfreeze endpoint to allow the wallet
+// to spend frozen outputs again.
+func (c *Client) WalletUnfreezePost(ids []types.SiacoinOutputID) (err error) {
+	json, err := json.Marshal(api.WalletFreezePOSTParams{
+		OutputIDs: ids,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/unfreeze", string(json), nil)
+	return
+}
+
+// WalletScheduleGet requests the /wallet/schedule endpoint to list the
+// scheduled payments.
+func (c *Client) WalletScheduleGet() (wsg api.WalletScheduleGET, err error) {
+	err = c.get("/wallet/schedule", &wsg)
+	return
+}
+
+// WalletSchedulePost uses the /wallet/schedule endpoint to schedule a
+// payment, or to check that it could be funded now if dryRun is set.
+func (c *Client) WalletSchedulePost(sp modules.ScheduledPayment, dryRun bool) (wsp api.WalletSchedulePOST, err error) {
+	json, err := json.Marshal(api.WalletSchedulePOSTParams{
+		Outputs:   sp.Outputs,
+		Height:    sp.Height,
+		Interval:  sp.Interval,
+		Remaining: sp.Remaining,
+		DryRun:    dryRun,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/schedule", string(json), &wsp)
+	return
+}
+
+// WalletScheduleCancelPost uses the /wallet/schedule/cancel endpoint to
+// cancel a scheduled payment.
+func (c *Client) WalletScheduleCancelPost(id crypto.Hash) (err error) {
+	json, err := json.Marshal(api.WalletScheduleCancelPOSTParams{
+		ID: id,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/schedule/cancel", string(json), nil)
+	return
+}
+
+// WalletTimelockedGet requests the /wallet/timelocked endpoint to list the
+// timelocked addresses of the wallet.
+func (c *Client) WalletTimelockedGet() (wtg api.WalletTimelockedGET, err error) {
+	err = c.get("/wallet/timelocked", &wtg)
+	return
+}
+
+// WalletTimelockedPost uses the /wallet/timelocked endpoint to create a new
+// address that can't be spent from before unlockHeight.
+func (c *Client) WalletTimelockedPost(unlockHeight types.BlockHeight) (wta api.WalletTimelockedAddress, err error) {
+	json, err := json.Marshal(api.WalletTimelockedPOSTParams{
+		UnlockHeight: unlockHeight,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/timelocked", string(json), &wta)
+	return
+}
+
+// WalletTimelockedSweepPost uses the /wallet/timelocked/sweep endpoint to
+// sweep the outputs of timelocked addresses whose timelock has expired.
+func (c *Client) WalletTimelockedSweepPost() (wsp api.WalletSiacoinsPOST, err error) {
+	err = c.post("/wallet/timelocked/sweep", "", &wsp)
+	return
+}
+
+// WalletLabelsGet requests the /wallet/labels endpoint to list the labeled
+// addresses.
+func (c *Client) WalletLabelsGet() (wlg api.WalletLabelsGET, err error) {
+	err = c.get("/wallet/labels", &wlg)
+	return
+}
+
+// WalletLabelsPost uses the /wallet/labels endpoint to label an address. An
+// empty label removes the label of the address.
+func (c *Client) WalletLabelsPost(addr types.UnlockHash, label string) (err error) {
+	json, err := json.Marshal(api.WalletAddressLabel{
+		Address: addr,
+		Label:   label,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/labels", string(json), nil)
+	return
+}
+
+// WalletNotePost uses the /wallet/note endpoint to attach a note to a
+// transaction. An empty note removes the note of the transaction.
+func (c *Client) WalletNotePost(txid types.TransactionID, note string) (err error) {
+	json, err := json.Marshal(api.WalletNotePOSTParams{
+		TransactionID: txid,
+		Note:          note,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/note", string(json), nil)
+	return
+}
+
+// WalletWebhooksGet requests the /wallet/webhooks endpoint to list the
+// registered webhooks.
+func (c *Client) WalletWebhooksGet() (wwg api.WalletWebhooksGET, err error) {
+	err = c.get("/wallet/webhooks", &wwg)
+	return
+}
+
+// WalletWebhooksPost uses the /wallet/webhooks endpoint to register a
+// webhook. The returned webhook contains the ID and secret chosen by the
+// wallet.
+func (c *Client) WalletWebhooksPost(wh modules.Webhook) (webhook modules.Webhook, err error) {
+	json, err := json.Marshal(api.WalletWebhooksPOSTParams{
+		URL:           wh.URL,
+		Events:        wh.Events,
+		Addresses:     wh.Addresses,
+		Confirmations: wh.Confirmations,
+		Secret:        wh.Secret,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/webhooks", string(json), &webhook)
+	return
+}
+
+// WalletWebhooksRemovePost uses the /wallet/webhooks/remove endpoint to
+// remove a webhook along with its delivery log.
+func (c *Client) WalletWebhooksRemovePost(id crypto.Hash) (err error) {
+	json, err := json.Marshal(api.WalletWebhooksRemovePOSTParams{
+		ID: id,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/webhooks/remove", string(json), nil)
+	return
+}
+
+// WalletWebhookDeliveriesGet requests the /wallet/webhooks/deliveries/:id
+// endpoint to get the delivery log of a webhook.
+func (c *Client) WalletWebhookDeliveriesGet(id crypto.Hash) (wwdg api.WalletWebhookDeliveriesGET, err error) {
+	err = c.get("/wallet/webhooks/deliveries/"+id.String(), &wwdg)
+	return
+}
+
+// WalletsGet requests the /wallets resource and returns the names of the
+// named wallets.
+func (c *Client) WalletsGet() (nw api.NamedWallets, err error) {
+	err = c.get("/wallets", &nw)
+	return
+}
+
+// WalletsCreatePost uses the /wallets/create endpoint to create a new named
+// wallet.
+func (c *Client) WalletsCreatePost(name string) (err error) {
+	values := url.Values{}
+	values.Set("name", name)
+	err = c.post("/wallets/create", values.Encode(), nil)
+	return
+}
+
+// WalletsDeletePost uses the /wallets/delete endpoint to delete a named
+// wallet.
+func (c *Client) WalletsDeletePost(name string) (err error) {
+	values := url.Values{}
+	values.Set("name", name)
+	err = c.post("/wallets/delete", values.Encode(), nil)
+	return
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"gitlab.com/NebulousLabs/Sia/build"
 	"gitlab.com/NebulousLabs/Sia/modules"
@@ -27,12 +28,21 @@ type (
 	// ContractInfoGET contains the information that is returned after a GET request
 	// to /host/contracts - information for the host about stored obligations.
 	ContractInfoGET struct {
-		Contracts []modules.StorageObligation `json:"contracts"`
+		Contracts  []modules.StorageObligation `json:"contracts"`
+		NextCursor string                      `json:"nextcursor"`
+	}
+
+	// HostContractGET contains the information that is returned after a GET
+	// request to /host/contracts/:id - the details of a single storage
+	// obligation.
+	HostContractGET struct {
+		modules.HostObligationDetails
 	}
 
 	// HostGET contains the information that is returned after a GET request to
 	// /host - a bunch of information about the status of the host.
 	HostGET struct {
+		Alerts               []modules.HostAlert              `json:"alerts"`
 		ExternalSettings     modules.HostExternalSettings     `json:"externalsettings"`
 		FinancialMetrics     modules.HostFinancialMetrics     `json:"financialmetrics"`
 		InternalSettings     modules.HostInternalSettings     `json:"internalsettings"`
@@ -48,11 +58,28 @@ type (
 		ConversionRate float64        `json:"conversionrate"`
 	}
 
+	// HostSectorsGET contains the information that is returned after a GET
+	// request to /host/storage/sectors - a page of the sectors referenced by
+	// the host's storage obligations.
+	HostSectorsGET struct {
+		Sectors    []modules.HostSector `json:"sectors"`
+		NextCursor string               `json:"nextcursor"`
+	}
+
+	// HostSectorsAuditGET contains the information that is returned after a
+	// GET request to /host/storage/sectors/audit - the result of
+	// cross-checking the host's storage obligations against the storage
+	// manager.
+	HostSectorsAuditGET struct {
+		modules.HostSectorAudit
+	}
+
 	// StorageGET contains the information that is returned after a GET request
 	// to /host/storage - a bunch of information about the status of storage
 	// management on the host.
 	StorageGET struct {
 		Folders []modules.StorageFolderMetadata `json:"folders"`
+		Scrub   modules.SectorScrubStatus       `json:"scrub"`
 	}
 )
 
@@ -69,11 +96,84 @@ func folderIndex(folderPath string, storageFolders []modules.StorageFolderMetada
 
 // hostContractInfoHandler handles the API call to get the contract information of the host.
 // Information is retrieved via the storage obligations from the host database.
+// The contracts can be filtered, sorted and paginated. Without a limit, all
+// matching contracts are returned.
 func (api *API) hostContractInfoHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
-	cg := ContractInfoGET{
-		Contracts: api.host.StorageObligations(),
+	q := modules.HostObligationQuery{
+		Cursor: req.FormValue("cursor"),
+		SortBy: req.FormValue("sortby"),
+		Status: req.FormValue("status"),
+	}
+	for _, param := range []struct {
+		name  string
+		value *types.BlockHeight
+	}{
+		{"maxexpiration", &q.MaxExpiration},
+		{"minexpiration", &q.MinExpiration},
+	} {
+		if str := req.FormValue(param.name); str != "" {
+			if _, err := fmt.Sscan(str, param.value); err != nil {
+				WriteError(w, Error{"unable to parse " + param.name + ": " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	for _, param := range []struct {
+		name  string
+		value **bool
+	}{
+		{"proofconfirmed", &q.ProofConfirmed},
+		{"proofconstructed", &q.ProofConstructed},
+	} {
+		if str := req.FormValue(param.name); str != "" {
+			b, err := strconv.ParseBool(str)
+			if err != nil {
+				WriteError(w, Error{"unable to parse " + param.name + ": " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+			*param.value = &b
+		}
+	}
+	if str := req.FormValue("limit"); str != "" {
+		if _, err := fmt.Sscan(str, &q.Limit); err != nil {
+			WriteError(w, Error{"unable to parse limit: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	switch order := req.FormValue("order"); order {
+	case "", "asc":
+	case "desc":
+		q.Descending = true
+	default:
+		WriteError(w, Error{"unknown order " + order + ", must be asc or desc"}, http.StatusBadRequest)
+		return
 	}
-	WriteJSON(w, cg)
+
+	page, err := api.host.StorageObligationsQuery(q)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, ContractInfoGET{
+		Contracts:  page.Obligations,
+		NextCursor: page.NextCursor,
+	})
+}
+
+// hostContractHandlerGET handles GET requests to /host/contracts/:id, which
+// return the details of a single storage obligation.
+func (api *API) hostContractHandlerGET(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	var id types.FileContractID
+	if err := id.LoadString(ps.ByName("id")); err != nil {
+		WriteError(w, Error{"unable to parse contract id: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	details, err := api.host.StorageObligation(id)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, HostContractGET{details})
 }
 
 // hostHandlerGET handles GET requests to the /host API endpoint, returning key
@@ -86,6 +186,7 @@ func (api *API) hostHandlerGET(w http.ResponseWriter, req *http.Request, _ httpr
 	cs := api.host.ConnectabilityStatus()
 	ws := api.host.WorkingStatus()
 	hg := HostGET{
+		Alerts:               api.host.Alerts(),
 		ExternalSettings:     es,
 		FinancialMetrics:     fm,
 		InternalSettings:     is,
@@ -142,6 +243,14 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		}
 		settings.NetAddress = x
 	}
+	if req.FormValue("sectorscrubrate") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("sectorscrubrate"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.SectorScrubRate = x
+	}
 	if req.FormValue("windowsize") != "" {
 		var x types.BlockHeight
 		_, err := fmt.Sscan(req.FormValue("windowsize"), &x)
@@ -151,6 +260,96 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		settings.WindowSize = x
 	}
 
+	if req.FormValue("folderhealthminoperations") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("folderhealthminoperations"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.FolderHealthMinOperations = x
+	}
+	if req.FormValue("foldermaxfailedreadrate") != "" {
+		var x float64
+		_, err := fmt.Sscan(req.FormValue("foldermaxfailedreadrate"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.FolderMaxFailedReadRate = x
+	}
+	if req.FormValue("foldermaxfailedwriterate") != "" {
+		var x float64
+		_, err := fmt.Sscan(req.FormValue("foldermaxfailedwriterate"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.FolderMaxFailedWriteRate = x
+	}
+
+	if req.FormValue("maxconnections") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxconnections"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxConnections = x
+	}
+	if req.FormValue("maxconnectionsperpeer") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxconnectionsperpeer"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxConnectionsPerPeer = x
+	}
+	if req.FormValue("maxdownloadspeed") != "" {
+		var x int64
+		_, err := fmt.Sscan(req.FormValue("maxdownloadspeed"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxDownloadSpeed = x
+	}
+	if req.FormValue("maxdownloadspeedperpeer") != "" {
+		var x int64
+		_, err := fmt.Sscan(req.FormValue("maxdownloadspeedperpeer"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxDownloadSpeedPerPeer = x
+	}
+	if req.FormValue("maxrpcrate") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxrpcrate"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxRPCRate = x
+	}
+	if req.FormValue("maxrpcrateperpeer") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxrpcrateperpeer"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxRPCRatePerPeer = x
+	}
+	if req.FormValue("maxuploadspeed") != "" {
+		var x int64
+		_, err := fmt.Sscan(req.FormValue("maxuploadspeed"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxUploadSpeed = x
+	}
+	if req.FormValue("maxuploadspeedperpeer") != "" {
+		var x int64
+		_, err := fmt.Sscan(req.FormValue("maxuploadspeedperpeer"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxUploadSpeedPerPeer = x
+	}
+
 	if req.FormValue("collateral") != "" {
 		var x types.Currency
 		_, err := fmt.Sscan(req.FormValue("collateral"), &x)
@@ -302,6 +501,7 @@ func (api *API) hostAnnounceHandler(w http.ResponseWriter, req *http.Request, _
 func (api *API) storageHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	WriteJSON(w, StorageGET{
 		Folders: api.host.StorageFolders(),
+		Scrub:   api.host.ScrubStatus(),
 	})
 }
 
@@ -351,6 +551,35 @@ func (api *API) storageFoldersResizeHandler(w http.ResponseWriter, req *http.Req
 	WriteSuccess(w)
 }
 
+// storageFoldersMoveHandler moves a storage folder in the storage manager to
+// a new path.
+func (api *API) storageFoldersMoveHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	folderPath := req.FormValue("path")
+	if folderPath == "" {
+		WriteError(w, Error{"path parameter is required"}, http.StatusBadRequest)
+		return
+	}
+	newPath := req.FormValue("newpath")
+	if newPath == "" {
+		WriteError(w, Error{"newpath parameter is required"}, http.StatusBadRequest)
+		return
+	}
+
+	storageFolders := api.host.StorageFolders()
+	folderIndex, err := folderIndex(folderPath, storageFolders)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	err = api.host.MoveStorageFolder(uint16(folderIndex), newPath)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // storageFoldersRemoveHandler removes a storage folder from the storage
 // manager.
 func (api *API) storageFoldersRemoveHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
@@ -376,6 +605,61 @@ func (api *API) storageFoldersRemoveHandler(w http.ResponseWriter, req *http.Req
 	WriteSuccess(w)
 }
 
+// storageFoldersResetHealthHandler resets the health statistics of a storage
+// folder, which also clears its degraded state.
+func (api *API) storageFoldersResetHealthHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	folderPath := req.FormValue("path")
+	if folderPath == "" {
+		WriteError(w, errNoPath, http.StatusBadRequest)
+		return
+	}
+
+	storageFolders := api.host.StorageFolders()
+	folderIndex, err := folderIndex(folderPath, storageFolders)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	err = api.host.ResetStorageFolderHealth(uint16(folderIndex))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// storageSectorsHandlerGET handles the call to page through the sectors
+// referenced by the host's storage obligations.
+func (api *API) storageSectorsHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var limit int
+	if str := req.FormValue("limit"); str != "" {
+		if _, err := fmt.Sscan(str, &limit); err != nil {
+			WriteError(w, Error{"unable to parse limit: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	page, err := api.host.Sectors(req.FormValue("cursor"), limit)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, HostSectorsGET{
+		Sectors:    page.Sectors,
+		NextCursor: page.NextCursor,
+	})
+}
+
+// storageSectorsAuditHandlerGET handles the call to cross-check the sectors
+// referenced by the host's storage obligations against the storage manager.
+func (api *API) storageSectorsAuditHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	audit, err := api.host.AuditSectors()
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, HostSectorsAuditGET{audit})
+}
+
 // storageSectorsDeleteHandler handles the call to delete a sector from the
 // storage manager.
 func (api *API) storageSectorsDeleteHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type (
+	// HostMaintenanceGET contains the information that is returned after a
+	// GET request to /host/maintenance.
+	HostMaintenanceGET struct {
+		modules.HostMaintenanceStatus
+	}
+)
+
+// hostMaintenanceHandlerGET handles GET requests to /host/maintenance.
+func (api *API) hostMaintenanceHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	status, err := api.host.Maintenance()
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, HostMaintenanceGET{status})
+}
+
+// hostMaintenanceHandlerPOST handles POST requests to /host/maintenance, which
+// schedule maintenance of the host.
+func (api *API) hostMaintenanceHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var m modules.HostMaintenance
+	if _, err := fmt.Sscan(req.FormValue("start"), &m.Start); err != nil {
+		WriteError(w, Error{"could not parse start: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if _, err := fmt.Sscan(req.FormValue("end"), &m.End); err != nil {
+		WriteError(w, Error{"could not parse end: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if req.FormValue("announce") != "" {
+		if _, err := fmt.Sscan(req.FormValue("announce"), &m.Announce); err != nil {
+			WriteError(w, Error{"could not parse announce: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if err := api.host.SetMaintenance(m); err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// hostMaintenanceCancelHandlerPOST handles POST requests to
+// /host/maintenance/cancel, which cancel the scheduled maintenance.
+func (api *API) hostMaintenanceCancelHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if err := api.host.SetMaintenance(modules.HostMaintenance{}); err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// defaultMetricsRange is the time range of a financial report if no start is
+// specified.
+const defaultMetricsRange = 30 * 24 * time.Hour
+
+// HostMetricsGET contains the information that is returned after a GET
+// request to /host/metrics.
+type HostMetricsGET struct {
+	modules.HostFinancialReport
+}
+
+// hostMetricsHandlerGET handles GET requests to /host/metrics, which return
+// the finances of the host for a time range. The range is given as unix
+// timestamps and defaults to the last 30 days. The report is returned as JSON
+// by default, or as CSV if format=csv, in which case data selects whether the
+// periods or the obligations are returned.
+func (api *API) hostMetricsHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	to := time.Now()
+	if toStr := req.FormValue("to"); toStr != "" {
+		toInt, err := strconv.ParseInt(toStr, 10, 64)
+		if err != nil {
+			WriteError(w, Error{"parsing integer value for parameter `to` failed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		to = time.Unix(toInt, 0)
+	}
+	from := to.Add(-defaultMetricsRange)
+	if fromStr := req.FormValue("from"); fromStr != "" {
+		fromInt, err := strconv.ParseInt(fromStr, 10, 64)
+		if err != nil {
+			WriteError(w, Error{"parsing integer value for parameter `from` failed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		from = time.Unix(fromInt, 0)
+	}
+
+	report, err := api.host.FinancialReport(from, to, req.FormValue("interval"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	switch format := req.FormValue("format"); format {
+	case "", "json":
+		WriteJSON(w, HostMetricsGET{report})
+	case "csv":
+		var write func(io.Writer, modules.HostFinancialReport) error
+		switch data := req.FormValue("data"); data {
+		case "", "periods":
+			write = writeFinancialPeriodsCSV
+		case "obligations":
+			write = writeObligationResultsCSV
+		default:
+			WriteError(w, Error{"unknown data " + data + ", must be periods or obligations"}, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		if err := write(w, report); err != nil {
+			WriteError(w, Error{"unable to write csv: " + err.Error()}, http.StatusInternalServerError)
+		}
+	default:
+		WriteError(w, Error{"unknown format " + format + ", must be json or csv"}, http.StatusBadRequest)
+	}
+}
+
+// writeFinancialPeriodsCSV writes the periods of a financial report to w as
+// CSV, one row per period.
+func writeFinancialPeriodsCSV(w io.Writer, report modules.HostFinancialReport) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"start", "end", "contractcount", "lockedstoragecollateral", "riskedstoragecollateral",
+		"contractcompensation", "downloadbandwidthrevenue", "storagerevenue", "uploadbandwidthrevenue", "totalrevenue",
+		"lostrevenue", "loststoragecollateral", "transactionfeeexpenses"})
+	if err != nil {
+		return err
+	}
+	for _, p := range report.Periods {
+		err := cw.Write([]string{p.Start.Format(time.RFC3339), p.End.Format(time.RFC3339), fmt.Sprint(p.ContractCount),
+			p.LockedStorageCollateral.String(), p.RiskedStorageCollateral.String(), p.ContractCompensation.String(),
+			p.DownloadBandwidthRevenue.String(), p.StorageRevenue.String(), p.UploadBandwidthRevenue.String(),
+			p.TotalRevenue.String(), p.LostRevenue.String(), p.LostStorageCollateral.String(), p.TransactionFeeExpenses.String()})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeObligationResultsCSV writes the obligations of a financial report to w
+// as CSV, one row per obligation.
+func writeObligationResultsCSV(w io.Writer, report modules.HostFinancialReport) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"obligationid", "obligationstatus", "resolutionheight", "timestamp", "contractcost",
+		"downloadrevenue", "storagerevenue", "uploadrevenue", "revenue", "lockedcollateral", "lostcollateral",
+		"lostrevenue", "transactionfees"})
+	if err != nil {
+		return err
+	}
+	for _, o := range report.Obligations {
+		err := cw.Write([]string{o.ObligationId.String(), o.ObligationStatus, fmt.Sprint(o.ResolutionHeight),
+			o.Timestamp.Format(time.RFC3339), o.ContractCost.String(), o.DownloadRevenue.String(),
+			o.StorageRevenue.String(), o.UploadRevenue.String(), o.Revenue.String(), o.LockedCollateral.String(),
+			o.LostCollateral.String(), o.LostRevenue.String(), o.TransactionFees.String()})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
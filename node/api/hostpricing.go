@@ -0,0 +1,146 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type (
+	// HostPricingGET contains the information that is returned after a GET
+	// request to /host/pricing.
+	HostPricingGET struct {
+		Policy modules.HostPricingPolicy `json:"policy"`
+	}
+
+	// HostPricingEstimateGET contains the information that is returned after
+	// a GET request to /host/pricing/estimate.
+	HostPricingEstimateGET struct {
+		modules.HostPricingEstimate
+	}
+
+	// HostPricingHistoryGET contains the information that is returned after a
+	// GET request to /host/pricing/history.
+	HostPricingHistoryGET struct {
+		History []modules.HostPriceChange `json:"history"`
+	}
+)
+
+// parseFillRules parses a comma separated list of fill rules of the form
+// 'utilization:multiplier', e.g. '0.5:1.2,0.9:2'. An empty string is parsed as
+// an empty list.
+func parseFillRules(s string) ([]modules.HostPricingFillRule, error) {
+	var rules []modules.HostPricingFillRule
+	if s == "" {
+		return rules, nil
+	}
+	for _, r := range strings.Split(s, ",") {
+		var rule modules.HostPricingFillRule
+		parts := strings.Split(r, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("fill rule %q must be of the form utilization:multiplier", r)
+		}
+		if _, err := fmt.Sscan(parts[0], &rule.Utilization); err != nil {
+			return nil, fmt.Errorf("could not parse utilization of fill rule %q: %v", r, err)
+		}
+		if _, err := fmt.Sscan(parts[1], &rule.Multiplier); err != nil {
+			return nil, fmt.Errorf("could not parse multiplier of fill rule %q: %v", r, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// hostPricingHandlerGET handles GET requests to /host/pricing.
+func (api *API) hostPricingHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, HostPricingGET{
+		Policy: api.host.PricingPolicy(),
+	})
+}
+
+// hostPricingHandlerPOST handles POST requests to /host/pricing, which update
+// the policy of the host's pricing engine. Unspecified parameters are left
+// unchanged.
+func (api *API) hostPricingHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	policy := api.host.PricingPolicy()
+	if req.FormValue("enabled") != "" {
+		_, err := fmt.Sscan(req.FormValue("enabled"), &policy.Enabled)
+		if err != nil {
+			WriteError(w, Error{"could not parse enabled: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if _, ok := req.Form["fillrules"]; ok {
+		rules, err := parseFillRules(req.FormValue("fillrules"))
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		policy.FillRules = rules
+	}
+	if req.FormValue("percentile") != "" {
+		_, err := fmt.Sscan(req.FormValue("percentile"), &policy.Percentile)
+		if err != nil {
+			WriteError(w, Error{"could not parse percentile: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if req.FormValue("updateinterval") != "" {
+		var x types.BlockHeight
+		_, err := fmt.Sscan(req.FormValue("updateinterval"), &x)
+		if err != nil {
+			WriteError(w, Error{"could not parse updateinterval: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		policy.UpdateInterval = x
+	}
+	if req.FormValue("maxstorageprice") != "" {
+		_, err := fmt.Sscan(req.FormValue("maxstorageprice"), &policy.MaxStoragePrice)
+		if err != nil {
+			WriteError(w, Error{"could not parse maxstorageprice: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if req.FormValue("minstorageprice") != "" {
+		_, err := fmt.Sscan(req.FormValue("minstorageprice"), &policy.MinStoragePrice)
+		if err != nil {
+			WriteError(w, Error{"could not parse minstorageprice: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	err := api.host.SetPricingPolicy(policy)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// hostPricingEstimateHandlerGET handles GET requests to
+// /host/pricing/estimate, which perform a dry run of the pricing engine.
+func (api *API) hostPricingEstimateHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	estimate, err := api.host.PricingEstimate()
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, HostPricingEstimateGET{estimate})
+}
+
+// hostPricingHistoryHandlerGET handles GET requests to /host/pricing/history.
+func (api *API) hostPricingHistoryHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	history, err := api.host.PriceHistory()
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, HostPricingHistoryGET{
+		History: history,
+	})
+}
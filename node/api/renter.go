@@ -1,7 +1,9 @@
 package api
 
 import (
+	"encoding/csv"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"path/filepath"
@@ -116,6 +118,12 @@ type (
 		GoodForRenew bool `json:"goodforrenew"`
 	}
 
+	// RenterContractHistory contains the financial history of the renter's
+	// contracts.
+	RenterContractHistory struct {
+		Contracts []modules.ContractHistory `json:"contracts"`
+	}
+
 	// RenterContracts contains the renter's contracts.
 	RenterContracts struct {
 		Contracts         []RenterContract `json:"contracts"`
@@ -151,6 +159,13 @@ type (
 		modules.Allowance
 	}
 
+	// RenterPricesDetailedGET lists the data that is returned when a GET call
+	// is made to /renter/prices with detailed set to true.
+	RenterPricesDetailedGET struct {
+		modules.RenterDetailedPriceEstimation
+		Allowance modules.Allowance `json:"allowance"`
+	}
+
 	// RenterShareASCII contains an ASCII-encoded .sia file.
 	RenterShareASCII struct {
 		ASCIIsia string `json:"asciisia"`
@@ -304,6 +319,143 @@ func (api *API) renterContractCancelHandler(w http.ResponseWriter, req *http.Req
 	WriteSuccess(w)
 }
 
+// renterContract converts a modules.RenterContract into the RenterContract
+// type returned by the API.
+func (api *API) renterContract(c modules.RenterContract) RenterContract {
+	var size uint64
+	if len(c.Transaction.FileContractRevisions) != 0 {
+		size = c.Transaction.FileContractRevisions[0].NewFileSize
+	}
+
+	// Fetch host address
+	var netAddress modules.NetAddress
+	hdbe, exists := api.renter.Host(c.HostPublicKey)
+	if exists {
+		netAddress = hdbe.NetAddress
+	}
+
+	return RenterContract{
+		DownloadSpending:          c.DownloadSpending,
+		EndHeight:                 c.EndHeight,
+		Fees:                      c.TxnFee.Add(c.SiafundFee).Add(c.ContractFee),
+		GoodForUpload:             c.Utility.GoodForUpload,
+		GoodForRenew:              c.Utility.GoodForRenew,
+		HostPublicKey:             c.HostPublicKey,
+		ID:                        c.ID,
+		LastTransaction:           c.Transaction,
+		NetAddress:                netAddress,
+		RenterFunds:               c.RenterFunds,
+		Size:                      size,
+		StartHeight:               c.StartHeight,
+		StorageSpending:           c.StorageSpending,
+		StorageSpendingDeprecated: c.StorageSpending,
+		TotalCost:                 c.TotalCost,
+		UploadSpending:            c.UploadSpending,
+	}
+}
+
+// renterContractFormHandler handles the API call to form a contract with a
+// specific host.
+func (api *API) renterContractFormHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var hostKey types.SiaPublicKey
+	hostKey.LoadString(req.FormValue("host"))
+	if hostKey.Key == nil {
+		WriteError(w, Error{"unable to parse host: invalid host public key"}, http.StatusBadRequest)
+		return
+	}
+	funds, ok := scanAmount(req.FormValue("funds"))
+	if !ok {
+		WriteError(w, Error{"unable to parse funds"}, http.StatusBadRequest)
+		return
+	}
+	// An endheight of 0 tells the contractor to use the end of the current
+	// period.
+	var endHeight types.BlockHeight
+	if eh := req.FormValue("endheight"); eh != "" {
+		if _, err := fmt.Sscan(eh, &endHeight); err != nil {
+			WriteError(w, Error{"unable to parse endheight: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	contract, err := api.renter.FormContract(hostKey, funds, endHeight)
+	if err != nil {
+		WriteError(w, Error{"unable to form contract: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, api.renterContract(contract))
+}
+
+// renterContractRefreshHandler handles the API call to add funds to a
+// contract without extending it.
+func (api *API) renterContractRefreshHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var fcid types.FileContractID
+	if err := fcid.LoadString(req.FormValue("id")); err != nil {
+		WriteError(w, Error{"unable to parse id: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	funds, ok := scanAmount(req.FormValue("funds"))
+	if !ok {
+		WriteError(w, Error{"unable to parse funds"}, http.StatusBadRequest)
+		return
+	}
+	contract, err := api.renter.RefreshContract(fcid, funds)
+	if err != nil {
+		WriteError(w, Error{"unable to refresh contract: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, api.renterContract(contract))
+}
+
+// renterContractRenewHandler handles the API call to renew a contract right
+// away.
+func (api *API) renterContractRenewHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var fcid types.FileContractID
+	if err := fcid.LoadString(req.FormValue("id")); err != nil {
+		WriteError(w, Error{"unable to parse id: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	funds, ok := scanAmount(req.FormValue("funds"))
+	if !ok {
+		WriteError(w, Error{"unable to parse funds"}, http.StatusBadRequest)
+		return
+	}
+	// An endheight of 0 tells the contractor to use the end of the current
+	// period.
+	var endHeight types.BlockHeight
+	if eh := req.FormValue("endheight"); eh != "" {
+		if _, err := fmt.Sscan(eh, &endHeight); err != nil {
+			WriteError(w, Error{"unable to parse endheight: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	contract, err := api.renter.RenewContract(fcid, funds, endHeight)
+	if err != nil {
+		WriteError(w, Error{"unable to renew contract: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, api.renterContract(contract))
+}
+
+// renterContractUtilityHandler handles the API call to lock or unlock the
+// utility of a contract.
+func (api *API) renterContractUtilityHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var fcid types.FileContractID
+	if err := fcid.LoadString(req.FormValue("id")); err != nil {
+		WriteError(w, Error{"unable to parse id: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	locked, err := scanBool(req.FormValue("locked"))
+	if err != nil {
+		WriteError(w, Error{"unable to parse locked: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.renter.LockContractUtility(fcid, locked); err != nil {
+		WriteError(w, Error{"unable to update contract utility: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // renterContractsHandler handles the API call to request the Renter's
 // contracts.
 //
@@ -337,37 +489,7 @@ func (api *API) renterContractsHandler(w http.ResponseWriter, req *http.Request,
 	inactiveContracts := []RenterContract{}
 	expiredContracts := []RenterContract{}
 	for _, c := range api.renter.Contracts() {
-		var size uint64
-		if len(c.Transaction.FileContractRevisions) != 0 {
-			size = c.Transaction.FileContractRevisions[0].NewFileSize
-		}
-
-		// Fetch host address
-		var netAddress modules.NetAddress
-		hdbe, exists := api.renter.Host(c.HostPublicKey)
-		if exists {
-			netAddress = hdbe.NetAddress
-		}
-
-		// Build the contract.
-		contract := RenterContract{
-			DownloadSpending:          c.DownloadSpending,
-			EndHeight:                 c.EndHeight,
-			Fees:                      c.TxnFee.Add(c.SiafundFee).Add(c.ContractFee),
-			GoodForUpload:             c.Utility.GoodForUpload,
-			GoodForRenew:              c.Utility.GoodForRenew,
-			HostPublicKey:             c.HostPublicKey,
-			ID:                        c.ID,
-			LastTransaction:           c.Transaction,
-			NetAddress:                netAddress,
-			RenterFunds:               c.RenterFunds,
-			Size:                      size,
-			StartHeight:               c.StartHeight,
-			StorageSpending:           c.StorageSpending,
-			StorageSpendingDeprecated: c.StorageSpending,
-			TotalCost:                 c.TotalCost,
-			UploadSpending:            c.UploadSpending,
-		}
+		contract := api.renterContract(c)
 		if c.Utility.GoodForRenew {
 			activeContracts = append(activeContracts, contract)
 		} else if inactive && !c.Utility.GoodForRenew {
@@ -379,36 +501,7 @@ func (api *API) renterContractsHandler(w http.ResponseWriter, req *http.Request,
 	// Get expired contracts
 	if expired || inactive {
 		for _, c := range api.renter.OldContracts() {
-			var size uint64
-			if len(c.Transaction.FileContractRevisions) != 0 {
-				size = c.Transaction.FileContractRevisions[0].NewFileSize
-			}
-
-			// Fetch host address
-			var netAddress modules.NetAddress
-			hdbe, exists := api.renter.Host(c.HostPublicKey)
-			if exists {
-				netAddress = hdbe.NetAddress
-			}
-
-			contract := RenterContract{
-				DownloadSpending:          c.DownloadSpending,
-				EndHeight:                 c.EndHeight,
-				Fees:                      c.TxnFee.Add(c.SiafundFee).Add(c.ContractFee),
-				GoodForUpload:             c.Utility.GoodForUpload,
-				GoodForRenew:              c.Utility.GoodForRenew,
-				HostPublicKey:             c.HostPublicKey,
-				ID:                        c.ID,
-				LastTransaction:           c.Transaction,
-				NetAddress:                netAddress,
-				RenterFunds:               c.RenterFunds,
-				Size:                      size,
-				StartHeight:               c.StartHeight,
-				StorageSpending:           c.StorageSpending,
-				StorageSpendingDeprecated: c.StorageSpending,
-				TotalCost:                 c.TotalCost,
-				UploadSpending:            c.UploadSpending,
-			}
+			contract := api.renterContract(c)
 			if expired && c.EndHeight < blockHeight {
 				expiredContracts = append(expiredContracts, contract)
 			} else if inactive && c.EndHeight >= blockHeight {
@@ -425,6 +518,67 @@ func (api *API) renterContractsHandler(w http.ResponseWriter, req *http.Request,
 	})
 }
 
+// renterContractsHistoryHandler handles the API call to request the financial
+// history of the renter's contracts. The history is returned as JSON by
+// default, or as CSV with one row per ledger entry if format=csv.
+func (api *API) renterContractsHistoryHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	history := api.renter.ContractHistory()
+	if idStr := req.FormValue("id"); idStr != "" {
+		var fcid types.FileContractID
+		if err := fcid.LoadString(idStr); err != nil {
+			WriteError(w, Error{"unable to parse id: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		var filtered []modules.ContractHistory
+		for _, h := range history {
+			if h.ID == fcid {
+				filtered = append(filtered, h)
+			}
+		}
+		history = filtered
+	}
+
+	switch format := req.FormValue("format"); format {
+	case "", "json":
+		if history == nil {
+			history = []modules.ContractHistory{}
+		}
+		WriteJSON(w, RenterContractHistory{Contracts: history})
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		if err := writeContractHistoryCSV(w, history); err != nil {
+			WriteError(w, Error{"unable to write csv: " + err.Error()}, http.StatusInternalServerError)
+		}
+	default:
+		WriteError(w, Error{"unknown format " + format + ", must be json or csv"}, http.StatusBadRequest)
+	}
+}
+
+// writeContractHistoryCSV writes the contract history to w as CSV. Every
+// ledger entry becomes a row which also contains the fields of its contract.
+func writeContractHistoryCSV(w io.Writer, history []modules.ContractHistory) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"contractid", "hostpublickey", "startheight", "endheight", "totalcost", "fees",
+		"blockheight", "revisionnumber", "downloadspending", "storagespending", "uploadspending", "renterfunds",
+		"resolved", "resolutionheight", "proofsubmitted", "renterpayout"})
+	if err != nil {
+		return err
+	}
+	for _, h := range history {
+		for _, e := range h.Ledger {
+			err := cw.Write([]string{h.ID.String(), h.HostPublicKey.String(), fmt.Sprint(h.StartHeight), fmt.Sprint(h.EndHeight),
+				h.TotalCost.String(), h.Fees.String(), fmt.Sprint(e.BlockHeight), fmt.Sprint(e.RevisionNumber),
+				e.DownloadSpending.String(), e.StorageSpending.String(), e.UploadSpending.String(), e.RenterFunds.String(),
+				fmt.Sprint(h.Resolved), fmt.Sprint(h.ResolutionHeight), fmt.Sprint(h.ProofSubmitted), h.RenterPayout.String()})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
 // renterClearDownloadsHandler handles the API call to request to clear the download queue.
 func (api *API) renterClearDownloadsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	var afterTime time.Time
@@ -634,6 +788,42 @@ func (api *API) renterPricesHandler(w http.ResponseWriter, req *http.Request, ps
 		}
 	}
 
+	// Make a detailed estimation if requested.
+	detailed, err := scanBool(req.FormValue("detailed"))
+	if err != nil {
+		WriteError(w, Error{"unable to parse detailed: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if detailed {
+		var params modules.RenterPriceEstimationParams
+		for _, p := range []struct {
+			name string
+			dst  *uint64
+		}{
+			{"datasize", &params.DataSize},
+			{"datapieces", &params.DataPieces},
+			{"paritypieces", &params.ParityPieces},
+			{"downloadsize", &params.DownloadSize},
+		} {
+			if v := req.FormValue(p.name); v != "" {
+				if _, err := fmt.Sscan(v, p.dst); err != nil {
+					WriteError(w, Error{"unable to parse " + p.name + ": " + err.Error()}, http.StatusBadRequest)
+					return
+				}
+			}
+		}
+		estimate, a, err := api.renter.DetailedPriceEstimation(params, allowance)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		WriteJSON(w, RenterPricesDetailedGET{
+			RenterDetailedPriceEstimation: estimate,
+			Allowance:                     a,
+		})
+		return
+	}
+
 	estimate, a, err := api.renter.PriceEstimation(allowance)
 	if err != nil {
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	// renterProfileHeader is the header that is used to select the renter
+	// profile that should serve a request.
+	renterProfileHeader = "Sia-Renter-Profile"
+
+	// renterProfilePrefix is the path prefix that is used to select the
+	// renter profile that should serve a request. A request to
+	// /renter/profile/<name>/files is served as /renter/files by the profile
+	// <name>.
+	renterProfilePrefix = "/renter/profile/"
+)
+
+var (
+	// errUnknownRenterProfile is returned when a request selects a renter
+	// profile that doesn't exist.
+	errUnknownRenterProfile = errors.New("unknown renter profile")
+)
+
+// RenterProfiles lists the names of the renter profiles.
+type RenterProfiles struct {
+	Profiles []string `json:"profiles"`
+}
+
+// renterProfileName returns the name of the renter profile that was selected
+// by a request. If the profile was selected using the path prefix, the path of
+// the request is rewritten to the path of the regular renter endpoint.
+func renterProfileName(req *http.Request) (string, bool) {
+	if strings.HasPrefix(req.URL.Path, renterProfilePrefix) {
+		rest := strings.TrimPrefix(req.URL.Path, renterProfilePrefix)
+		i := strings.Index(rest, "/")
+		if i <= 0 {
+			return "", false
+		}
+		req.URL.Path = "/renter" + rest[i:]
+		req.URL.RawPath = ""
+		return rest[:i], true
+	}
+	if name := req.Header.Get(renterProfileHeader); name != "" {
+		return name, true
+	}
+	return "", false
+}
+
+// managedProfileAPI returns the API that serves the requests for the renter
+// profile with the given name.
+func (api *API) managedProfileAPI(name string) (*API, error) {
+	if api.renter == nil {
+		return nil, errUnknownRenterProfile
+	}
+	profile, exists := api.renter.Profile(name)
+	if !exists {
+		return nil, errUnknownRenterProfile
+	}
+
+	api.profileMu.Lock()
+	defer api.profileMu.Unlock()
+	profileAPI, exists := api.profileAPIs[name]
+	if !exists || profileAPI.renter != profile {
+		profileAPI = &API{
+			cs:       api.cs,
+			explorer: api.explorer,
+			gateway:  api.gateway,
+			host:     api.host,
+			miner:    api.miner,
+			renter:   profile,
+			tpool:    api.tpool,
+			wallet:   api.wallet,
+		}
+		profileAPI.buildHTTPRoutes(api.requiredUserAgent, api.requiredPassword)
+		api.profileAPIs[name] = profileAPI
+	}
+	return profileAPI, nil
+}
+
+// renterProfilesHandler handles the API call to list the renter profiles.
+func (api *API) renterProfilesHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	profiles := api.renter.Profiles()
+	if profiles == nil {
+		profiles = []string{}
+	}
+	WriteJSON(w, RenterProfiles{Profiles: profiles})
+}
+
+// renterProfilesCreateHandler handles the API call to create a renter
+// profile.
+func (api *API) renterProfilesCreateHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	name := req.FormValue("name")
+	if name == "" {
+		WriteError(w, Error{"name must be specified"}, http.StatusBadRequest)
+		return
+	}
+	if _, err := api.renter.CreateProfile(name); err != nil {
+		WriteError(w, Error{"unable to create profile: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterProfilesDeleteHandler handles the API call to delete a renter
+// profile.
+func (api *API) renterProfilesDeleteHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	name := req.FormValue("name")
+	if name == "" {
+		WriteError(w, Error{"name must be specified"}, http.StatusBadRequest)
+		return
+	}
+	if err := api.renter.DeleteProfile(name); err != nil {
+		WriteError(w, Error{"unable to delete profile: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	api.profileMu.Lock()
+	delete(api.profileAPIs, name)
+	api.profileMu.Unlock()
+	WriteSuccess(w)
+}
@@ -46,13 +46,26 @@ func (api *API) buildHTTPRoutes(requiredUserAgent string, requiredPassword strin
 		router.POST("/host", RequirePassword(api.hostHandlerPOST, requiredPassword))              // Change the settings of the host.
 		router.POST("/host/announce", RequirePassword(api.hostAnnounceHandler, requiredPassword)) // Announce the host to the network.
 		router.GET("/host/contracts", api.hostContractInfoHandler)                                // Get info about contracts.
+		router.GET("/host/contracts/:id", api.hostContractHandlerGET)                             // Get info about a single contract.
 		router.GET("/host/estimatescore", api.hostEstimateScoreGET)
+		router.GET("/host/maintenance", api.hostMaintenanceHandlerGET)
+		router.POST("/host/maintenance", RequirePassword(api.hostMaintenanceHandlerPOST, requiredPassword))
+		router.POST("/host/maintenance/cancel", RequirePassword(api.hostMaintenanceCancelHandlerPOST, requiredPassword))
+		router.GET("/host/metrics", api.hostMetricsHandlerGET)
+		router.GET("/host/pricing", api.hostPricingHandlerGET)
+		router.POST("/host/pricing", RequirePassword(api.hostPricingHandlerPOST, requiredPassword))
+		router.GET("/host/pricing/estimate", api.hostPricingEstimateHandlerGET)
+		router.GET("/host/pricing/history", api.hostPricingHistoryHandlerGET)
 
 		// Calls pertaining to the storage manager that the host uses.
 		router.GET("/host/storage", api.storageHandler)
 		router.POST("/host/storage/folders/add", RequirePassword(api.storageFoldersAddHandler, requiredPassword))
+		router.POST("/host/storage/folders/move", RequirePassword(api.storageFoldersMoveHandler, requiredPassword))
 		router.POST("/host/storage/folders/remove", RequirePassword(api.storageFoldersRemoveHandler, requiredPassword))
+		router.POST("/host/storage/folders/resethealth", RequirePassword(api.storageFoldersResetHealthHandler, requiredPassword))
 		router.POST("/host/storage/folders/resize", RequirePassword(api.storageFoldersResizeHandler, requiredPassword))
+		router.GET("/host/storage/sectors", api.storageSectorsHandlerGET)
+		router.GET("/host/storage/sectors/audit", api.storageSectorsAuditHandlerGET)
 		router.POST("/host/storage/sectors/delete/:merkleroot", RequirePassword(api.storageSectorsDeleteHandler, requiredPassword))
 	}
 
@@ -70,12 +83,20 @@ func (api *API) buildHTTPRoutes(requiredUserAgent string, requiredPassword strin
 		router.GET("/renter", api.renterHandlerGET)
 		router.POST("/renter", RequirePassword(api.renterHandlerPOST, requiredPassword))
 		router.POST("/renter/contract/cancel", RequirePassword(api.renterContractCancelHandler, requiredPassword))
+		router.POST("/renter/contract/form", RequirePassword(api.renterContractFormHandler, requiredPassword))
+		router.POST("/renter/contract/refresh", RequirePassword(api.renterContractRefreshHandler, requiredPassword))
+		router.POST("/renter/contract/renew", RequirePassword(api.renterContractRenewHandler, requiredPassword))
+		router.POST("/renter/contract/utility", RequirePassword(api.renterContractUtilityHandler, requiredPassword))
 		router.GET("/renter/contracts", api.renterContractsHandler)
+		router.GET("/renter/contracts/history", api.renterContractsHistoryHandler)
 		router.GET("/renter/downloads", api.renterDownloadsHandler)
 		router.POST("/renter/downloads/clear", RequirePassword(api.renterClearDownloadsHandler, requiredPassword))
 		router.GET("/renter/files", api.renterFilesHandler)
 		router.GET("/renter/file/*siapath", api.renterFileHandlerGET)
 		router.GET("/renter/prices", api.renterPricesHandler)
+		router.GET("/renter/profiles", api.renterProfilesHandler)
+		router.POST("/renter/profiles/create", RequirePassword(api.renterProfilesCreateHandler, requiredPassword))
+		router.POST("/renter/profiles/delete", RequirePassword(api.renterProfilesDeleteHandler, requiredPassword))
 
 		// TODO: re-enable these routes once the new .sia format has been
 		// standardized and implemented.
@@ -112,32 +133,7 @@ func (api *API) buildHTTPRoutes(requiredUserAgent string, requiredPassword strin
 
 	// Wallet API Calls
 	if api.wallet != nil {
-		router.GET("/wallet", api.walletHandler)
-		router.POST("/wallet/033x", RequirePassword(api.wallet033xHandler, requiredPassword))
-		router.GET("/wallet/address", RequirePassword(api.walletAddressHandler, requiredPassword))
-		router.GET("/wallet/addresses", api.walletAddressesHandler)
-		router.GET("/wallet/backup", RequirePassword(api.walletBackupHandler, requiredPassword))
-		router.POST("/wallet/init", RequirePassword(api.walletInitHandler, requiredPassword))
-		router.POST("/wallet/init/seed", RequirePassword(api.walletInitSeedHandler, requiredPassword))
-		router.POST("/wallet/lock", RequirePassword(api.walletLockHandler, requiredPassword))
-		router.POST("/wallet/seed", RequirePassword(api.walletSeedHandler, requiredPassword))
-		router.GET("/wallet/seeds", RequirePassword(api.walletSeedsHandler, requiredPassword))
-		router.POST("/wallet/siacoins", RequirePassword(api.walletSiacoinsHandler, requiredPassword))
-		router.POST("/wallet/siafunds", RequirePassword(api.walletSiafundsHandler, requiredPassword))
-		router.POST("/wallet/siagkey", RequirePassword(api.walletSiagkeyHandler, requiredPassword))
-		router.POST("/wallet/sweep/seed", RequirePassword(api.walletSweepSeedHandler, requiredPassword))
-		router.GET("/wallet/transaction/:id", api.walletTransactionHandler)
-		router.GET("/wallet/transactions", api.walletTransactionsHandler)
-		router.GET("/wallet/transactions/:addr", api.walletTransactionsAddrHandler)
-		router.GET("/wallet/verify/address/:addr", api.walletVerifyAddressHandler)
-		router.POST("/wallet/unlock", RequirePassword(api.walletUnlockHandler, requiredPassword))
-		router.POST("/wallet/changepassword", RequirePassword(api.walletChangePasswordHandler, requiredPassword))
-		router.GET("/wallet/unlockconditions/:addr", RequirePassword(api.walletUnlockConditionsHandlerGET, requiredPassword))
-		router.POST("/wallet/unlockconditions", RequirePassword(api.walletUnlockConditionsHandlerPOST, requiredPassword))
-		router.GET("/wallet/unspent", RequirePassword(api.walletUnspentHandler, requiredPassword))
-		router.POST("/wallet/sign", RequirePassword(api.walletSignHandler, requiredPassword))
-		router.GET("/wallet/watch", RequirePassword(api.walletWatchHandlerGET, requiredPassword))
-		router.POST("/wallet/watch", RequirePassword(api.walletWatchHandlerPOST, requiredPassword))
+		api.buildWalletRoutes(router, requiredPassword)
 	}
 
 	// Apply UserAgent middleware and return the Router
@@ -145,6 +141,76 @@ func (api *API) buildHTTPRoutes(requiredUserAgent string, requiredPassword strin
 	return
 }
 
+// routeRegistrar registers the routes of an API. It is implemented by
+// *httprouter.Router.
+type routeRegistrar interface {
+	GET(path string, handle httprouter.Handle)
+	POST(path string, handle httprouter.Handle)
+}
+
+// buildWalletRoutes registers the routes of the wallet API calls. The first
+// path segments of the /wallet/ routes must be contained in
+// wallet.ReservedWalletNames.
+func (api *API) buildWalletRoutes(router routeRegistrar, requiredPassword string) {
+	router.GET("/wallet", api.walletHandler)
+	router.POST("/wallet/033x", RequirePassword(api.wallet033xHandler, requiredPassword))
+	router.GET("/wallet/address", RequirePassword(api.walletAddressHandler, requiredPassword))
+	router.GET("/wallet/addresses", api.walletAddressesHandler)
+	router.GET("/wallet/backup", RequirePassword(api.walletBackupHandler, requiredPassword))
+	router.POST("/wallet/bump", RequirePassword(api.walletBumpHandlerPOST, requiredPassword))
+	router.POST("/wallet/freeze", RequirePassword(api.walletFreezeHandlerPOST, requiredPassword))
+	router.GET("/wallet/frozen", RequirePassword(api.walletFrozenHandlerGET, requiredPassword))
+	router.POST("/wallet/init", RequirePassword(api.walletInitHandler, requiredPassword))
+	router.POST("/wallet/init/seed", RequirePassword(api.walletInitSeedHandler, requiredPassword))
+	router.POST("/wallet/init/watchonly", RequirePassword(api.walletInitWatchOnlyHandlerPOST, requiredPassword))
+	router.GET("/wallet/labels", RequirePassword(api.walletLabelsHandlerGET, requiredPassword))
+	router.POST("/wallet/labels", RequirePassword(api.walletLabelsHandlerPOST, requiredPassword))
+	router.POST("/wallet/lock", RequirePassword(api.walletLockHandler, requiredPassword))
+	router.GET("/wallet/multisig", RequirePassword(api.walletMultisigHandlerGET, requiredPassword))
+	router.POST("/wallet/multisig/add", RequirePassword(api.walletMultisigAddHandlerPOST, requiredPassword))
+	router.POST("/wallet/multisig/build", RequirePassword(api.walletMultisigBuildHandlerPOST, requiredPassword))
+	router.POST("/wallet/multisig/combine", RequirePassword(api.walletMultisigCombineHandlerPOST, requiredPassword))
+	router.POST("/wallet/multisig/sign", RequirePassword(api.walletMultisigSignHandlerPOST, requiredPassword))
+	router.POST("/wallet/note", RequirePassword(api.walletNoteHandlerPOST, requiredPassword))
+	router.POST("/wallet/pst/broadcast", RequirePassword(api.walletPSTBroadcastHandlerPOST, requiredPassword))
+	router.POST("/wallet/pst/combine", RequirePassword(api.walletPSTCombineHandlerPOST, requiredPassword))
+	router.POST("/wallet/pst/create", RequirePassword(api.walletPSTCreateHandlerPOST, requiredPassword))
+	router.POST("/wallet/pst/sign", RequirePassword(api.walletPSTSignHandlerPOST, requiredPassword))
+	router.GET("/wallet/schedule", RequirePassword(api.walletScheduleHandlerGET, requiredPassword))
+	router.POST("/wallet/schedule", RequirePassword(api.walletScheduleHandlerPOST, requiredPassword))
+	router.POST("/wallet/schedule/cancel", RequirePassword(api.walletScheduleCancelHandlerPOST, requiredPassword))
+	router.POST("/wallet/seed", RequirePassword(api.walletSeedHandler, requiredPassword))
+	router.GET("/wallet/seeds", RequirePassword(api.walletSeedsHandler, requiredPassword))
+	router.POST("/wallet/siacoins", RequirePassword(api.walletSiacoinsHandler, requiredPassword))
+	router.POST("/wallet/siafunds", RequirePassword(api.walletSiafundsHandler, requiredPassword))
+	router.POST("/wallet/siagkey", RequirePassword(api.walletSiagkeyHandler, requiredPassword))
+	router.POST("/wallet/sweep/seed", RequirePassword(api.walletSweepSeedHandler, requiredPassword))
+	router.GET("/wallet/timelocked", RequirePassword(api.walletTimelockedHandlerGET, requiredPassword))
+	router.POST("/wallet/timelocked", RequirePassword(api.walletTimelockedHandlerPOST, requiredPassword))
+	router.POST("/wallet/timelocked/sweep", RequirePassword(api.walletTimelockedSweepHandlerPOST, requiredPassword))
+	router.GET("/wallet/transaction/:id", api.walletTransactionHandler)
+	router.GET("/wallet/transactions", api.walletTransactionsHandler)
+	router.GET("/wallet/transactions/:addr", api.walletTransactionsAddrHandler)
+	router.GET("/wallet/verify/address/:addr", api.walletVerifyAddressHandler)
+	router.POST("/wallet/unfreeze", RequirePassword(api.walletUnfreezeHandlerPOST, requiredPassword))
+	router.POST("/wallet/unlock", RequirePassword(api.walletUnlockHandler, requiredPassword))
+	router.POST("/wallet/changepassword", RequirePassword(api.walletChangePasswordHandler, requiredPassword))
+	router.GET("/wallet/unlockconditions/:addr", RequirePassword(api.walletUnlockConditionsHandlerGET, requiredPassword))
+	router.POST("/wallet/unlockconditions", RequirePassword(api.walletUnlockConditionsHandlerPOST, requiredPassword))
+	router.GET("/wallet/unspent", RequirePassword(api.walletUnspentHandler, requiredPassword))
+	router.POST("/wallet/sign", RequirePassword(api.walletSignHandler, requiredPassword))
+	router.GET("/wallet/watch", RequirePassword(api.walletWatchHandlerGET, requiredPassword))
+	router.POST("/wallet/watch", RequirePassword(api.walletWatchHandlerPOST, requiredPassword))
+	router.POST("/wallet/watchkeys", RequirePassword(api.walletWatchKeysHandlerPOST, requiredPassword))
+	router.GET("/wallet/webhooks", RequirePassword(api.walletWebhooksHandlerGET, requiredPassword))
+	router.POST("/wallet/webhooks", RequirePassword(api.walletWebhooksHandlerPOST, requiredPassword))
+	router.GET("/wallet/webhooks/deliveries/:id", RequirePassword(api.walletWebhookDeliveriesHandlerGET, requiredPassword))
+	router.POST("/wallet/webhooks/remove", RequirePassword(api.walletWebhooksRemoveHandlerPOST, requiredPassword))
+	router.GET("/wallets", api.walletsHandler)
+	router.POST("/wallets/create", RequirePassword(api.walletsCreateHandler, requiredPassword))
+	router.POST("/wallets/delete", RequirePassword(api.walletsDeleteHandler, requiredPassword))
+}
+
 // cleanCloseHandler wraps the entire API, ensuring that underlying conns are
 // not leaked if the remote end closes the connection before the underlying
 // handler finishes.
@@ -33,6 +33,9 @@ type (
 		SiafundBalance      types.Currency `json:"siafundbalance"`
 
 		DustThreshold types.Currency `json:"dustthreshold"`
+
+		WatchOnly          bool   `json:"watchonly"`
+		WatchKeysRemaining uint64 `json:"watchkeysremaining"`
 	}
 
 	// WalletAddressGET contains an address returned by a GET call to
@@ -153,7 +156,7 @@ type (
 // encryptionKeys enumerates the possible encryption keys that can be derived
 // from an input string.
 func encryptionKeys(seedStr string) (validKeys []crypto.TwofishKey) {
-	dicts := []mnemonics.DictionaryID{"english", "german", "japanese"}
+	dicts := []mnemonics.DictionaryID{"english", "german", "japanese", modules.BIP39}
 	for _, dict := range dicts {
 		seed, err := modules.StringToSeed(seedStr, dict)
 		if err != nil {
@@ -202,6 +205,11 @@ func (api *API) walletHandler(w http.ResponseWriter, req *http.Request, _ httpro
 		WriteError(w, Error{fmt.Sprintf("Error when calling /wallet: %v", err)}, http.StatusBadRequest)
 		return
 	}
+	watchOnly, watchKeysRemaining, err := api.wallet.WatchOnly()
+	if err != nil {
+		WriteError(w, Error{fmt.Sprintf("Error when calling /wallet: %v", err)}, http.StatusBadRequest)
+		return
+	}
 	WriteJSON(w, WalletGET{
 		Encrypted:  encrypted,
 		Unlocked:   unlocked,
@@ -216,6 +224,9 @@ func (api *API) walletHandler(w http.ResponseWriter, req *http.Request, _ httpro
 		SiacoinClaimBalance: siaclaimBal,
 
 		DustThreshold: dustThreshold,
+
+		WatchOnly:          watchOnly,
+		WatchKeysRemaining: watchKeysRemaining,
 	})
 }
 
@@ -457,6 +468,13 @@ func (api *API) walletSeedsHandler(w http.ResponseWriter, req *http.Request, _ h
 
 // walletSiacoinsHandler handles API calls to /wallet/siacoins.
 func (api *API) walletSiacoinsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	cc, err := scanCoinControl(req)
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/siacoins: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	useCoinControl := cc.Strategy != "" || len(cc.Pinned) > 0 || len(cc.Excluded) > 0
+
 	var txns []types.Transaction
 	if req.FormValue("outputs") != "" {
 		// multiple amounts + destinations
@@ -471,7 +489,11 @@ func (api *API) walletSiacoinsHandler(w http.ResponseWriter, req *http.Request,
 			WriteError(w, Error{"could not decode outputs: " + err.Error()}, http.StatusInternalServerError)
 			return
 		}
-		txns, err = api.wallet.SendSiacoinsMulti(outputs)
+		if useCoinControl {
+			txns, err = api.wallet.SendSiacoinsWithCoinControl(outputs, cc)
+		} else {
+			txns, err = api.wallet.SendSiacoinsMulti(outputs)
+		}
 		if err != nil {
 			WriteError(w, Error{"error when calling /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
 			return
@@ -489,7 +511,11 @@ func (api *API) walletSiacoinsHandler(w http.ResponseWriter, req *http.Request,
 			return
 		}
 
-		txns, err = api.wallet.SendSiacoins(amount, dest)
+		if useCoinControl {
+			txns, err = api.wallet.SendSiacoinsWithCoinControl([]types.SiacoinOutput{{Value: amount, UnlockHash: dest}}, cc)
+		} else {
+			txns, err = api.wallet.SendSiacoins(amount, dest)
+		}
 		if err != nil {
 			WriteError(w, Error{"error when calling /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
 			return
@@ -618,10 +644,36 @@ func (api *API) walletTransactionsHandler(w http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	WriteJSON(w, WalletTransactionsGET{
-		ConfirmedTransactions:   confirmedTxns,
-		UnconfirmedTransactions: unconfirmedTxns,
-	})
+	// Filter the transactions by category and label.
+	category, err := parseTransactionCategory(req.FormValue("category"))
+	if err != nil {
+		WriteError(w, Error{"unable to parse category: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	labels, err := api.wallet.AddressLabels()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/transactions: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	label := req.FormValue("label")
+	confirmedTxns = filterTransactions(confirmedTxns, category, label, labels)
+	unconfirmedTxns = filterTransactions(unconfirmedTxns, category, label, labels)
+
+	switch format := req.FormValue("format"); format {
+	case "", "json":
+		WriteJSON(w, WalletTransactionsGET{
+			ConfirmedTransactions:   confirmedTxns,
+			UnconfirmedTransactions: unconfirmedTxns,
+		})
+	case "csv":
+		// Only confirmed transactions are exported.
+		w.Header().Set("Content-Type", "text/csv")
+		if err := writeTransactionsCSV(w, confirmedTxns, labels); err != nil {
+			WriteError(w, Error{"unable to write csv: " + err.Error()}, http.StatusInternalServerError)
+		}
+	default:
+		WriteError(w, Error{"unknown format " + format + ", must be json or csv"}, http.StatusBadRequest)
+	}
 }
 
 // walletTransactionsAddrHandler handles API calls to
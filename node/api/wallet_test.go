@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -20,6 +21,8 @@ import (
 	"gitlab.com/NebulousLabs/Sia/types"
 	"gitlab.com/NebulousLabs/errors"
 	"gitlab.com/NebulousLabs/fastrand"
+
+	"github.com/julienschmidt/httprouter"
 )
 
 // TestWalletGETEncrypted probes the GET call to /wallet when the
@@ -315,6 +318,46 @@ func TestIntegrationWalletInitSeed(t *testing.T) {
 	if !unlocked {
 		t.Error("wallet is not unlocked")
 	}
+
+	// Reinitialize the wallet using a BIP-39 phrase.
+	fastrand.Read(seed[:])
+	bip39Str, err := modules.SeedToString(seed, modules.BIP39)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qs = url.Values{}
+	qs.Set("seed", bip39Str)
+	qs.Set("dictionary", string(modules.BIP39))
+	qs.Set("force", "true")
+	err = st.stdPostAPI("/wallet/init/seed", qs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := w.PrimarySeed(); err != modules.ErrLockedWallet {
+		t.Fatal("expected the reinitialized wallet to be locked, got", err)
+	}
+
+	// The BIP-39 phrase unlocks the wallet and is returned by /wallet/seeds.
+	unlockValues.Set("encryptionpassword", bip39Str)
+	err = st.stdPostAPI("/wallet/unlock", unlockValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	primarySeed, _, err := w.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if primarySeed != seed {
+		t.Fatal("wallet was initialized with the wrong seed")
+	}
+	var wsg WalletSeedsGET
+	err = st.getAPI("/wallet/seeds?dictionary=bip39", &wsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wsg.PrimarySeed != bip39Str {
+		t.Fatal("wrong BIP-39 phrase", wsg.PrimarySeed)
+	}
 }
 
 // TestWalletGETSiacoins probes the GET call to /wallet when the
@@ -1762,3 +1805,41 @@ func TestWalletTransactionsGetAddr(t *testing.T) {
 		t.Errorf("There should be exactly 0 unconfirmed and 1 confirmed related txns")
 	}
 }
+
+// routeRecorder is a routeRegistrar that records the paths of the registered
+// routes.
+type routeRecorder []string
+
+// GET records the path of a GET route.
+func (r *routeRecorder) GET(path string, _ httprouter.Handle) { *r = append(*r, path) }
+
+// POST records the path of a POST route.
+func (r *routeRecorder) POST(path string, _ httprouter.Handle) { *r = append(*r, path) }
+
+// TestReservedWalletNames checks that the first path segments of the routes
+// registered by the wallet router are exactly the reserved wallet names, so
+// that named wallets can't shadow wallet endpoints.
+func TestReservedWalletNames(t *testing.T) {
+	var routes routeRecorder
+	new(API).buildWalletRoutes(&routes, "")
+
+	segments := make(map[string]struct{})
+	for _, path := range routes {
+		if !strings.HasPrefix(path, namedWalletPrefix) {
+			continue
+		}
+		segment := strings.SplitN(strings.TrimPrefix(path, namedWalletPrefix), "/", 2)[0]
+		segments[segment] = struct{}{}
+		if _, reserved := wallet.ReservedWalletNames[segment]; !reserved {
+			t.Errorf("wallet endpoint %v is not reserved", path)
+		}
+	}
+	if len(segments) == 0 {
+		t.Fatal("no wallet routes found")
+	}
+	for name := range wallet.ReservedWalletNames {
+		if _, exists := segments[name]; !exists {
+			t.Errorf("reserved wallet name %v is not used by a wallet endpoint", name)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type (
+	// WalletBumpPOSTParams contains the parameters of a POST call to
+	// /wallet/bump. Fee is the new total fee of the transaction, its
+	// unconfirmed parents and the child transaction that pays for them. If
+	// Rebroadcast is set, the whole replacement set is broadcast to the
+	// node's peers instead of only the child transaction.
+	WalletBumpPOSTParams struct {
+		TransactionID types.TransactionID `json:"transactionid"`
+		Fee           types.Currency      `json:"fee"`
+		Rebroadcast   bool                `json:"rebroadcast"`
+	}
+
+	// WalletBumpPOST contains the IDs of the replacement set created by a POST
+	// call to /wallet/bump. The last ID is the ID of the child transaction.
+	WalletBumpPOST struct {
+		TransactionIDs []types.TransactionID `json:"transactionids"`
+	}
+)
+
+// walletBumpHandlerPOST handles POST calls to /wallet/bump.
+func (api *API) walletBumpHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletBumpPOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	txns, err := api.wallet.BumpFee(params.TransactionID, params.Fee)
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/bump: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	// The transaction pool only relays the child transaction. Peers that
+	// evicted the stuck transaction need the whole set to accept the child.
+	if params.Rebroadcast {
+		api.tpool.Broadcast(txns)
+	}
+	var txids []types.TransactionID
+	for _, txn := range txns {
+		txids = append(txids, txn.ID())
+	}
+	WriteJSON(w, WalletBumpPOST{
+		TransactionIDs: txids,
+	})
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type (
+	// WalletFrozenGET contains the frozen outputs of the wallet.
+	WalletFrozenGET struct {
+		OutputIDs []types.SiacoinOutputID `json:"outputids"`
+	}
+
+	// WalletFreezePOSTParams contains the outputs to freeze or unfreeze in a
+	// POST call to /wallet/freeze or /wallet/unfreeze.
+	WalletFreezePOSTParams struct {
+		OutputIDs []types.SiacoinOutputID `json:"outputids"`
+	}
+)
+
+// scanCoinControl parses the coin control parameters of a request. The pinned
+// and excluded outputs are JSON arrays of output IDs.
+func scanCoinControl(req *http.Request) (cc modules.CoinControl, err error) {
+	cc.Strategy = modules.CoinSelectionStrategy(req.FormValue("strategy"))
+	if pinned := req.FormValue("pinned"); pinned != "" {
+		if err := json.Unmarshal([]byte(pinned), &cc.Pinned); err != nil {
+			return modules.CoinControl{}, errors.New("could not decode pinned outputs: " + err.Error())
+		}
+	}
+	if excluded := req.FormValue("excluded"); excluded != "" {
+		if err := json.Unmarshal([]byte(excluded), &cc.Excluded); err != nil {
+			return modules.CoinControl{}, errors.New("could not decode excluded outputs: " + err.Error())
+		}
+	}
+	return cc, nil
+}
+
+// walletFrozenHandlerGET handles GET calls to /wallet/frozen.
+func (api *API) walletFrozenHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	ids, err := api.wallet.FrozenOutputs()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/frozen: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletFrozenGET{
+		OutputIDs: ids,
+	})
+}
+
+// walletFreezeHandlerPOST handles POST calls to /wallet/freeze.
+func (api *API) walletFreezeHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletFreezePOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.wallet.FreezeOutputs(params.OutputIDs); err != nil {
+		WriteError(w, Error{"error when calling /wallet/freeze: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletUnfreezeHandlerPOST handles POST calls to /wallet/unfreeze.
+func (api *API) walletUnfreezeHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletFreezePOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.wallet.UnfreezeOutputs(params.OutputIDs); err != nil {
+		WriteError(w, Error{"error when calling /wallet/unfreeze: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type (
+	// WalletAddressLabel is an address along with its label.
+	WalletAddressLabel struct {
+		Address types.UnlockHash `json:"address"`
+		Label   string           `json:"label"`
+	}
+
+	// WalletLabelsGET contains the labeled addresses, ordered by label.
+	WalletLabelsGET struct {
+		Labels []WalletAddressLabel `json:"labels"`
+	}
+
+	// WalletNotePOSTParams contains the note to attach to a transaction in a
+	// POST call to /wallet/note.
+	WalletNotePOSTParams struct {
+		TransactionID types.TransactionID `json:"transactionid"`
+		Note          string              `json:"note"`
+	}
+)
+
+// walletLabelsHandlerGET handles GET calls to /wallet/labels.
+func (api *API) walletLabelsHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	labels, err := api.wallet.AddressLabels()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/labels: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	wlg := WalletLabelsGET{
+		Labels: make([]WalletAddressLabel, 0, len(labels)),
+	}
+	for addr, label := range labels {
+		wlg.Labels = append(wlg.Labels, WalletAddressLabel{
+			Address: addr,
+			Label:   label,
+		})
+	}
+	sort.Slice(wlg.Labels, func(i, j int) bool {
+		if wlg.Labels[i].Label != wlg.Labels[j].Label {
+			return wlg.Labels[i].Label < wlg.Labels[j].Label
+		}
+		return wlg.Labels[i].Address.String() < wlg.Labels[j].Address.String()
+	})
+	WriteJSON(w, wlg)
+}
+
+// walletLabelsHandlerPOST handles POST calls to /wallet/labels.
+func (api *API) walletLabelsHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletAddressLabel
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.wallet.SetAddressLabel(params.Address, params.Label); err != nil {
+		WriteError(w, Error{"error when calling /wallet/labels: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletNoteHandlerPOST handles POST calls to /wallet/note.
+func (api *API) walletNoteHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletNotePOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.wallet.SetTransactionNote(params.TransactionID, params.Note); err != nil {
+		WriteError(w, Error{"error when calling /wallet/note: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// parseTransactionCategory parses the category filter of a call to
+// /wallet/transactions. An empty string matches all categories.
+func parseTransactionCategory(s string) (modules.TransactionCategory, error) {
+	if s == "" {
+		return "", nil
+	}
+	for _, c := range modules.TransactionCategories {
+		if modules.TransactionCategory(s) == c {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("unknown category %v", s)
+}
+
+// filterTransactions returns the transactions of the provided category that
+// are related to an address with the provided label. An empty category or
+// label matches all transactions.
+func filterTransactions(pts []modules.ProcessedTransaction, category modules.TransactionCategory, label string, labels map[types.UnlockHash]string) []modules.ProcessedTransaction {
+	if category == "" && label == "" {
+		return pts
+	}
+	var filtered []modules.ProcessedTransaction
+	for _, pt := range pts {
+		if category != "" && pt.Category != category {
+			continue
+		}
+		if label != "" && !hasLabel(pt, label, labels) {
+			continue
+		}
+		filtered = append(filtered, pt)
+	}
+	return filtered
+}
+
+// hasLabel returns whether a transaction is related to an address with the
+// provided label.
+func hasLabel(pt modules.ProcessedTransaction, label string, labels map[types.UnlockHash]string) bool {
+	for _, input := range pt.Inputs {
+		if labels[input.RelatedAddress] == label {
+			return true
+		}
+	}
+	for _, output := range pt.Outputs {
+		if labels[output.RelatedAddress] == label {
+			return true
+		}
+	}
+	return false
+}
+
+// transactionLabels returns the distinct labels of the addresses that a
+// transaction is related to.
+func transactionLabels(pt modules.ProcessedTransaction, labels map[types.UnlockHash]string) []string {
+	seen := make(map[string]struct{})
+	var ls []string
+	add := func(addr types.UnlockHash) {
+		label, ok := labels[addr]
+		if !ok {
+			return
+		}
+		if _, dup := seen[label]; !dup {
+			seen[label] = struct{}{}
+			ls = append(ls, label)
+		}
+	}
+	for _, input := range pt.Inputs {
+		add(input.RelatedAddress)
+	}
+	for _, output := range pt.Outputs {
+		add(output.RelatedAddress)
+	}
+	sort.Strings(ls)
+	return ls
+}
+
+// writeTransactionsCSV writes the transactions to w as CSV, one row per
+// transaction. The incoming and outgoing columns contain the siacoins that the
+// transaction pays to and spends from the addresses of the wallet.
+func writeTransactionsCSV(w io.Writer, pts []modules.ProcessedTransaction, labels map[types.UnlockHash]string) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"transactionid", "confirmationheight", "confirmationtimestamp", "category",
+		"incoming", "outgoing", "fee", "labels", "note"})
+	if err != nil {
+		return err
+	}
+	for _, pt := range pts {
+		var incoming, outgoing, fee types.Currency
+		for _, input := range pt.Inputs {
+			if input.WalletAddress && input.FundType == types.SpecifierSiacoinInput {
+				outgoing = outgoing.Add(input.Value)
+			}
+		}
+		for _, output := range pt.Outputs {
+			switch {
+			case output.FundType == types.SpecifierMinerFee:
+				fee = fee.Add(output.Value)
+			case output.WalletAddress && output.FundType != types.SpecifierSiafundOutput:
+				incoming = incoming.Add(output.Value)
+			}
+		}
+		err := cw.Write([]string{pt.TransactionID.String(), fmt.Sprint(pt.ConfirmationHeight),
+			fmt.Sprint(pt.ConfirmationTimestamp), string(pt.Category), incoming.String(), outgoing.String(),
+			fee.String(), strings.Join(transactionLabels(pt, labels), ";"), pt.Note})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/modules/wallet"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type (
+	// WalletMultisigGET contains the multisig addresses registered with the
+	// wallet.
+	WalletMultisigGET struct {
+		Addresses []modules.MultisigAddress `json:"addresses"`
+	}
+
+	// WalletMultisigAddPOSTParams contains the public keys and the number of
+	// required signatures of a multisig address.
+	WalletMultisigAddPOSTParams struct {
+		PublicKeys         []types.SiaPublicKey `json:"publickeys"`
+		SignaturesRequired uint64               `json:"signaturesrequired"`
+		Unused             bool                 `json:"unused"`
+	}
+
+	// WalletMultisigAddPOST contains the address registered by a POST call to
+	// /wallet/multisig/add.
+	WalletMultisigAddPOST struct {
+		Address types.UnlockHash `json:"address"`
+	}
+
+	// WalletMultisigBuildPOSTParams contains the multisig address to spend
+	// from and the outputs to send the siacoins to.
+	WalletMultisigBuildPOSTParams struct {
+		Address types.UnlockHash      `json:"address"`
+		Outputs []types.SiacoinOutput `json:"outputs"`
+	}
+
+	// WalletMultisigCombinePOSTParams contains the copies of a transaction
+	// signed by different co-signers.
+	WalletMultisigCombinePOSTParams struct {
+		Transactions []types.Transaction `json:"transactions"`
+	}
+
+	// WalletMultisigTransaction contains a multisig transaction. It is the
+	// response of the POST calls to /wallet/multisig/build, sign and combine
+	// and the parameters of /wallet/multisig/sign.
+	WalletMultisigTransaction struct {
+		Transaction types.Transaction `json:"transaction"`
+	}
+)
+
+// walletMultisigHandlerGET handles GET calls to /wallet/multisig.
+func (api *API) walletMultisigHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	addrs, err := api.wallet.MultisigAddresses()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/multisig: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletMultisigGET{
+		Addresses: addrs,
+	})
+}
+
+// walletMultisigAddHandlerPOST handles POST calls to /wallet/multisig/add.
+func (api *API) walletMultisigAddHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletMultisigAddPOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	addr, err := api.wallet.AddMultisigAddress(types.UnlockConditions{
+		PublicKeys:         params.PublicKeys,
+		SignaturesRequired: params.SignaturesRequired,
+	}, params.Unused)
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/multisig/add: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletMultisigAddPOST{
+		Address: addr,
+	})
+}
+
+// walletMultisigBuildHandlerPOST handles POST calls to /wallet/multisig/build.
+func (api *API) walletMultisigBuildHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletMultisigBuildPOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	txn, err := api.wallet.BuildMultisigTransaction(params.Address, params.Outputs)
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/multisig/build: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletMultisigTransaction{
+		Transaction: txn,
+	})
+}
+
+// walletMultisigSignHandlerPOST handles POST calls to /wallet/multisig/sign.
+func (api *API) walletMultisigSignHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletMultisigTransaction
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.wallet.SignTransaction(&params.Transaction, nil); err != nil {
+		WriteError(w, Error{"failed to sign transaction: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, params)
+}
+
+// walletMultisigCombineHandlerPOST handles POST calls to
+// /wallet/multisig/combine.
+func (api *API) walletMultisigCombineHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletMultisigCombinePOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	txn, err := wallet.CombineSignatures(params.Transactions)
+	if err != nil {
+		WriteError(w, Error{"failed to combine signatures: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletMultisigTransaction{
+		Transaction: txn,
+	})
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/modules/wallet"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type (
+	// WalletPSTCreatePOSTParams contains the parameters of a POST call to
+	// /wallet/pst/create. If outputs are provided, the wallet funds a new
+	// transaction that sends siacoins to them. Otherwise the transaction and
+	// its parents are wrapped as they are.
+	WalletPSTCreatePOSTParams struct {
+		Outputs     []types.SiacoinOutput `json:"outputs"`
+		Transaction types.Transaction     `json:"transaction"`
+		Parents     []types.Transaction   `json:"parents"`
+	}
+
+	// WalletPSTCombinePOSTParams contains the copies of a partially signed
+	// transaction that were signed by different signers.
+	WalletPSTCombinePOSTParams struct {
+		PSTs []modules.PartiallySignedTransaction `json:"psts"`
+	}
+
+	// WalletPST contains a partially signed transaction. It is the response
+	// of the POST calls to /wallet/pst/create, sign and combine and the
+	// parameters of /wallet/pst/sign and broadcast.
+	WalletPST struct {
+		PST modules.PartiallySignedTransaction `json:"pst"`
+	}
+
+	// WalletPSTBroadcastPOST contains the ID of the transaction broadcast by a
+	// POST call to /wallet/pst/broadcast.
+	WalletPSTBroadcastPOST struct {
+		TransactionID types.TransactionID `json:"transactionid"`
+	}
+)
+
+// walletPSTCreateHandlerPOST handles POST calls to /wallet/pst/create.
+func (api *API) walletPSTCreateHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletPSTCreatePOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var pst modules.PartiallySignedTransaction
+	var err error
+	if len(params.Outputs) > 0 {
+		pst, err = api.wallet.FundPST(params.Outputs)
+	} else {
+		pst, err = api.wallet.CreatePST(params.Transaction, params.Parents)
+	}
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/pst/create: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletPST{
+		PST: pst,
+	})
+}
+
+// walletPSTSignHandlerPOST handles POST calls to /wallet/pst/sign.
+func (api *API) walletPSTSignHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletPST
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.wallet.SignPST(&params.PST); err != nil {
+		WriteError(w, Error{"failed to sign partially signed transaction: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, params)
+}
+
+// walletPSTCombineHandlerPOST handles POST calls to /wallet/pst/combine.
+func (api *API) walletPSTCombineHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletPSTCombinePOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	pst, err := wallet.CombinePSTs(params.PSTs)
+	if err != nil {
+		WriteError(w, Error{"failed to combine partially signed transactions: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletPST{
+		PST: pst,
+	})
+}
+
+// walletPSTBroadcastHandlerPOST handles POST calls to /wallet/pst/broadcast.
+func (api *API) walletPSTBroadcastHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletPST
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	pst := params.PST
+	if err := wallet.UpdatePSTStatus(&pst); err != nil {
+		WriteError(w, Error{"invalid partially signed transaction: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	for _, in := range pst.Inputs {
+		if !in.Signed {
+			WriteError(w, Error{"partially signed transaction is missing signatures for input " + in.ParentID.String()}, http.StatusBadRequest)
+			return
+		}
+	}
+	txnSet := append(pst.Parents, pst.Transaction)
+	api.tpool.Broadcast(txnSet)
+	err := api.tpool.AcceptTransactionSet(txnSet)
+	if err != nil && err != modules.ErrDuplicateTransactionSet {
+		WriteError(w, Error{"error accepting transaction set: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletPSTBroadcastPOST{
+		TransactionID: pst.Transaction.ID(),
+	})
+}
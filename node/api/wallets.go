@@ -0,0 +1,132 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	// namedWalletHeader is the header that is used to select the named
+	// wallet that should serve a request.
+	namedWalletHeader = "Sia-Wallet"
+
+	// namedWalletPrefix is the path prefix that is used to select the named
+	// wallet that should serve a request. A request to /wallet/<name>/address
+	// is served as /wallet/address by the named wallet <name>.
+	namedWalletPrefix = "/wallet/"
+)
+
+var (
+	// errUnknownNamedWallet is returned when a request selects a named wallet
+	// that doesn't exist.
+	errUnknownNamedWallet = errors.New("unknown wallet")
+)
+
+// NamedWallets lists the names of the named wallets.
+type NamedWallets struct {
+	Wallets []string `json:"wallets"`
+}
+
+// namedWalletName returns the name of the named wallet that was selected by a
+// request. If the wallet was selected using the path prefix, the path of the
+// request is rewritten to the path of the regular wallet endpoint. The header
+// is removed from the request so that the request isn't dispatched twice.
+func (api *API) namedWalletName(req *http.Request) (string, bool) {
+	if api.wallet == nil {
+		return "", false
+	}
+	if name := req.Header.Get(namedWalletHeader); name != "" {
+		req.Header.Del(namedWalletHeader)
+		return name, true
+	}
+	if strings.HasPrefix(req.URL.Path, namedWalletPrefix) {
+		rest := strings.TrimPrefix(req.URL.Path, namedWalletPrefix)
+		i := strings.Index(rest, "/")
+		if i <= 0 {
+			return "", false
+		}
+		if _, exists := api.wallet.NamedWallet(rest[:i]); !exists {
+			return "", false
+		}
+		req.URL.Path = "/wallet" + rest[i:]
+		req.URL.RawPath = ""
+		return rest[:i], true
+	}
+	return "", false
+}
+
+// managedWalletAPI returns the API that serves the requests for the named
+// wallet with the given name.
+func (api *API) managedWalletAPI(name string) (*API, error) {
+	wallet, exists := api.wallet.NamedWallet(name)
+	if !exists {
+		return nil, errUnknownNamedWallet
+	}
+
+	api.walletMu.Lock()
+	defer api.walletMu.Unlock()
+	walletAPI, exists := api.walletAPIs[name]
+	if !exists || walletAPI.wallet != wallet {
+		walletAPI = &API{
+			cs:       api.cs,
+			explorer: api.explorer,
+			gateway:  api.gateway,
+			host:     api.host,
+			miner:    api.miner,
+			renter:   api.renter,
+			tpool:    api.tpool,
+			wallet:   wallet,
+
+			profileAPIs:       make(map[string]*API),
+			requiredPassword:  api.requiredPassword,
+			requiredUserAgent: api.requiredUserAgent,
+		}
+		walletAPI.buildHTTPRoutes(api.requiredUserAgent, api.requiredPassword)
+		api.walletAPIs[name] = walletAPI
+	}
+	return walletAPI, nil
+}
+
+// walletsHandler handles the API call to list the named wallets.
+func (api *API) walletsHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	wallets := api.wallet.NamedWallets()
+	if wallets == nil {
+		wallets = []string{}
+	}
+	WriteJSON(w, NamedWallets{Wallets: wallets})
+}
+
+// walletsCreateHandler handles the API call to create a named wallet.
+func (api *API) walletsCreateHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	name := req.FormValue("name")
+	if name == "" {
+		WriteError(w, Error{"name must be specified"}, http.StatusBadRequest)
+		return
+	}
+	if _, err := api.wallet.CreateNamedWallet(name); err != nil {
+		WriteError(w, Error{"unable to create wallet: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletsDeleteHandler handles the API call to delete a named wallet.
+func (api *API) walletsDeleteHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	name := req.FormValue("name")
+	if name == "" {
+		WriteError(w, Error{"name must be specified"}, http.StatusBadRequest)
+		return
+	}
+	if err := api.wallet.DeleteNamedWallet(name); err != nil {
+		WriteError(w, Error{"unable to delete wallet: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	api.walletMu.Lock()
+	delete(api.walletAPIs, name)
+	api.walletMu.Unlock()
+	WriteSuccess(w)
+}
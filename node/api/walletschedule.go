@@ -0,0 +1,181 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type (
+	// WalletTimelockedAddress is a timelocked address of the wallet. Its
+	// outputs can't be spent before UnlockConditions.Timelock.
+	WalletTimelockedAddress struct {
+		Address          types.UnlockHash       `json:"address"`
+		UnlockConditions types.UnlockConditions `json:"unlockconditions"`
+	}
+
+	// WalletTimelockedGET contains the timelocked addresses of the wallet.
+	WalletTimelockedGET struct {
+		Addresses []WalletTimelockedAddress `json:"addresses"`
+	}
+
+	// WalletTimelockedPOSTParams contains the unlock height of a new
+	// timelocked address.
+	WalletTimelockedPOSTParams struct {
+		UnlockHeight types.BlockHeight `json:"unlockheight"`
+	}
+
+	// WalletScheduleGET contains the scheduled payments of the wallet.
+	WalletScheduleGET struct {
+		Payments []modules.ScheduledPayment `json:"payments"`
+	}
+
+	// WalletSchedulePOSTParams contains the parameters of a POST call to
+	// /wallet/schedule. If DryRun is set, the payment is not scheduled;
+	// instead the wallet checks that it could fund the payment now.
+	WalletSchedulePOSTParams struct {
+		Outputs   []types.SiacoinOutput `json:"outputs"`
+		Height    types.BlockHeight     `json:"height"`
+		Interval  types.BlockHeight     `json:"interval"`
+		Remaining uint64                `json:"remaining"`
+		DryRun    bool                  `json:"dryrun"`
+	}
+
+	// WalletSchedulePOST contains the payment scheduled by a POST call to
+	// /wallet/schedule. Fee is the miner fee that the payment would pay
+	// now; it is only set for a dry run.
+	WalletSchedulePOST struct {
+		Payment modules.ScheduledPayment `json:"payment"`
+		Fee     types.Currency           `json:"fee"`
+	}
+
+	// WalletScheduleCancelPOSTParams contains the ID of the payment to cancel
+	// in a POST call to /wallet/schedule/cancel.
+	WalletScheduleCancelPOSTParams struct {
+		ID crypto.Hash `json:"id"`
+	}
+)
+
+// walletTimelockedHandlerGET handles GET calls to /wallet/timelocked.
+func (api *API) walletTimelockedHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	ucs, err := api.wallet.TimelockedAddresses()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/timelocked: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	addrs := make([]WalletTimelockedAddress, 0, len(ucs))
+	for _, uc := range ucs {
+		addrs = append(addrs, WalletTimelockedAddress{
+			Address:          uc.UnlockHash(),
+			UnlockConditions: uc,
+		})
+	}
+	WriteJSON(w, WalletTimelockedGET{
+		Addresses: addrs,
+	})
+}
+
+// walletTimelockedHandlerPOST handles POST calls to /wallet/timelocked.
+func (api *API) walletTimelockedHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletTimelockedPOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	uc, err := api.wallet.NewTimelockedAddress(params.UnlockHeight)
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/timelocked: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletTimelockedAddress{
+		Address:          uc.UnlockHash(),
+		UnlockConditions: uc,
+	})
+}
+
+// walletTimelockedSweepHandlerPOST handles POST calls to
+// /wallet/timelocked/sweep.
+func (api *API) walletTimelockedSweepHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	txns, err := api.wallet.SweepTimelocked()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/timelocked/sweep: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	var txids []types.TransactionID
+	for _, txn := range txns {
+		txids = append(txids, txn.ID())
+	}
+	WriteJSON(w, WalletSiacoinsPOST{
+		TransactionIDs: txids,
+	})
+}
+
+// walletScheduleHandlerGET handles GET calls to /wallet/schedule.
+func (api *API) walletScheduleHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	sps, err := api.wallet.ScheduledPayments()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/schedule: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if sps == nil {
+		sps = []modules.ScheduledPayment{}
+	}
+	WriteJSON(w, WalletScheduleGET{
+		Payments: sps,
+	})
+}
+
+// walletScheduleHandlerPOST handles POST calls to /wallet/schedule.
+func (api *API) walletScheduleHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletSchedulePOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	sp := modules.ScheduledPayment{
+		Outputs:   params.Outputs,
+		Height:    params.Height,
+		Interval:  params.Interval,
+		Remaining: params.Remaining,
+	}
+	if params.DryRun {
+		fee, err := api.wallet.DryRunPayment(sp.Outputs)
+		if err != nil {
+			WriteError(w, Error{"dry run failed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		WriteJSON(w, WalletSchedulePOST{
+			Payment: sp,
+			Fee:     fee,
+		})
+		return
+	}
+	sp, err := api.wallet.SchedulePayment(sp)
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/schedule: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletSchedulePOST{
+		Payment: sp,
+	})
+}
+
+// walletScheduleCancelHandlerPOST handles POST calls to
+// /wallet/schedule/cancel.
+func (api *API) walletScheduleCancelHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletScheduleCancelPOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.wallet.CancelScheduledPayment(params.ID); err != nil {
+		WriteError(w, Error{"error when calling /wallet/schedule/cancel: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
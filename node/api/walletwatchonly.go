@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type (
+	// WalletInitWatchOnlyPOSTParams contains the parameters of a POST call to
+	// /wallet/init/watchonly. The watch keys must have been exported from the
+	// seed starting at index 0.
+	WalletInitWatchOnlyPOSTParams struct {
+		EncryptionPassword string            `json:"encryptionpassword"`
+		WatchKeys          modules.WatchKeys `json:"watchkeys"`
+		Force              bool              `json:"force"`
+	}
+
+	// WalletWatchKeysPOSTParams contains the parameters of a POST call to
+	// /wallet/watchkeys.
+	WalletWatchKeysPOSTParams struct {
+		WatchKeys modules.WatchKeys `json:"watchkeys"`
+		Unused    bool              `json:"unused"`
+	}
+)
+
+// walletInitWatchOnlyHandlerPOST handles POST calls to /wallet/init/watchonly.
+func (api *API) walletInitWatchOnlyHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletInitWatchOnlyPOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if params.EncryptionPassword == "" {
+		WriteError(w, Error{"error when calling /wallet/init/watchonly: an encryption password is required"}, http.StatusBadRequest)
+		return
+	}
+	encryptionKey := crypto.TwofishKey(crypto.HashObject(params.EncryptionPassword))
+
+	if params.Force {
+		if err := api.wallet.Reset(); err != nil {
+			WriteError(w, Error{"error when calling /wallet/init/watchonly: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if err := api.wallet.InitWatchOnly(encryptionKey, params.WatchKeys); err != nil {
+		WriteError(w, Error{"error when calling /wallet/init/watchonly: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletWatchKeysHandlerPOST handles POST calls to /wallet/watchkeys.
+func (api *API) walletWatchKeysHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletWatchKeysPOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.wallet.AddWatchKeys(params.WatchKeys, params.Unused); err != nil {
+		WriteError(w, Error{"error when calling /wallet/watchkeys: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type (
+	// WalletWebhooksGET contains the registered webhooks.
+	WalletWebhooksGET struct {
+		Webhooks []modules.Webhook `json:"webhooks"`
+	}
+
+	// WalletWebhooksPOSTParams contains the parameters of a POST call to
+	// /wallet/webhooks. Empty Events or Addresses select all event types or
+	// all addresses of the wallet; an empty Secret is generated by the
+	// wallet.
+	WalletWebhooksPOSTParams struct {
+		URL           string                     `json:"url"`
+		Events        []modules.WebhookEventType `json:"events"`
+		Addresses     []types.UnlockHash         `json:"addresses"`
+		Confirmations types.BlockHeight          `json:"confirmations"`
+		Secret        string                     `json:"secret"`
+	}
+
+	// WalletWebhooksRemovePOSTParams contains the ID of the webhook to remove
+	// in a POST call to /wallet/webhooks/remove.
+	WalletWebhooksRemovePOSTParams struct {
+		ID crypto.Hash `json:"id"`
+	}
+
+	// WalletWebhookDeliveriesGET contains the delivery log of a webhook.
+	WalletWebhookDeliveriesGET struct {
+		Deliveries []modules.WebhookDelivery `json:"deliveries"`
+	}
+)
+
+// walletWebhooksHandlerGET handles GET calls to /wallet/webhooks.
+func (api *API) walletWebhooksHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	whs, err := api.wallet.Webhooks()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/webhooks: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if whs == nil {
+		whs = []modules.Webhook{}
+	}
+	WriteJSON(w, WalletWebhooksGET{
+		Webhooks: whs,
+	})
+}
+
+// walletWebhooksHandlerPOST handles POST calls to /wallet/webhooks.
+func (api *API) walletWebhooksHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletWebhooksPOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	wh, err := api.wallet.AddWebhook(modules.Webhook{
+		URL:           params.URL,
+		Events:        params.Events,
+		Addresses:     params.Addresses,
+		Confirmations: params.Confirmations,
+		Secret:        params.Secret,
+	})
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/webhooks: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, wh)
+}
+
+// walletWebhooksRemoveHandlerPOST handles POST calls to
+// /wallet/webhooks/remove.
+func (api *API) walletWebhooksRemoveHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletWebhooksRemovePOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.wallet.RemoveWebhook(params.ID); err != nil {
+		WriteError(w, Error{"error when calling /wallet/webhooks/remove: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletWebhookDeliveriesHandlerGET handles GET calls to
+// /wallet/webhooks/deliveries/:id.
+func (api *API) walletWebhookDeliveriesHandlerGET(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	var id crypto.Hash
+	if err := id.LoadString(ps.ByName("id")); err != nil {
+		WriteError(w, Error{"unable to parse webhook id: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	wds, err := api.wallet.WebhookDeliveries(id)
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/webhooks/deliveries: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if wds == nil {
+		wds = []modules.WebhookDelivery{}
+	}
+	WriteJSON(w, WalletWebhookDeliveriesGET{
+		Deliveries: wds,
+	})
+}
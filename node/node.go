@@ -85,6 +85,12 @@ type NodeParams struct {
 	// Custom settings for modules
 	Allowance modules.Allowance
 
+	// HostWallet and RenterWallet are the names of the named wallets that are
+	// used by the host and the renter. The named wallets are created if they
+	// don't exist. If empty, the primary wallet is used.
+	HostWallet   string
+	RenterWallet string
+
 	// The following fields are used to skip parts of the node set up
 	SkipSetAllowance     bool
 	SkipHostDiscovery    bool
@@ -245,7 +251,11 @@ func New(params NodeParams) (*Node, error) {
 		if !params.CreateHost {
 			return nil, nil
 		}
-		return host.New(cs, g, tp, w, "localhost:0", filepath.Join(dir, modules.HostDir))
+		hw, err := wallet.NamedWallet(w, params.HostWallet)
+		if err != nil {
+			return nil, err
+		}
+		return host.New(cs, g, tp, hw, "localhost:0", filepath.Join(dir, modules.HostDir))
 	}()
 	if err != nil {
 		return nil, errors.Extend(err, errors.New("unable to create host"))
@@ -278,6 +288,10 @@ func New(params NodeParams) (*Node, error) {
 		if renterDeps == nil {
 			renterDeps = modules.ProdDependencies
 		}
+		rw, err := wallet.NamedWallet(w, params.RenterWallet)
+		if err != nil {
+			return nil, err
+		}
 		persistDir := filepath.Join(dir, modules.RenterDir)
 
 		// HostDB
@@ -295,11 +309,11 @@ func New(params NodeParams) (*Node, error) {
 		if err != nil {
 			return nil, err
 		}
-		hc, err := contractor.NewCustomContractor(cs, &contractor.WalletBridge{W: w}, tp, hdb, contractSet, contractor.NewPersist(persistDir), logger, contractorDeps)
+		hc, err := contractor.NewCustomContractor(cs, &contractor.WalletBridge{W: rw}, tp, hdb, contractSet, contractor.NewPersist(persistDir), logger, contractorDeps)
 		if err != nil {
 			return nil, err
 		}
-		return renter.NewCustomRenter(g, cs, tp, hdb, hc, persistDir, renterDeps)
+		return renter.NewCustomRenter(g, cs, tp, hdb, rw, hc, persistDir, renterDeps)
 	}()
 	if err != nil {
 		return nil, errors.Extend(err, errors.New("unable to create renter"))
@@ -0,0 +1,198 @@
+package host
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/node/api/client"
+	"gitlab.com/NebulousLabs/Sia/siatest"
+)
+
+// TestHostPricingEngine checks that the pricing engine of a host learns the
+// prices of the other hosts on the network and applies them.
+func TestHostPricingEngine(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	groupParams := siatest.GroupParams{
+		Hosts:  3,
+		Miners: 1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(hostTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Give the other hosts different storage prices.
+	hosts := tg.Hosts()
+	hg, err := hosts[0].HostGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	basePrice := hg.InternalSettings.MinStoragePrice
+	for i, host := range hosts[1:] {
+		price := basePrice.Mul64(uint64(i + 2))
+		if err := host.HostModifySettingPost(client.HostParamMinStoragePrice, price); err != nil {
+			t.Fatal(err)
+		}
+	}
+	maxPrice := basePrice.Mul64(uint64(len(hosts)))
+
+	// Target the most expensive host.
+	hpg, err := hosts[0].HostPricingGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := hpg.Policy
+	if policy.Enabled {
+		t.Fatal("pricing engine should be disabled by default")
+	}
+	policy.Percentile = 100
+	if err := hosts[0].HostPricingPost(policy); err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait for the host to scan the other hosts. Mine a block first to make
+	// sure that all announcements are confirmed.
+	if err := tg.Miners()[0].MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 500*time.Millisecond, func() error {
+		est, err := hosts[0].HostPricingEstimateGet()
+		if err != nil {
+			return err
+		}
+		if est.MarketHosts != uint64(len(hosts)-1) {
+			return fmt.Errorf("expected %v market hosts, got %v", len(hosts)-1, est.MarketHosts)
+		}
+		if !est.Proposed.MinStoragePrice.Equals(maxPrice) {
+			return fmt.Errorf("expected storage price %v, got %v", maxPrice, est.Proposed.MinStoragePrice)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The dry run shouldn't have changed the prices.
+	hg, err = hosts[0].HostGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hg.InternalSettings.MinStoragePrice.Equals(basePrice) {
+		t.Fatal("dry run changed the storage price")
+	}
+
+	// Enable the pricing engine with a fill rule that always applies.
+	policy.Enabled = true
+	policy.FillRules = []modules.HostPricingFillRule{{Utilization: 0, Multiplier: 2}}
+	if err := hosts[0].HostPricingPost(policy); err != nil {
+		t.Fatal(err)
+	}
+	hpg, err = hosts[0].HostPricingGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hpg.Policy.Enabled || len(hpg.Policy.FillRules) != 1 || hpg.Policy.FillRules[0].Multiplier != 2 {
+		t.Fatalf("policy wasn't updated: %+v", hpg.Policy)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		hg, err := hosts[0].HostGet()
+		if err != nil {
+			return err
+		}
+		if !hg.InternalSettings.MinStoragePrice.Equals(maxPrice.Mul64(2)) {
+			return errors.New("storage price wasn't updated")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hphg, err := hosts[0].HostPricingHistoryGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hphg.History) != 1 {
+		t.Fatal("expected one price change, got", len(hphg.History))
+	}
+	change := hphg.History[0]
+	if !change.OldPrices.MinStoragePrice.Equals(basePrice) || !change.NewPrices.MinStoragePrice.Equals(maxPrice.Mul64(2)) {
+		t.Fatalf("unexpected price change: %+v", change)
+	}
+
+	// Invalid policies should be rejected.
+	policy.Percentile = 101
+	if err := hosts[0].HostPricingPost(policy); err == nil {
+		t.Fatal("expected an invalid percentile to be rejected")
+	}
+}
+
+// TestHostFinancialReport checks that the host reports its finances by time
+// range through the API.
+func TestHostFinancialReport(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	groupParams := siatest.GroupParams{
+		Hosts:  1,
+		Miners: 1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(hostTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	host := tg.Hosts()[0]
+
+	// Request a report of the last week.
+	to := time.Now()
+	from := to.AddDate(0, 0, -7)
+	hmg, err := host.HostMetricsGet(from, to, "day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hmg.Periods) != 7 {
+		t.Fatal("expected 7 periods, got", len(hmg.Periods))
+	}
+	if hmg.Periods[0].Start.Unix() != from.Unix() || hmg.Periods[6].End.Unix() != to.Unix() {
+		t.Fatal("periods don't cover the requested range")
+	}
+
+	// The same report should be available as CSV, with a header and a row
+	// per period.
+	csv, err := host.HostMetricsCSVGet(from, to, "day", "periods")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(csv)), "\n")
+	if len(lines) != 8 || !strings.HasPrefix(lines[0], "start,end,") {
+		t.Fatalf("unexpected csv: %s", csv)
+	}
+	csv, err = host.HostMetricsCSVGet(from, to, "day", "obligations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(csv), "obligationid,") {
+		t.Fatalf("unexpected csv: %s", csv)
+	}
+
+	// Invalid intervals should be rejected.
+	if _, err := host.HostMetricsGet(from, to, "year"); err == nil {
+		t.Fatal("expected an invalid interval to be rejected")
+	}
+}
@@ -1,8 +1,10 @@
 package renter
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"math/big"
 	"os"
@@ -10,6 +12,7 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -87,6 +90,8 @@ func TestRenter(t *testing.T) {
 		{"TestDownloadAfterRenew", testDownloadAfterRenew},
 		{"TestDownloadMultipleLargeSectors", testDownloadMultipleLargeSectors},
 		{"TestLocalRepair", testLocalRepair},
+		{"TestContractHistory", testContractHistory},
+		{"TestDetailedPriceEstimation", testDetailedPriceEstimation},
 	}
 
 	// Run tests
@@ -337,6 +342,110 @@ func testClearDownloadHistory(t *testing.T, tg *siatest.TestGroup) {
 	}
 }
 
+// testDetailedPriceEstimation checks that a detailed price estimation
+// selects a host for every piece and prices each of them.
+func testDetailedPriceEstimation(t *testing.T, tg *siatest.TestGroup) {
+	renter := tg.Renters()[0]
+	params := modules.RenterPriceEstimationParams{
+		DataSize:     modules.SectorSize * 3,
+		DataPieces:   1,
+		ParityPieces: uint64(len(tg.Hosts())) - 1,
+		DownloadSize: modules.SectorSize,
+	}
+	rpg, err := renter.RenterPricesDetailedGet(params, modules.Allowance{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rpg.Hosts) != len(tg.Hosts()) {
+		t.Fatalf("Expected %v hosts in the breakdown, got %v", len(tg.Hosts()), len(rpg.Hosts))
+	}
+	hosts := make(map[string]struct{})
+	for _, h := range rpg.Hosts {
+		if _, exists := hosts[h.PublicKey.String()]; exists {
+			t.Fatal("Host was selected twice", h.PublicKey)
+		}
+		hosts[h.PublicKey.String()] = struct{}{}
+		if h.StorageCost.IsZero() || h.Total.IsZero() {
+			t.Fatal("Host costs should be priced", h)
+		}
+	}
+	if rpg.TotalLowerBound.Cmp(rpg.Total) > 0 || rpg.TotalUpperBound.Cmp(rpg.Total) < 0 {
+		t.Fatal("Total should be within the bounds", rpg.TotalLowerBound, rpg.Total, rpg.TotalUpperBound)
+	}
+
+	// Estimating without a data size should fail.
+	params.DataSize = 0
+	if _, err := renter.RenterPricesDetailedGet(params, modules.Allowance{}); err == nil {
+		t.Fatal("Expected estimation without data size to fail")
+	}
+}
+
+// testContractHistory tests that uploads and downloads are recorded in the
+// financial history of the renter's contracts.
+func testContractHistory(t *testing.T, tg *siatest.TestGroup) {
+	// Grab the first of the group's renters and upload and download a file.
+	renter := tg.Renters()[0]
+	dataPieces := uint64(1)
+	parityPieces := uint64(len(tg.Hosts())) - dataPieces
+	_, remoteFile, err := renter.UploadNewFileBlocking(100+siatest.Fuzz(), dataPieces, parityPieces)
+	if err != nil {
+		t.Fatal("Failed to upload a file for testing: ", err)
+	}
+	if _, err := renter.DownloadByStream(remoteFile); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every active contract should have a history. The last ledger entry
+	// should match the contract.
+	rc, err := renter.RenterContractsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rch, err := renter.RenterContractHistoryGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	history := make(map[types.FileContractID]modules.ContractHistory)
+	for _, h := range rch.Contracts {
+		history[h.ID] = h
+	}
+	var uploaded, downloaded bool
+	for _, c := range rc.ActiveContracts {
+		h, exists := history[c.ID]
+		if !exists {
+			t.Fatal("No history for contract", c.ID)
+		}
+		if len(h.Ledger) == 0 {
+			t.Fatal("Ledger of contract is empty", c.ID)
+		}
+		last := h.Ledger[len(h.Ledger)-1]
+		if last.RenterFunds.Cmp(c.RenterFunds) != 0 || last.UploadSpending.Cmp(c.UploadSpending) != 0 {
+			t.Fatalf("Ledger doesn't match contract: %v %v", last, c)
+		}
+		uploaded = uploaded || !last.UploadSpending.IsZero()
+		downloaded = downloaded || !last.DownloadSpending.IsZero()
+		if h.Resolved {
+			t.Fatal("Active contract shouldn't be resolved")
+		}
+	}
+	if !uploaded || !downloaded {
+		t.Fatal("Upload or download wasn't recorded in the ledger")
+	}
+
+	// The CSV export should have a header and a row for every ledger entry.
+	data, err := renter.RenterContractHistoryCSVGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries int
+	for _, h := range rch.Contracts {
+		entries += len(h.Ledger)
+	}
+	if rows := strings.Count(string(data), "\n"); rows != entries+1 {
+		t.Fatalf("Expected %v rows, got %v", entries+1, rows)
+	}
+}
+
 // testDownloadAfterRenew makes sure that we can still download a file
 // after the contract period has ended.
 func testDownloadAfterRenew(t *testing.T, tg *siatest.TestGroup) {
@@ -1875,6 +1984,143 @@ func TestRenterContracts(t *testing.T) {
 	}
 }
 
+// TestRenterManualContracts tests forming, renewing, refreshing and locking
+// contracts manually.
+func TestRenterManualContracts(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a group with 2 hosts.
+	groupParams := siatest.GroupParams{
+		Hosts:  2,
+		Miners: 1,
+	}
+	testDir := siatest.TestDir(t.Name())
+	tg, err := siatest.NewGroupFromTemplate(testDir, groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group:", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Add a renter that only forms a contract with 1 of the hosts.
+	allowance := siatest.DefaultAllowance
+	allowance.Hosts = 1
+	renterParams := node.Renter(testDir + "/renter")
+	renterParams.Allowance = allowance
+	nodes, err := tg.AddNodes(renterParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := nodes[0]
+
+	// Find the host the renter doesn't have a contract with.
+	rc, err := r.RenterContractsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rc.ActiveContracts) != 1 {
+		t.Fatalf("Expected 1 active contract, got %v", len(rc.ActiveContracts))
+	}
+	var hostKey types.SiaPublicKey
+	for _, h := range tg.Hosts() {
+		pk, err := h.HostPublicKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pk.String() != rc.ActiveContracts[0].HostPublicKey.String() {
+			hostKey = pk
+		}
+	}
+
+	// Forming a contract with more money than the allowance has left should
+	// fail.
+	if _, err := r.RenterContractFormPost(hostKey, allowance.Funds, 0); err == nil {
+		t.Fatal("Expected forming a contract exceeding the allowance to fail")
+	}
+
+	// Form a contract with the host.
+	funds := allowance.Funds.Div64(10)
+	contract, err := r.RenterContractFormPost(hostKey, funds, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contract.HostPublicKey.String() != hostKey.String() {
+		t.Fatal("Contract was formed with the wrong host")
+	}
+	if !contract.GoodForUpload || !contract.GoodForRenew {
+		t.Fatal("Manually formed contract should be good for upload and renew")
+	}
+	if _, err := r.RenterContractFormPost(hostKey, funds, 0); err == nil {
+		t.Fatal("Expected forming a second contract with the same host to fail")
+	}
+
+	// Refresh the contract. The new contract should have the same end height
+	// and the old one should be inactive.
+	refreshed, err := r.RenterContractRefreshPost(contract.ID, funds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.ID == contract.ID || refreshed.EndHeight != contract.EndHeight {
+		t.Fatalf("Unexpected refreshed contract: id %v, end height %v", refreshed.ID, refreshed.EndHeight)
+	}
+	rc, err = r.RenterInactiveContractsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, c := range rc.InactiveContracts {
+		found = found || c.ID == contract.ID
+	}
+	if !found {
+		t.Fatal("Refreshed contract should be inactive")
+	}
+
+	// Renew the contract with a later end height.
+	renewed, err := r.RenterContractRenewPost(refreshed.ID, funds, refreshed.EndHeight+10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renewed.EndHeight != refreshed.EndHeight+10 {
+		t.Fatalf("Expected end height %v, got %v", refreshed.EndHeight+10, renewed.EndHeight)
+	}
+	if _, err := r.RenterContractRenewPost(renewed.ID, funds, renewed.EndHeight-1); err == nil {
+		t.Fatal("Expected renewing with a lower end height to fail")
+	}
+
+	// Cancel the contract and unlock its utility. The contractor should mark
+	// it as good for renew again.
+	if err := r.RenterContractCancelPost(renewed.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RenterContractUtilityPost(renewed.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		if err := tg.Miners()[0].MineBlock(); err != nil {
+			return err
+		}
+		rc, err := r.RenterContractsGet()
+		if err != nil {
+			return err
+		}
+		for _, c := range rc.ActiveContracts {
+			if c.ID == renewed.ID {
+				return nil
+			}
+		}
+		return errors.New("unlocked contract is not active")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 // TestRenterLosingHosts tests that hosts will be replaced if they go offline
 // and downloads will succeed with hosts going offline until the redundancy
 // drops below 1
@@ -3132,3 +3378,188 @@ func testSetFileTrackingPath(t *testing.T, tg *siatest.TestGroup) {
 		t.Fatal("Changing repair path to a nonexistent file shouldn't work")
 	}
 }
+
+// TestRenterProfiles tests that renter profiles have their own allowance,
+// contracts and files.
+func TestRenterProfiles(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a group with 2 hosts and a renter that only forms a contract
+	// with 1 of them.
+	groupParams := siatest.GroupParams{
+		Hosts:  2,
+		Miners: 1,
+	}
+	testDir := siatest.TestDir(t.Name())
+	tg, err := siatest.NewGroupFromTemplate(testDir, groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group:", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	allowance := siatest.DefaultAllowance
+	allowance.Hosts = 1
+	renterParams := node.Renter(filepath.Join(testDir, "renter"))
+	renterParams.Allowance = allowance
+	nodes, err := tg.AddNodes(renterParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := nodes[0]
+
+	// Create a profile and a client that uses it.
+	if err := r.RenterProfileCreatePost("backup"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RenterProfileCreatePost("backup"); err == nil {
+		t.Fatal("Creating the same profile twice should fail")
+	}
+	if err := r.RenterProfileCreatePost("in/valid"); err == nil {
+		t.Fatal("Creating a profile with an invalid name should fail")
+	}
+	pc := r.Client
+	pc.RenterProfile = "backup"
+
+	// The profile shouldn't have an allowance or contracts yet.
+	rg, err := pc.RenterGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rg.Settings.Allowance, modules.Allowance{}) {
+		t.Fatal("New profile shouldn't have an allowance", rg.Settings.Allowance)
+	}
+
+	// Set an allowance for the profile that forms contracts with both hosts.
+	profileAllowance := siatest.DefaultAllowance
+	profileAllowance.Hosts = 2
+	if err := pc.RenterPostAllowance(profileAllowance); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		rc, err := pc.RenterContractsGet()
+		if err != nil {
+			return err
+		}
+		if len(rc.ActiveContracts) != 2 {
+			return fmt.Errorf("Expected 2 contracts for the profile, got %v", len(rc.ActiveContracts))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The default renter should still have its own allowance and contract.
+	rg, err = r.RenterGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rg.Settings.Allowance.Hosts != 1 {
+		t.Fatal("Allowance of the default renter changed", rg.Settings.Allowance)
+	}
+	rc, err := r.RenterContractsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rc.ActiveContracts) != 1 {
+		t.Fatalf("Expected 1 contract for the default renter, got %v", len(rc.ActiveContracts))
+	}
+
+	// Upload a file using the profile. It should only show up in the files of
+	// the profile.
+	path := filepath.Join(testDir, "profilefile")
+	if err := ioutil.WriteFile(path, fastrand.Bytes(100), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := pc.RenterUploadPost(path, "/profilefile", 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	rf, err := pc.RenterFilesGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rf.Files) != 1 {
+		t.Fatalf("Expected 1 file for the profile, got %v", len(rf.Files))
+	}
+	rf, err = r.RenterFilesGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rf.Files) != 0 {
+		t.Fatalf("Expected no files for the default renter, got %v", len(rf.Files))
+	}
+
+	// The profile can also be selected using the path.
+	resp, err := api.HttpGET("http://" + r.Address + "/renter/profile/backup/files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prf api.RenterFiles
+	err = json.NewDecoder(resp.Body).Decode(&prf)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prf.Files) != 1 {
+		t.Fatalf("Expected 1 file for the profile, got %v", len(prf.Files))
+	}
+
+	// Unknown profiles should be rejected.
+	uc := r.Client
+	uc.RenterProfile = "unknown"
+	if _, err := uc.RenterGet(); err == nil {
+		t.Fatal("Request for an unknown profile should fail")
+	}
+
+	// The profile can't be deleted while it has files and contracts, but an
+	// empty profile can.
+	if err := r.RenterProfileDeletePost("backup"); err == nil {
+		t.Fatal("Deleting a profile with files should fail")
+	}
+	if err := r.RenterProfileCreatePost("empty"); err != nil {
+		t.Fatal(err)
+	}
+	rp, err := r.RenterProfilesGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rp.Profiles, []string{"backup", "empty"}) {
+		t.Fatal("Unexpected profiles", rp.Profiles)
+	}
+	if err := r.RenterProfileDeletePost("empty"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The profile should be loaded again after a restart.
+	if err := r.RestartNode(); err != nil {
+		t.Fatal(err)
+	}
+	pc.Address = r.Address
+	rp, err = r.RenterProfilesGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rp.Profiles, []string{"backup"}) {
+		t.Fatal("Unexpected profiles after restart", rp.Profiles)
+	}
+	rc, err = pc.RenterContractsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rc.ActiveContracts) != 2 {
+		t.Fatalf("Expected 2 contracts for the profile after restart, got %v", len(rc.ActiveContracts))
+	}
+	rf, err = pc.RenterFilesGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rf.Files) != 1 {
+		t.Fatalf("Expected 1 file for the profile after restart, got %v", len(rf.Files))
+	}
+}
@@ -1,8 +1,18 @@
 package wallet
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -10,6 +20,7 @@ import (
 	"gitlab.com/NebulousLabs/Sia/crypto"
 	"gitlab.com/NebulousLabs/Sia/modules"
 	"gitlab.com/NebulousLabs/Sia/node"
+	"gitlab.com/NebulousLabs/Sia/node/api"
 	"gitlab.com/NebulousLabs/Sia/siatest"
 	"gitlab.com/NebulousLabs/Sia/types"
 )
@@ -430,3 +441,299 @@ func TestUnspentOutputs(t *testing.T) {
 		t.Fatal("shouldn't see addr in UnspentOutputs")
 	}
 }
+
+// TestTransactionFiltersAndCSV tests filtering the transactions of the wallet
+// by category and label, and exporting them as CSV.
+func TestTransactionFiltersAndCSV(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	// Create a new server
+	testNode, err := siatest.NewNode(node.AllModules(siatest.TestDir(t.Name())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := testNode.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Label an address, pay it and attach a note to the payment.
+	addr := types.UnlockHash{1}
+	if err := testNode.WalletLabelsPost(addr, "landlord"); err != nil {
+		t.Fatal(err)
+	}
+	wsp, err := testNode.WalletSiacoinsPost(types.SiacoinPrecision.Mul64(77), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txid := wsp.TransactionIDs[len(wsp.TransactionIDs)-1]
+	if err := testNode.WalletNotePost(txid, "rent, March"); err != nil {
+		t.Fatal(err)
+	}
+	if err := testNode.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	wlg, err := testNode.WalletLabelsGet()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(wlg.Labels) != 1 || wlg.Labels[0].Address != addr || wlg.Labels[0].Label != "landlord" {
+		t.Fatal("wrong labels", wlg.Labels)
+	}
+
+	// Only the payment is related to the labeled address.
+	wtg, err := testNode.WalletTransactionsFilteredGet(0, 1e6, "", "landlord")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wtg.ConfirmedTransactions) != 1 || wtg.ConfirmedTransactions[0].TransactionID != txid {
+		t.Fatal("wrong transactions for label", len(wtg.ConfirmedTransactions))
+	}
+	pt := wtg.ConfirmedTransactions[0]
+	if pt.Category != modules.CategorySend || pt.Note != "rent, March" {
+		t.Fatal("wrong category or note", pt.Category, pt.Note)
+	}
+
+	// The node mined all other blocks.
+	wtg, err = testNode.WalletTransactionsFilteredGet(0, 1e6, modules.CategoryMinerPayout, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wtg.ConfirmedTransactions) == 0 {
+		t.Fatal("no miner payouts")
+	}
+	for _, pt := range wtg.ConfirmedTransactions {
+		if pt.Category != modules.CategoryMinerPayout {
+			t.Fatal("wrong category", pt.Category)
+		}
+	}
+	if _, err := testNode.WalletTransactionsFilteredGet(0, 1e6, "foo", ""); err == nil {
+		t.Fatal("expected error for unknown category")
+	}
+
+	// The CSV export contains a header and the payment.
+	b, err := testNode.WalletTransactionsCSVGet(0, 1e6, modules.CategorySend, "landlord")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0][0] != "transactionid" {
+		t.Fatalf("unexpected csv: %s", b)
+	}
+	record := records[1]
+	if record[0] != txid.String() || record[3] != "send" || record[7] != "landlord" || record[8] != "rent, March" {
+		t.Fatalf("unexpected csv record: %v", record)
+	}
+}
+
+// TestWebhooksAPI tests registering a webhook through the API and receiving
+// signed events.
+func TestWebhooksAPI(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	// Create a new server
+	testNode, err := siatest.NewNode(node.AllModules(siatest.TestDir(t.Name())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := testNode.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Record the bodies and signatures of the events.
+	var mu sync.Mutex
+	var bodies, signatures []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		signatures = append(signatures, req.Header.Get("Sia-Webhook-Signature"))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	// Register a webhook for received payments, the miner payouts of the
+	// mined blocks.
+	wh, err := testNode.WalletWebhooksPost(modules.Webhook{
+		URL:    srv.URL,
+		Events: []modules.WebhookEventType{modules.WebhookReceived},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wh.Secret == "" || wh.Confirmations == 0 {
+		t.Fatal("secret or confirmations not set", wh)
+	}
+	wwg, err := testNode.WalletWebhooksGet()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(wwg.Webhooks) != 1 || wwg.Webhooks[0].ID != wh.ID {
+		t.Fatal("wrong webhooks", wwg.Webhooks)
+	}
+	if err := testNode.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The payout is delivered and signed with the secret.
+	var body, signature string
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(bodies) == 0 {
+			return errors.New("no event delivered")
+		}
+		body, signature = bodies[0], signatures[0]
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var event modules.WebhookEvent
+	if err := json.Unmarshal([]byte(body), &event); err != nil {
+		t.Fatal(err)
+	}
+	mac := hmac.New(sha256.New, []byte(wh.Secret))
+	mac.Write([]byte(body))
+	if signature != hex.EncodeToString(mac.Sum(nil)) {
+		t.Fatal("wrong signature")
+	}
+	if event.Type != modules.WebhookReceived || len(event.Outputs) == 0 ||
+		event.Outputs[0].FundType != types.SpecifierMinerPayout {
+		t.Fatal("wrong event", event)
+	}
+
+	// The delivery is logged until the webhook is removed.
+	err = build.Retry(50, 100*time.Millisecond, func() error {
+		wwdg, err := testNode.WalletWebhookDeliveriesGet(wh.ID)
+		if err != nil {
+			return err
+		} else if len(wwdg.Deliveries) == 0 || !wwdg.Deliveries[0].Delivered || wwdg.Deliveries[0].StatusCode != http.StatusOK {
+			return errors.New("delivery not logged")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := testNode.WalletWebhooksRemovePost(wh.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testNode.WalletWebhookDeliveriesGet(wh.ID); err == nil {
+		t.Fatal("expected error for removed webhook")
+	}
+}
+
+// TestNamedWalletsAPI tests managing and using named wallets through the API.
+func TestNamedWalletsAPI(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	// Create a new server
+	testNode, err := siatest.NewNode(node.AllModules(siatest.TestDir(t.Name())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := testNode.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Create a named wallet. Names of wallet endpoints are reserved.
+	if err := testNode.WalletsCreatePost("seeds"); err == nil {
+		t.Fatal("Creating a wallet with a reserved name should fail")
+	}
+	if err := testNode.WalletsCreatePost("hot"); err != nil {
+		t.Fatal(err)
+	}
+	nw, err := testNode.WalletsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nw.Wallets) != 1 || nw.Wallets[0] != "hot" {
+		t.Fatal("wrong named wallets", nw.Wallets)
+	}
+
+	// Initialize and unlock the named wallet using the header.
+	hc := testNode.Client
+	hc.NamedWallet = "hot"
+	wg, err := hc.WalletGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wg.Encrypted {
+		t.Fatal("New named wallet shouldn't be encrypted")
+	}
+	if _, err := hc.WalletInitPost("hotpassword", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := hc.WalletUnlockPost("hotpassword"); err != nil {
+		t.Fatal(err)
+	}
+	unknown := testNode.Client
+	unknown.NamedWallet = "unknown"
+	if _, err := unknown.WalletGet(); err == nil {
+		t.Fatal("Selecting an unknown wallet should fail")
+	}
+
+	// Get an address of the named wallet using the path prefix.
+	req, err := testNode.NewRequest("GET", "/wallet/hot/address", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wag api.WalletAddressGET
+	err = json.NewDecoder(resp.Body).Decode(&wag)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Pay the named wallet from the primary wallet.
+	if _, err := testNode.WalletSiacoinsPost(types.SiacoinPrecision, wag.Address); err != nil {
+		t.Fatal(err)
+	}
+	if err := testNode.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		wg, err := hc.WalletGet()
+		if err != nil {
+			return err
+		}
+		if !wg.ConfirmedSiacoinBalance.Equals(types.SiacoinPrecision) {
+			return errors.New("wrong balance of the named wallet: " + wg.ConfirmedSiacoinBalance.String())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wag2, err := testNode.WalletAddressesGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range wag2.Addresses {
+		if addr == wag.Address {
+			t.Fatal("The primary wallet shouldn't own the address of the named wallet")
+		}
+	}
+
+	// The named wallet can't be deleted while it has coins.
+	if err := testNode.WalletsDeletePost("hot"); err == nil {
+		t.Fatal("Deleting a wallet with coins should fail")
+	}
+}